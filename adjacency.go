@@ -0,0 +1,78 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// adjacencyPattern returns the cache pattern for a join lookup that lists
+// one node's edges with one predicate, as a Navigator's ArchOut and ArchIn
+// steps do: a concrete predicate, exactly one of subject and object
+// concrete, and no IN clause, filter, limit, offset or reverse. The other
+// field is a wildcard, so lookups binding different variables share entries.
+func adjacencyPattern(p *graph.Pattern) (*graph.Pattern, bool) {
+	if p.Filter != nil || p.Limit > 0 || p.Offset > 0 || p.Reverse || len(p.InFields()) > 0 {
+		return nil, false
+	}
+	predicate := p.GetConcreteValue("predicate")
+	subject := p.GetConcreteValue("subject")
+	object := p.GetConcreteValue("object")
+	switch {
+	case predicate == nil:
+		return nil, false
+	case subject != nil && object == nil:
+		return &graph.Pattern{Subject: graph.Exact(subject), Predicate: graph.Exact(predicate)}, true
+	case subject == nil && object != nil:
+		return &graph.Pattern{Predicate: graph.Exact(predicate), Object: graph.Exact(object)}, true
+	}
+	return nil, false
+}
+
+// joinLookup returns the triples matching a join step's pattern, served
+// from the adjacency cache when it is enabled and the step lists one node's
+// edges (see adjacencyPattern). idx is as for getUnlocked.
+// Caller must hold at least a read lock.
+func (db *DB) joinLookup(pattern *graph.Pattern, idx IndexName) ([]*graph.Triple, error) {
+	pattern = db.canonicalPattern(pattern)
+	if db.adjacency == nil {
+		return db.getUnlocked(pattern, idx)
+	}
+	edges, ok := adjacencyPattern(pattern)
+	if !ok {
+		return db.getUnlocked(pattern, idx)
+	}
+	key, _ := queryCacheKey(edges)
+	triples, hit, generation := db.adjacency.get(key)
+	if hit {
+		return triples, nil
+	}
+	triples, err := db.getUnlocked(edges, idx)
+	if err != nil {
+		return nil, err
+	}
+	db.adjacency.add(key, generation, edges, triples)
+	return triples, nil
+}
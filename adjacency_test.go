@@ -0,0 +1,144 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func setupAdjacencyCacheDB(t *testing.T, maxNodes int) (*DB, *iteratorCountingStore) {
+	t.Helper()
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	counting := &iteratorCountingStore{KVStore: store}
+	db, err := OpenWithDB(counting, WithAdjacencyCache(maxNodes))
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, counting
+}
+
+func TestAdjacencyCache_RepeatedTraversal(t *testing.T) {
+	t.Parallel()
+	db, store := setupAdjacencyCacheDB(t, 100)
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("carol", "knows", "bob"),
+		graph.NewTripleFromStrings("bob", "likes", "tennis"),
+		graph.NewTripleFromStrings("carol", "likes", "golf"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Who else knows the people alice knows, and what do they like?
+	traverse := func() []string {
+		t.Helper()
+		values, err := db.Nav(ctx, "alice").ArchOut("knows").ArchIn("knows").ArchOut("likes").Values()
+		if err != nil {
+			t.Fatalf("Values() error = %v", err)
+		}
+		var out []string
+		for _, v := range values {
+			out = append(out, string(v))
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	first := traverse()
+	if want := []string{"golf"}; !slices.Equal(first, want) {
+		t.Fatalf("first traversal = %v, want %v", first, want)
+	}
+	before := store.total
+	second := traverse()
+	if !slices.Equal(second, first) {
+		t.Errorf("second traversal = %v, want %v", second, first)
+	}
+	if n := store.total - before; n != 0 {
+		t.Errorf("second traversal opened %d iterators, want 0 (cached)", n)
+	}
+}
+
+func TestAdjacencyCache_InvalidatedByWrites(t *testing.T) {
+	t.Parallel()
+	db, store := setupAdjacencyCacheDB(t, 100)
+
+	ctx := context.Background()
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	count := func(nav *Navigator) int {
+		t.Helper()
+		n, err := nav.Count()
+		if err != nil {
+			t.Fatalf("Count() error = %v", err)
+		}
+		return n
+	}
+	count(db.Nav(ctx, "alice").ArchOut("knows"))
+	count(db.Nav(ctx, "bob").ArchIn("knows"))
+	count(db.Nav(ctx, "carol").ArchOut("knows"))
+
+	// Adding an edge invalidates both of its endpoints' entries.
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "knows", "dave")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if n := count(db.Nav(ctx, "alice").ArchOut("knows")); n != 2 {
+		t.Errorf("alice knows %d people after Put, want 2", n)
+	}
+	if n := count(db.Nav(ctx, "dave").ArchIn("knows")); n != 1 {
+		t.Errorf("dave is known by %d people after Put, want 1", n)
+	}
+
+	// Unrelated entries stay cached.
+	before := store.total
+	if n := count(db.Nav(ctx, "bob").ArchIn("knows")); n != 1 {
+		t.Errorf("bob is known by %d people, want 1", n)
+	}
+	if store.total != before {
+		t.Error("ArchIn(bob) after an unrelated Put was not served from the cache")
+	}
+
+	// Deleting an edge is visible at once.
+	if err := db.Del(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	if n := count(db.Nav(ctx, "bob").ArchIn("knows")); n != 0 {
+		t.Errorf("bob is known by %d people after Del, want 0", n)
+	}
+	if n := count(db.Nav(ctx, "alice").ArchOut("knows")); n != 1 {
+		t.Errorf("alice knows %d people after Del, want 1", n)
+	}
+}
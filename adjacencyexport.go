@@ -0,0 +1,177 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// Formats for AdjacencyExport.
+const (
+	// AdjacencyCSV writes one "row,col" line per edge, with zero-based
+	// node indexes.
+	AdjacencyCSV = "csv"
+	// AdjacencyMatrixMarket writes a Matrix Market coordinate pattern
+	// matrix, with one-based node indexes as the format requires.
+	AdjacencyMatrixMarket = "mtx"
+)
+
+// ErrUnknownExportFormat is returned by AdjacencyExport for a format other
+// than AdjacencyCSV or AdjacencyMatrixMarket.
+var ErrUnknownExportFormat = errors.New("levelgraph: unknown export format")
+
+// AdjacencyExport writes the edges of predicate to w as a sparse adjacency
+// matrix in format, AdjacencyCSV or AdjacencyMatrixMarket. Subjects are
+// rows and objects are columns, over one shared set of nodes: every
+// subject and object of the predicate, numbered in byte order. The nodes
+// are returned in index order, so nodes[i] is the node of index i in the
+// CSV output and of index i+1 in the Matrix Market output.
+//
+// The edges are streamed from the PSO index in two passes, one to number
+// the nodes and count the edges and one to write them, so only the nodes
+// are held in memory. Triples soft-deleted with SoftDel are left out.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	nodes, err := db.AdjacencyExport(ctx, []byte("knows"), &buf, levelgraph.AdjacencyMatrixMarket)
+//	// scipy.io.mmread(buf) gives a len(nodes) x len(nodes) matrix
+func (db *DB) AdjacencyExport(ctx context.Context, predicate []byte, w io.Writer, format string) ([][]byte, error) {
+	if format != AdjacencyCSV && format != AdjacencyMatrixMarket {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExportFormat, format)
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	byPredicate := db.canonicalPattern(&graph.Pattern{Predicate: graph.Exact(predicate)})
+	prefix := index.GenKeyFromPattern(index.IndexPSO, byPredicate)
+	// One iterator for both passes, so they read the same edges
+	iter := db.store.NewIterator(&Range{Start: prefix, Limit: prefixLimit(prefix)}, nil)
+	defer iter.Release()
+
+	// First pass: collect the nodes and count the edges
+	seen := make(map[string]int)
+	edges := 0
+	err := db.eachAdjacencyEdge(ctx, iter, func(triple *graph.Triple) error {
+		seen[string(triple.Subject)] = 0
+		seen[string(triple.Object)] = 0
+		edges++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	nodes := make([][]byte, len(names))
+	for i, name := range names {
+		nodes[i] = []byte(name)
+		seen[name] = i
+	}
+
+	// Second pass: write the edges
+	base := 0
+	bw := bufio.NewWriter(w)
+	if format == AdjacencyMatrixMarket {
+		base = 1
+		bw.WriteString("%%MatrixMarket matrix coordinate pattern general\n")
+		fmt.Fprintf(bw, "%d %d %d\n", len(nodes), len(nodes), edges)
+	}
+	sep := byte(',')
+	if format == AdjacencyMatrixMarket {
+		sep = ' '
+	}
+	var line []byte
+	err = db.eachAdjacencyEdge(ctx, iter, func(triple *graph.Triple) error {
+		line = strconv.AppendInt(line[:0], int64(seen[string(triple.Subject)]+base), 10)
+		line = append(line, sep)
+		line = strconv.AppendInt(line, int64(seen[string(triple.Object)]+base), 10)
+		line = append(line, '\n')
+		_, err := bw.Write(line)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, fmt.Errorf("levelgraph: adjacency export: %w", err)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("adjacency export", "predicate", string(predicate), "nodes", len(nodes), "edges", edges)
+	}
+	return nodes, nil
+}
+
+// eachAdjacencyEdge calls fn for every live triple of iter, from its first
+// key on.
+// Caller must hold at least a read lock.
+func (db *DB) eachAdjacencyEdge(ctx context.Context, iter Iterator, fn func(*graph.Triple) error) error {
+	skipDeleted := db.tombstones.Load()
+	count := 0
+	for ok := iter.First(); ok; ok = iter.Next() {
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("levelgraph: %w", err)
+			}
+		}
+		count++
+
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return fmt.Errorf("levelgraph: adjacency export: %w", err)
+		}
+		if skipDeleted {
+			if _, err := db.store.Get(genTombstoneKey(triple), nil); err == nil {
+				continue
+			}
+		}
+		if err := fn(triple); err != nil {
+			return fmt.Errorf("levelgraph: adjacency export: %w", err)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("levelgraph: adjacency export: %w", err)
+	}
+	return nil
+}
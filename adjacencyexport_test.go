@@ -0,0 +1,101 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_AdjacencyExport(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	err := db.Put(ctx,
+		graph.NewTripleFromStrings("carol", "knows", "alice"),
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "knows", "carol"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("alice", "likes", "dave"),
+	)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{AdjacencyCSV, "0,1\n0,2\n1,2\n2,0\n"},
+		{AdjacencyMatrixMarket, "%%MatrixMarket matrix coordinate pattern general\n3 3 4\n1 2\n1 3\n2 3\n3 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			nodes, err := db.AdjacencyExport(ctx, []byte("knows"), &buf, tt.format)
+			if err != nil {
+				t.Fatalf("AdjacencyExport() error = %v", err)
+			}
+
+			wantNodes := []string{"alice", "bob", "carol"}
+			if len(nodes) != len(wantNodes) {
+				t.Fatalf("AdjacencyExport() nodes = %q, want %q", nodes, wantNodes)
+			}
+			for i, node := range nodes {
+				if string(node) != wantNodes[i] {
+					t.Errorf("nodes[%d] = %q, want %q", i, node, wantNodes[i])
+				}
+			}
+			if buf.String() != tt.want {
+				t.Errorf("AdjacencyExport() wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown predicate", func(t *testing.T) {
+		var buf bytes.Buffer
+		nodes, err := db.AdjacencyExport(ctx, []byte("hates"), &buf, AdjacencyMatrixMarket)
+		if err != nil {
+			t.Fatalf("AdjacencyExport() error = %v", err)
+		}
+		if len(nodes) != 0 || buf.String() != "%%MatrixMarket matrix coordinate pattern general\n0 0 0\n" {
+			t.Errorf("AdjacencyExport() = %q, wrote %q, want an empty matrix", nodes, buf.String())
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := db.AdjacencyExport(ctx, []byte("knows"), &buf, "graphml")
+		if !errors.Is(err, ErrUnknownExportFormat) {
+			t.Errorf("AdjacencyExport() error = %v, want ErrUnknownExportFormat", err)
+		}
+	})
+}
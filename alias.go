@@ -0,0 +1,155 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// canonicalTriples rewrites each triple whose predicate is an alias in
+// Options.PredicateAliases to use the canonical predicate. Rewritten
+// triples are copies; the caller's triples are never modified.
+func (db *DB) canonicalTriples(triples []*graph.Triple) []*graph.Triple {
+	if len(db.options.PredicateAliases) == 0 {
+		return triples
+	}
+
+	var result []*graph.Triple
+	for i, triple := range triples {
+		canonical, ok := db.options.PredicateAliases[string(triple.Predicate)]
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = append([]*graph.Triple(nil), triples...)
+		}
+		rewritten := *triple
+		rewritten.Predicate = []byte(canonical)
+		result[i] = &rewritten
+	}
+	if result == nil {
+		return triples
+	}
+	return result
+}
+
+// canonicalTriple is canonicalTriples for a single triple.
+func (db *DB) canonicalTriple(triple *graph.Triple) *graph.Triple {
+	return db.canonicalTriples([]*graph.Triple{triple})[0]
+}
+
+// canonicalPredicate returns the canonical predicate for predicate, or
+// predicate itself when it is not an alias.
+func (db *DB) canonicalPredicate(predicate []byte) []byte {
+	if canonical, ok := db.options.PredicateAliases[string(predicate)]; ok {
+		return []byte(canonical)
+	}
+	return predicate
+}
+
+// canonicalInverses rewrites the predicates in options.InversePredicates to
+// their canonical form. Put canonicalizes triples before adding inverses,
+// so an inverse registered under an alias would otherwise never apply. An
+// entry registered under the canonical predicate itself takes precedence.
+func canonicalInverses(options *Options) {
+	aliases := options.PredicateAliases
+	if len(aliases) == 0 || len(options.InversePredicates) == 0 {
+		return
+	}
+	canonical := func(predicate string) string {
+		if c, ok := aliases[predicate]; ok {
+			return c
+		}
+		return predicate
+	}
+
+	inverses := make(map[string]string, len(options.InversePredicates))
+	for predicate, inverse := range options.InversePredicates {
+		if _, ok := aliases[predicate]; !ok {
+			inverses[predicate] = canonical(inverse)
+		}
+	}
+	for predicate, inverse := range options.InversePredicates {
+		if c, ok := aliases[predicate]; ok {
+			if _, exists := inverses[c]; !exists {
+				inverses[c] = canonical(inverse)
+			}
+		}
+	}
+	options.InversePredicates = inverses
+}
+
+// canonicalPattern returns pattern with an aliased exact predicate, or
+// aliases in PredicateIn, replaced by their canonical predicate, so a query
+// for an alias matches the triples stored under the canonical form. pattern
+// itself is returned when nothing needs rewriting.
+func (db *DB) canonicalPattern(pattern *graph.Pattern) *graph.Pattern {
+	aliases := db.options.PredicateAliases
+	if len(aliases) == 0 {
+		return pattern
+	}
+
+	rewritten := *pattern
+	changed := false
+	if pattern.Predicate.IsExact() {
+		if canonical, ok := aliases[string(pattern.Predicate.Data())]; ok {
+			rewritten.Predicate = graph.ExactString(canonical)
+			changed = true
+		}
+	}
+	if pattern.PredicateIn != nil {
+		in := make([][]byte, 0, len(pattern.PredicateIn))
+		for _, predicate := range pattern.PredicateIn {
+			if canonical, ok := aliases[string(predicate)]; ok {
+				predicate = []byte(canonical)
+				changed = true
+			}
+			if !slices.ContainsFunc(in, func(v []byte) bool { return bytes.Equal(v, predicate) }) {
+				in = append(in, predicate)
+			}
+		}
+		rewritten.PredicateIn = in
+	}
+	if !changed {
+		return pattern
+	}
+	return &rewritten
+}
+
+// canonicalPatterns applies canonicalPattern to each of patterns, returning
+// patterns itself when there are no aliases.
+func (db *DB) canonicalPatterns(patterns []*graph.Pattern) []*graph.Pattern {
+	if len(db.options.PredicateAliases) == 0 {
+		return patterns
+	}
+	result := make([]*graph.Pattern, len(patterns))
+	for i, pattern := range patterns {
+		result[i] = db.canonicalPattern(pattern)
+	}
+	return result
+}
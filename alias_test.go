@@ -0,0 +1,219 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/vector"
+)
+
+func TestDB_PredicateAlias(t *testing.T) {
+	t.Parallel()
+
+	for _, cached := range []bool{false, true} {
+		opts := []Option{WithPredicateAlias("knows", "is_friend_of")}
+		if cached {
+			opts = append(opts, WithQueryCache(16))
+		}
+		db, err := Open(filepath.Join(t.TempDir(), "test.db"), opts...)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer db.Close()
+
+		ctx := context.Background()
+		byPredicate := func(p string) []*graph.Triple {
+			t.Helper()
+			triples, err := db.Get(ctx, &graph.Pattern{Predicate: graph.ExactString(p)})
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			return triples
+		}
+
+		// Prime the cache before the write so a stale entry would show.
+		if got := byPredicate("is_friend_of"); len(got) != 0 {
+			t.Fatalf("cached=%v: Get(alias) before Put = %v, want none", cached, got)
+		}
+
+		if err := db.Put(ctx,
+			graph.NewTripleFromStrings("alice", "is_friend_of", "bob"),
+			graph.NewTripleFromStrings("bob", "knows", "carol"),
+		); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		want := []string{"alice knows bob", "bob knows carol"}
+		for _, p := range []string{"knows", "is_friend_of"} {
+			got := byPredicate(p)
+			if len(got) != len(want) {
+				t.Fatalf("cached=%v: Get(%q) returned %d triples, want %d", cached, p, len(got), len(want))
+			}
+			for i, triple := range got {
+				if s := string(triple.Subject) + " " + string(triple.Predicate) + " " + string(triple.Object); s != want[i] {
+					t.Errorf("cached=%v: Get(%q)[%d] = %q, want %q", cached, p, i, s, want[i])
+				}
+			}
+		}
+
+		in, err := db.Get(ctx, &graph.Pattern{PredicateIn: [][]byte{[]byte("is_friend_of"), []byte("knows")}})
+		if err != nil {
+			t.Fatalf("Get(PredicateIn) error = %v", err)
+		}
+		if len(in) != 2 {
+			t.Errorf("cached=%v: Get(PredicateIn) returned %d triples, want 2", cached, len(in))
+		}
+
+		solutions, err := db.Search(ctx, []*Pattern{
+			NewPattern(V("x"), "is_friend_of", V("y")),
+			NewPattern(V("y"), "is_friend_of", V("z")),
+		}, nil)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(solutions) != 1 || string(solutions[0]["z"]) != "carol" {
+			t.Errorf("cached=%v: Search() = %v, want z=carol", cached, solutions)
+		}
+
+		iter, err := db.SearchIterator(ctx, []*Pattern{NewPattern(V("x"), "is_friend_of", "carol")}, nil)
+		if err != nil {
+			t.Fatalf("SearchIterator() error = %v", err)
+		}
+		if !iter.Next() || string(iter.Solution()["x"]) != "bob" {
+			t.Errorf("cached=%v: SearchIterator() did not yield x=bob", cached)
+		}
+		iter.Close()
+
+		exists, err := db.ExistsMany(ctx, graph.NewTripleFromStrings("bob", "is_friend_of", "carol"))
+		if err != nil {
+			t.Fatalf("ExistsMany() error = %v", err)
+		}
+		if !exists[0] {
+			t.Errorf("cached=%v: ExistsMany(alias) = false, want true", cached)
+		}
+
+		if err := db.Del(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+			t.Fatalf("Del() error = %v", err)
+		}
+		if got := byPredicate("is_friend_of"); len(got) != 1 {
+			t.Errorf("cached=%v: Get(alias) after Del returned %d triples, want 1", cached, len(got))
+		}
+	}
+}
+
+func TestWithPredicateAlias(t *testing.T) {
+	t.Parallel()
+
+	opts := defaultOptions()
+	WithPredicateAlias("knows", "knows", "is_friend_of", "friendOf")(opts)
+
+	if len(opts.PredicateAliases) != 2 {
+		t.Fatalf("PredicateAliases = %v, want 2 entries", opts.PredicateAliases)
+	}
+	for _, alias := range []string{"is_friend_of", "friendOf"} {
+		if got := opts.PredicateAliases[alias]; got != "knows" {
+			t.Errorf("PredicateAliases[%q] = %q, want %q", alias, got, "knows")
+		}
+	}
+}
+
+func TestDB_PredicateAliasFacetsAndVectors(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+		WithPredicateAlias("knows", "is_friend_of"), WithFacets(), WithVectors(vector.NewFlatIndex(3)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alias := graph.NewTripleFromStrings("alice", "is_friend_of", "bob")
+	canonical := graph.NewTripleFromStrings("alice", "knows", "bob")
+	if err := db.Put(ctx, alias); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if err := db.SetTripleFacet(ctx, alias, []byte("since"), []byte("2020")); err != nil {
+		t.Fatalf("SetTripleFacet() error = %v", err)
+	}
+	if v, err := db.GetTripleFacet(ctx, canonical, []byte("since")); err != nil || string(v) != "2020" {
+		t.Errorf("GetTripleFacet(canonical) = %q, %v, want 2020", v, err)
+	}
+	if err := db.SetPairFacet(ctx, []byte("alice"), []byte("is_friend_of"), []byte("count"), []byte("1")); err != nil {
+		t.Fatalf("SetPairFacet() error = %v", err)
+	}
+	if v, err := db.GetPairFacet(ctx, []byte("alice"), []byte("knows"), []byte("count")); err != nil || string(v) != "1" {
+		t.Errorf("GetPairFacet(canonical) = %q, %v, want 1", v, err)
+	}
+	if err := db.SetTripleVector(ctx, alias, []float32{1, 0, 0}); err != nil {
+		t.Fatalf("SetTripleVector() error = %v", err)
+	}
+	if _, err := db.GetVector(ctx, TripleVectorID(canonical)); err != nil {
+		t.Errorf("GetVector(canonical) error = %v", err)
+	}
+
+	if err := db.Del(ctx, canonical); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	facets, err := db.GetTripleFacets(ctx, alias)
+	if err != nil {
+		t.Fatalf("GetTripleFacets() error = %v", err)
+	}
+	if len(facets) != 0 {
+		t.Errorf("GetTripleFacets(alias) after Del = %v, want none", facets)
+	}
+	if db.VectorCount() != 0 {
+		t.Errorf("VectorCount() after Del = %d, want 0", db.VectorCount())
+	}
+}
+
+func TestDB_PredicateAliasInverse(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+		WithInversePredicates(map[string]string{"parent": "childOf"}),
+		WithPredicateAlias("parentOf", "parent"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "parentOf", "bob")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	exists, err := db.ExistsMany(ctx, graph.NewTripleFromStrings("bob", "childOf", "alice"))
+	if err != nil {
+		t.Fatalf("ExistsMany() error = %v", err)
+	}
+	if !exists[0] {
+		t.Error("inverse registered under an alias was not written")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func bulkTriples(n int) []*graph.Triple {
+	triples := make([]*graph.Triple, n)
+	for i := range triples {
+		triples[i] = graph.NewTripleFromStrings(
+			fmt.Sprintf("subject%d", i%997),
+			fmt.Sprintf("predicate%d", i%7),
+			fmt.Sprintf("object%06d", i),
+		)
+	}
+	return triples
+}
+
+func TestDB_BulkLoadSorted(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupJournalDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	triples := bulkTriples(25000)
+	if err := db.BulkLoadSorted(ctx, triples); err != nil {
+		t.Fatalf("BulkLoadSorted() error = %v", err)
+	}
+
+	// Every triple is reachable through each index
+	for _, triple := range triples[:500] {
+		patterns := []*graph.Pattern{
+			graph.NewPattern(triple.Subject, triple.Predicate, triple.Object),
+			graph.NewPattern(nil, nil, triple.Object),
+			graph.NewPattern(nil, triple.Predicate, triple.Object),
+		}
+		for _, pattern := range patterns {
+			results, err := db.Get(ctx, pattern)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if len(results) != 1 || !results[0].Equal(triple) {
+				t.Fatalf("Get(%v) = %v, want [%v]", pattern, results, triple)
+			}
+		}
+	}
+
+	all, err := db.Get(ctx, &graph.Pattern{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(all) != len(triples) {
+		t.Errorf("Get() returned %d triples, want %d", len(all), len(triples))
+	}
+
+	count, err := db.JournalCount(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("JournalCount() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("JournalCount() = %d, want 0", count)
+	}
+}
+
+func TestDB_BulkLoadSortedInvalid(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	triples := []*graph.Triple{
+		graph.NewTripleFromStrings("a", "b", "c"),
+		{Subject: []byte("a"), Predicate: nil, Object: []byte("c")},
+	}
+	if err := db.BulkLoadSorted(ctx, triples); err == nil {
+		t.Fatal("BulkLoadSorted() error = nil, want validation error")
+	}
+
+	// Nothing is written when validation fails
+	results, err := db.Get(ctx, &graph.Pattern{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Get() returned %d triples, want 0", len(results))
+	}
+}
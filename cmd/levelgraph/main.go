@@ -25,14 +25,22 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
 
 	"github.com/benbenbenbenbenben/levelgraph"
+	"github.com/benbenbenbenbenben/levelgraph/memstore"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
 )
 
 func main() {
@@ -47,8 +55,14 @@ func main() {
 type CLI struct {
 	Out io.Writer // Output writer (default: os.Stdout)
 	Err io.Writer // Error writer (default: os.Stderr)
+
+	dryRun   bool // set by parseFlags from -dry-run
+	progress bool // set by parseFlags from -progress
 }
 
+// loadBatchSize is the number of triples load writes per batch.
+const loadBatchSize = 1000
+
 // Run executes the CLI with the given arguments and returns an exit code.
 func (c *CLI) Run(args []string) int {
 	if len(args) < 1 {
@@ -69,6 +83,8 @@ func (c *CLI) Run(args []string) int {
 		err = c.runDump(cmdArgs)
 	case "load":
 		err = c.runLoad(cmdArgs)
+	case "verify":
+		err = c.runVerify(cmdArgs)
 	case "help", "-h", "--help":
 		c.printUsage()
 		return 0
@@ -94,12 +110,16 @@ Usage:
 Commands:
   put <subject> <predicate> <object>   Add a triple
   get <subject> <predicate> <object>   Get triples (use '*' as wildcard)
-  dump                                 Dump all triples
-  load <file>                          Load triples from a file (N-Triples format)
+  dump                                 Dump all triples, sorted by subject, predicate, object
+  load <file>                          Load triples from a file (N-Triples format),
+                                       printing the ID minted for each blank node
+  verify                               Check that all six indexes are consistent
   help                                 Show this help message
 
 Global Flags:
   -db <path>                           Path to database (default: levelgraph.db)
+  -dry-run                             Report what put and load would change without writing
+  -progress                            Report the running count while loading
 `)
 }
 
@@ -108,18 +128,34 @@ func (c *CLI) parseFlags(args []string) (*levelgraph.DB, []string, error) {
 	fs := flag.NewFlagSet("levelgraph", flag.ContinueOnError)
 	fs.SetOutput(c.Err)
 	dbPath := fs.String("db", "levelgraph.db", "Path to database")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "Report changes without writing")
+	fs.BoolVar(&c.progress, "progress", false, "Report the running count while loading")
 
 	if err := fs.Parse(args); err != nil {
 		return nil, nil, err
 	}
 
-	db, err := levelgraph.Open(*dbPath)
+	open := levelgraph.Open
+	if c.dryRun {
+		open = openForDryRun
+	}
+	db, err := open(*dbPath)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open database: %w", err)
 	}
 	return db, fs.Args(), nil
 }
 
+// openForDryRun opens the database at path read-only, so a dry run never
+// writes to it. A database that does not exist yet is planned against an
+// empty in-memory store rather than created.
+func openForDryRun(path string, opts ...levelgraph.Option) (*levelgraph.DB, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return levelgraph.OpenWithDB(memstore.New(), opts...)
+	}
+	return levelgraph.Open(path, append(opts, levelgraph.WithReadOnly())...)
+}
+
 func (c *CLI) runPut(args []string) error {
 	db, remaining, err := c.parseFlags(args)
 	if err != nil {
@@ -131,7 +167,17 @@ func (c *CLI) runPut(args []string) error {
 		return fmt.Errorf("usage: levelgraph put <subject> <predicate> <object>")
 	}
 
-	err = db.Put(context.Background(), levelgraph.NewTripleFromStrings(remaining[0], remaining[1], remaining[2]))
+	triple := levelgraph.NewTripleFromStrings(remaining[0], remaining[1], remaining[2])
+	if c.dryRun {
+		plan := newDryRunPlan(db)
+		if err := plan.add(context.Background(), triple); err != nil {
+			return fmt.Errorf("failed to plan put: %w", err)
+		}
+		c.reportDryRun(plan.added)
+		return nil
+	}
+
+	err = db.Put(context.Background(), triple)
 	if err != nil {
 		return fmt.Errorf("failed to put triple: %w", err)
 	}
@@ -139,6 +185,45 @@ func (c *CLI) runPut(args []string) error {
 	return nil
 }
 
+// dryRunPlan counts the triples a put or load would add, without writing.
+// Each call to add is planned with DB.PlanPut, so predicate aliases and
+// inverse predicates count as Put would write them.
+type dryRunPlan struct {
+	db    *levelgraph.DB
+	seen  map[string]bool
+	added int
+}
+
+func newDryRunPlan(db *levelgraph.DB) *dryRunPlan {
+	return &dryRunPlan{db: db, seen: make(map[string]bool)}
+}
+
+// add plans a Put of triples, counting each triple it would add unless an
+// earlier call already counted it.
+func (p *dryRunPlan) add(ctx context.Context, triples ...*levelgraph.Triple) error {
+	plan, err := p.db.PlanPut(ctx, triples...)
+	if err != nil {
+		return err
+	}
+	for _, t := range plan.Added {
+		key := string(index.GenKey(index.IndexSPO, t))
+		if !p.seen[key] {
+			p.seen[key] = true
+			p.added++
+		}
+	}
+	return nil
+}
+
+// reportDryRun prints the number of triples a dry run would have added.
+func (c *CLI) reportDryRun(added int) {
+	noun := "triples"
+	if added == 1 {
+		noun = "triple"
+	}
+	fmt.Fprintf(c.Out, "Dry run: %d %s would be added.\n", added, noun)
+}
+
 func (c *CLI) runGet(args []string) error {
 	db, remaining, err := c.parseFlags(args)
 	if err != nil {
@@ -165,6 +250,7 @@ func (c *CLI) runGet(args []string) error {
 	}
 
 	for _, t := range triples {
+		t = t.Decoded()
 		fmt.Fprintf(c.Out, "%s %s %s\n", t.Subject, t.Predicate, t.Object)
 	}
 	return nil
@@ -182,12 +268,54 @@ func (c *CLI) runDump(args []string) error {
 		return fmt.Errorf("failed to dump triples: %w", err)
 	}
 
+	// Sort explicitly so dumps are reproducible and diffable regardless of
+	// which index served the scan.
+	slices.SortFunc(triples, func(a, b *levelgraph.Triple) int {
+		return cmp.Or(
+			bytes.Compare(a.Subject, b.Subject),
+			bytes.Compare(a.Predicate, b.Predicate),
+			bytes.Compare(a.Object, b.Object),
+		)
+	})
+
 	for _, t := range triples {
+		t = t.Decoded()
 		fmt.Fprintf(c.Out, "%s %s %s\n", t.Subject, t.Predicate, t.Object)
 	}
 	return nil
 }
 
+func (c *CLI) runVerify(args []string) error {
+	db, _, err := c.parseFlags(args)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	report, err := db.Verify(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to verify database: %w", err)
+	}
+
+	for _, m := range report.Missing {
+		fmt.Fprintf(c.Out, "missing %s entry for %s %s %s\n", m.Index, m.Triple.Subject, m.Triple.Predicate, m.Triple.Object)
+	}
+	if !report.OK() {
+		return fmt.Errorf("%d of %d triples have missing index entries", countTriples(report.Missing), report.Triples)
+	}
+	fmt.Fprintf(c.Out, "OK: %d triples verified\n", report.Triples)
+	return nil
+}
+
+// countTriples returns the number of distinct triples in missing.
+func countTriples(missing []levelgraph.MissingIndexKey) int {
+	seen := make(map[*levelgraph.Triple]bool)
+	for _, m := range missing {
+		seen[m.Triple] = true
+	}
+	return len(seen)
+}
+
 func (c *CLI) runLoad(args []string) error {
 	db, remaining, err := c.parseFlags(args)
 	if err != nil {
@@ -206,22 +334,150 @@ func (c *CLI) runLoad(args []string) error {
 	}
 	defer file.Close()
 
-	count, err := c.loadTriples(db, file)
+	// Stop cleanly on Ctrl-C, keeping what has been loaded so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if c.dryRun {
+		plan := newDryRunPlan(db)
+		var pending []*levelgraph.Triple
+		var lines []int
+		flush := func() {
+			if err := plan.add(ctx, pending...); err != nil {
+				// Fall back to one plan per triple to report the failing lines
+				for i, triple := range pending {
+					if err := plan.add(ctx, triple); err != nil {
+						fmt.Fprintf(c.Err, "Warning: line %d: failed to plan triple: %v\n", lines[i], err)
+					}
+				}
+			}
+			pending, lines = nil, nil
+		}
+		_, err := scanTriples(ctx, db, file, func(lineNum int, triple *levelgraph.Triple) error {
+			pending = append(pending, triple)
+			lines = append(lines, lineNum)
+			if len(pending) >= loadBatchSize {
+				flush()
+			}
+			return nil
+		})
+		flush()
+		if err != nil {
+			return fmt.Errorf("failed to plan load: %w", err)
+		}
+		c.reportDryRun(plan.added)
+		return nil
+	}
+
+	var progress func(int)
+	if c.progress {
+		progress = func(triplesLoaded int) {
+			fmt.Fprintf(c.Err, "Loaded %d triples...\n", triplesLoaded)
+		}
+	}
+
+	count, blankNodes, err := c.loadTriples(ctx, db, file, progress)
 	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Fprintf(c.Out, "Interrupted after loading %d triples.\n", count)
+			c.printBlankNodes(blankNodes)
+		}
 		return err
 	}
 
 	fmt.Fprintf(c.Out, "Loaded %d triples.\n", count)
+	c.printBlankNodes(blankNodes)
 	return nil
 }
 
-// loadTriples loads triples from an N-Triples format reader into the database.
-func (c *CLI) loadTriples(db *levelgraph.DB, r io.Reader) (int, error) {
-	scanner := bufio.NewScanner(r)
+// printBlankNodes prints the minted ID of each blank node label a load
+// rewrote, sorted by label, so the loaded nodes can be found again.
+func (c *CLI) printBlankNodes(blankNodes map[string][]byte) {
+	if len(blankNodes) == 0 {
+		return
+	}
+	labels := slices.Sorted(maps.Keys(blankNodes))
+	fmt.Fprintf(c.Out, "Blank nodes:\n")
+	for _, label := range labels {
+		fmt.Fprintf(c.Out, "  %s%s => %s\n", levelgraph.BlankNodePrefix, label, blankNodes[label])
+	}
+}
+
+// loadTriples loads triples from an N-Triples format reader into the database,
+// writing them in batches of loadBatchSize. If progress is non-nil it is
+// called with the running total after each batch is committed. The returned
+// map gives the minted ID for each blank node label (see scanTriples).
+//
+// If ctx is cancelled the triples read so far are committed, and the count is
+// returned along with ctx's error.
+func (c *CLI) loadTriples(ctx context.Context, db *levelgraph.DB, r io.Reader, progress func(triplesLoaded int)) (int, map[string][]byte, error) {
+	// Batches are written even after ctx is cancelled, so partial loads stick
+	writeCtx := context.WithoutCancel(ctx)
+
 	count := 0
+	var pending []*levelgraph.Triple
+	var lines []int
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := db.Put(writeCtx, pending...); err != nil {
+			// Fall back to one put per triple to report the failing lines
+			for i, triple := range pending {
+				if err := db.Put(writeCtx, triple); err != nil {
+					fmt.Fprintf(c.Err, "Warning: line %d: failed to put triple: %v\n", lines[i], err)
+				} else {
+					count++
+				}
+			}
+		} else {
+			count += len(pending)
+		}
+		pending, lines = nil, nil
+		if progress != nil {
+			progress(count)
+		}
+	}
+
+	blankNodes, err := scanTriples(ctx, db, r, func(lineNum int, triple *levelgraph.Triple) error {
+		pending = append(pending, triple)
+		lines = append(lines, lineNum)
+		if len(pending) >= loadBatchSize {
+			flush()
+		}
+		return nil
+	})
+	flush()
+	return count, blankNodes, err
+}
+
+// scanTriples parses an N-Triples format reader, calling fn with each triple
+// and its line number; an error from fn, or ctx being cancelled, stops the
+// scan. Blank node labels ("_:b1") are local to the reader: each label is
+// rewritten to a freshly minted blank node plus the label, consistently within
+// this reader. The returned map gives the minted ID for each label.
+func scanTriples(ctx context.Context, db *levelgraph.DB, r io.Reader, fn func(lineNum int, triple *levelgraph.Triple) error) (map[string][]byte, error) {
+	scanner := bufio.NewScanner(r)
 	lineNum := 0
+	blankNodes := make(map[string][]byte)
+
+	blankNode := func(term string) []byte {
+		label, ok := strings.CutPrefix(term, levelgraph.BlankNodePrefix)
+		if !ok {
+			return []byte(term)
+		}
+		id, ok := blankNodes[label]
+		if !ok {
+			id = append(db.MintBlankNode(), "-"+label...)
+			blankNodes[label] = id
+		}
+		return id
+	}
 
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return blankNodes, err
+		}
 		lineNum++
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -236,18 +492,15 @@ func (c *CLI) loadTriples(db *levelgraph.DB, r io.Reader) (int, error) {
 			obj := strings.Join(parts[2:], " ")
 			obj = strings.TrimSuffix(obj, " .")
 
-			err := db.Put(context.Background(), levelgraph.NewTripleFromStrings(sub, pred, obj))
-			if err != nil {
-				fmt.Fprintf(c.Err, "Warning: line %d: failed to put triple: %v\n", lineNum, err)
-			} else {
-				count++
+			if err := fn(lineNum, levelgraph.NewTriple(blankNode(sub), []byte(pred), blankNode(obj))); err != nil {
+				return blankNodes, err
 			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return count, fmt.Errorf("error reading input: %w", err)
+		return blankNodes, fmt.Errorf("error reading input: %w", err)
 	}
 
-	return count, nil
+	return blankNodes, nil
 }
@@ -25,10 +25,17 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+	"github.com/syndtr/goleveldb/leveldb"
 )
 
 func TestCLI_Help(t *testing.T) {
@@ -219,6 +226,263 @@ dave follows alice .
 	})
 }
 
+func TestCLI_LoadBlankNodes(t *testing.T) {
+	db, err := levelgraph.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	var errOut bytes.Buffer
+	cli := &CLI{Out: &bytes.Buffer{}, Err: &errOut}
+
+	first, firstBlanks, err := cli.loadTriples(context.Background(), db, strings.NewReader("_:b1 name alice .\n_:b1 knows _:b2 .\n"), nil)
+	if err != nil {
+		t.Fatalf("loadTriples failed: %v", err)
+	}
+	second, secondBlanks, err := cli.loadTriples(context.Background(), db, strings.NewReader("_:b1 name bob .\n"), nil)
+	if err != nil {
+		t.Fatalf("loadTriples failed: %v", err)
+	}
+	if first != 2 || second != 1 {
+		t.Fatalf("loaded %d and %d triples, want 2 and 1", first, second)
+	}
+	if len(firstBlanks) != 2 || len(secondBlanks) != 1 {
+		t.Fatalf("got %d and %d blank nodes, want 2 and 1", len(firstBlanks), len(secondBlanks))
+	}
+
+	b1 := firstBlanks["b1"]
+	if !strings.HasPrefix(string(b1), levelgraph.BlankNodePrefix) || !strings.HasSuffix(string(b1), "-b1") {
+		t.Errorf("minted blank node = %s, want _:<uuid>-b1", b1)
+	}
+	if bytes.Equal(b1, secondBlanks["b1"]) {
+		t.Fatalf("_:b1 collides across loads: %s", b1)
+	}
+
+	// Within the first document _:b1 is one node.
+	ctx := context.Background()
+	triples, err := db.Get(ctx, &levelgraph.Pattern{Subject: levelgraph.Exact(b1)})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(triples) != 2 {
+		t.Fatalf("expected 2 triples about %s, got %d", b1, len(triples))
+	}
+	knows, err := db.Get(ctx, &levelgraph.Pattern{Subject: levelgraph.Exact(b1), Predicate: levelgraph.ExactString("knows")})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(knows) != 1 || !bytes.Equal(knows[0].Object, firstBlanks["b2"]) {
+		t.Errorf("knows = %v, want object %s", knows, firstBlanks["b2"])
+	}
+
+	triples, err = db.Get(ctx, &levelgraph.Pattern{Subject: levelgraph.Exact(secondBlanks["b1"])})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(triples) != 1 || string(triples[0].Object) != "bob" {
+		t.Errorf("second document's _:b1 = %v, want one triple naming bob", triples)
+	}
+}
+
+func TestCLI_LoadPrintsBlankNodes(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	inputFile := filepath.Join(dir, "blanks.nt")
+	if err := os.WriteFile(inputFile, []byte("_:b1 name alice .\n"), 0644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	cli := &CLI{Out: &out, Err: &errOut}
+	if exitCode := cli.Run([]string{"load", "-db", dbPath, inputFile}); exitCode != 0 {
+		t.Fatalf("load failed with exit code %d, stderr: %s", exitCode, errOut.String())
+	}
+
+	_, mapping, ok := strings.Cut(out.String(), "_:b1 => ")
+	if !ok {
+		t.Fatalf("expected blank node mapping in output, got: %s", out.String())
+	}
+	minted := strings.TrimSpace(mapping)
+
+	out.Reset()
+	if exitCode := cli.Run([]string{"dump", "-db", dbPath}); exitCode != 0 {
+		t.Fatalf("dump failed with exit code %d, stderr: %s", exitCode, errOut.String())
+	}
+	if want := minted + " name alice\n"; out.String() != want {
+		t.Errorf("dump = %q, want %q", out.String(), want)
+	}
+}
+
+func TestCLI_LoadCancel(t *testing.T) {
+	db, err := levelgraph.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+
+	var input strings.Builder
+	const total = 5 * loadBatchSize
+	for i := 0; i < total; i++ {
+		fmt.Fprintf(&input, "s%d p o%d .\n", i, i)
+	}
+
+	// Cancel once the second batch is committed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var reports []int
+	progress := func(triplesLoaded int) {
+		reports = append(reports, triplesLoaded)
+		if triplesLoaded >= 2*loadBatchSize {
+			cancel()
+		}
+	}
+
+	cli := &CLI{Out: &bytes.Buffer{}, Err: &bytes.Buffer{}}
+	count, _, err := cli.loadTriples(ctx, db, strings.NewReader(input.String()), progress)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("loadTriples() error = %v, want context.Canceled", err)
+	}
+	if count != 2*loadBatchSize {
+		t.Errorf("loadTriples() count = %d, want %d", count, 2*loadBatchSize)
+	}
+	if len(reports) != 2 || reports[0] != loadBatchSize || reports[1] != 2*loadBatchSize {
+		t.Errorf("progress reports = %v, want [%d %d]", reports, loadBatchSize, 2*loadBatchSize)
+	}
+
+	// Everything counted is committed
+	triples, err := db.Get(context.Background(), &levelgraph.Pattern{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(triples) != count {
+		t.Errorf("database has %d triples, want %d", len(triples), count)
+	}
+}
+func TestCLI_DryRun(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out, errOut bytes.Buffer
+		cli := &CLI{Out: &out, Err: &errOut}
+		if exitCode := cli.Run(args); exitCode != 0 {
+			t.Fatalf("%v failed with exit code %d, stderr: %s", args, exitCode, errOut.String())
+		}
+		return out.String()
+	}
+
+	out := run("put", "-db", dbPath, "-dry-run", "alice", "knows", "bob")
+	if !strings.Contains(out, "1 triple would be added") {
+		t.Errorf("expected '1 triple would be added' in output, got: %s", out)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("dry run created the database, stat error = %v", err)
+	}
+	if out := run("dump", "-db", dbPath); out != "" {
+		t.Errorf("dry run wrote to the database, dump: %s", out)
+	}
+
+	// Triples already stored, or repeated in the input, are not counted.
+	run("put", "-db", dbPath, "alice", "knows", "bob")
+	inputFile := filepath.Join(t.TempDir(), "triples.nt")
+	input := "alice knows bob .\nbob knows carol .\nbob knows carol .\ncarol knows dave .\n"
+	if err := os.WriteFile(inputFile, []byte(input), 0644); err != nil {
+		t.Fatalf("failed to write input file: %v", err)
+	}
+	out = run("load", "-db", dbPath, "-dry-run", inputFile)
+	if !strings.Contains(out, "2 triples would be added") {
+		t.Errorf("expected '2 triples would be added' in output, got: %s", out)
+	}
+	if out := run("dump", "-db", dbPath); out != "alice knows bob\n" {
+		t.Errorf("dry run load wrote to the database, dump: %s", out)
+	}
+}
+
+func TestCLI_DumpDeterministic(t *testing.T) {
+	triples := []string{
+		"carol knows dave .",
+		"alice knows bob .",
+		"bob likes alice .",
+		"alice age 30 .",
+	}
+
+	dump := func(order []string) string {
+		t.Helper()
+		dir := t.TempDir()
+		dbPath := filepath.Join(dir, "test.db")
+		inputFile := filepath.Join(dir, "triples.nt")
+		if err := os.WriteFile(inputFile, []byte(strings.Join(order, "\n")+"\n"), 0644); err != nil {
+			t.Fatalf("failed to write input file: %v", err)
+		}
+		var out, errOut bytes.Buffer
+		cli := &CLI{Out: &out, Err: &errOut}
+		if exitCode := cli.Run([]string{"load", "-db", dbPath, inputFile}); exitCode != 0 {
+			t.Fatalf("load failed with exit code %d, stderr: %s", exitCode, errOut.String())
+		}
+		out.Reset()
+		if exitCode := cli.Run([]string{"dump", "-db", dbPath}); exitCode != 0 {
+			t.Fatalf("dump failed with exit code %d, stderr: %s", exitCode, errOut.String())
+		}
+		return out.String()
+	}
+
+	reversed := make([]string, len(triples))
+	for i, line := range triples {
+		reversed[len(triples)-1-i] = line
+	}
+
+	first, second := dump(triples), dump(reversed)
+	if first != second {
+		t.Errorf("dump depends on insertion order:\n%s\nvs\n%s", first, second)
+	}
+	want := "alice age 30\nalice knows bob\nbob likes alice\ncarol knows dave\n"
+	if first != want {
+		t.Errorf("dump = %q, want %q", first, want)
+	}
+}
+
+func TestCLI_Verify(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	var out, errOut bytes.Buffer
+	cli := &CLI{Out: &out, Err: &errOut}
+	if exitCode := cli.Run([]string{"put", "-db", dbPath, "alice", "knows", "bob"}); exitCode != 0 {
+		t.Fatalf("put failed with exit code %d, stderr: %s", exitCode, errOut.String())
+	}
+
+	out.Reset()
+	if exitCode := cli.Run([]string{"verify", "-db", dbPath}); exitCode != 0 {
+		t.Fatalf("verify failed with exit code %d, stderr: %s", exitCode, errOut.String())
+	}
+	if !strings.Contains(out.String(), "OK: 1 triples verified") {
+		t.Errorf("expected OK report, got: %s", out.String())
+	}
+
+	// Remove the OSP entry directly from the store.
+	store, err := leveldb.OpenFile(dbPath, nil)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	triple := levelgraph.NewTripleFromStrings("alice", "knows", "bob")
+	if err := store.Delete(index.GenKey(index.IndexOSP, triple), nil); err != nil {
+		t.Fatalf("failed to delete key: %v", err)
+	}
+	store.Close()
+
+	out.Reset()
+	errOut.Reset()
+	if exitCode := cli.Run([]string{"verify", "-db", dbPath}); exitCode != 1 {
+		t.Errorf("expected exit code 1 for inconsistent database, got %d", exitCode)
+	}
+	if !strings.Contains(out.String(), "missing osp entry for alice knows bob") {
+		t.Errorf("expected missing osp entry in output, got: %s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "1 of 1 triples have missing index entries") {
+		t.Errorf("expected summary in error output, got: %s", errOut.String())
+	}
+}
+
 func TestCLI_PutMissingArgs(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "levelgraph-cli-test")
 	if err != nil {
@@ -241,6 +505,32 @@ func TestCLI_PutMissingArgs(t *testing.T) {
 	}
 }
 
+func TestCLI_GetDecoded(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := levelgraph.Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.Put(context.Background(), levelgraph.NewTriple([]byte("alice"), []byte("age"), levelgraph.EncodeInt(30))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	db.Close()
+
+	for _, args := range [][]string{
+		{"get", "-db", dbPath, "alice", "age", "*"},
+		{"dump", "-db", dbPath},
+	} {
+		var out, errOut bytes.Buffer
+		cli := &CLI{Out: &out, Err: &errOut}
+		if exitCode := cli.Run(args); exitCode != 0 {
+			t.Fatalf("%s failed with exit code %d, stderr: %s", args[0], exitCode, errOut.String())
+		}
+		if got := out.String(); got != "alice age 30\n" {
+			t.Errorf("%s output = %q, want %q", args[0], got, "alice age 30\n")
+		}
+	}
+}
+
 func TestCLI_GetMissingArgs(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "levelgraph-cli-test")
 	if err != nil {
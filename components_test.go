@@ -0,0 +1,90 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_ConnectedComponents(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		// Cluster one, connected through edges in both directions
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("carol", "knows", "bob"),
+		graph.NewTripleFromStrings("carol", "knows", "dave"),
+		// Cluster two
+		graph.NewTripleFromStrings("erin", "knows", "frank"),
+		graph.NewTripleFromStrings("frank", "knows", "grace"),
+		// Only joined to cluster one by another predicate
+		graph.NewTripleFromStrings("dave", "likes", "grace"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	components, err := db.ConnectedComponents(ctx, []byte("knows"))
+	if err != nil {
+		t.Fatalf("ConnectedComponents() error = %v", err)
+	}
+	want := [][]string{
+		{"alice", "bob", "carol", "dave"},
+		{"erin", "frank", "grace"},
+	}
+	if len(components) != len(want) {
+		t.Fatalf("ConnectedComponents() = %s, want %v", components, want)
+	}
+	for i, members := range components {
+		if len(members) != len(want[i]) {
+			t.Fatalf("component %d = %s, want %v", i, members, want[i])
+		}
+		for j, m := range members {
+			if string(m) != want[i][j] {
+				t.Errorf("component %d = %s, want %v", i, members, want[i])
+				break
+			}
+		}
+	}
+
+	// With all predicates, the likes edge merges the clusters.
+	components, err = db.ConnectedComponents(ctx, nil)
+	if err != nil {
+		t.Fatalf("ConnectedComponents(nil) error = %v", err)
+	}
+	if len(components) != 1 || len(components[0]) != 7 {
+		t.Errorf("ConnectedComponents(nil) = %s, want one component of 7", components)
+	}
+
+	components, err = db.ConnectedComponents(ctx, []byte("unknown"))
+	if err != nil || len(components) != 0 {
+		t.Errorf("ConnectedComponents(unknown) = %s, %v, want none", components, err)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/snappy"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// compressedValuePrefix marks a snappy-compressed triple value. A triple's
+// binary form starts with a minimal uvarint, which never has a zero byte
+// after a continuation byte, so the prefix cannot start an uncompressed value.
+var compressedValuePrefix = []byte{0x80, 0x00}
+
+// encodeTripleValue returns the value stored for triple under its index
+// keys: its binary form, snappy-compressed when longer than
+// Options.ValueCompressionThreshold and smaller for it.
+func (db *DB) encodeTripleValue(triple *graph.Triple) ([]byte, error) {
+	value, err := triple.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := db.options.ValueCompressionThreshold
+	if threshold <= 0 || len(value) <= threshold {
+		return value, nil
+	}
+	compressed := append(bytes.Clone(compressedValuePrefix), snappy.Encode(nil, value)...)
+	if len(compressed) >= len(value) {
+		return value, nil
+	}
+	return compressed, nil
+}
+
+// decodeTripleValue decodes a value written by encodeTripleValue,
+// compressed or not, so values stay readable whatever the current options.
+func decodeTripleValue(value []byte) (*graph.Triple, error) {
+	if data, ok := bytes.CutPrefix(value, compressedValuePrefix); ok {
+		decoded, err := snappy.Decode(nil, data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+		}
+		value = decoded
+	}
+
+	var triple graph.Triple
+	if err := triple.UnmarshalBinary(value); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+	}
+	return &triple, nil
+}
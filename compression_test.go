@@ -0,0 +1,94 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+func TestDB_ValueCompression(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithValueCompression(256))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	doc := `{"name": "alice", "tags": [` + strings.Repeat(`"graph", "database", `, 200) + `"end"]}`
+	large := graph.NewTripleFromStrings("alice", "profile", doc)
+	small := graph.NewTripleFromStrings("alice", "age", "30")
+	if err := db.Put(ctx, large, small); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	raw := func(triple *graph.Triple) []byte {
+		t.Helper()
+		value, err := db.store.Get(index.GenKey(index.IndexSPO, triple), nil)
+		if err != nil {
+			t.Fatalf("store.Get() error = %v", err)
+		}
+		return value
+	}
+	if value := raw(large); !bytes.HasPrefix(value, compressedValuePrefix) || len(value) >= len(doc) {
+		t.Errorf("large value stored as %d bytes, want compressed below %d", len(value), len(doc))
+	}
+	if value, _ := small.MarshalBinary(); !bytes.Equal(raw(small), value) {
+		t.Errorf("small value = %q, want uncompressed %q", raw(small), value)
+	}
+
+	// Reads through every index decompress transparently.
+	for _, pattern := range []*graph.Pattern{
+		{Subject: graph.ExactString("alice"), Predicate: graph.ExactString("profile")},
+		{Object: graph.ExactString(doc)},
+	} {
+		triples, err := db.Get(ctx, pattern)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if len(triples) != 1 || !triples[0].Equal(large) {
+			t.Errorf("Get() = %d triples, want the large triple back", len(triples))
+		}
+	}
+
+	if err := db.Del(ctx, large); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	triples, err := db.Get(ctx, &graph.Pattern{Subject: graph.ExactString("alice")})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(triples) != 1 || !triples[0].Equal(small) {
+		t.Errorf("Get() after Del = %v, want only the small triple", triples)
+	}
+}
@@ -265,13 +265,13 @@ func TestTriple_Unmarshal_Error_Extra(t *testing.T) {
 	if err := tr.UnmarshalJSON([]byte(`{`)); err == nil {
 		t.Error("expected error")
 	}
-	if err := tr.UnmarshalJSON([]byte(`{"subject": "!!!"}`)); err == nil {
+	if err := tr.UnmarshalJSON([]byte(`{"subject": {"base64": "!!!"}}`)); err == nil {
 		t.Error("expected error")
 	}
-	if err := tr.UnmarshalJSON([]byte(`{"subject": "YQ==", "predicate": "!!!"}`)); err == nil {
+	if err := tr.UnmarshalJSON([]byte(`{"subject": "a", "predicate": {"base64": "!!!"}}`)); err == nil {
 		t.Error("expected error")
 	}
-	if err := tr.UnmarshalJSON([]byte(`{"subject": "YQ==", "predicate": "Yg==", "object": "!!!"}`)); err == nil {
+	if err := tr.UnmarshalJSON([]byte(`{"subject": "a", "predicate": "b", "object": {"base64": "!!!"}}`)); err == nil {
 		t.Error("expected error")
 	}
 }
@@ -201,6 +201,7 @@ func cmdFind(args []string) {
 	}
 
 	for _, t := range results {
+		t = t.Decoded()
 		fmt.Printf("%s → %s → %s\n", t.Subject, t.Predicate, t.Object)
 	}
 	fmt.Printf("\n(%d results)\n", len(results))
@@ -354,9 +355,9 @@ func cmdJoin(args []string) {
 	// Print results
 	for _, sol := range results {
 		var parts []string
-		for k, v := range sol {
+		for _, k := range sol.SortedKeys() {
 			if !strings.HasPrefix(k, "_wild_") {
-				parts = append(parts, fmt.Sprintf("%s=%s", k, v))
+				parts = append(parts, fmt.Sprintf("%s=%s", k, sol[k]))
 			}
 		}
 		fmt.Println(strings.Join(parts, ", "))
@@ -643,6 +644,7 @@ func cmdDump() {
 	}
 
 	for _, t := range results {
+		t = t.Decoded()
 		fmt.Printf("%s → %s → %s\n", t.Subject, t.Predicate, t.Object)
 	}
 	fmt.Printf("\n(%d triples)\n", len(results))
@@ -0,0 +1,108 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// ExistsMany reports which of triples are stored, as a slice parallel to
+// triples: result[i] is true when triples[i] exists and is not soft-deleted
+// with SoftDel. It looks up the SPO key
+// of each triple with a single iterator, seeking in key order, so triples
+// sharing a subject are found with one forward pass.
+//
+// Example:
+//
+//	exists, _ := db.ExistsMany(ctx, batch...)
+//	var delta []*levelgraph.Triple
+//	for i, t := range batch {
+//	    if !exists[i] {
+//	        delta = append(delta, t)
+//	    }
+//	}
+func (db *DB) ExistsMany(ctx context.Context, triples ...*graph.Triple) ([]bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	result := make([]bool, len(triples))
+	if len(triples) == 0 {
+		return result, nil
+	}
+
+	canonical := db.canonicalTriples(triples)
+	keys := make([][]byte, len(triples))
+	order := make([]int, len(triples))
+	for i, triple := range canonical {
+		keys[i] = index.GenKey(index.IndexSPO, triple)
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return bytes.Compare(keys[order[a]], keys[order[b]]) < 0
+	})
+
+	last := keys[order[len(order)-1]]
+	iter := db.store.NewIterator(&Range{
+		Start: keys[order[0]],
+		Limit: append(append([]byte(nil), last...), 0x00),
+	}, nil)
+	defer iter.Release()
+
+	for n, i := range order {
+		if n%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("levelgraph: %w", err)
+			}
+		}
+		if iter.Seek(keys[i]) && bytes.Equal(iter.Key(), keys[i]) {
+			deleted, err := db.softDeleted(canonical[i])
+			if err != nil {
+				return nil, fmt.Errorf("levelgraph: exists: %w", err)
+			}
+			result[i] = !deleted
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("levelgraph: exists: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_ExistsMany(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	stored := []*graph.Triple{
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "likes", "tennis"),
+		graph.NewTripleFromStrings("carol", "knows", "dave"),
+	}
+	if err := db.Put(ctx, stored...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	query := []*graph.Triple{
+		graph.NewTripleFromStrings("carol", "knows", "dave"),
+		graph.NewTripleFromStrings("alice", "knows", "carol"),
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("zed", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "likes", "tennis"),
+		graph.NewTripleFromStrings("alice", "likes", "tennis"),
+		graph.NewTripleFromStrings("alice", "likes", "tenni"),
+		graph.NewTripleFromStrings("a", "b", "c"),
+	}
+	got, err := db.ExistsMany(ctx, query...)
+	if err != nil {
+		t.Fatalf("ExistsMany() error = %v", err)
+	}
+	want := []bool{true, false, true, false, true, true, false, false}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExistsMany() = %v, want %v", got, want)
+	}
+
+	got, err = db.ExistsMany(ctx)
+	if err != nil {
+		t.Fatalf("ExistsMany() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ExistsMany() with no triples = %v, want empty", got)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := db.ExistsMany(canceled, stored...); err == nil {
+		t.Error("ExistsMany() with canceled context error = nil, want error")
+	}
+}
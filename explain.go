@@ -0,0 +1,170 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// QueryPlan is the plan a search actually ran, as recorded by
+// SearchWithPlan or SearchOptions.Explain. Unlike an estimate, its counts
+// are the real numbers of lookups, matches and partial solutions.
+type QueryPlan struct {
+	// Steps holds one step per pattern, in join order. Steps after one
+	// that produced no solutions are never run and have zero counts.
+	Steps []PlanStep
+	// VectorFirst is true when the VectorFilter's candidates were fetched
+	// first and the patterns joined once per candidate; the step counts
+	// are then summed over the candidates.
+	VectorFirst bool
+	// Solutions is the number of solutions returned, after filters,
+	// Offset and Limit.
+	Solutions int
+	// Duration is how long the search took.
+	Duration time.Duration
+}
+
+// PlanStep is the realized join of one pattern.
+type PlanStep struct {
+	// Pattern is the pattern as joined, with predicate aliases resolved.
+	Pattern *Pattern
+	// Index is the index its lookups scanned.
+	Index IndexName
+	// Lookups is the number of index lookups, one per partial solution
+	// coming from the previous steps.
+	Lookups int
+	// Triples is the number of triples the lookups matched.
+	Triples int
+	// Solutions is the number of partial solutions after this step, once
+	// pattern filters and SearchOptions.IncrementalFilter have run.
+	Solutions int
+}
+
+// addSteps starts a step for each pattern, unless an earlier join of the
+// same search already did. plan may be nil.
+func (p *QueryPlan) addSteps(patterns []*Pattern) {
+	if p == nil || len(p.Steps) > 0 {
+		return
+	}
+	p.Steps = make([]PlanStep, len(patterns))
+	for i, pattern := range patterns {
+		p.Steps[i].Pattern = pattern
+	}
+}
+
+// recordLookup counts a lookup of step i for pattern, which matched
+// triples. idx is the index the lookup was asked to use, if any; otherwise
+// the one chosen for pattern is recorded. plan may be nil.
+func (p *QueryPlan) recordLookup(i int, pattern *Pattern, idx IndexName, triples int) {
+	if p == nil {
+		return
+	}
+	step := &p.Steps[i]
+	if step.Index == "" {
+		step.Index = idx
+		if idx == "" {
+			step.Index = chooseIndex(pattern)
+		}
+	}
+	step.Lookups++
+	step.Triples += triples
+}
+
+// reset drops the steps recorded so far, when a vector-first attempt falls
+// back to the regular join. plan may be nil.
+func (p *QueryPlan) reset() {
+	if p != nil {
+		p.Steps = nil
+	}
+}
+
+// explainUnlocked runs the search recording its plan, including how long
+// it took, in plan.
+// Caller must hold at least a read lock.
+func (db *DB) explainUnlocked(ctx context.Context, patterns []*Pattern, indexes []IndexName, opts *SearchOptions, plan *QueryPlan) ([]Solution, error) {
+	start := time.Now()
+	solutions, err := db.searchPlanUnlocked(ctx, patterns, indexes, opts, plan)
+	plan.Duration = time.Since(start)
+	return solutions, err
+}
+
+// logPlan logs plan at debug level, one record per step.
+func (db *DB) logPlan(plan *QueryPlan) {
+	if db.options.Logger == nil {
+		return
+	}
+	for i, step := range plan.Steps {
+		db.options.Logger.Debug("search plan step",
+			"step", i,
+			"index", string(step.Index),
+			"lookups", step.Lookups,
+			"triples", step.Triples,
+			"solutions", step.Solutions,
+		)
+	}
+	db.options.Logger.Debug("search plan",
+		"steps", len(plan.Steps),
+		"vectorFirst", plan.VectorFirst,
+		"solutions", plan.Solutions,
+		"duration", plan.Duration,
+	)
+}
+
+// SearchWithPlan runs Search and returns its solutions together with the
+// plan it realized: the index each pattern's lookups used, the join order
+// and the real intermediate counts. Use it to log why a query was slow or
+// to check that a pattern order prunes early.
+//
+// Example:
+//
+//	solutions, plan, err := db.SearchWithPlan(ctx, patterns, nil)
+//	for i, step := range plan.Steps {
+//	    log.Printf("step %d: %s, %d lookups, %d solutions", i, step.Index, step.Lookups, step.Solutions)
+//	}
+func (db *DB) SearchWithPlan(ctx context.Context, patterns []*Pattern, opts *SearchOptions) (solutions []Solution, plan *QueryPlan, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search", start, len(solutions), err) }()
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	plan = &QueryPlan{}
+	solutions, err = db.explainUnlocked(ctx, patterns, nil, opts, plan)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts != nil && opts.Explain {
+		db.logPlan(plan)
+	}
+	return solutions, plan, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_SearchWithPlan(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupSocialGraph(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Six knows edges; the people they reach like 2+2+2+2+2+1 things
+	patterns := []*Pattern{
+		graph.NewPattern(graph.V("x"), "knows", graph.V("y")),
+		graph.NewPattern(graph.V("y"), "likes", graph.V("z")),
+	}
+	solutions, plan, err := db.SearchWithPlan(ctx, patterns, nil)
+	if err != nil {
+		t.Fatalf("SearchWithPlan() error = %v", err)
+	}
+	want, err := db.Search(ctx, patterns, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(solutions) != len(want) || len(solutions) != 11 {
+		t.Fatalf("SearchWithPlan() returned %d solutions, Search %d, want 11", len(solutions), len(want))
+	}
+
+	wantSteps := []PlanStep{
+		{Index: chooseIndex(graph.NewPattern(nil, "knows", nil)), Lookups: 1, Triples: 6, Solutions: 6},
+		{Index: chooseIndex(graph.NewPattern("bob", "likes", nil)), Lookups: 6, Triples: 11, Solutions: 11},
+	}
+	if len(plan.Steps) != len(wantSteps) {
+		t.Fatalf("plan has %d steps, want %d", len(plan.Steps), len(wantSteps))
+	}
+	for i, step := range plan.Steps {
+		if step.Pattern != patterns[i] {
+			t.Errorf("step %d pattern = %v, want patterns[%d]", i, step.Pattern, i)
+		}
+		step.Pattern = nil
+		if step != wantSteps[i] {
+			t.Errorf("step %d = %+v, want %+v", i, step, wantSteps[i])
+		}
+	}
+	if plan.Solutions != 11 || plan.VectorFirst {
+		t.Errorf("plan = %+v, want 11 solutions without VectorFirst", plan)
+	}
+
+	// Limit and a failing step show in the counts
+	limited, plan, err := db.SearchWithPlan(ctx, []*Pattern{
+		graph.NewPattern("alice", "knows", graph.V("y")),
+		graph.NewPattern(graph.V("y"), "livesIn", "Mars"),
+		graph.NewPattern(graph.V("y"), "likes", graph.V("z")),
+	}, &SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("SearchWithPlan() error = %v", err)
+	}
+	if len(limited) != 0 || plan.Solutions != 0 {
+		t.Errorf("SearchWithPlan() = %d solutions, plan %d, want 0", len(limited), plan.Solutions)
+	}
+	if got := plan.Steps[1]; got.Lookups != 2 || got.Triples != 0 || got.Solutions != 0 {
+		t.Errorf("step 1 = %+v, want 2 lookups and no matches", got)
+	}
+	if got := plan.Steps[2]; got.Lookups != 0 || got.Index != "" {
+		t.Errorf("step 2 = %+v, want it never run", got)
+	}
+}
+
+func TestSearchOptions_Explain(t *testing.T) {
+	t.Parallel()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	patterns := []*Pattern{graph.NewPattern(graph.V("x"), "knows", graph.V("y"))}
+	if _, err := db.Search(ctx, patterns, nil); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if strings.Contains(logs.String(), "search plan") {
+		t.Errorf("Search() without Explain logged a plan:\n%s", logs.String())
+	}
+	if _, err := db.Search(ctx, patterns, &SearchOptions{Explain: true}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "search plan step") || !strings.Contains(logs.String(), "solutions=1") {
+		t.Errorf("Search() with Explain logged:\n%s", logs.String())
+	}
+}
@@ -40,6 +40,9 @@ var (
 	// tripleFacetPrefix is the prefix for triple-level facets
 	tripleFacetPrefix = []byte("triple_facet::")
 
+	// pairFacetPrefix is the prefix for subject-predicate pair facets
+	pairFacetPrefix = []byte("pair_facet::")
+
 	// ErrFacetsDisabled is returned when facets operations are called but facets are not enabled.
 	ErrFacetsDisabled = errors.New("levelgraph: facets are not enabled")
 )
@@ -56,6 +59,17 @@ const (
 	FacetObject FacetType = "object"
 )
 
+// FacetMode controls how SetFacets and SetTripleFacets treat existing facets.
+type FacetMode int
+
+const (
+	// FacetMerge keeps existing facets that are not in the new set.
+	FacetMerge FacetMode = iota
+	// FacetReplace deletes existing facets that are not in the new set, so
+	// the facets afterwards are exactly the new set.
+	FacetReplace
+)
+
 // genFacetKey generates a key for a component facet.
 // Format: facet::<type>::<value>::<key>
 func genFacetKey(facetType FacetType, value []byte, key []byte) []byte {
@@ -109,6 +123,27 @@ func genTripleFacetPrefix(triple *graph.Triple) []byte {
 	return buf.Bytes()
 }
 
+// genPairFacetKey generates a key for a subject-predicate pair facet.
+// Format: pair_facet::<subject>::<predicate>::<key>
+func genPairFacetKey(subject, predicate, key []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(genPairFacetPrefix(subject, predicate))
+	buf.Write(index.Escape(key))
+	return buf.Bytes()
+}
+
+// genPairFacetPrefix generates a prefix for iterating facets on a pair.
+// Format: pair_facet::<subject>::<predicate>::
+func genPairFacetPrefix(subject, predicate []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(pairFacetPrefix)
+	buf.Write(index.Escape(subject))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(predicate))
+	buf.Write(index.KeySeparator)
+	return buf.Bytes()
+}
+
 // SetFacet sets a facet on a component (subject, predicate, or object value).
 // The facet is a key-value pair attached to the component.
 func (db *DB) SetFacet(ctx context.Context, facetType FacetType, value []byte, key []byte, facetValue []byte) error {
@@ -119,6 +154,10 @@ func (db *DB) SetFacet(ctx context.Context, facetType FacetType, value []byte, k
 		return ErrClosed
 	}
 
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -216,6 +255,10 @@ func (db *DB) DelFacet(ctx context.Context, facetType FacetType, value []byte, k
 		return ErrClosed
 	}
 
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -239,6 +282,10 @@ func (db *DB) SetTripleFacet(ctx context.Context, triple *graph.Triple, key []by
 		return ErrClosed
 	}
 
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -249,7 +296,7 @@ func (db *DB) SetTripleFacet(ctx context.Context, triple *graph.Triple, key []by
 		return ErrFacetsDisabled
 	}
 
-	dbKey := genTripleFacetKey(triple, key)
+	dbKey := genTripleFacetKey(db.canonicalTriple(triple), key)
 	return db.store.Put(dbKey, value, nil)
 }
 
@@ -272,7 +319,7 @@ func (db *DB) GetTripleFacet(ctx context.Context, triple *graph.Triple, key []by
 		return nil, ErrFacetsDisabled
 	}
 
-	dbKey := genTripleFacetKey(triple, key)
+	dbKey := genTripleFacetKey(db.canonicalTriple(triple), key)
 	result, err := db.store.Get(dbKey, nil)
 	if err == ErrNotFound {
 		return nil, nil
@@ -299,7 +346,7 @@ func (db *DB) GetTripleFacets(ctx context.Context, triple *graph.Triple) (map[st
 		return nil, ErrFacetsDisabled
 	}
 
-	prefix := genTripleFacetPrefix(triple)
+	prefix := genTripleFacetPrefix(db.canonicalTriple(triple))
 	upperBound := append(prefix, 0xFF)
 
 	iter := db.store.NewIterator(&Range{Start: prefix, Limit: upperBound}, nil)
@@ -334,6 +381,10 @@ func (db *DB) DelTripleFacet(ctx context.Context, triple *graph.Triple, key []by
 		return ErrClosed
 	}
 
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -344,7 +395,7 @@ func (db *DB) DelTripleFacet(ctx context.Context, triple *graph.Triple, key []by
 		return ErrFacetsDisabled
 	}
 
-	dbKey := genTripleFacetKey(triple, key)
+	dbKey := genTripleFacetKey(db.canonicalTriple(triple), key)
 	return db.store.Delete(dbKey, nil)
 }
 
@@ -357,6 +408,10 @@ func (db *DB) DelAllTripleFacets(ctx context.Context, triple *graph.Triple) erro
 		return ErrClosed
 	}
 
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -367,24 +422,243 @@ func (db *DB) DelAllTripleFacets(ctx context.Context, triple *graph.Triple) erro
 		return ErrFacetsDisabled
 	}
 
+	batch := NewBatch()
+	if err := db.deleteTripleFacets(batch, db.canonicalTriple(triple)); err != nil {
+		return err
+	}
+
+	return db.writeBatch(batch)
+}
+
+// deleteTripleFacets adds a delete of each of triple's facets to batch.
+// Caller must hold at least a read lock.
+func (db *DB) deleteTripleFacets(batch *Batch, triple *graph.Triple) error {
 	prefix := genTripleFacetPrefix(triple)
 	upperBound := append(prefix, 0xFF)
 
 	iter := db.store.NewIterator(&Range{Start: prefix, Limit: upperBound}, nil)
 	defer iter.Release()
 
-	batch := NewBatch()
 	for iter.Next() {
 		keyCopy := make([]byte, len(iter.Key()))
 		copy(keyCopy, iter.Key())
 		batch.Delete(keyCopy)
 	}
 
+	return iter.Error()
+}
+
+// SetPairFacet sets a facet on a (subject, predicate) pair. Pair facets are
+// separate from component facets on the subject and from triple facets, so
+// the same key can hold a different value for each predicate a subject uses.
+func (db *DB) SetPairFacet(ctx context.Context, subject, predicate []byte, key []byte, value []byte) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return ErrClosed
+	}
+
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if !db.options.FacetsEnabled {
+		return ErrFacetsDisabled
+	}
+
+	dbKey := genPairFacetKey(subject, db.canonicalPredicate(predicate), key)
+	return db.store.Put(dbKey, value, nil)
+}
+
+// GetPairFacet retrieves a facet from a (subject, predicate) pair.
+func (db *DB) GetPairFacet(ctx context.Context, subject, predicate []byte, key []byte) ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, ErrClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if !db.options.FacetsEnabled {
+		return nil, ErrFacetsDisabled
+	}
+
+	dbKey := genPairFacetKey(subject, db.canonicalPredicate(predicate), key)
+	result, err := db.store.Get(dbKey, nil)
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	return result, err
+}
+
+// GetPairFacets retrieves all facets from a (subject, predicate) pair.
+func (db *DB) GetPairFacets(ctx context.Context, subject, predicate []byte) (map[string][]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, ErrClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if !db.options.FacetsEnabled {
+		return nil, ErrFacetsDisabled
+	}
+
+	prefix := genPairFacetPrefix(subject, db.canonicalPredicate(predicate))
+	upperBound := append(prefix, 0xFF)
+
+	iter := db.store.NewIterator(&Range{Start: prefix, Limit: upperBound}, nil)
+	defer iter.Release()
+
+	result := make(map[string][]byte)
+	prefixLen := len(prefix)
+
+	for iter.Next() {
+		fullKey := iter.Key()
+		if len(fullKey) > prefixLen {
+			facetKey := index.Unescape(fullKey[prefixLen:])
+			facetValue := make([]byte, len(iter.Value()))
+			copy(facetValue, iter.Value())
+			result[string(facetKey)] = facetValue
+		}
+	}
+
 	if err := iter.Error(); err != nil {
-		return err
+		return nil, err
 	}
 
-	return db.store.Write(batch, nil)
+	return result, nil
+}
+
+// DelPairFacet deletes a facet from a (subject, predicate) pair.
+func (db *DB) DelPairFacet(ctx context.Context, subject, predicate []byte, key []byte) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return ErrClosed
+	}
+
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if !db.options.FacetsEnabled {
+		return ErrFacetsDisabled
+	}
+
+	dbKey := genPairFacetKey(subject, db.canonicalPredicate(predicate), key)
+	return db.store.Delete(dbKey, nil)
+}
+
+// SetFacets sets several facets on a component in a single atomic write.
+// With FacetReplace, any other facets on the component are deleted in the
+// same write.
+func (db *DB) SetFacets(ctx context.Context, facetType FacetType, value []byte, facets map[string][]byte, mode FacetMode) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return ErrClosed
+	}
+
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if !db.options.FacetsEnabled {
+		return ErrFacetsDisabled
+	}
+
+	return db.writeFacets(genFacetPrefix(facetType, value), facets, mode)
+}
+
+// SetTripleFacets sets several facets on a triple in a single atomic write.
+// With FacetReplace, any other facets on the triple are deleted in the same
+// write.
+func (db *DB) SetTripleFacets(ctx context.Context, triple *graph.Triple, facets map[string][]byte, mode FacetMode) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return ErrClosed
+	}
+
+	if db.options.ReadOnly {
+		return ErrReadOnly
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if !db.options.FacetsEnabled {
+		return ErrFacetsDisabled
+	}
+
+	return db.writeFacets(genTripleFacetPrefix(db.canonicalTriple(triple)), facets, mode)
+}
+
+// writeFacets writes facets under prefix in one batch, first deleting the
+// facets not in the set when mode is FacetReplace.
+func (db *DB) writeFacets(prefix []byte, facets map[string][]byte, mode FacetMode) error {
+	batch := NewBatch()
+
+	if mode == FacetReplace {
+		upperBound := append(bytes.Clone(prefix), 0xFF)
+		iter := db.store.NewIterator(&Range{Start: prefix, Limit: upperBound}, nil)
+		for iter.Next() {
+			key := index.Unescape(iter.Key()[len(prefix):])
+			if _, ok := facets[string(key)]; !ok {
+				batch.Delete(iter.Key())
+			}
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, value := range facets {
+		dbKey := append(bytes.Clone(prefix), index.Escape([]byte(key))...)
+		batch.Put(dbKey, value)
+	}
+
+	return db.writeBatch(batch)
 }
 
 // FacetIterator iterates over facets on a component or triple.
@@ -441,7 +715,7 @@ func (db *DB) GetTripleFacetIterator(ctx context.Context, triple *graph.Triple)
 		return nil, ErrFacetsDisabled
 	}
 
-	prefix := genTripleFacetPrefix(triple)
+	prefix := genTripleFacetPrefix(db.canonicalTriple(triple))
 	upperBound := append(prefix, 0xFF)
 
 	iter := db.store.NewIterator(&Range{Start: prefix, Limit: upperBound}, nil)
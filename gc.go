@@ -0,0 +1,316 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+	"github.com/benbenbenbenbenben/levelgraph/vector"
+)
+
+// GC removes facets and vectors that refer to graph elements no longer in
+// the database: component facets and subject, predicate and object vectors
+// whose value no triple uses, pair facets whose (subject, predicate) pair
+// has no triples, and triple facets and triple vectors whose triple is gone.
+// Del removes a triple's own facets and vectors, so GC is needed for
+// component data, and for triple data left by older versions or by a
+// custom VectorIDCodec that reuses IDs. Facet vectors and custom vector IDs
+// are never removed.
+//
+// Example:
+//
+//	facets, vectors, err := db.GC(ctx)
+//	log.Printf("removed %d facets and %d vectors", facets, vectors)
+func (db *DB) GC(ctx context.Context) (facetsRemoved, vectorsRemoved int, err error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return 0, 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.ReadOnly {
+		return 0, 0, fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, 0, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	facetsRemoved, err = db.gcFacets(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if db.options.VectorIndex != nil {
+		vectorsRemoved, err = db.gcVectors(ctx)
+		if err != nil {
+			return facetsRemoved, 0, err
+		}
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("gc", "facets", facetsRemoved, "vectors", vectorsRemoved)
+	}
+	return facetsRemoved, vectorsRemoved, nil
+}
+
+// gcFacets deletes the component, pair and triple facets whose owner is
+// gone and returns how many it deleted.
+// Caller must hold at least a read lock.
+func (db *DB) gcFacets(ctx context.Context) (int, error) {
+	scans := []struct {
+		prefix []byte
+		owner  func(parts [][]byte) (*graph.Pattern, bool)
+	}{
+		{facetPrefix, func(parts [][]byte) (*graph.Pattern, bool) {
+			if len(parts) != 3 {
+				return nil, false
+			}
+			return componentPattern(FacetType(parts[0]), index.Unescape(parts[1]))
+		}},
+		{pairFacetPrefix, func(parts [][]byte) (*graph.Pattern, bool) {
+			if len(parts) != 3 {
+				return nil, false
+			}
+			return &graph.Pattern{
+				Subject:   graph.Exact(index.Unescape(parts[0])),
+				Predicate: graph.Exact(index.Unescape(parts[1])),
+			}, true
+		}},
+		{tripleFacetPrefix, func(parts [][]byte) (*graph.Pattern, bool) {
+			if len(parts) != 4 {
+				return nil, false
+			}
+			return &graph.Pattern{
+				Subject:   graph.Exact(index.Unescape(parts[0])),
+				Predicate: graph.Exact(index.Unescape(parts[1])),
+				Object:    graph.Exact(index.Unescape(parts[2])),
+			}, true
+		}},
+	}
+
+	batch := NewBatch()
+	removed := 0
+	for _, scan := range scans {
+		iter := db.store.NewIterator(&Range{Start: scan.prefix, Limit: prefixLimit(scan.prefix)}, nil)
+		// Facets of one owner are adjacent, so remember the last owner checked
+		var lastOwner []byte
+		lastExists := false
+		for n := 0; iter.Next(); n++ {
+			if n%1000 == 0 {
+				if err := ctx.Err(); err != nil {
+					iter.Release()
+					return 0, fmt.Errorf("levelgraph: %w", err)
+				}
+			}
+
+			key := bytes.Clone(iter.Key())
+			parts := splitIndexKey(key[len(scan.prefix):])
+			pattern, ok := scan.owner(parts)
+			if !ok {
+				continue
+			}
+			owner := key[:len(key)-len(parts[len(parts)-1])]
+			if !bytes.Equal(owner, lastOwner) {
+				exists, err := db.patternExists(pattern)
+				if err != nil {
+					iter.Release()
+					return 0, fmt.Errorf("levelgraph: gc: %w", err)
+				}
+				lastOwner, lastExists = owner, exists
+			}
+			if !lastExists {
+				batch.Delete(key)
+				removed++
+			}
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return 0, fmt.Errorf("levelgraph: gc: %w", err)
+		}
+	}
+
+	if removed > 0 {
+		if err := db.writeBatch(batch); err != nil {
+			return 0, fmt.Errorf("levelgraph: gc: %w", err)
+		}
+	}
+	return removed, nil
+}
+
+// gcVectors deletes the subject, predicate, object and triple vectors whose
+// value or triple is gone and returns how many it deleted. Triple vector IDs
+// hold only a hash, so when there are any the SPO index is scanned once to
+// find the hashes still in use. Legacy triple vector IDs hold the triple and
+// are checked directly.
+// Caller must hold at least a read lock.
+func (db *DB) gcVectors(ctx context.Context) (int, error) {
+	codec := db.vectorIDCodec()
+
+	var orphans [][]byte
+	tripleIDs := make(map[string][]byte)
+	iter := db.store.NewIterator(&Range{Start: vectorPrefix, Limit: prefixLimit(vectorPrefix)}, nil)
+	for n := 0; iter.Next(); n++ {
+		if n%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				iter.Release()
+				return 0, fmt.Errorf("levelgraph: %w", err)
+			}
+		}
+
+		id := bytes.Clone(iter.Key()[len(vectorPrefix):])
+		idType, parts := codec.ParseID(id)
+		if idType == vector.IDTypeTriple && len(parts) == 3 {
+			// A legacy triple vector ID holds the triple itself
+			triple := &graph.Triple{Subject: parts[0], Predicate: parts[1], Object: parts[2]}
+			if _, err := db.store.Get(index.GenKey(index.IndexSPO, triple), nil); err != nil {
+				if !errors.Is(err, ErrNotFound) {
+					iter.Release()
+					return 0, fmt.Errorf("levelgraph: gc: %w", err)
+				}
+				orphans = append(orphans, id)
+			}
+			continue
+		}
+		if len(parts) != 1 {
+			continue
+		}
+		if idType == vector.IDTypeTriple {
+			tripleIDs[string(parts[0])] = id
+			continue
+		}
+		pattern, ok := componentPattern(FacetType(idType), parts[0])
+		if !ok {
+			continue
+		}
+		exists, err := db.patternExists(pattern)
+		if err != nil {
+			iter.Release()
+			return 0, fmt.Errorf("levelgraph: gc: %w", err)
+		}
+		if !exists {
+			orphans = append(orphans, id)
+		}
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return 0, fmt.Errorf("levelgraph: gc: %w", err)
+	}
+
+	if len(tripleIDs) > 0 {
+		all := &graph.Pattern{}
+		spo := db.store.NewIterator(&Range{
+			Start: index.GenKeyFromPattern(index.IndexSPO, all),
+			Limit: index.GenKeyWithUpperBound(index.IndexSPO, all),
+		}, nil)
+		for n := 0; len(tripleIDs) > 0 && spo.Next(); n++ {
+			if n%1000 == 0 {
+				if err := ctx.Err(); err != nil {
+					spo.Release()
+					return 0, fmt.Errorf("levelgraph: %w", err)
+				}
+			}
+			triple, err := decodeTripleValue(spo.Value())
+			if err != nil {
+				spo.Release()
+				return 0, fmt.Errorf("levelgraph: gc: %w", err)
+			}
+			delete(tripleIDs, triple.HashHex())
+		}
+		err := spo.Error()
+		spo.Release()
+		if err != nil {
+			return 0, fmt.Errorf("levelgraph: gc: %w", err)
+		}
+		for _, id := range tripleIDs {
+			orphans = append(orphans, id)
+		}
+	}
+
+	for _, id := range orphans {
+		if err := db.removeVector(id, true); err != nil {
+			return 0, fmt.Errorf("levelgraph: gc: %w", err)
+		}
+	}
+	return len(orphans), nil
+}
+
+// componentPattern returns the pattern matching the triples that use value
+// as the given component, or false for a type that is not a component.
+func componentPattern(facetType FacetType, value []byte) (*graph.Pattern, bool) {
+	switch facetType {
+	case FacetSubject:
+		return &graph.Pattern{Subject: graph.Exact(value)}, true
+	case FacetPredicate:
+		return &graph.Pattern{Predicate: graph.Exact(value)}, true
+	case FacetObject:
+		return &graph.Pattern{Object: graph.Exact(value)}, true
+	}
+	return nil, false
+}
+
+// patternExists reports whether any triple matches pattern's exact fields.
+// Caller must hold at least a read lock.
+func (db *DB) patternExists(pattern *graph.Pattern) (bool, error) {
+	idx := chooseIndex(pattern)
+	iter := db.store.NewIterator(&Range{
+		Start: index.GenKeyFromPattern(idx, pattern),
+		Limit: index.GenKeyWithUpperBound(idx, pattern),
+	}, nil)
+	defer iter.Release()
+
+	if iter.First() {
+		return true, nil
+	}
+	return false, iter.Error()
+}
+
+// splitIndexKey splits the escaped parts of a key on the "::" separators
+// between them. Escape escapes every colon inside a part, so a separator is
+// any unescaped pair of colons.
+func splitIndexKey(key []byte) [][]byte {
+	var parts [][]byte
+	start := 0
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\':
+			i++
+		case key[i] == ':' && i+1 < len(key) && key[i+1] == ':':
+			parts = append(parts, key[start:i])
+			i++
+			start = i + 1
+		}
+	}
+	return append(parts, key[start:])
+}
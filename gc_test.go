@@ -0,0 +1,197 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/vector"
+)
+
+func setupGCTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithFacets(), WithVectors(vector.NewFlatIndex(3)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDB_DelRemovesTripleFacetsAndVector(t *testing.T) {
+	t.Parallel()
+	db := setupGCTestDB(t)
+
+	ctx := context.Background()
+	triple := graph.NewTripleFromStrings("alice", "knows", "bob:smith")
+	kept := graph.NewTripleFromStrings("alice", "knows", "carol")
+	if err := db.Put(ctx, triple, kept); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	for _, tr := range []*graph.Triple{triple, kept} {
+		if err := db.SetTripleFacet(ctx, tr, []byte("since"), []byte("2020")); err != nil {
+			t.Fatalf("SetTripleFacet() error = %v", err)
+		}
+		if err := db.SetTripleVector(ctx, tr, []float32{1, 0, 0}); err != nil {
+			t.Fatalf("SetTripleVector() error = %v", err)
+		}
+	}
+	// A vector stored under the ID used before triple vectors were hashed
+	legacyID := vector.MakeID(vector.IDTypeTriple, triple.Subject, triple.Predicate, triple.Object)
+	if err := db.SetVector(ctx, legacyID, []float32{0, 1, 0}); err != nil {
+		t.Fatalf("SetVector() error = %v", err)
+	}
+
+	if err := db.Del(ctx, triple); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+
+	facets, err := db.GetTripleFacets(ctx, triple)
+	if err != nil {
+		t.Fatalf("GetTripleFacets() error = %v", err)
+	}
+	if len(facets) != 0 {
+		t.Errorf("GetTripleFacets() after Del = %v, want none", facets)
+	}
+	if _, err := db.GetVector(ctx, TripleVectorID(triple)); !errors.Is(err, vector.ErrNotFound) {
+		t.Errorf("GetVector() after Del error = %v, want vector.ErrNotFound", err)
+	}
+	if _, err := db.GetVector(ctx, legacyID); !errors.Is(err, vector.ErrNotFound) {
+		t.Errorf("GetVector(legacy) after Del error = %v, want vector.ErrNotFound", err)
+	}
+
+	facets, err = db.GetTripleFacets(ctx, kept)
+	if err != nil {
+		t.Fatalf("GetTripleFacets() error = %v", err)
+	}
+	if string(facets["since"]) != "2020" {
+		t.Errorf("GetTripleFacets(kept) = %v, want since=2020", facets)
+	}
+	if db.VectorCount() != 1 {
+		t.Errorf("VectorCount() = %d, want 1", db.VectorCount())
+	}
+}
+
+func TestDB_GC(t *testing.T) {
+	t.Parallel()
+	db := setupGCTestDB(t)
+
+	ctx := context.Background()
+	gone := graph.NewTripleFromStrings("alice", "knows", "bob")
+	kept := graph.NewTripleFromStrings("carol", "knows", "dave")
+	if err := db.Put(ctx, gone, kept); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	vectors := map[string][]byte{
+		"alice": vector.MakeID(vector.IDTypeSubject, []byte("alice")),
+		"bob":   vector.MakeID(vector.IDTypeObject, []byte("bob")),
+		"carol": vector.MakeID(vector.IDTypeSubject, []byte("carol")),
+		"knows": vector.MakeID(vector.IDTypePredicate, []byte("knows")),
+		"never": TripleVectorID(graph.NewTripleFromStrings("x", "y", "z")),
+		"kept":  TripleVectorID(kept),
+		"own":   vector.MakeID(vector.IDTypeCustom, []byte("doc-1")),
+		// Legacy triple vector IDs hold the triple rather than its hash
+		"legacyNever": vector.MakeID(vector.IDTypeTriple, []byte("x"), []byte("y"), []byte("z")),
+		"legacyKept":  vector.MakeID(vector.IDTypeTriple, kept.Subject, kept.Predicate, kept.Object),
+	}
+	for _, id := range vectors {
+		if err := db.SetVector(ctx, id, []float32{1, 0, 0}); err != nil {
+			t.Fatalf("SetVector() error = %v", err)
+		}
+	}
+	facets := []struct {
+		facetType FacetType
+		value     string
+	}{
+		{FacetSubject, "alice"},
+		{FacetObject, "bob"},
+		{FacetObject, "dave"},
+		{FacetPredicate, "knows"},
+	}
+	for _, f := range facets {
+		if err := db.SetFacet(ctx, f.facetType, []byte(f.value), []byte("label"), []byte(f.value)); err != nil {
+			t.Fatalf("SetFacet() error = %v", err)
+		}
+	}
+	if err := db.SetPairFacet(ctx, []byte("alice"), []byte("knows"), []byte("count"), []byte("1")); err != nil {
+		t.Fatalf("SetPairFacet() error = %v", err)
+	}
+
+	if err := db.Del(ctx, gone); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+
+	facetsRemoved, vectorsRemoved, err := db.GC(ctx)
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	// alice and bob component facets and the alice/knows pair facet
+	if facetsRemoved != 3 {
+		t.Errorf("GC() facetsRemoved = %d, want 3", facetsRemoved)
+	}
+	// alice and bob component vectors and the never-stored triple's vectors
+	if vectorsRemoved != 4 {
+		t.Errorf("GC() vectorsRemoved = %d, want 4", vectorsRemoved)
+	}
+
+	for name, id := range vectors {
+		_, err := db.GetVector(ctx, id)
+		orphan := name == "alice" || name == "bob" || name == "never" || name == "legacyNever"
+		if orphan != errors.Is(err, vector.ErrNotFound) {
+			t.Errorf("GetVector(%s) after GC error = %v, orphan %v", name, err, orphan)
+		}
+	}
+	if v, err := db.GetFacet(ctx, FacetObject, []byte("dave"), []byte("label")); err != nil || string(v) != "dave" {
+		t.Errorf("GetFacet(dave) after GC = %q, %v, want dave", v, err)
+	}
+	if v, err := db.GetFacet(ctx, FacetSubject, []byte("alice"), []byte("label")); err != nil || v != nil {
+		t.Errorf("GetFacet(alice) after GC = %q, %v, want none", v, err)
+	}
+
+	facetsRemoved, vectorsRemoved, err = db.GC(ctx)
+	if err != nil || facetsRemoved != 0 || vectorsRemoved != 0 {
+		t.Errorf("second GC() = %d, %d, %v, want 0, 0, nil", facetsRemoved, vectorsRemoved, err)
+	}
+}
+
+func TestSplitIndexKey(t *testing.T) {
+	t.Parallel()
+
+	got := splitIndexKey([]byte(`a\:::b\\::c`))
+	want := []string{`a\:`, `b\\`, "c"}
+	if len(got) != len(want) {
+		t.Fatalf("splitIndexKey() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if string(got[i]) != want[i] {
+			t.Errorf("splitIndexKey()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -6,7 +6,7 @@ require github.com/syndtr/goleveldb v1.0.0
 
 require (
 	github.com/benbenbenbenbenben/luxical-one-go v0.0.0-20251220105655-f98d9527440d
-	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db // indirect
+	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db
 )
 
 replace github.com/benbenbenbenbenben/luxical-one-go => /home/ben/luxical-one/go/luxical
@@ -0,0 +1,133 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	// internExtPrefix is the prefix for external ID -> node ID mappings
+	internExtPrefix = []byte("intern::ext::")
+
+	// internNodePrefix is the prefix for node ID -> external ID mappings
+	internNodePrefix = []byte("intern::node::")
+
+	// ErrEmptyExternalID is returned by Intern for an empty external ID.
+	ErrEmptyExternalID = errors.New("levelgraph: empty external ID")
+)
+
+// NodeIDPrefix starts every node ID minted by Intern.
+const NodeIDPrefix = "node:"
+
+// Intern returns the internal node ID for an external key, minting one the
+// first time the key is seen. Later calls with the same key return the same
+// ID, so a graph can be built on opaque IDs that never collide across data
+// sources, and Resolve maps an ID back to its key:
+//
+//	alice, _ := db.Intern(ctx, []byte("crm:42"))
+//	db.Put(ctx, levelgraph.NewTriple(alice, []byte("name"), []byte("Alice")))
+//
+// Node IDs are NodeIDPrefix followed by 32 random hex digits. Both directions
+// of the mapping are stored outside the triple indexes.
+func (db *DB) Intern(ctx context.Context, externalID []byte) ([]byte, error) {
+	if len(externalID) == 0 {
+		return nil, ErrEmptyExternalID
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	// Serialize minting so concurrent calls for a new key agree on one ID.
+	db.internMu.Lock()
+	defer db.internMu.Unlock()
+
+	extKey := append(bytes.Clone(internExtPrefix), externalID...)
+	id, err := db.store.Get(extKey, nil)
+	if err == nil {
+		return id, nil
+	}
+	if err != ErrNotFound {
+		return nil, fmt.Errorf("levelgraph: intern: %w", err)
+	}
+
+	if db.options.ReadOnly {
+		return nil, fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
+	id = mintNodeID()
+	batch := NewBatch()
+	batch.Put(extKey, id)
+	batch.Put(append(bytes.Clone(internNodePrefix), id...), externalID)
+	if err := db.writeBatch(batch); err != nil {
+		return nil, fmt.Errorf("levelgraph: intern: %w", err)
+	}
+	return id, nil
+}
+
+// Resolve returns the external key a node ID was minted for by Intern. It
+// returns an error wrapping ErrNotFound for IDs Intern did not mint.
+func (db *DB) Resolve(ctx context.Context, internalID []byte) ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	ext, err := db.store.Get(append(bytes.Clone(internNodePrefix), internalID...), nil)
+	if err != nil {
+		return nil, fmt.Errorf("levelgraph: resolve %q: %w", internalID, err)
+	}
+	return ext, nil
+}
+
+// mintNodeID returns a new random node ID.
+func mintNodeID() []byte {
+	var b [16]byte
+	rand.Read(b[:])
+	return []byte(NodeIDPrefix + hex.EncodeToString(b[:]))
+}
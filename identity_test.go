@@ -0,0 +1,119 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDB_InternResolve(t *testing.T) {
+	t.Parallel()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	ctx := context.Background()
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	alice, err := db.Intern(ctx, []byte("crm:alice"))
+	if err != nil {
+		t.Fatalf("Intern() error = %v", err)
+	}
+	if !strings.HasPrefix(string(alice), NodeIDPrefix) {
+		t.Errorf("Intern() = %q, want %s prefix", alice, NodeIDPrefix)
+	}
+	again, err := db.Intern(ctx, []byte("crm:alice"))
+	if err != nil {
+		t.Fatalf("Intern() error = %v", err)
+	}
+	if !bytes.Equal(again, alice) {
+		t.Errorf("Intern() repeat = %q, want %q", again, alice)
+	}
+	bob, err := db.Intern(ctx, []byte("crm:bob"))
+	if err != nil {
+		t.Fatalf("Intern() error = %v", err)
+	}
+	if bytes.Equal(bob, alice) {
+		t.Errorf("Intern() gave crm:alice and crm:bob the same ID %q", bob)
+	}
+
+	ext, err := db.Resolve(ctx, alice)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if string(ext) != "crm:alice" {
+		t.Errorf("Resolve() = %q, want crm:alice", ext)
+	}
+	if _, err := db.Resolve(ctx, []byte("node:unknown")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve(unknown) error = %v, want ErrNotFound", err)
+	}
+	if _, err := db.Intern(ctx, nil); !errors.Is(err, ErrEmptyExternalID) {
+		t.Errorf("Intern(nil) error = %v, want ErrEmptyExternalID", err)
+	}
+
+	// Interning does not add triples.
+	if triples, _ := db.Get(ctx, &Pattern{}); len(triples) != 0 {
+		t.Errorf("Get() after Intern returned %d triples, want 0", len(triples))
+	}
+
+	// Concurrent calls for a new key agree on one ID.
+	ids := make([][]byte, 8)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids[i], _ = db.Intern(ctx, []byte("crm:carol"))
+		}()
+	}
+	wg.Wait()
+	for _, id := range ids[1:] {
+		if !bytes.Equal(id, ids[0]) {
+			t.Errorf("concurrent Intern() = %q and %q, want one ID", ids[0], id)
+		}
+	}
+	db.Close()
+
+	// IDs are stable across reopen.
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	again, err = db.Intern(ctx, []byte("crm:alice"))
+	if err != nil {
+		t.Fatalf("Intern() after reopen error = %v", err)
+	}
+	if !bytes.Equal(again, alice) {
+		t.Errorf("Intern() after reopen = %q, want %q", again, alice)
+	}
+}
@@ -28,8 +28,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
-	"sync/atomic"
 	"time"
 
 	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
@@ -38,6 +39,14 @@ import (
 var (
 	// journalPrefix is the prefix for all journal entries
 	journalPrefix = []byte("journal::")
+
+	// ErrInvalidJournalKey is returned by JournalPage when afterKey is not a
+	// journal key.
+	ErrInvalidJournalKey = errors.New("levelgraph: invalid journal key")
+
+	// ErrInvalidJournalOp is returned by GetJournalEntriesByOp when op is
+	// not "put" or "del".
+	ErrInvalidJournalOp = errors.New("levelgraph: invalid journal operation")
 )
 
 // JournalEntry represents a recorded operation in the journal.
@@ -48,6 +57,10 @@ type JournalEntry struct {
 	Triple *Triple `json:"triple"`
 	// Timestamp is when the operation occurred
 	Timestamp time.Time `json:"ts"`
+	// Seq is the entry's sequence number. It is strictly increasing in
+	// journal order, including across reopens, and is taken from the key
+	// rather than stored in the entry.
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 // MarshalBinary implements encoding.BinaryMarshaler for JournalEntry.
@@ -117,21 +130,70 @@ func (e *JournalEntry) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
-// genJournalKey generates a unique key for a journal entry.
-// Format: journal::<timestamp_ns>::<counter>
-// Using nanosecond timestamp + counter ensures uniqueness and ordering.
-func (db *DB) genJournalKey(ts time.Time) []byte {
-	// Use nanoseconds since Unix epoch for ordering
+// genJournalKey generates a unique key for a journal entry and returns it
+// with the timestamp it encodes.
+// Format: journal::<timestamp_ns>::<seq>
+// Timestamps are clamped so they never go backwards, and seq increases by one
+// per entry, so key order is exactly write order even for entries recorded in
+// the same nanosecond or across a clock step.
+func (db *DB) genJournalKey(ts time.Time) ([]byte, time.Time) {
+	db.journalMu.Lock()
+	if !db.journalSeeded {
+		db.seedJournalSeq()
+	}
 	nsec := ts.UnixNano()
-	counter := atomic.AddUint64(&db.journalCounter, 1)
+	if nsec < db.journalLastNs {
+		nsec = db.journalLastNs
+		ts = time.Unix(0, nsec)
+	}
+	db.journalLastNs = nsec
+	db.journalSeq++
+	seq := db.journalSeq
+	db.journalMu.Unlock()
 
-	// Create key: prefix + 8 bytes timestamp + 8 bytes counter
+	// Create key: prefix + 8 bytes timestamp + 8 bytes sequence
 	key := make([]byte, len(journalPrefix)+16)
 	copy(key, journalPrefix)
 	binary.BigEndian.PutUint64(key[len(journalPrefix):], uint64(nsec))
-	binary.BigEndian.PutUint64(key[len(journalPrefix)+8:], counter)
+	binary.BigEndian.PutUint64(key[len(journalPrefix)+8:], seq)
+
+	return key, ts
+}
+
+// seedJournalSeq continues the sequence and clock from the newest journal
+// entry, so entries written after a reopen sort after the existing ones.
+// The caller must hold db.journalMu.
+func (db *DB) seedJournalSeq() {
+	iter := db.store.NewIterator(&Range{Start: journalPrefix, Limit: journalUpperBound()}, nil)
+	defer iter.Release()
+
+	if iter.Last() {
+		key := iter.Key()
+		if len(key) == len(journalPrefix)+16 {
+			db.journalLastNs = int64(binary.BigEndian.Uint64(key[len(journalPrefix):]))
+			db.journalSeq = journalSeqFromKey(key)
+		}
+	}
+	db.journalSeeded = true
+}
 
-	return key
+// journalSeqFromKey returns the sequence number encoded in a journal key,
+// or 0 if the key is malformed.
+func journalSeqFromKey(key []byte) uint64 {
+	if len(key) != len(journalPrefix)+16 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(key[len(journalPrefix)+8:])
+}
+
+// journalUpperBound returns a key greater than every journal key.
+func journalUpperBound() []byte {
+	upperBound := make([]byte, len(journalPrefix)+16)
+	copy(upperBound, journalPrefix)
+	for i := len(journalPrefix); i < len(upperBound); i++ {
+		upperBound[i] = 0xFF
+	}
+	return upperBound
 }
 
 // recordJournalEntry adds a journal entry to the batch.
@@ -140,7 +202,7 @@ func (db *DB) recordJournalEntry(batch *Batch, op string, triple *graph.Triple)
 		return nil
 	}
 
-	ts := time.Now()
+	key, ts := db.genJournalKey(time.Now())
 	entry := &JournalEntry{
 		Operation: op,
 		Triple:    triple,
@@ -152,7 +214,6 @@ func (db *DB) recordJournalEntry(batch *Batch, op string, triple *graph.Triple)
 		return err
 	}
 
-	key := db.genJournalKey(ts)
 	batch.Put(key, value)
 	return nil
 }
@@ -183,12 +244,7 @@ func (db *DB) GetJournalIterator(ctx context.Context, before time.Time) (*Journa
 	var rng *Range
 	if before.IsZero() {
 		// All entries
-		upperBound := make([]byte, len(journalPrefix)+16)
-		copy(upperBound, journalPrefix)
-		for i := len(journalPrefix); i < len(upperBound); i++ {
-			upperBound[i] = 0xFF
-		}
-		rng = &Range{Start: journalPrefix, Limit: upperBound}
+		rng = &Range{Start: journalPrefix, Limit: journalUpperBound()}
 	} else {
 		// Only entries before the given time
 		upperKey := make([]byte, len(journalPrefix)+8)
@@ -215,8 +271,9 @@ func (ji *JournalIterator) Entry() (*JournalEntry, error) {
 	value := ji.iter.Value()
 	var entry JournalEntry
 	if err := entry.UnmarshalBinary(value); err != nil { // Use binary unmarshaling
-		return nil, err
+		return nil, errors.Join(ErrCorrupted, err)
 	}
+	entry.Seq = journalSeqFromKey(ji.iter.Key())
 	return &entry, nil
 }
 
@@ -259,6 +316,64 @@ func (db *DB) GetJournalEntries(ctx context.Context, before time.Time) ([]*Journ
 	return entries, nil
 }
 
+// GetJournalEntriesByOp returns the journal entries recorded at or after
+// since (all entries if since is zero) whose Operation is op, "put" or
+// "del", in journal order. For example, the deletes of the last hour:
+//
+//	dels, err := db.GetJournalEntriesByOp(ctx, time.Now().Add(-time.Hour), "del")
+func (db *DB) GetJournalEntriesByOp(ctx context.Context, since time.Time, op string) ([]JournalEntry, error) {
+	if op != "put" && op != "del" {
+		return nil, ErrInvalidJournalOp
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	startKey := journalPrefix
+	if !since.IsZero() {
+		startKey = make([]byte, len(journalPrefix)+8)
+		copy(startKey, journalPrefix)
+		binary.BigEndian.PutUint64(startKey[len(journalPrefix):], uint64(since.UnixNano()))
+	}
+	iter := db.store.NewIterator(&Range{Start: startKey, Limit: journalUpperBound()}, nil)
+	defer iter.Release()
+
+	var entries []JournalEntry
+	for n := 0; iter.Next(); n++ {
+		if n%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		var entry JournalEntry
+		if err := entry.UnmarshalBinary(iter.Value()); err != nil {
+			return nil, errors.Join(ErrCorrupted, err)
+		}
+		if entry.Operation != op {
+			continue
+		}
+		entry.Seq = journalSeqFromKey(iter.Key())
+		entries = append(entries, entry)
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 // Trim removes all journal entries before the given time.
 func (db *DB) Trim(ctx context.Context, before time.Time) (int, error) {
 	db.mu.Lock()
@@ -268,6 +383,10 @@ func (db *DB) Trim(ctx context.Context, before time.Time) (int, error) {
 		return 0, ErrClosed
 	}
 
+	if db.options.ReadOnly {
+		return 0, ErrReadOnly
+	}
+
 	select {
 	case <-ctx.Done():
 		return 0, ctx.Err()
@@ -302,7 +421,7 @@ func (db *DB) Trim(ctx context.Context, before time.Time) (int, error) {
 	}
 
 	if count > 0 {
-		if err := db.store.Write(batch, nil); err != nil {
+		if err := db.writeBatch(batch); err != nil {
 			return 0, err
 		}
 	}
@@ -324,6 +443,10 @@ func (db *DB) TrimAndExport(ctx context.Context, before time.Time, targetDB *DB)
 		return 0, ErrClosed
 	}
 
+	if db.options.ReadOnly {
+		return 0, ErrReadOnly
+	}
+
 	select {
 	case <-ctx.Done():
 		return 0, ctx.Err()
@@ -370,10 +493,10 @@ func (db *DB) TrimAndExport(ctx context.Context, before time.Time, targetDB *DB)
 
 	if count > 0 {
 		// First write to target, then delete from source
-		if err := targetDB.store.Write(exportBatch, nil); err != nil {
+		if err := targetDB.writeBatch(exportBatch); err != nil {
 			return 0, err
 		}
-		if err := db.store.Write(deleteBatch, nil); err != nil {
+		if err := db.writeBatch(deleteBatch); err != nil {
 			return 0, err
 		}
 	}
@@ -413,16 +536,9 @@ func (db *DB) ReplayJournal(ctx context.Context, after time.Time, targetDB *DB)
 		binary.BigEndian.PutUint64(startKey[len(journalPrefix):], uint64(after.UnixNano()))
 	}
 
-	// End key is max journal key
-	endKey := make([]byte, len(journalPrefix)+16)
-	copy(endKey, journalPrefix)
-	for i := len(journalPrefix); i < len(endKey); i++ {
-		endKey[i] = 0xFF
-	}
-
 	iter := db.store.NewIterator(&Range{
 		Start: startKey,
-		Limit: endKey,
+		Limit: journalUpperBound(),
 	}, nil)
 	defer iter.Release()
 
@@ -481,12 +597,7 @@ func (db *DB) JournalCount(ctx context.Context, before time.Time) (int, error) {
 	var rng *Range
 	if before.IsZero() {
 		// All entries
-		upperBound := make([]byte, len(journalPrefix)+16)
-		copy(upperBound, journalPrefix)
-		for i := len(journalPrefix); i < len(upperBound); i++ {
-			upperBound[i] = 0xFF
-		}
-		rng = &Range{Start: journalPrefix, Limit: upperBound}
+		rng = &Range{Start: journalPrefix, Limit: journalUpperBound()}
 	} else {
 		// Only entries before the given time
 		upperKey := make([]byte, len(journalPrefix)+8)
@@ -509,3 +620,83 @@ func (db *DB) JournalCount(ctx context.Context, before time.Time) (int, error) {
 
 	return count, nil
 }
+
+// JournalPage returns up to limit journal entries, in order, for shipping the
+// journal elsewhere in batches. Entries are taken from since onwards (from the
+// beginning if since is zero) and after afterKey (from the start if nil). A
+// limit of zero or less returns every remaining entry.
+//
+// nextKey is the key of the last entry returned, or afterKey if there were
+// none; pass it as afterKey to fetch the next page. Keys are stable, so a
+// consumer that persists nextKey can resume exactly where it left off after a
+// restart. An empty page means the consumer has caught up.
+//
+// Example:
+//
+//	var cursor []byte // e.g. loaded from the consumer's own storage
+//	for {
+//	    entries, next, err := db.JournalPage(ctx, time.Time{}, cursor, 500)
+//	    if err != nil || len(entries) == 0 {
+//	        break
+//	    }
+//	    ship(entries)
+//	    cursor = next
+//	}
+func (db *DB) JournalPage(ctx context.Context, since time.Time, afterKey []byte, limit int) (entries []JournalEntry, nextKey []byte, err error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	default:
+	}
+
+	if afterKey != nil && !bytes.HasPrefix(afterKey, journalPrefix) {
+		return nil, nil, ErrInvalidJournalKey
+	}
+
+	startKey := journalPrefix
+	if !since.IsZero() {
+		startKey = make([]byte, len(journalPrefix)+8)
+		copy(startKey, journalPrefix)
+		binary.BigEndian.PutUint64(startKey[len(journalPrefix):], uint64(since.UnixNano()))
+	}
+	if afterKey != nil {
+		// The smallest key greater than afterKey
+		after := append(bytes.Clone(afterKey), 0)
+		if bytes.Compare(after, startKey) > 0 {
+			startKey = after
+		}
+	}
+
+	iter := db.store.NewIterator(&Range{Start: startKey, Limit: journalUpperBound()}, nil)
+	defer iter.Release()
+
+	nextKey = afterKey
+	for (limit <= 0 || len(entries) < limit) && iter.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		default:
+		}
+
+		var entry JournalEntry
+		if err := entry.UnmarshalBinary(iter.Value()); err != nil {
+			return nil, nil, errors.Join(ErrCorrupted, err)
+		}
+		entry.Seq = journalSeqFromKey(iter.Key())
+		entries = append(entries, entry)
+		nextKey = bytes.Clone(iter.Key())
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, nil, err
+	}
+
+	return entries, nextKey, nil
+}
@@ -36,13 +36,22 @@
 //	defer db.Close()
 //
 //	// Insert a triple
-//	err = db.Put(levelgraph.NewTripleFromStrings("alice", "knows", "bob"))
+//	err = db.Put(ctx, levelgraph.NewTripleFromStrings("alice", "knows", "bob"))
 //
 //	// Query triples
-//	triples, err := db.Get(&levelgraph.Pattern{
-//	    Subject: []byte("alice"),
+//	triples, err := db.Get(ctx, &levelgraph.Pattern{
+//	    Subject: levelgraph.ExactString("alice"),
 //	})
 //
+//	// Join patterns, binding variables
+//	solutions, err := db.Search(ctx, []*levelgraph.Pattern{
+//	    {Subject: levelgraph.ExactString("alice"), Predicate: levelgraph.ExactString("knows"), Object: levelgraph.Binding("x")},
+//	    {Subject: levelgraph.Binding("x"), Predicate: levelgraph.ExactString("knows"), Object: levelgraph.Binding("y")},
+//	}, nil)
+//
+// Pattern fields are built with Exact, ExactString, Binding and Wildcard.
+// A zero field is a wildcard; Exact([]byte{}) matches only an empty value.
+//
 // With features enabled:
 //
 //	db, err := levelgraph.Open("/path/to/db",
@@ -50,20 +59,25 @@
 //	    levelgraph.WithFacets(),
 //	)
 //
-// For WebAssembly builds, use OpenWithStore with NewMemStore:
+// For WebAssembly builds, use OpenWithDB with NewMemStore:
 //
 //	store := levelgraph.NewMemStore()
-//	db := levelgraph.OpenWithStore(store)
+//	db, err := levelgraph.OpenWithDB(store)
 package levelgraph
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
 	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+	"github.com/benbenbenbenbenben/levelgraph/vector"
 )
 
 // Triple is an alias for graph.Triple representing a subject-predicate-object triple.
@@ -78,6 +92,32 @@ type Variable = graph.Variable
 // Solution is an alias for graph.Solution representing query result bindings.
 type Solution = graph.Solution
 
+// IndexName is an alias for index.IndexName naming one of the six hexastore indexes.
+type IndexName = index.IndexName
+
+// ObjectOrder is an alias for graph.ObjectOrder, the Pattern.OrderByObject
+// sort order of Get results.
+type ObjectOrder = graph.ObjectOrder
+
+// The orders Get can sort results in; see graph.ObjectOrder.
+const (
+	OrderNone        = graph.OrderNone
+	OrderAsc         = graph.OrderAsc
+	OrderDesc        = graph.OrderDesc
+	OrderNumericAsc  = graph.OrderNumericAsc
+	OrderNumericDesc = graph.OrderNumericDesc
+)
+
+// The hexastore indexes, named after the order of their key components.
+const (
+	IndexSPO = index.IndexSPO
+	IndexSOP = index.IndexSOP
+	IndexPOS = index.IndexPOS
+	IndexPSO = index.IndexPSO
+	IndexOPS = index.IndexOPS
+	IndexOSP = index.IndexOSP
+)
+
 var (
 	// NewTriple refers to graph.NewTriple
 	NewTriple = graph.NewTriple
@@ -87,23 +127,61 @@ var (
 	NewPattern = graph.NewPattern
 	// V refers to graph.V
 	V = graph.V
-	// Wildcard refers to graph.Wildcard
+	// Wildcard refers to graph.Wildcard: a pattern field matching any value.
+	// It is the zero value of a pattern field.
 	Wildcard = graph.Wildcard
-	// Exact refers to graph.Exact
+	// Exact refers to graph.Exact: a pattern field matching exactly the given
+	// bytes. Exact([]byte{}) matches only an empty value, never any value.
 	Exact = graph.Exact
-	// ExactString refers to graph.ExactString
+	// ExactString refers to graph.ExactString: Exact for a string value.
 	ExactString = graph.ExactString
-	// Binding refers to graph.Binding
+	// Binding refers to graph.Binding: a pattern field capturing the matched
+	// value into the named variable, equivalent to V(name) in NewPattern.
 	Binding = graph.Binding
+	// EncodeInt refers to graph.EncodeInt: an integer encoded so that byte
+	// order is numeric order.
+	EncodeInt = graph.EncodeInt
+	// EncodeFloat refers to graph.EncodeFloat: EncodeInt for a float64.
+	EncodeFloat = graph.EncodeFloat
+	// DecodeInt refers to graph.DecodeInt
+	DecodeInt = graph.DecodeInt
+	// DecodeFloat refers to graph.DecodeFloat
+	DecodeFloat = graph.DecodeFloat
+	// DecodeValue refers to graph.DecodeValue: the readable form of a
+	// possibly typed value.
+	DecodeValue = graph.DecodeValue
+	// MakeKey refers to graph.MakeKey: a composite value packing several
+	// parts, which may contain any bytes.
+	MakeKey = graph.MakeKey
+	// SplitKey refers to graph.SplitKey
+	SplitKey = graph.SplitKey
 )
 
 var (
 	// ErrClosed is returned when operating on a closed database.
 	ErrClosed = errors.New("levelgraph: database is closed")
-	// ErrInvalidTriple is returned when a triple is invalid.
-	ErrInvalidTriple = errors.New("levelgraph: invalid triple - subject, predicate, and object are required")
-	// ErrDimensionMismatch is returned when Embedder and VectorIndex have different dimensions.
-	ErrDimensionMismatch = errors.New("levelgraph: embedder and vector index dimension mismatch")
+	// ErrInvalidTriple is returned when a triple is nil or lacks its
+	// subject, predicate or object. The error names the missing part; see
+	// Triple.Validate.
+	ErrInvalidTriple = graph.ErrInvalidTriple
+	// ErrDimensionMismatch is returned when vector dimensions disagree: an Embedder
+	// and VectorIndex configured with different dimensions, or a vector or query
+	// whose length does not match the index.
+	ErrDimensionMismatch = errors.New("levelgraph: vector dimension mismatch")
+	// ErrPathRequired is returned by Open when no path is given.
+	ErrPathRequired = errors.New("levelgraph: path is required")
+	// ErrCorrupted is returned when a stored value cannot be decoded.
+	ErrCorrupted = errors.New("levelgraph: corrupted data")
+	// ErrReadOnly is returned by write operations on a database opened with WithReadOnly.
+	ErrReadOnly = errors.New("levelgraph: database is read-only")
+	// ErrComponentTooLarge is returned by Put when a subject, predicate or
+	// object is longer than Options.MaxComponentSize.
+	ErrComponentTooLarge = errors.New("levelgraph: triple component too large")
+	// ErrMultipleInClauses is returned when a pattern sets more than one of
+	// SubjectIn, PredicateIn and ObjectIn.
+	ErrMultipleInClauses = errors.New("levelgraph: only one IN clause allowed per pattern")
+	// ErrUnknownIndex is returned when an IndexName is not one of the six indexes.
+	ErrUnknownIndex = errors.New("levelgraph: unknown index")
 )
 
 // KVStore defines the interface for the underlying key-value store.
@@ -118,24 +196,53 @@ type KVStore interface {
 
 // DB represents a LevelGraph database.
 type DB struct {
-	store          KVStore
-	options        *Options
-	closed         bool
-	mu             sync.RWMutex
-	journalCounter uint64
+	store   KVStore
+	options *Options
+	closed  bool
+	mu      sync.RWMutex
+
+	closeOnce sync.Once // Guards shutdown so Close runs exactly once
+
+	// Journal sequence fields
+	journalMu     sync.Mutex // Guards the fields below
+	journalSeeded bool       // Whether journalSeq was loaded from the store
+	journalSeq    uint64     // Sequence number of the last journal entry
+	journalLastNs int64      // Timestamp of the last journal entry
+
+	internMu    sync.Mutex // Serializes node ID minting in Intern
+	versionMu   sync.Mutex // Serializes version numbering with WithVersioning
+	timestampMu sync.Mutex // Serializes createdAt checks with WithTimestamps
+
+	queryCache *queryCache // Get result cache, nil unless WithQueryCache is set
+	adjacency  *queryCache // Join edge cache, nil unless WithAdjacencyCache is set
+
+	tombstones atomic.Bool // Whether SoftDel tombstones may exist
+
+	// Vector delta log fields
+	vectorDeltaMu     sync.Mutex // Serializes vector writes with snapshot compaction
+	vectorDeltaCount  int        // Deltas recorded since the last snapshot
+	vectorDeltaSeeded bool       // Whether vectorDeltaSeq was loaded from the store
+	vectorDeltaSeq    uint64     // Sequence number of the last delta
+	vectorDeltaLastNs int64      // Timestamp of the last delta
 
 	// Async embedding fields
-	embedQueue   chan []*graph.Triple // Queue for async embedding
-	embedDone    chan struct{}        // Signals worker goroutine has finished
-	embedWg      sync.WaitGroup       // Tracks pending embed operations
-	embedStarted bool                 // Whether the embed worker was started
+	embedQueue    chan []*graph.Triple // Queue for async embedding
+	embedWorkers  sync.WaitGroup       // Tracks running worker goroutines
+	embedWg       sync.WaitGroup       // Tracks pending embed operations
+	embedStarted  bool                 // Whether the embed workers were started
+	embedMu       sync.Mutex           // Guards embedInFlight
+	embedInFlight map[string]bool      // Vector IDs being embedded right now
+	embedRetrying atomic.Int64         // Embed operations waiting to be retried
 }
 
 // Open opens or creates a LevelGraph database at the specified path.
-// For WebAssembly builds, use OpenWithStore with NewMemStore instead.
+// A new database is stamped with FormatVersion; an existing one written in a
+// different version is migrated with WithMigrations or rejected with
+// ErrVersionMismatch.
+// For WebAssembly builds, use OpenWithDB with NewMemStore instead.
 func Open(path string, opts ...Option) (*DB, error) {
 	if path == "" {
-		return nil, errors.New("levelgraph: path is required")
+		return nil, ErrPathRequired
 	}
 	options := applyOptions(opts...)
 
@@ -144,14 +251,25 @@ func Open(path string, opts ...Option) (*DB, error) {
 		return nil, err
 	}
 
-	store, err := openLevelDB(path)
+	store, err := openLevelDB(path, options.ReadOnly)
 	if err != nil {
 		return nil, fmt.Errorf("levelgraph: open %s: %w", path, err)
 	}
 
 	db := &DB{
-		store:   store,
-		options: options,
+		store:      store,
+		options:    options,
+		queryCache: newQueryCache(options.QueryCacheSize),
+		adjacency:  newQueryCache(options.AdjacencyCacheSize),
+	}
+
+	if err := db.checkFormatVersion(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	if err := db.loadTombstones(); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("levelgraph: %w", err)
 	}
 
 	// Start async embed worker if enabled
@@ -175,8 +293,17 @@ func OpenWithDB(store KVStore, opts ...Option) (*DB, error) {
 	}
 
 	db := &DB{
-		store:   store,
-		options: options,
+		store:      store,
+		options:    options,
+		queryCache: newQueryCache(options.QueryCacheSize),
+		adjacency:  newQueryCache(options.AdjacencyCacheSize),
+	}
+
+	if err := db.checkFormatVersion(); err != nil {
+		return nil, err
+	}
+	if err := db.loadTombstones(); err != nil {
+		return nil, fmt.Errorf("levelgraph: %w", err)
 	}
 
 	// Start async embed worker if enabled
@@ -188,6 +315,13 @@ func OpenWithDB(store KVStore, opts ...Option) (*DB, error) {
 // validateOptions validates the option configuration.
 // Returns an error if the configuration is invalid.
 func validateOptions(options *Options) error {
+	// The delta log persists the HNSW graph structure, which only HNSW has
+	if options.VectorDeltaLog {
+		if _, ok := options.VectorIndex.(*vector.HNSWIndex); !ok {
+			return ErrVectorSnapshotUnsupported
+		}
+	}
+
 	// Validate that Embedder and VectorIndex dimensions match
 	if options.Embedder != nil && options.VectorIndex != nil {
 		embedDims := options.Embedder.Dimensions()
@@ -202,57 +336,49 @@ func validateOptions(options *Options) error {
 
 // Close closes the database.
 // If async embedding is enabled, Close waits for all pending embeddings to complete.
+// Close is safe to call more than once and from concurrent goroutines; only the
+// first call closes the store, and later calls return nil.
 func (db *DB) Close() error {
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
-	if db.closed {
-		return nil
-	}
-
-	db.closed = true
-
-	// Stop embed worker if running
-	db.stopEmbedWorker()
-
-	if db.options.Logger != nil {
-		db.options.Logger.Info("database closed")
-	}
-
-	return db.store.Close()
+	return db.shutdown("database closed")
 }
 
 // CloseGracefully closes the database gracefully, waiting for the context
 // to be cancelled or for a clean shutdown. This allows pending read operations
 // and async embeddings to complete before closing.
 func (db *DB) CloseGracefully(ctx context.Context) error {
-	// First, mark as closing to prevent new writes
-	db.mu.Lock()
-	if db.closed {
-		db.mu.Unlock()
-		return nil
-	}
-
-	// Check context before proceeding
+	// Check context before proceeding; a cancelled context leaves the
+	// database open so a later Close can still shut it down.
 	select {
 	case <-ctx.Done():
-		db.mu.Unlock()
+		if !db.IsOpen() {
+			return nil
+		}
 		return fmt.Errorf("levelgraph: graceful close: %w", ctx.Err())
 	default:
 	}
 
-	db.closed = true
+	return db.shutdown("database closed gracefully")
+}
 
-	// Stop embed worker if running
-	db.stopEmbedWorker()
+// shutdown marks the database closed, drains the async embed workers and
+// closes the store. Only the first call does any work and sees the store's
+// Close error; every later or concurrent call returns nil once the first has
+// finished.
+func (db *DB) shutdown(msg string) (err error) {
+	db.closeOnce.Do(func() {
+		db.mu.Lock()
+		db.closed = true
 
-	err := db.store.Close()
-	db.mu.Unlock()
+		// Stop embed worker if running
+		db.stopEmbedWorker()
 
-	if db.options.Logger != nil {
-		db.options.Logger.Info("database closed gracefully")
-	}
+		err = db.store.Close()
+		db.mu.Unlock()
 
+		if db.options.Logger != nil {
+			db.options.Logger.Info(msg)
+		}
+	})
 	return err
 }
 
@@ -263,6 +389,16 @@ func (db *DB) IsOpen() bool {
 	return !db.closed
 }
 
+// emitLogEvent reports a completed operation to the LogHook if it took at
+// least SlowThreshold.
+func (db *DB) emitLogEvent(op string, start time.Time, results int, err error) {
+	d := time.Since(start)
+	if d < db.options.SlowThreshold {
+		return
+	}
+	db.options.LogHook(LogEvent{Operation: op, Duration: d, Results: results, Err: err})
+}
+
 // V creates a new Variable for use in queries.
 // This is a convenience method that calls the package-level V function.
 func (db *DB) V(name string) *graph.Variable {
@@ -273,6 +409,30 @@ func (db *DB) V(name string) *graph.Variable {
 // If auto-embedding is enabled (via WithAutoEmbed), vectors will be
 // automatically generated for the configured triple components.
 func (db *DB) Put(ctx context.Context, triples ...*graph.Triple) error {
+	return db.PutWithOptions(ctx, PutOptions{}, triples...)
+}
+
+// PutOptions turns off side effects of a put, for bulk loads that rebuild
+// them afterwards.
+type PutOptions struct {
+	// SkipJournal writes no journal entries for the triples, even with
+	// WithJournal enabled.
+	SkipJournal bool
+
+	// SkipAutoEmbed embeds nothing for the triples, even with WithAutoEmbed
+	// enabled. Call ReEmbedAll after the load to fill in the vectors.
+	SkipAutoEmbed bool
+}
+
+// PutWithOptions is Put with the side effects in opts turned off. Index
+// entries, inverse triples and text postings are always written.
+//
+// Example:
+//
+//	// Bulk import without journalling or embedding, then embed once
+//	err := db.PutWithOptions(ctx, levelgraph.PutOptions{SkipJournal: true, SkipAutoEmbed: true}, triples...)
+//	err = db.ReEmbedAll(ctx)
+func (db *DB) PutWithOptions(ctx context.Context, opts PutOptions, triples ...*graph.Triple) error {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
@@ -280,42 +440,22 @@ func (db *DB) Put(ctx context.Context, triples ...*graph.Triple) error {
 		return fmt.Errorf("levelgraph: %w", ErrClosed)
 	}
 
+	if db.options.ReadOnly {
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
 	select {
 	case <-ctx.Done():
 		return fmt.Errorf("levelgraph: %w", ctx.Err())
 	default:
 	}
 
-	batch := NewBatch()
-
-	for _, triple := range triples {
-		if err := validateTriple(triple); err != nil {
-			return fmt.Errorf("levelgraph: %w", err)
-		}
-
-		ops, err := db.generateBatchOps(triple, "put")
-		if err != nil {
-			return fmt.Errorf("levelgraph: %w", err)
-		}
-
-		for _, op := range ops {
-			batch.Put(op.Key, op.Value)
-		}
-
-		// Record in journal if enabled
-		if db.options.JournalEnabled {
-			if err := db.recordJournalEntry(batch, "put", triple); err != nil {
-				return fmt.Errorf("levelgraph: journal: %w", err)
-			}
-		}
-	}
-
-	if err := db.store.Write(batch, nil); err != nil {
-		return fmt.Errorf("levelgraph: write batch: %w", err)
+	if err := db.writeTriples(triples, "put", !opts.SkipJournal); err != nil {
+		return err
 	}
 
 	// Auto-embed if configured (done after write to not block on embedding)
-	if db.options.Embedder != nil && db.options.AutoEmbedTargets != AutoEmbedNone && db.options.VectorIndex != nil {
+	if !opts.SkipAutoEmbed && db.options.Embedder != nil && db.autoEmbedConfigured() && db.options.VectorIndex != nil {
 		if err := db.autoEmbedTriples(ctx, triples); err != nil {
 			// Log but don't fail the Put - embedding is secondary
 			if db.options.Logger != nil {
@@ -330,7 +470,10 @@ func (db *DB) Put(ctx context.Context, triples ...*graph.Triple) error {
 	return nil
 }
 
-// Del deletes one or more triples from the database.
+// Del deletes one or more triples from the database, together with their
+// triple facets and triple vectors. Facets and vectors of subject, predicate
+// and object values are kept even when no triple uses the value any more;
+// GC removes those.
 func (db *DB) Del(ctx context.Context, triples ...*graph.Triple) error {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
@@ -339,48 +482,197 @@ func (db *DB) Del(ctx context.Context, triples ...*graph.Triple) error {
 		return fmt.Errorf("levelgraph: %w", ErrClosed)
 	}
 
+	if db.options.ReadOnly {
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
 	select {
 	case <-ctx.Done():
 		return fmt.Errorf("levelgraph: %w", ctx.Err())
 	default:
 	}
 
+	if err := db.writeTriples(triples, "del", true); err != nil {
+		return err
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("del", "count", len(triples))
+	}
+	return nil
+}
+
+// writeTriples writes the index entries (and journal entries, if enabled and
+// journal is set) for triples, where action is "put" or "del". All triples are validated
+// before anything is written. With MaxBatchSize set, the writes are split
+// into batches of at most that many triples.
+// Caller must hold at least a read lock.
+func (db *DB) writeTriples(triples []*graph.Triple, action string, journal bool) error {
+	triples, err := db.prepareTriples(triples, action)
+	if err != nil {
+		return err
+	}
+	// Invalidate caches after the writes, including a partial failure
+	if db.queryCache != nil {
+		defer db.queryCache.invalidate(triples)
+	}
+	if db.adjacency != nil {
+		defer db.adjacency.invalidate(triples)
+	}
+
 	batch := NewBatch()
+	pending := 0
 
-	for _, triple := range triples {
-		if err := validateTriple(triple); err != nil {
-			return fmt.Errorf("levelgraph: %w", err)
-		}
+	var versions map[string]int64
+	if action == "put" && db.options.Versioning {
+		db.versionMu.Lock()
+		defer db.versionMu.Unlock()
+		versions = make(map[string]int64)
+	}
 
-		ops, err := db.generateBatchOps(triple, "del")
+	var stamp int64
+	if action == "put" && db.options.Timestamps {
+		db.timestampMu.Lock()
+		defer db.timestampMu.Unlock()
+		stamp = time.Now().UnixNano()
+	}
+
+	for _, triple := range triples {
+		ops, err := db.generateBatchOps(triple, action)
 		if err != nil {
 			return fmt.Errorf("levelgraph: %w", err)
 		}
 
 		for _, op := range ops {
-			batch.Delete(op.Key)
+			if action == "put" {
+				batch.Put(op.Key, op.Value)
+			} else {
+				batch.Delete(op.Key)
+			}
+		}
+
+		if db.options.TextIndex {
+			db.recordTextIndex(batch, action, triple)
+		}
+
+		if versions != nil {
+			if err := db.recordVersion(batch, triple, versions); err != nil {
+				return fmt.Errorf("levelgraph: record version: %w", err)
+			}
+		}
+
+		if stamp != 0 {
+			if err := db.recordTimestamps(batch, triple, stamp); err != nil {
+				return fmt.Errorf("levelgraph: record timestamps: %w", err)
+			}
+		}
+
+		// Deleting a triple deletes its facets too, and its version and
+		// timestamps
+		if action == "del" && db.options.FacetsEnabled {
+			if err := db.deleteTripleFacets(batch, triple); err != nil {
+				return fmt.Errorf("levelgraph: delete triple facets: %w", err)
+			}
+		}
+		if action == "del" && db.options.Versioning {
+			batch.Delete(genTripleVersionKey(triple))
+		}
+		if action == "del" && db.options.Timestamps {
+			deleteTimestamps(batch, triple)
+		}
+
+		// Putting or deleting a triple clears its soft-delete tombstone
+		if db.tombstones.Load() {
+			batch.Delete(genTombstoneKey(triple))
 		}
 
 		// Record in journal if enabled
-		if db.options.JournalEnabled {
-			if err := db.recordJournalEntry(batch, "del", triple); err != nil {
+		if journal && db.options.JournalEnabled {
+			if err := db.recordJournalEntry(batch, action, triple); err != nil {
 				return fmt.Errorf("levelgraph: journal: %w", err)
 			}
 		}
+
+		pending++
+		if db.options.MaxBatchSize > 0 && pending >= db.options.MaxBatchSize {
+			if err := db.writeBatch(batch); err != nil {
+				return fmt.Errorf("levelgraph: write batch: %w", err)
+			}
+			batch = NewBatch()
+			pending = 0
+		}
 	}
 
-	if err := db.store.Write(batch, nil); err != nil {
-		return fmt.Errorf("levelgraph: write batch: %w", err)
+	if pending > 0 || len(triples) == 0 {
+		if err := db.writeBatch(batch); err != nil {
+			return fmt.Errorf("levelgraph: write batch: %w", err)
+		}
 	}
 
-	if db.options.Logger != nil {
-		db.options.Logger.Debug("del", "count", len(triples))
+	if action == "del" && db.options.VectorIndex != nil {
+		if err := db.removeTripleVectors(triples); err != nil {
+			return fmt.Errorf("levelgraph: %w", err)
+		}
 	}
+
 	return nil
 }
 
+// prepareTriples validates triples for a write, where action is "put" or
+// "del", and returns the triples the write stores or deletes: triples with
+// predicate aliases and inverse predicates applied.
+func (db *DB) prepareTriples(triples []*graph.Triple, action string) ([]*graph.Triple, error) {
+	for _, triple := range triples {
+		if err := triple.Validate(); err != nil {
+			return nil, fmt.Errorf("levelgraph: %w", err)
+		}
+		if action == "put" {
+			if err := db.checkComponentSize(triple); err != nil {
+				return nil, fmt.Errorf("levelgraph: %w", err)
+			}
+		}
+	}
+	return db.withInverses(db.canonicalTriples(triples)), nil
+}
+
+// withInverses appends the inverse of each triple whose predicate has one in
+// Options.InversePredicates. Inverses identical to a triple already in the
+// list, such as the reverse of a self-loop with a self-inverse predicate,
+// are not added again.
+func (db *DB) withInverses(triples []*graph.Triple) []*graph.Triple {
+	if len(db.options.InversePredicates) == 0 {
+		return triples
+	}
+
+	seen := make(map[string]bool, len(triples))
+	for _, triple := range triples {
+		seen[string(index.GenKey(index.IndexSPO, triple))] = true
+	}
+
+	result := append([]*graph.Triple(nil), triples...)
+	for _, triple := range triples {
+		inverse, ok := db.options.InversePredicates[string(triple.Predicate)]
+		if !ok {
+			continue
+		}
+		reversed := triple.Reverse()
+		reversed.Predicate = []byte(inverse)
+		key := string(index.GenKey(index.IndexSPO, reversed))
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, reversed)
+		}
+	}
+	return result
+}
+
 // Get retrieves triples matching the given pattern.
-func (db *DB) Get(ctx context.Context, pattern *graph.Pattern) ([]*graph.Triple, error) {
+func (db *DB) Get(ctx context.Context, pattern *graph.Pattern) (result []*graph.Triple, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("get", start, len(result), err) }()
+	}
+
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
@@ -394,13 +686,21 @@ func (db *DB) Get(ctx context.Context, pattern *graph.Pattern) ([]*graph.Triple,
 	default:
 	}
 
-	return db.getUnlocked(pattern)
+	if db.queryCache != nil {
+		return db.cachedGet(pattern)
+	}
+	return db.getUnlocked(pattern, "")
 }
 
 // getUnlocked is the internal get method that doesn't acquire locks.
+// idx is passed on to getIteratorUnlocked.
 // Caller must hold at least a read lock.
-func (db *DB) getUnlocked(pattern *graph.Pattern) ([]*graph.Triple, error) {
-	iter, err := db.getIteratorUnlocked(pattern)
+func (db *DB) getUnlocked(pattern *graph.Pattern, idx IndexName) ([]*graph.Triple, error) {
+	if pattern.OrderByObject != graph.OrderNone {
+		return db.getOrderedUnlocked(pattern, idx)
+	}
+
+	iter, err := db.getIteratorUnlocked(pattern, idx)
 	if err != nil {
 		return nil, err
 	}
@@ -422,6 +722,44 @@ func (db *DB) getUnlocked(pattern *graph.Pattern) ([]*graph.Triple, error) {
 	return results, nil
 }
 
+// getOrderedUnlocked is getUnlocked for a pattern with OrderByObject set:
+// it reads every match, sorts them, then applies Offset and Limit.
+// Caller must hold at least a read lock.
+func (db *DB) getOrderedUnlocked(pattern *graph.Pattern, idx IndexName) ([]*graph.Triple, error) {
+	all := *pattern
+	all.OrderByObject = graph.OrderNone
+	all.Offset, all.Limit = 0, 0
+	iter, err := db.getIteratorUnlocked(&all, idx)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+	iter.limit = 0 // DefaultLimit applies after sorting
+
+	var results []*graph.Triple
+	for iter.Next() {
+		triple, err := iter.Triple()
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: parse triple: %w", err)
+		}
+		results = append(results, triple)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(results, pattern.OrderByObject.Compare)
+	results = results[min(max(pattern.Offset, 0), len(results)):]
+	limit := pattern.Limit
+	if limit <= 0 && db.options.DefaultLimit > 0 {
+		limit = db.options.DefaultLimit
+	}
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
 // GetIterator returns an iterator for triples matching the pattern.
 func (db *DB) GetIterator(ctx context.Context, pattern *graph.Pattern) (*TripleIterator, error) {
 	db.mu.RLock()
@@ -431,35 +769,143 @@ func (db *DB) GetIterator(ctx context.Context, pattern *graph.Pattern) (*TripleI
 		return nil, ErrClosed
 	}
 
-	return db.getIteratorUnlocked(pattern)
+	return db.getIteratorUnlocked(pattern, "")
 }
 
 // getIteratorUnlocked is the internal iterator method that doesn't acquire locks.
+// idx, if set, is the index to scan and must have the pattern's concrete
+// fields as its prefix; otherwise the index is chosen from those fields.
 // Caller must hold at least a read lock.
-func (db *DB) getIteratorUnlocked(pattern *graph.Pattern) (*TripleIterator, error) {
+func (db *DB) getIteratorUnlocked(pattern *graph.Pattern, idx IndexName) (*TripleIterator, error) {
+	pattern = db.canonicalPattern(pattern)
+	inFields := pattern.InFields()
+	if len(inFields) > 1 {
+		return nil, ErrMultipleInClauses
+	}
+
+	// Apply default limit if pattern has no limit and a default is configured
+	limit := pattern.Limit
+	if limit <= 0 && db.options.DefaultLimit > 0 {
+		limit = db.options.DefaultLimit
+	}
+
+	ti := &TripleIterator{
+		store:       db.store,
+		pattern:     pattern,
+		offset:      pattern.Offset,
+		limit:       limit,
+		reverse:     pattern.Reverse,
+		skipDeleted: db.tombstones.Load(),
+	}
+
 	// Determine the best index to use
 	fields := pattern.ConcreteFields()
-	idx := index.FindIndex(fields, "")
+	if len(inFields) == 1 && seekInClause(pattern, inFields[0], fields) {
+		ti.pending = inClauseRanges(pattern, inFields[0])
+		if len(ti.pending) == 0 {
+			// An empty IN clause matches nothing.
+			key := index.GenKeyFromPattern(index.IndexSPO, pattern)
+			ti.iter = db.store.NewIterator(&Range{Start: key, Limit: key}, nil)
+			return ti, nil
+		}
+		if ti.reverse {
+			slices.Reverse(ti.pending)
+		}
+		ti.iter = db.store.NewIterator(ti.pending[0], nil)
+		ti.pending = ti.pending[1:]
+		return ti, nil
+	}
+
+	if idx == "" {
+		idx = chooseIndex(pattern)
+	}
 
 	// Create range for the query
 	startKey := index.GenKeyFromPattern(idx, pattern)
 	endKey := index.GenKeyWithUpperBound(idx, pattern)
 
-	iter := db.store.NewIterator(&Range{Start: startKey, Limit: endKey}, nil)
+	ti.iter = db.store.NewIterator(&Range{Start: startKey, Limit: endKey}, nil)
+	ti.checkIn = len(inFields) == 1
+	return ti, nil
+}
 
-	// Apply default limit if pattern has no limit and a default is configured
-	limit := pattern.Limit
-	if limit <= 0 && db.options.DefaultLimit > 0 {
-		limit = db.options.DefaultLimit
+// ChooseIndex reports how Get scans for p: the index it reads, the key
+// prefix shared by every key it reads, and whether it reads backwards. The
+// prefix is relative to the index, as for RawIter, so the keys a RawIterator
+// over idx visits from Seek(prefix) while they start with prefix are exactly
+// the keys Get considers:
+//
+//	idx, prefix, _ := db.ChooseIndex(pattern)
+//	it, err := db.RawIterator(ctx, idx)
+//	for ok := it.Seek(prefix); ok && bytes.HasPrefix(it.Key(), prefix); ok = it.Next() {
+//	    // ...
+//	}
+//
+// When an IN clause is answered by one seek per value, the prefix covers
+// only the concrete fields and the IN field follows it. ChooseIndex does not
+// read the database.
+func (db *DB) ChooseIndex(p *Pattern) (idx IndexName, prefix []byte, reverse bool) {
+	if p == nil {
+		p = &graph.Pattern{}
+	}
+	idx = chooseIndex(p)
+	key := index.GenKeyFromPattern(idx, p)
+	return idx, key[len(index.GenKeyFromPattern(idx, &graph.Pattern{})):], p.Reverse
+}
+
+// chooseIndex returns the index Get scans for pattern: one with the
+// concrete fields as its prefix, followed by the IN field when the IN
+// clause is answered by seeks.
+func chooseIndex(pattern *graph.Pattern) IndexName {
+	fields := pattern.ConcreteFields()
+	if in := pattern.InFields(); len(in) == 1 && seekInClause(pattern, in[0], fields) {
+		fields = append(slices.Clone(fields), in[0])
 	}
+	return index.FindIndex(fields, "")
+}
 
-	return &TripleIterator{
-		iter:    iter,
-		pattern: pattern,
-		offset:  pattern.Offset,
-		limit:   limit,
-		reverse: pattern.Reverse,
-	}, nil
+// inSeekThreshold is the largest IN clause that is answered with one index
+// seek per value when another concrete field already narrows the scan.
+// Bigger sets are answered with a single scan and a membership check.
+const inSeekThreshold = 64
+
+// seekInClause decides how to answer an IN clause on field: by one index
+// range per value, or by a single scan filtered on membership. Seeking is
+// preferred unless the field is already concrete or the set is large and the
+// scan range is narrow anyway.
+func seekInClause(pattern *graph.Pattern, field string, fields []string) bool {
+	if pattern.GetConcreteValue(field) != nil {
+		return false
+	}
+	return len(fields) == 0 || len(pattern.InValues(field)) <= inSeekThreshold
+}
+
+// inClauseRanges returns one key range per distinct value of the IN clause on
+// field, in key order, using an index that has the concrete fields and the IN
+// field as its prefix.
+func inClauseRanges(pattern *graph.Pattern, field string) []*Range {
+	values := slices.Clone(pattern.InValues(field))
+	slices.SortFunc(values, bytes.Compare)
+	values = slices.CompactFunc(values, bytes.Equal)
+
+	idx := chooseIndex(pattern)
+	ranges := make([]*Range, 0, len(values))
+	for _, value := range values {
+		bound := *pattern
+		switch field {
+		case "subject":
+			bound.Subject = graph.Exact(value)
+		case "predicate":
+			bound.Predicate = graph.Exact(value)
+		case "object":
+			bound.Object = graph.Exact(value)
+		}
+		ranges = append(ranges, &Range{
+			Start: index.GenKeyFromPattern(idx, &bound),
+			Limit: index.GenKeyWithUpperBound(idx, &bound),
+		})
+	}
+	return ranges
 }
 
 // GenerateBatch generates batch operations for a triple.
@@ -477,7 +923,7 @@ type BatchOp struct {
 
 // generateBatchOps generates the batch operations for all indexes.
 func (db *DB) generateBatchOps(triple *graph.Triple, action string) ([]BatchOp, error) {
-	value, err := triple.MarshalBinary()
+	value, err := db.encodeTripleValue(triple)
 	if err != nil {
 		return nil, fmt.Errorf("levelgraph: marshal triple: %w", err)
 	}
@@ -496,20 +942,43 @@ func (db *DB) generateBatchOps(triple *graph.Triple, action string) ([]BatchOp,
 	return ops, nil
 }
 
-// validateTriple checks that a triple has all required fields.
-func validateTriple(triple *graph.Triple) error {
-	if triple == nil {
-		return ErrInvalidTriple
+// checkComponentSize returns ErrComponentTooLarge if any part of triple is
+// longer than Options.MaxComponentSize.
+func (db *DB) checkComponentSize(triple *graph.Triple) error {
+	limit := db.options.MaxComponentSize
+	if limit <= 0 {
+		return nil
 	}
-	if triple.Subject == nil || triple.Predicate == nil || triple.Object == nil {
-		return ErrInvalidTriple
+	if len(triple.Subject) > limit || len(triple.Predicate) > limit || len(triple.Object) > limit {
+		return ErrComponentTooLarge
 	}
 	return nil
 }
 
+// writeBatch commits batch to the store, retrying transient failures as
+// configured by WithWriteRetry.
+func (db *DB) writeBatch(batch *Batch) error {
+	backoff := db.options.WriteRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := db.store.Write(batch, nil)
+		if err == nil || attempt >= db.options.WriteRetries || isPermanentStoreError(err) {
+			return err
+		}
+		if db.options.Logger != nil {
+			db.options.Logger.Warn("write failed, retrying", "attempt", attempt+1, "error", err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
 // TripleIterator iterates over triples from a query.
 type TripleIterator struct {
 	iter         Iterator
+	store        KVStore
+	pending      []*Range // further ranges to scan once iter is exhausted
+	checkIn      bool     // filter on the pattern's IN clause
+	skipDeleted  bool     // skip triples soft-deleted with SoftDel
 	pattern      *graph.Pattern
 	offset       int
 	limit        int
@@ -544,18 +1013,33 @@ func (ti *TripleIterator) Next() bool {
 		}
 
 		if !hasNext {
-			return false
+			if len(ti.pending) == 0 || ti.iter.Error() != nil {
+				return false
+			}
+			ti.iter.Release()
+			ti.iter = ti.store.NewIterator(ti.pending[0], nil)
+			ti.pending = ti.pending[1:]
+			ti.started = false
+			continue
 		}
 
-		// Apply filter if present
-		if ti.pattern.Filter != nil {
+		// Apply IN clause, filter and tombstones if present
+		if ti.checkIn || ti.pattern.Filter != nil || ti.skipDeleted {
 			triple, err := ti.parseCurrentValue()
 			if err != nil {
 				continue
 			}
-			if !ti.pattern.Filter(triple) {
+			if ti.checkIn && !ti.pattern.Matches(triple) {
+				continue
+			}
+			if ti.pattern.Filter != nil && !ti.pattern.Filter(triple) {
 				continue
 			}
+			if ti.skipDeleted {
+				if _, err := ti.store.Get(genTombstoneKey(triple), nil); err == nil {
+					continue
+				}
+			}
 		}
 
 		// Handle offset
@@ -577,12 +1061,7 @@ func (ti *TripleIterator) Triple() (*graph.Triple, error) {
 
 // parseCurrentValue parses the current iterator value into a Triple.
 func (ti *TripleIterator) parseCurrentValue() (*graph.Triple, error) {
-	value := ti.iter.Value()
-	var triple graph.Triple
-	if err := triple.UnmarshalBinary(value); err != nil {
-		return nil, err
-	}
-	return &triple, nil
+	return decodeTripleValue(ti.iter.Value())
 }
 
 // Error returns any error from the iterator.
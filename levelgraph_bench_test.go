@@ -318,6 +318,36 @@ func BenchmarkJournalPut(b *testing.B) {
 	}
 }
 
+// BenchmarkJournalPutSkipJournal measures bulk-load Put performance with the
+// journal enabled but skipped via PutOptions.
+func BenchmarkJournalPutSkipJournal(b *testing.B) {
+	dir, err := os.MkdirTemp("", "levelgraph-bench-journal-*")
+	if err != nil {
+		b.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "bench.db")
+	db, err := Open(dbPath, WithJournal())
+	if err != nil {
+		b.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	opts := PutOptions{SkipJournal: true}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		triple := graph.NewTripleFromStrings(
+			fmt.Sprintf("subject%d", i),
+			"predicate",
+			fmt.Sprintf("object%d", i),
+		)
+		if err := db.PutWithOptions(context.Background(), opts, triple); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // BenchmarkFacetSet measures facet set performance.
 func BenchmarkFacetSet(b *testing.B) {
 	dir, err := os.MkdirTemp("", "levelgraph-bench-facet-*")
@@ -397,3 +427,38 @@ func BenchmarkIndexGenKeys(b *testing.B) {
 		_ = index.GenKeys(triple)
 	}
 }
+
+// BenchmarkBulkLoadSorted measures loading 100k triples with BulkLoadSorted.
+func BenchmarkBulkLoadSorted(b *testing.B) {
+	triples := bulkTriples(100000)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		db, cleanup := setupBenchDB(b)
+		b.StartTimer()
+		if err := db.BulkLoadSorted(context.Background(), triples); err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		cleanup()
+	}
+}
+
+// BenchmarkBulkLoadPutLoop measures loading the same 100k triples with one
+// Put per triple, for comparison with BenchmarkBulkLoadSorted.
+func BenchmarkBulkLoadPutLoop(b *testing.B) {
+	triples := bulkTriples(100000)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		db, cleanup := setupBenchDB(b)
+		b.StartTimer()
+		for _, triple := range triples {
+			if err := db.Put(context.Background(), triple); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.StopTimer()
+		cleanup()
+	}
+}
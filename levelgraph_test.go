@@ -25,10 +25,15 @@
 package levelgraph
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +41,7 @@ import (
 
 	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
 	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+	"github.com/benbenbenbenbenben/levelgraph/vector"
 )
 
 func TestTriple(t *testing.T) {
@@ -371,6 +377,126 @@ func TestDB_PutAndGet(t *testing.T) {
 	})
 }
 
+func TestDB_EmptyComponents(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	empty := graph.NewTriple([]byte("s"), []byte("p"), []byte{})
+	if err := db.Put(ctx, empty, graph.NewTripleFromStrings("s", "p", "o")); err != nil {
+		t.Fatalf("Put() with empty object error = %v", err)
+	}
+	err := db.Put(ctx, graph.NewTriple([]byte("s"), []byte("p"), nil))
+	if !errors.Is(err, ErrInvalidTriple) {
+		t.Errorf("Put() with nil object error = %v, want ErrInvalidTriple", err)
+	} else if !strings.Contains(err.Error(), "object is nil") {
+		t.Errorf("Put() with nil object error = %q, want it to name the object", err)
+	}
+
+	tests := []struct {
+		name    string
+		pattern *graph.Pattern
+		want    int
+	}{
+		{"NewPattern empty is wildcard", graph.NewPattern("s", "p", ""), 2},
+		{"Wildcard", &graph.Pattern{Subject: ExactString("s"), Object: Wildcard()}, 2},
+		{"Exact empty", &graph.Pattern{Subject: ExactString("s"), Object: Exact([]byte{})}, 1},
+		{"Exact nil", &graph.Pattern{Subject: ExactString("s"), Object: Exact(nil)}, 1},
+		{"ExactString empty", &graph.Pattern{Object: ExactString("")}, 1},
+	}
+	for _, tt := range tests {
+		results, err := db.Get(ctx, tt.pattern)
+		if err != nil {
+			t.Fatalf("%s: Get() error = %v", tt.name, err)
+		}
+		if len(results) != tt.want {
+			t.Errorf("%s: Get() returned %d triples, want %d", tt.name, len(results), tt.want)
+		}
+	}
+
+	// A variable bound to an empty value joins on it exactly.
+	solutions, err := db.Search(ctx, []*Pattern{
+		{Subject: ExactString("s"), Predicate: ExactString("p"), Object: Binding("o")},
+		{Subject: ExactString("s"), Predicate: ExactString("p"), Object: Binding("o")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(solutions) != 2 {
+		t.Errorf("Search() returned %d solutions, want 2", len(solutions))
+	}
+
+	if err := db.Del(ctx, empty); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	results, err := db.Get(ctx, &graph.Pattern{Object: Exact([]byte{})})
+	if err != nil || len(results) != 0 {
+		t.Errorf("Get() after Del = %v, %v, want none", results, err)
+	}
+}
+
+func TestDB_PatternHelpers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "likes", "tennis"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("carol", "knows", "alice"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	getTests := []struct {
+		name    string
+		helpers *Pattern
+		raw     *Pattern
+	}{
+		{"subject", &Pattern{Subject: ExactString("alice")}, NewPattern("alice", nil, nil)},
+		{"bytes", &Pattern{Predicate: Exact([]byte("knows")), Object: ExactString("bob")}, NewPattern(nil, []byte("knows"), "bob")},
+		{"wildcard", &Pattern{Subject: Wildcard(), Predicate: ExactString("knows")}, NewPattern("", "knows", nil)},
+		{"all", &Pattern{}, NewPattern(nil, nil, nil)},
+	}
+	for _, tt := range getTests {
+		want, err := db.Get(ctx, tt.raw)
+		if err != nil {
+			t.Fatalf("%s: Get(raw) error = %v", tt.name, err)
+		}
+		got, err := db.Get(ctx, tt.helpers)
+		if err != nil {
+			t.Fatalf("%s: Get(helpers) error = %v", tt.name, err)
+		}
+		if len(want) == 0 || len(got) != len(want) {
+			t.Fatalf("%s: Get(helpers) = %v, Get(raw) = %v", tt.name, got, want)
+		}
+		for i := range got {
+			if !got[i].Equal(want[i]) {
+				t.Errorf("%s: triple %d = %v, want %v", tt.name, i, got[i], want[i])
+			}
+		}
+	}
+
+	want, err := db.Search(ctx, []*Pattern{
+		NewPattern("alice", "knows", V("x")),
+		NewPattern(V("x"), "knows", V("y")),
+	}, nil)
+	if err != nil {
+		t.Fatalf("Search(raw) error = %v", err)
+	}
+	got, err := db.Search(ctx, []*Pattern{
+		{Subject: ExactString("alice"), Predicate: ExactString("knows"), Object: Binding("x")},
+		{Subject: Binding("x"), Predicate: ExactString("knows"), Object: Binding("y")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Search(helpers) error = %v", err)
+	}
+	if len(want) != 1 || len(got) != len(want) || !got[0].Equal(want[0]) {
+		t.Errorf("Search(helpers) = %v, Search(raw) = %v", got, want)
+	}
+}
+
 func TestDB_Del(t *testing.T) {
 	t.Parallel()
 	db, cleanup := setupTestDB(t)
@@ -560,6 +686,436 @@ func TestDB_Options(t *testing.T) {
 	}
 }
 
+func TestDB_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("PathRequired", func(t *testing.T) {
+		if _, err := Open(""); !errors.Is(err, ErrPathRequired) {
+			t.Errorf("Open(\"\") error = %v, want ErrPathRequired", err)
+		}
+	})
+
+	t.Run("InvalidTriple", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		if err := db.Put(ctx, &graph.Triple{Subject: []byte("a")}); !errors.Is(err, ErrInvalidTriple) {
+			t.Errorf("Put() error = %v, want ErrInvalidTriple", err)
+		}
+		if err := db.Del(ctx, nil); !errors.Is(err, ErrInvalidTriple) {
+			t.Errorf("Del() error = %v, want ErrInvalidTriple", err)
+		}
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		triple := graph.NewTripleFromStrings("a", "b", "c")
+		for _, key := range index.GenKeys(triple) {
+			if err := db.store.Put(key, []byte{0xff}, nil); err != nil {
+				t.Fatalf("store.Put() error = %v", err)
+			}
+		}
+		if _, err := db.Get(ctx, &graph.Pattern{}); !errors.Is(err, ErrCorrupted) {
+			t.Errorf("Get() error = %v, want ErrCorrupted", err)
+		}
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		db, _ := setupTestDB(t)
+		db.Close()
+
+		triple := graph.NewTripleFromStrings("a", "b", "c")
+		if err := db.Put(ctx, triple); !errors.Is(err, ErrClosed) {
+			t.Errorf("Put() error = %v, want ErrClosed", err)
+		}
+		if err := db.Del(ctx, triple); !errors.Is(err, ErrClosed) {
+			t.Errorf("Del() error = %v, want ErrClosed", err)
+		}
+		if _, err := db.Get(ctx, &graph.Pattern{}); !errors.Is(err, ErrClosed) {
+			t.Errorf("Get() error = %v, want ErrClosed", err)
+		}
+		if _, err := db.Search(ctx, []*Pattern{{Subject: Binding("x")}}, nil); !errors.Is(err, ErrClosed) {
+			t.Errorf("Search() error = %v, want ErrClosed", err)
+		}
+	})
+}
+
+func TestDB_ClosedReturnsErrClosed(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	db, err := Open(filepath.Join(dir, "test.db"), WithJournal(), WithFacets(), WithVectors(vector.NewFlatIndex(3)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	ctx := context.Background()
+	triple := graph.NewTripleFromStrings("a", "b", "c")
+	if err := db.Put(ctx, triple); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	db.Close()
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"Put", func() error { return db.Put(ctx, triple) }},
+		{"Del", func() error { return db.Del(ctx, triple) }},
+		{"Get", func() error { _, err := db.Get(ctx, &graph.Pattern{}); return err }},
+		{"GetIterator", func() error { _, err := db.GetIterator(ctx, &graph.Pattern{}); return err }},
+		{"GetLatest", func() error { _, err := db.GetLatest(ctx, &graph.Pattern{}); return err }},
+		{"PlanPut", func() error { _, err := db.PlanPut(ctx, graph.NewTripleFromStrings("a", "b", "c")); return err }},
+		{"SearchWithPlan", func() error { _, _, err := db.SearchWithPlan(ctx, []*graph.Pattern{{}}, nil); return err }},
+		{"GetTimestamps", func() error { _, _, err := db.GetTimestamps(ctx, triple); return err }},
+		{"AdjacencyExport", func() error {
+			_, err := db.AdjacencyExport(ctx, []byte("knows"), io.Discard, AdjacencyCSV)
+			return err
+		}},
+		{"Search", func() error { _, err := db.Search(ctx, []*Pattern{{Subject: Binding("x")}}, nil); return err }},
+		{"SearchIterator", func() error { _, err := db.SearchIterator(ctx, []*Pattern{{Subject: Binding("x")}}, nil); return err }},
+		{"SearchEach", func() error {
+			return db.SearchEach(ctx, []*Pattern{{Subject: Binding("x")}}, nil, func(Solution) error { return nil })
+		}},
+		{"SortedIterator", func() error { _, err := db.SortedIterator(ctx, &Pattern{Subject: Binding("x")}, "x"); return err }},
+		{"Intern", func() error { _, err := db.Intern(ctx, []byte("x")); return err }},
+		{"Resolve", func() error { _, err := db.Resolve(ctx, []byte("node:x")); return err }},
+		{"PredicateStats", func() error { _, err := db.PredicateStats(ctx); return err }},
+		{"SubjectSummaries", func() error { _, err := db.SubjectSummaries(ctx); return err }},
+		{"PutWithOptions", func() error { return db.PutWithOptions(ctx, PutOptions{SkipJournal: true}, triple) }},
+		{"BulkLoadSorted", func() error { return db.BulkLoadSorted(ctx, []*graph.Triple{triple}) }},
+		{"ReEmbedAll", func() error { return db.ReEmbedAll(ctx) }},
+		{"ExistsMany", func() error { _, err := db.ExistsMany(ctx, triple); return err }},
+		{"SearchOne", func() error { _, _, err := db.SearchOne(ctx, []*Pattern{{Subject: Binding("x")}}, nil); return err }},
+		{"PreparePattern", func() error { _, err := db.PreparePattern([]*Pattern{{Subject: Binding("x")}}); return err }},
+		{"Nav.Solutions", func() error { _, err := db.Nav(ctx, "a").ArchOut("b").Solutions(); return err }},
+		{"Nav.Values", func() error { _, err := db.Nav(ctx, "a").Values(); return err }},
+		{"Nav.Count", func() error { _, err := db.Nav(ctx, "a").Count(); return err }},
+		{"Nav.CountUpTo", func() error { _, _, err := db.Nav(ctx, "a").CountUpTo(1); return err }},
+		{"SearchText", func() error { _, err := db.SearchText(ctx, []byte("p"), "x"); return err }},
+		{"Sample", func() error { _, err := db.Sample(ctx, 1); return err }},
+		{"RandomWalk", func() error { _, err := db.RandomWalk(ctx, []byte("a"), nil, 1, nil); return err }},
+		{"Follow", func() error { _, err := db.Follow(ctx, []byte("a"), []byte("b")); return err }},
+		{"ConnectedComponents", func() error { _, err := db.ConnectedComponents(ctx, nil); return err }},
+		{"Nav.First", func() error { _, err := db.Nav(ctx, "a").First(); return err }},
+		{"Nav.Exists", func() error { _, err := db.Nav(ctx, "a").Exists(); return err }},
+		{"Nav.Triples", func() error { _, err := db.Nav(ctx, "a").Triples(&graph.Pattern{}); return err }},
+		{"Nav.TriplesAll", func() error { _, err := db.Nav(ctx, "a").TriplesAll(&graph.Pattern{}); return err }},
+		{"Nav.Paths", func() error { _, err := db.Nav(ctx, "a").ArchOut("b").Paths(); return err }},
+		{"SetFacet", func() error { return db.SetFacet(ctx, FacetSubject, []byte("a"), []byte("k"), []byte("v")) }},
+		{"SetFacets", func() error { return db.SetFacets(ctx, FacetSubject, []byte("a"), nil, FacetMerge) }},
+		{"GetTripleFacets", func() error { _, err := db.GetTripleFacets(ctx, triple); return err }},
+		{"SetPairFacet", func() error { return db.SetPairFacet(ctx, []byte("a"), []byte("b"), []byte("k"), []byte("v")) }},
+		{"GetJournalEntries", func() error { _, err := db.GetJournalEntries(ctx, time.Now()); return err }},
+		{"GetJournalEntriesByOp", func() error { _, err := db.GetJournalEntriesByOp(ctx, time.Time{}, "del"); return err }},
+		{"ExportJSON", func() error { _, err := db.ExportJSON(ctx, io.Discard); return err }},
+		{"ImportJSON", func() error { _, err := db.ImportJSON(ctx, strings.NewReader("[]")); return err }},
+		{"SoftDel", func() error { return db.SoftDel(ctx, triple) }},
+		{"GetIncludingDeleted", func() error { _, err := db.GetIncludingDeleted(ctx, &Pattern{}); return err }},
+		{"PurgeDeleted", func() error { _, err := db.PurgeDeleted(ctx, time.Now()); return err }},
+		{"GC", func() error { _, _, err := db.GC(ctx); return err }},
+		{"JournalCount", func() error { _, err := db.JournalCount(ctx, time.Now()); return err }},
+		{"SetVector", func() error { return db.SetVector(ctx, []byte("v"), []float32{1, 0, 0}) }},
+		{"SearchVectors", func() error { _, err := db.SearchVectors(ctx, []float32{1, 0, 0}, 1); return err }},
+		{"SearchVectorsBatch", func() error { _, err := db.SearchVectorsBatch(ctx, [][]float32{{1, 0, 0}}, 1); return err }},
+		{"SearchVectorsWindow", func() error { _, err := db.SearchVectorsWindow(ctx, []float32{1, 0, 0}, 1, 1); return err }},
+		{"SearchVectorsWithinHops", func() error {
+			_, err := db.SearchVectorsWithinHops(ctx, []byte("a"), []byte("b"), 1, []float32{1, 0, 0}, 1)
+			return err
+		}},
+		{"SearchSimilarObjects", func() error { _, err := db.SearchSimilarObjects(ctx, []float32{1, 0, 0}, 1); return err }},
+		{"DeleteVectorsByType", func() error { _, err := db.DeleteVectorsByType(ctx, vector.IDTypeObject); return err }},
+		{"LoadVectors", func() error { return db.LoadVectors(ctx) }},
+		{"Verify", func() error { _, err := db.Verify(ctx); return err }},
+		{"JournalPage", func() error { _, _, err := db.JournalPage(ctx, time.Time{}, nil, 1); return err }},
+		{"RawIterator", func() error { _, err := db.RawIterator(ctx, IndexSPO); return err }},
+		{"ReindexSecondary", func() error { _, err := db.ReindexSecondary(ctx); return err }},
+	}
+
+	for _, tt := range tests {
+		if err := tt.fn(); !errors.Is(err, ErrClosed) {
+			t.Errorf("%s() after Close error = %v, want ErrClosed", tt.name, err)
+		}
+	}
+}
+
+// countingStore counts batch writes to the wrapped KVStore.
+type countingStore struct {
+	KVStore
+	writes int
+}
+
+func (c *countingStore) Write(batch *Batch, wo *WriteOptions) error {
+	c.writes++
+	return c.KVStore.Write(batch, wo)
+}
+
+func TestDB_InversePredicates(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithInversePredicates(map[string]string{
+		"parentOf":  "childOf",
+		"marriedTo": "marriedTo",
+	}))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	count := func() int {
+		t.Helper()
+		triples, err := db.Get(ctx, &graph.Pattern{})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return len(triples)
+	}
+	has := func(s, p, o string) bool {
+		t.Helper()
+		triples, err := db.Get(ctx, &graph.Pattern{
+			Subject:   graph.ExactString(s),
+			Predicate: graph.ExactString(p),
+			Object:    graph.ExactString(o),
+		})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return len(triples) == 1
+	}
+
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "parentOf", "carol"),
+		graph.NewTripleFromStrings("alice", "marriedTo", "bob"),
+		graph.NewTripleFromStrings("alice", "knows", "dave"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !has("carol", "childOf", "alice") || !has("bob", "marriedTo", "alice") {
+		t.Error("Put() did not insert the inverse triples")
+	}
+	if has("dave", "knows", "alice") {
+		t.Error("Put() inserted an inverse for a predicate without one")
+	}
+	if got := count(); got != 5 {
+		t.Errorf("stored %d triples, want 5", got)
+	}
+
+	// The pair applies both ways, and a self-inverse self-loop is one triple.
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("erin", "childOf", "frank"),
+		graph.NewTripleFromStrings("gina", "marriedTo", "gina"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !has("frank", "parentOf", "erin") {
+		t.Error("Put() did not insert frank parentOf erin")
+	}
+	if got := count(); got != 8 {
+		t.Errorf("stored %d triples, want 8", got)
+	}
+
+	if err := db.Del(ctx,
+		graph.NewTripleFromStrings("alice", "parentOf", "carol"),
+		graph.NewTripleFromStrings("bob", "marriedTo", "alice"),
+	); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	for _, triple := range [][3]string{
+		{"alice", "parentOf", "carol"}, {"carol", "childOf", "alice"},
+		{"alice", "marriedTo", "bob"}, {"bob", "marriedTo", "alice"},
+	} {
+		if has(triple[0], triple[1], triple[2]) {
+			t.Errorf("Del() left %v", triple)
+		}
+	}
+	if got := count(); got != 4 {
+		t.Errorf("stored %d triples after Del, want 4", got)
+	}
+}
+
+func TestDB_MaxComponentSize(t *testing.T) {
+	t.Parallel()
+
+	const limit = 1 << 20
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithMaxComponentSize(limit))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	atLimit := graph.NewTriple([]byte("doc"), []byte("body"), bytes.Repeat([]byte("x"), limit))
+	if err := db.Put(ctx, atLimit); err != nil {
+		t.Fatalf("Put() at the limit error = %v", err)
+	}
+	triples, err := db.Get(ctx, &graph.Pattern{Subject: graph.ExactString("doc")})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(triples) != 1 || len(triples[0].Object) != limit {
+		t.Fatalf("Get() did not return the %d-byte object", limit)
+	}
+
+	for _, tooLarge := range []*graph.Triple{
+		graph.NewTriple([]byte("doc2"), []byte("body"), bytes.Repeat([]byte("x"), limit+1)),
+		graph.NewTriple(bytes.Repeat([]byte("s"), limit+1), []byte("body"), []byte("x")),
+	} {
+		if err := db.Put(ctx, tooLarge); !errors.Is(err, ErrComponentTooLarge) {
+			t.Errorf("Put() over the limit error = %v, want ErrComponentTooLarge", err)
+		}
+	}
+
+	// Deleting is never limited, so oversized data written earlier can go.
+	if err := db.Del(ctx, atLimit); err != nil {
+		t.Errorf("Del() error = %v", err)
+	}
+}
+
+func TestDB_MaxBatchSize(t *testing.T) {
+	t.Parallel()
+
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	counting := &countingStore{KVStore: store}
+	db, err := OpenWithDB(counting, WithJournal(), WithMaxBatchSize(10))
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	triples := make([]*graph.Triple, 95)
+	for i := range triples {
+		triples[i] = graph.NewTripleFromStrings(fmt.Sprintf("s%02d", i), "p", "o")
+	}
+
+	if err := db.Put(ctx, triples...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if counting.writes != 10 {
+		t.Errorf("Put() wrote %d batches, want 10", counting.writes)
+	}
+
+	results, err := db.Get(ctx, &graph.Pattern{Predicate: graph.ExactString("p")})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(results) != 95 {
+		t.Errorf("Get() returned %d triples, want 95", len(results))
+	}
+
+	entries, err := db.GetJournalEntries(ctx, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("GetJournalEntries() error = %v", err)
+	}
+	if len(entries) != 95 {
+		t.Fatalf("GetJournalEntries() returned %d entries, want 95", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Operation != "put" || !entry.Triple.Equal(triples[i]) {
+			t.Errorf("journal entry %d = %s %v, want put %v", i, entry.Operation, entry.Triple, triples[i])
+		}
+	}
+
+	// An invalid triple anywhere in the input rejects the whole call
+	counting.writes = 0
+	if err := db.Del(ctx, append(triples[:20:20], nil)...); !errors.Is(err, ErrInvalidTriple) {
+		t.Errorf("Del() error = %v, want ErrInvalidTriple", err)
+	}
+	if counting.writes != 0 {
+		t.Errorf("Del() with invalid triple wrote %d batches, want 0", counting.writes)
+	}
+}
+
+func TestDB_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	ctx := context.Background()
+	triple := graph.NewTripleFromStrings("alice", "knows", "bob")
+
+	db, err := Open(dbPath, WithFacets(), WithJournal())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := db.Put(ctx, triple); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := db.SetFacet(ctx, FacetSubject, []byte("alice"), []byte("age"), []byte("30")); err != nil {
+		t.Fatalf("SetFacet() error = %v", err)
+	}
+	db.Close()
+
+	db, err = Open(dbPath, WithReadOnly(), WithFacets(), WithJournal(), WithVectors(vector.NewFlatIndex(3)))
+	if err != nil {
+		t.Fatalf("Open(WithReadOnly) error = %v", err)
+	}
+	defer db.Close()
+
+	// Reads work normally
+	results, err := db.Get(ctx, &graph.Pattern{Subject: graph.ExactString("alice")})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Get() = %v, %v, want 1 triple", results, err)
+	}
+	values, err := db.Nav(ctx, "alice").ArchOut("knows").Values()
+	if err != nil || len(values) != 1 || string(values[0]) != "bob" {
+		t.Errorf("Nav().Values() = %q, %v, want [bob]", values, err)
+	}
+	facet, err := db.GetFacet(ctx, FacetSubject, []byte("alice"), []byte("age"))
+	if err != nil || string(facet) != "30" {
+		t.Errorf("GetFacet() = %q, %v, want 30", facet, err)
+	}
+
+	// Writes are rejected
+	writes := []struct {
+		name string
+		fn   func() error
+	}{
+		{"Put", func() error { return db.Put(ctx, graph.NewTripleFromStrings("a", "b", "c")) }},
+		{"Del", func() error { return db.Del(ctx, triple) }},
+		{"SetFacet", func() error { return db.SetFacet(ctx, FacetSubject, []byte("alice"), []byte("k"), []byte("v")) }},
+		{"SetFacets", func() error { return db.SetFacets(ctx, FacetSubject, []byte("a"), nil, FacetMerge) }},
+		{"DelTripleFacet", func() error { return db.DelTripleFacet(ctx, triple, []byte("k")) }},
+		{"DelPairFacet", func() error { return db.DelPairFacet(ctx, []byte("alice"), []byte("knows"), []byte("k")) }},
+		{"SetVector", func() error { return db.SetVector(ctx, []byte("v"), []float32{1, 0, 0}) }},
+		{"Trim", func() error { _, err := db.Trim(ctx, time.Now()); return err }},
+		{"ReindexSecondary", func() error { _, err := db.ReindexSecondary(ctx); return err }},
+		{"Intern", func() error { _, err := db.Intern(ctx, []byte("new")); return err }},
+		{"PutWithOptions", func() error { return db.PutWithOptions(ctx, PutOptions{SkipJournal: true}, triple) }},
+		{"BulkLoadSorted", func() error { return db.BulkLoadSorted(ctx, []*graph.Triple{triple}) }},
+		{"ReEmbedAll", func() error { return db.ReEmbedAll(ctx) }},
+		{"ImportJSON", func() error {
+			_, err := db.ImportJSON(ctx, strings.NewReader(`[{"subject": "a", "predicate": "b", "object": "c"}]`))
+			return err
+		}},
+		{"SoftDel", func() error { return db.SoftDel(ctx, triple) }},
+		{"PurgeDeleted", func() error { _, err := db.PurgeDeleted(ctx, time.Now()); return err }},
+		{"GC", func() error { _, _, err := db.GC(ctx); return err }},
+	}
+	for _, w := range writes {
+		if err := w.fn(); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s() error = %v, want ErrReadOnly", w.name, err)
+		}
+	}
+
+	// The data is unchanged
+	results, _ = db.Get(ctx, &graph.Pattern{})
+	if len(results) != 1 {
+		t.Errorf("Get() after rejected writes returned %d triples, want 1", len(results))
+	}
+
+	// A read-only database must already exist
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.db"), WithReadOnly()); err == nil {
+		t.Error("Open(WithReadOnly) of a missing database should fail")
+	}
+}
+
 func TestGenerateBatch(t *testing.T) {
 	t.Parallel()
 	db, cleanup := setupTestDB(t)
@@ -740,6 +1296,53 @@ func TestSearch_Join(t *testing.T) {
 		}
 	})
 
+	t.Run("mutual friends unordered pairs", func(t *testing.T) {
+		patterns := []*Pattern{
+			{
+				Subject:   graph.Binding("x"),
+				Predicate: graph.ExactString("friend"),
+				Object:    graph.Binding("y"),
+			},
+			{
+				Subject:   graph.Binding("y"),
+				Predicate: graph.ExactString("friend"),
+				Object:    graph.Binding("x"),
+			},
+		}
+		opts := &SearchOptions{UnorderedPairs: "x,y"}
+		results, err := db.Search(context.Background(), patterns, opts)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		// Only the canonical daniele<->matteo ordering remains
+		if len(results) != 1 {
+			t.Fatalf("expected 1 mutual friend pair, got %d", len(results))
+		}
+		if string(results[0]["x"]) != "daniele" || string(results[0]["y"]) != "matteo" {
+			t.Errorf("expected (daniele, matteo), got (%s, %s)", results[0]["x"], results[0]["y"])
+		}
+
+		iter, err := db.SearchIterator(context.Background(), patterns, opts)
+		if err != nil {
+			t.Fatalf("SearchIterator failed: %v", err)
+		}
+		defer iter.Close()
+		count := 0
+		for iter.Next() {
+			count++
+		}
+		if count != 1 {
+			t.Errorf("SearchIterator returned %d pairs, want 1", count)
+		}
+
+		for _, bad := range []string{"x", "x,x", "x,", "x,y,z"} {
+			_, err := db.Search(context.Background(), patterns, &SearchOptions{UnorderedPairs: bad})
+			if !errors.Is(err, ErrInvalidUnorderedPairs) {
+				t.Errorf("UnorderedPairs %q: error = %v, want ErrInvalidUnorderedPairs", bad, err)
+			}
+		}
+	})
+
 	t.Run("common friends", func(t *testing.T) {
 		// Find common friends of lucio and daniele
 		results, err := db.Search(context.Background(), []*Pattern{
@@ -890,54 +1493,421 @@ func TestSearch_Materialized(t *testing.T) {
 	if len(results) != 2 {
 		t.Fatalf("expected 2 materialized results, got %d", len(results))
 	}
-
-	for _, r := range results {
-		if string(r["predicate"]) != "newpredicate" {
-			t.Errorf("expected predicate='newpredicate', got '%s'", r["predicate"])
-		}
-		if string(r["object"]) != "abcde" {
-			t.Errorf("expected object='abcde', got '%s'", r["object"])
-		}
+
+	for _, r := range results {
+		if string(r["predicate"]) != "newpredicate" {
+			t.Errorf("expected predicate='newpredicate', got '%s'", r["predicate"])
+		}
+		if string(r["object"]) != "abcde" {
+			t.Errorf("expected object='abcde', got '%s'", r["object"])
+		}
+	}
+}
+
+func TestSearch_PatternFilter(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := setupFOAFData(db); err != nil {
+		t.Fatalf("failed to setup data: %v", err)
+	}
+
+	// Find friends of daniele but filter at pattern level
+	results, err := db.Search(context.Background(), []*Pattern{
+		{
+			Subject:   graph.Binding("x"),
+			Predicate: graph.ExactString("friend"),
+			Object:    graph.ExactString("daniele"),
+			Filter: func(t *graph.Triple) bool {
+				return string(t.Subject) != "matteo"
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected 0 results after filter, got %d", len(results))
+	}
+}
+
+func TestSearch_MaxIntermediate(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	var triples []*graph.Triple
+	for i := 0; i < 20; i++ {
+		triples = append(triples, graph.NewTripleFromStrings(fmt.Sprintf("n%d", i), "linked", "hub"))
+	}
+	if err := db.Put(ctx, triples...); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// Every node is linked to the hub, so this self-join yields 20^3 solutions.
+	patterns := []*Pattern{
+		NewPattern(db.V("a"), []byte("linked"), db.V("h")),
+		NewPattern(db.V("b"), []byte("linked"), db.V("h")),
+		NewPattern(db.V("c"), []byte("linked"), db.V("h")),
+	}
+
+	results, err := db.Search(ctx, patterns, &SearchOptions{MaxIntermediate: 1000})
+	if !errors.Is(err, ErrQueryTooExpensive) {
+		t.Fatalf("Search error = %v, want ErrQueryTooExpensive", err)
+	}
+	if results != nil {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+
+	iter, err := db.SearchIterator(ctx, patterns, &SearchOptions{MaxIntermediate: 1000})
+	if err != nil {
+		t.Fatalf("SearchIterator failed: %v", err)
+	}
+	defer iter.Close()
+	for iter.Next() {
+	}
+	if !errors.Is(iter.Error(), ErrQueryTooExpensive) {
+		t.Errorf("iterator error = %v, want ErrQueryTooExpensive", iter.Error())
+	}
+
+	// A budget that covers the whole join is not exceeded.
+	results, err = db.Search(ctx, patterns[:2], &SearchOptions{MaxIntermediate: 420})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 400 {
+		t.Errorf("expected 400 results, got %d", len(results))
+	}
+}
+
+func TestSearch_Timeout(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	var triples []*graph.Triple
+	for i := 0; i < 30; i++ {
+		triples = append(triples, graph.NewTripleFromStrings(fmt.Sprintf("n%d", i), "linked", "hub"))
+	}
+	if err := db.Put(ctx, triples...); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	// Every node is linked to the hub, so this self-join yields 30^5 solutions.
+	var patterns []*Pattern
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		patterns = append(patterns, NewPattern(db.V(v), []byte("linked"), db.V("h")))
+	}
+
+	start := time.Now()
+	results, err := db.Search(ctx, patterns, &SearchOptions{Timeout: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Search error = %v, want context.DeadlineExceeded", err)
+	}
+	if results != nil {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Search took %v after a 1ms timeout", elapsed)
+	}
+
+	iter, err := db.SearchIterator(ctx, patterns, &SearchOptions{Timeout: time.Millisecond})
+	if err != nil {
+		t.Fatalf("SearchIterator failed: %v", err)
+	}
+	for iter.Next() {
+	}
+	iter.Close()
+	if !errors.Is(iter.Error(), context.DeadlineExceeded) {
+		t.Errorf("iterator error = %v, want context.DeadlineExceeded", iter.Error())
+	}
+
+	// A generous timeout lets the search finish.
+	results, err = db.Search(ctx, patterns[:2], &SearchOptions{Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 900 {
+		t.Errorf("expected 900 results, got %d", len(results))
+	}
+}
+
+func TestSearchTable(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := setupFOAFData(db); err != nil {
+		t.Fatalf("failed to setup data: %v", err)
+	}
+
+	// Friends of friends of matteo, with the friend's age
+	columns, rows, err := db.SearchTable(context.Background(), []*Pattern{
+		NewPattern([]byte("matteo"), []byte("friend"), db.V("x")),
+		NewPattern(db.V("x"), []byte("friend"), db.V("y")),
+		NewPattern(db.V("y"), []byte("age"), db.V("age")),
+	}, nil)
+	if err != nil {
+		t.Fatalf("SearchTable failed: %v", err)
+	}
+
+	if want := []string{"age", "x", "y"}; !slices.Equal(columns, want) {
+		t.Fatalf("columns = %q, want %q", columns, want)
+	}
+	// daniele's friends are matteo (no age) and marco (32)
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	if got := fmt.Sprintf("%s", rows[0]); got != "[32 daniele marco]" {
+		t.Errorf("row = %s, want [32 daniele marco]", got)
+	}
+
+	// Without results, the columns still come from the patterns.
+	columns, rows, err = db.SearchTable(context.Background(), []*Pattern{
+		NewPattern(db.V("x"), []byte("friend"), db.V("y")),
+	}, &SearchOptions{InitialSolution: Solution{"x": []byte("nobody")}})
+	if err != nil {
+		t.Fatalf("SearchTable failed: %v", err)
+	}
+	if want := []string{"x", "y"}; !slices.Equal(columns, want) || len(rows) != 0 {
+		t.Errorf("columns = %q with %d rows, want %q and no rows", columns, len(rows), want)
+	}
+}
+
+func TestSearch_IncrementalFilter(t *testing.T) {
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	counting := &iteratorCountingStore{KVStore: store}
+	db, err := OpenWithDB(counting)
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	defer db.Close()
+
+	if err := setupFOAFData(db); err != nil {
+		t.Fatalf("failed to setup data: %v", err)
+	}
+
+	// Friends of friends, keeping only those whose friend is marco
+	ctx := context.Background()
+	patterns := []*Pattern{
+		NewPattern(db.V("x"), []byte("friend"), db.V("y")),
+		NewPattern(db.V("y"), []byte("friend"), db.V("z")),
+	}
+	isMarco := func(s Solution) bool { return string(s["y"]) == "marco" }
+
+	counting.total = 0
+	filtered, err := db.Search(ctx, patterns, &SearchOptions{Filter: isMarco})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	fullScans := counting.total
+
+	var calls []string
+	counting.total = 0
+	pruned, err := db.Search(ctx, patterns, &SearchOptions{
+		IncrementalFilter: func(partial Solution, justBound string) bool {
+			calls = append(calls, justBound)
+			return justBound != "y" || isMarco(partial)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(pruned) != len(filtered) || len(pruned) != 2 {
+		t.Fatalf("IncrementalFilter gave %d solutions, Filter gave %d, want 2", len(pruned), len(filtered))
+	}
+	for _, sol := range pruned {
+		if string(sol["y"]) != "marco" || string(sol["z"]) != "davide" {
+			t.Errorf("unexpected solution %v", sol)
+		}
+	}
+	// One lookup for the first pattern, then one per surviving partial
+	// solution instead of one per friend edge.
+	if counting.total != 3 || counting.total >= fullScans {
+		t.Errorf("IncrementalFilter opened %d iterators, Filter %d; want 3", counting.total, fullScans)
+	}
+	// Each friend edge binds x and y, then the two marco rows bind z.
+	if want := 6*2 + 2; len(calls) != want {
+		t.Errorf("IncrementalFilter called %d times, want %d", len(calls), want)
+	}
+
+	// SearchIterator prunes the same way.
+	iter, err := db.SearchIterator(ctx, patterns, &SearchOptions{
+		IncrementalFilter: func(partial Solution, justBound string) bool {
+			return justBound != "y" || isMarco(partial)
+		},
+	})
+	if err != nil {
+		t.Fatalf("SearchIterator failed: %v", err)
+	}
+	defer iter.Close()
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("SearchIterator returned %d solutions, want 2", count)
+	}
+}
+
+func TestSearch_EmptyPatterns(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	results, err := db.Search(context.Background(), []*Pattern{}, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected 0 results for empty patterns, got %d", len(results))
+	}
+}
+
+func TestSearchOptions_Clone(t *testing.T) {
+	t.Parallel()
+	opts := &SearchOptions{
+		Limit:           10,
+		Filter:          func(Solution) bool { return true },
+		Materialized:    graph.NewPattern(V("x"), "knows", "bob"),
+		InitialSolution: Solution{"x": []byte("alice")},
+		VectorFilter:    &VectorFilter{Variable: "x", Query: []float32{1, 0}},
+	}
+
+	clone := opts.Clone()
+	clone.Limit = 1
+	clone.Materialized.Object.Data()[0] = 'X'
+	clone.InitialSolution["x"][0] = 'X'
+	clone.VectorFilter.Query[0] = 9
+	clone.VectorFilter.Variable = "y"
+
+	if opts.Limit != 10 {
+		t.Errorf("original Limit = %d, want 10", opts.Limit)
+	}
+	if got := string(opts.Materialized.Object.Data()); got != "bob" {
+		t.Errorf("original Materialized object = %q, want bob", got)
+	}
+	if got := string(opts.InitialSolution["x"]); got != "alice" {
+		t.Errorf("original InitialSolution[x] = %q, want alice", got)
+	}
+	if opts.VectorFilter.Query[0] != 1 || opts.VectorFilter.Variable != "x" {
+		t.Errorf("original VectorFilter = %+v, want unchanged", opts.VectorFilter)
+	}
+	if clone.Filter == nil {
+		t.Error("Clone() dropped Filter")
+	}
+	if (*SearchOptions)(nil).Clone() != nil {
+		t.Error("Clone() of nil options should be nil")
 	}
 }
 
-func TestSearch_PatternFilter(t *testing.T) {
+func TestSearchOne(t *testing.T) {
+	t.Parallel()
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	if err := setupFOAFData(db); err != nil {
-		t.Fatalf("failed to setup data: %v", err)
+		t.Fatalf("setupFOAFData() error = %v", err)
 	}
+	ctx := context.Background()
 
-	// Find friends of daniele but filter at pattern level
-	results, err := db.Search(context.Background(), []*Pattern{
-		{
-			Subject:   graph.Binding("x"),
-			Predicate: graph.ExactString("friend"),
-			Object:    graph.ExactString("daniele"),
-			Filter: func(t *graph.Triple) bool {
-				return string(t.Subject) != "matteo"
-			},
-		},
+	// Friends of lucio aged over 30
+	patterns := []*Pattern{
+		graph.NewPattern("lucio", "friend", V("x")),
+		graph.NewPattern(V("x"), "age", V("age")),
+	}
+	sol, found, err := db.SearchOne(ctx, patterns, &SearchOptions{
+		Filter: func(s Solution) bool { return string(s["age"]) > "30" },
+	})
+	if err != nil {
+		t.Fatalf("SearchOne() error = %v", err)
+	}
+	if !found || string(sol["x"]) != "marco" || string(sol["age"]) != "32" {
+		t.Errorf("SearchOne() = %v, %v, want x=marco age=32", sol, found)
+	}
+
+	// No match
+	sol, found, err = db.SearchOne(ctx, []*Pattern{
+		graph.NewPattern("lucio", "friend", V("x")),
+		graph.NewPattern(V("x"), "age", "99"),
 	}, nil)
 	if err != nil {
-		t.Fatalf("Search failed: %v", err)
+		t.Fatalf("SearchOne() error = %v", err)
 	}
-	if len(results) != 0 {
-		t.Fatalf("expected 0 results after filter, got %d", len(results))
+	if found || sol != nil {
+		t.Errorf("SearchOne() = %v, %v, want nil, false", sol, found)
+	}
+
+	// No patterns never match
+	if sol, found, err := db.SearchOne(ctx, nil, nil); err != nil || found || sol != nil {
+		t.Errorf("SearchOne(no patterns) = %v, %v, %v, want nil, false, nil", sol, found, err)
 	}
 }
 
-func TestSearch_EmptyPatterns(t *testing.T) {
+func TestSearchEach(t *testing.T) {
+	t.Parallel()
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	results, err := db.Search(context.Background(), []*Pattern{}, nil)
+	if err := setupFOAFData(db); err != nil {
+		t.Fatalf("setupFOAFData() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Friends of friends, with their age
+	patterns := []*Pattern{
+		graph.NewPattern(V("a"), "friend", V("b")),
+		graph.NewPattern(V("b"), "friend", V("c")),
+		graph.NewPattern(V("c"), "age", V("age")),
+	}
+	want, err := db.Search(ctx, patterns, nil)
 	if err != nil {
-		t.Fatalf("Search failed: %v", err)
+		t.Fatalf("Search() error = %v", err)
 	}
-	if len(results) != 0 {
-		t.Fatalf("expected 0 results for empty patterns, got %d", len(results))
+	if len(want) < 2 {
+		t.Fatalf("Search() returned %d solutions, want at least 2", len(want))
+	}
+
+	var got []Solution
+	if err := db.SearchEach(ctx, patterns, nil, func(sol Solution) error {
+		got = append(got, sol)
+		return nil
+	}); err != nil {
+		t.Fatalf("SearchEach() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("SearchEach() called fn %d times, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if fmt.Sprint(got[i]) != fmt.Sprint(want[i]) {
+			t.Errorf("SearchEach() solution %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// StopIteration ends the search after the first solution without error.
+	calls := 0
+	if err := db.SearchEach(ctx, patterns, nil, func(Solution) error {
+		calls++
+		return StopIteration
+	}); err != nil {
+		t.Errorf("SearchEach(StopIteration) error = %v, want nil", err)
+	}
+	if calls != 1 {
+		t.Errorf("SearchEach(StopIteration) called fn %d times, want 1", calls)
+	}
+
+	// Other errors stop the search and are returned.
+	errBoom := errors.New("boom")
+	calls = 0
+	err = db.SearchEach(ctx, patterns, &SearchOptions{Materialized: graph.NewPattern(V("a"), "fof", V("c"))}, func(Solution) error {
+		calls++
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) || calls != 1 {
+		t.Errorf("SearchEach(error) = %v after %d calls, want boom after 1", err, calls)
 	}
 }
 
@@ -975,6 +1945,94 @@ func TestSearchIterator(t *testing.T) {
 	}
 }
 
+// iteratorCountingStore tracks how many iterators over the wrapped KVStore
+// are open at once and in total.
+type iteratorCountingStore struct {
+	KVStore
+	open, peak, total int
+}
+
+func (c *iteratorCountingStore) NewIterator(slice *Range, ro *ReadOptions) Iterator {
+	c.total++
+	c.open++
+	c.peak = max(c.peak, c.open)
+	return &countedIterator{Iterator: c.KVStore.NewIterator(slice, ro), store: c}
+}
+
+type countedIterator struct {
+	Iterator
+	store    *iteratorCountingStore
+	released bool
+}
+
+func (it *countedIterator) Release() {
+	if !it.released {
+		it.released = true
+		it.store.open--
+	}
+	it.Iterator.Release()
+}
+
+func TestSearchIterator_ConstantMemory(t *testing.T) {
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	counting := &iteratorCountingStore{KVStore: store}
+	db, err := OpenWithDB(counting)
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	const n = 5000
+	triples := make([]*graph.Triple, 0, 2*n)
+	for i := 0; i < n; i++ {
+		person := fmt.Sprintf("p%05d", i)
+		triples = append(triples,
+			graph.NewTripleFromStrings(person, "knows", fmt.Sprintf("p%05d", (i+1)%n)),
+			graph.NewTripleFromStrings(person, "age", fmt.Sprintf("%d", i%100)),
+		)
+	}
+	if err := db.Put(ctx, triples...); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	iter, err := db.SearchIterator(ctx, []*Pattern{
+		NewPattern(db.V("x"), []byte("knows"), db.V("y")),
+		NewPattern(db.V("y"), []byte("age"), db.V("age")),
+	}, nil)
+	if err != nil {
+		t.Fatalf("SearchIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	count := 0
+	for iter.Next() {
+		sol := iter.Solution()
+		var i int
+		fmt.Sscanf(string(sol["y"]), "p%d", &i)
+		if want := fmt.Sprintf("%d", i%100); string(sol["age"]) != want {
+			t.Fatalf("solution %v: age = %s, want %s", sol, sol["age"], want)
+		}
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		t.Fatalf("iterator error = %v", err)
+	}
+	if count != n {
+		t.Errorf("expected %d solutions, got %d", n, count)
+	}
+	// One store iterator per pattern, however many solutions stream past.
+	if counting.peak > 2 {
+		t.Errorf("peak open iterators = %d, want at most 2", counting.peak)
+	}
+	if counting.open != 0 {
+		t.Errorf("%d iterators left open after exhaustion", counting.open)
+	}
+}
+
 // Navigator tests - ported from JS navigator_spec.js
 
 func TestNavigator_SingleVertex(t *testing.T) {
@@ -1300,6 +2358,100 @@ func TestNavigator_Count(t *testing.T) {
 	}
 }
 
+func TestNavigator_CountUpTo(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	const degree = 1000
+	triples := make([]*graph.Triple, degree)
+	for i := range triples {
+		triples[i] = graph.NewTripleFromStrings("hub", "friend", fmt.Sprintf("user%04d", i))
+	}
+	if err := db.Put(ctx, triples...); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	visited := 0
+	count, capped, err := db.Nav(ctx, "hub").ArchOut("friend").Filter(func(*graph.Triple) bool {
+		visited++
+		return true
+	}).CountUpTo(3)
+	if err != nil {
+		t.Fatalf("CountUpTo failed: %v", err)
+	}
+	if count != 3 || !capped {
+		t.Errorf("CountUpTo(3) = (%d, %v), want (3, true)", count, capped)
+	}
+	if visited >= degree {
+		t.Errorf("CountUpTo(3) visited %d edges, want early stop", visited)
+	}
+
+	count, capped, err = db.Nav(ctx, "hub").ArchOut("friend").CountUpTo(degree + 1)
+	if err != nil {
+		t.Fatalf("CountUpTo failed: %v", err)
+	}
+	if count != degree || capped {
+		t.Errorf("CountUpTo(%d) = (%d, %v), want (%d, false)", degree+1, count, capped, degree)
+	}
+}
+
+func TestNavigator_ArchOutAny(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "likes", "carol"),
+		graph.NewTripleFromStrings("alice", "likes", "bob"),
+		graph.NewTripleFromStrings("alice", "hates", "dave"),
+		graph.NewTripleFromStrings("erin", "knows", "carol"),
+	)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	nav := db.Nav(ctx, "alice").ArchOutAny("knows", "likes")
+	if step := nav.conditions[0]; len(step.PredicateIn) != 2 || step.Filter != nil {
+		t.Errorf("ArchOutAny step = %+v, want a PredicateIn clause and no filter", step)
+	}
+	values, err := nav.Values()
+	if err != nil {
+		t.Fatalf("Navigator failed: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, v := range values {
+		found[string(v)] = true
+	}
+	if len(values) != 2 || !found["bob"] || !found["carol"] {
+		t.Errorf("expected bob and carol, got %v", found)
+	}
+
+	values, err = db.Nav(ctx, "carol").ArchInAny("knows", "likes").Values()
+	if err != nil {
+		t.Fatalf("Navigator failed: %v", err)
+	}
+	found = make(map[string]bool)
+	for _, v := range values {
+		found[string(v)] = true
+	}
+	if len(values) != 2 || !found["alice"] || !found["erin"] {
+		t.Errorf("expected alice and erin, got %v", found)
+	}
+
+	// A Filter narrows the predicate set further
+	values, err = db.Nav(ctx, "alice").ArchOutAny("knows", "likes").
+		Filter(func(tr *graph.Triple) bool { return string(tr.Object) != "bob" }).
+		Values()
+	if err != nil {
+		t.Fatalf("Navigator failed: %v", err)
+	}
+	if len(values) != 1 || string(values[0]) != "carol" {
+		t.Errorf("expected only carol, got %q", values)
+	}
+}
+
 func TestNavigator_Clone(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1398,6 +2550,104 @@ func TestJournal_RecordsOperations(t *testing.T) {
 	}
 }
 
+func TestDB_PutWithOptionsSkipJournal(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupJournalDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	var bulk []*graph.Triple
+	for i := range 100 {
+		bulk = append(bulk, graph.NewTripleFromStrings(fmt.Sprintf("s%d", i), "p", "o"))
+	}
+	if err := db.PutWithOptions(ctx, PutOptions{SkipJournal: true}, bulk...); err != nil {
+		t.Fatalf("PutWithOptions() error = %v", err)
+	}
+
+	count, err := db.JournalCount(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("JournalCount() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("JournalCount() after SkipJournal put = %d, want 0", count)
+	}
+	results, err := db.Get(ctx, &graph.Pattern{Predicate: graph.ExactString("p")})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(results) != len(bulk) {
+		t.Errorf("Get() returned %d triples, want %d", len(results), len(bulk))
+	}
+
+	// A normal put still journals.
+	if err := db.Put(ctx, graph.NewTripleFromStrings("a", "b", "c")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if count, _ := db.JournalCount(ctx, time.Time{}); count != 1 {
+		t.Errorf("JournalCount() after Put = %d, want 1", count)
+	}
+}
+
+func TestJournal_GetEntriesByOp(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupJournalDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	t1 := graph.NewTripleFromStrings("a", "b", "c")
+	t2 := graph.NewTripleFromStrings("d", "e", "f")
+	t3 := graph.NewTripleFromStrings("g", "h", "i")
+	if err := db.Put(ctx, t1, t2); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := db.Del(ctx, t1); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	mid := time.Now()
+	if err := db.Put(ctx, t3); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := db.Del(ctx, t2); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+
+	dels, err := db.GetJournalEntriesByOp(ctx, time.Time{}, "del")
+	if err != nil {
+		t.Fatalf("GetJournalEntriesByOp(del) error = %v", err)
+	}
+	if len(dels) != 2 || !dels[0].Triple.Equal(t1) || !dels[1].Triple.Equal(t2) {
+		t.Fatalf("GetJournalEntriesByOp(del) = %v, want dels of a b c and d e f", dels)
+	}
+	for _, e := range dels {
+		if e.Operation != "del" {
+			t.Errorf("GetJournalEntriesByOp(del) entry op = %q", e.Operation)
+		}
+	}
+	if dels[0].Seq >= dels[1].Seq {
+		t.Errorf("GetJournalEntriesByOp(del) seqs = %d, %d, want increasing", dels[0].Seq, dels[1].Seq)
+	}
+
+	puts, err := db.GetJournalEntriesByOp(ctx, time.Time{}, "put")
+	if err != nil {
+		t.Fatalf("GetJournalEntriesByOp(put) error = %v", err)
+	}
+	if len(puts) != 3 {
+		t.Errorf("GetJournalEntriesByOp(put) returned %d entries, want 3", len(puts))
+	}
+
+	recent, err := db.GetJournalEntriesByOp(ctx, mid, "del")
+	if err != nil {
+		t.Fatalf("GetJournalEntriesByOp(since) error = %v", err)
+	}
+	if len(recent) != 1 || !recent[0].Triple.Equal(t2) {
+		t.Errorf("GetJournalEntriesByOp(since) = %v, want the del of d e f", recent)
+	}
+
+	if _, err := db.GetJournalEntriesByOp(ctx, time.Time{}, "update"); !errors.Is(err, ErrInvalidJournalOp) {
+		t.Errorf("GetJournalEntriesByOp(update) error = %v, want ErrInvalidJournalOp", err)
+	}
+}
+
 func TestJournal_Trim(t *testing.T) {
 	db, cleanup := setupJournalDB(t)
 	defer cleanup()
@@ -1553,28 +2803,208 @@ func TestJournal_Iterator(t *testing.T) {
 
 	iter, err := db.GetJournalIterator(context.Background(), time.Time{})
 	if err != nil {
-		t.Fatalf("GetJournalIterator failed: %v", err)
+		t.Fatalf("GetJournalIterator failed: %v", err)
+	}
+	defer iter.Close()
+
+	count := 0
+	for iter.Next() {
+		entry, err := iter.Entry()
+		if err != nil {
+			t.Fatalf("Entry() failed: %v", err)
+		}
+		if entry.Operation != "put" {
+			t.Errorf("expected 'put' operation")
+		}
+		count++
+	}
+
+	if err := iter.Error(); err != nil {
+		t.Fatalf("iterator error: %v", err)
+	}
+
+	if count != 3 {
+		t.Errorf("expected 3 entries, got %d", count)
+	}
+}
+
+func TestJournal_Page(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath, WithJournal())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	ctx := context.Background()
+	const total = 25
+	for i := 0; i < total; i++ {
+		if err := db.Put(ctx, graph.NewTripleFromStrings(fmt.Sprintf("s%02d", i), "p", "o")); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	var got []string
+	var cursor []byte
+	page := func() int {
+		t.Helper()
+		entries, next, err := db.JournalPage(ctx, time.Time{}, cursor, 7)
+		if err != nil {
+			t.Fatalf("JournalPage() error = %v", err)
+		}
+		if len(entries) > 7 {
+			t.Fatalf("JournalPage() returned %d entries, limit 7", len(entries))
+		}
+		for _, e := range entries {
+			got = append(got, string(e.Triple.Subject))
+		}
+		cursor = next
+		return len(entries)
+	}
+
+	// Two pages, then resume with the saved cursor after reopening.
+	page()
+	page()
+	db.Close()
+	db, err = Open(dbPath, WithJournal())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	for page() > 0 {
+	}
+
+	if len(got) != total {
+		t.Fatalf("paged %d entries, want %d", len(got), total)
+	}
+	for i, subject := range got {
+		if want := fmt.Sprintf("s%02d", i); subject != want {
+			t.Errorf("entry %d = %s, want %s", i, subject, want)
+		}
+	}
+
+	// A caught-up cursor sees only entries written later.
+	if err := db.Put(ctx, graph.NewTripleFromStrings("late", "p", "o")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	entries, next, err := db.JournalPage(ctx, time.Time{}, cursor, 0)
+	if err != nil {
+		t.Fatalf("JournalPage() error = %v", err)
+	}
+	if len(entries) != 1 || string(entries[0].Triple.Subject) != "late" || bytes.Equal(next, cursor) {
+		t.Errorf("JournalPage() after new write = %v, want only late", entries)
+	}
+
+	// since skips older entries.
+	entries, _, err = db.JournalPage(ctx, entries[0].Timestamp, nil, 0)
+	if err != nil {
+		t.Fatalf("JournalPage() error = %v", err)
+	}
+	if len(entries) != 1 || string(entries[0].Triple.Subject) != "late" {
+		t.Errorf("JournalPage(since) = %v, want only late", entries)
+	}
+
+	if _, _, err := db.JournalPage(ctx, time.Time{}, []byte("spo::x"), 1); !errors.Is(err, ErrInvalidJournalKey) {
+		t.Errorf("JournalPage() with foreign key error = %v, want ErrInvalidJournalKey", err)
+	}
+}
+
+func TestJournal_StrictOrdering(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath, WithJournal())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
 	}
-	defer iter.Close()
 
-	count := 0
+	ctx := context.Background()
+	const total = 1000
+	for i := 0; i < total; i++ {
+		if err := db.Put(ctx, graph.NewTripleFromStrings(fmt.Sprintf("s%04d", i), "p", "o")); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	iter, err := db.GetJournalIterator(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("GetJournalIterator() error = %v", err)
+	}
+	seen := make(map[string]bool)
+	var prevKey []byte
 	for iter.Next() {
-		entry, err := iter.Entry()
-		if err != nil {
-			t.Fatalf("Entry() failed: %v", err)
+		key := string(iter.Key())
+		if seen[key] {
+			t.Fatalf("duplicate journal key %x", key)
 		}
-		if entry.Operation != "put" {
-			t.Errorf("expected 'put' operation")
+		seen[key] = true
+		if prevKey != nil && bytes.Compare(prevKey, iter.Key()) >= 0 {
+			t.Fatalf("journal keys not strictly increasing: %x then %x", prevKey, iter.Key())
 		}
-		count++
+		prevKey = bytes.Clone(iter.Key())
 	}
-
 	if err := iter.Error(); err != nil {
-		t.Fatalf("iterator error: %v", err)
+		t.Fatalf("iterator error = %v", err)
+	}
+	iter.Close()
+	if len(seen) != total {
+		t.Fatalf("journal has %d distinct keys, want %d", len(seen), total)
 	}
 
-	if count != 3 {
-		t.Errorf("expected 3 entries, got %d", count)
+	entries, err := db.GetJournalEntries(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("GetJournalEntries() error = %v", err)
+	}
+	if len(entries) != total {
+		t.Fatalf("GetJournalEntries() returned %d entries, want %d", len(entries), total)
+	}
+	for i, e := range entries {
+		if want := fmt.Sprintf("s%04d", i); string(e.Triple.Subject) != want {
+			t.Fatalf("entry %d subject = %q, want %q", i, e.Triple.Subject, want)
+		}
+		if i > 0 {
+			if e.Seq <= entries[i-1].Seq {
+				t.Fatalf("entry %d seq = %d, not after %d", i, e.Seq, entries[i-1].Seq)
+			}
+			if e.Timestamp.Before(entries[i-1].Timestamp) {
+				t.Fatalf("entry %d timestamp goes backwards", i)
+			}
+		}
+	}
+	last := entries[total-1]
+
+	// The sequence continues after a reopen
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	db, err = Open(dbPath, WithJournal())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Put(ctx, graph.NewTripleFromStrings("after", "p", "o")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	entries, err = db.GetJournalEntries(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("GetJournalEntries() error = %v", err)
+	}
+	if len(entries) != total+1 {
+		t.Fatalf("GetJournalEntries() returned %d entries, want %d", len(entries), total+1)
+	}
+	if e := entries[total]; string(e.Triple.Subject) != "after" || e.Seq != last.Seq+1 {
+		t.Errorf("entry after reopen = %q seq %d, want %q seq %d", e.Triple.Subject, e.Seq, "after", last.Seq+1)
+	}
+
+	// Trim by time still works with sequenced keys
+	trimmed, err := db.Trim(ctx, last.Timestamp.Add(time.Nanosecond))
+	if err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	if trimmed < total {
+		t.Errorf("Trim() = %d, want at least %d", trimmed, total)
 	}
 }
 
@@ -1721,6 +3151,160 @@ func TestFacet_TripleFacets(t *testing.T) {
 	}
 }
 
+func TestFacet_PairFacets(t *testing.T) {
+	db, cleanup := setupFacetDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	alice := []byte("alice")
+
+	// The same key on different pairs does not collide
+	if err := db.SetPairFacet(ctx, alice, []byte("knows"), []byte("since"), []byte("2019")); err != nil {
+		t.Fatalf("SetPairFacet() error = %v", err)
+	}
+	if err := db.SetPairFacet(ctx, alice, []byte("likes"), []byte("since"), []byte("2021")); err != nil {
+		t.Fatalf("SetPairFacet() error = %v", err)
+	}
+
+	value, err := db.GetPairFacet(ctx, alice, []byte("knows"), []byte("since"))
+	if err != nil || string(value) != "2019" {
+		t.Errorf("GetPairFacet(knows) = %q, %v, want 2019", value, err)
+	}
+	value, err = db.GetPairFacet(ctx, alice, []byte("likes"), []byte("since"))
+	if err != nil || string(value) != "2021" {
+		t.Errorf("GetPairFacet(likes) = %q, %v, want 2021", value, err)
+	}
+
+	// Pair facets are separate from component and triple facets
+	if value, _ := db.GetFacet(ctx, FacetSubject, alice, []byte("since")); value != nil {
+		t.Errorf("GetFacet(subject) = %q, want nil", value)
+	}
+	triple := graph.NewTripleFromStrings("alice", "knows", "bob")
+	if facets, _ := db.GetTripleFacets(ctx, triple); len(facets) != 0 {
+		t.Errorf("GetTripleFacets() = %v, want none", facets)
+	}
+
+	// A predicate that is a prefix of another does not leak into it
+	if err := db.SetPairFacet(ctx, alice, []byte("know"), []byte("weight"), []byte("1")); err != nil {
+		t.Fatalf("SetPairFacet() error = %v", err)
+	}
+	facets, err := db.GetPairFacets(ctx, alice, []byte("knows"))
+	if err != nil {
+		t.Fatalf("GetPairFacets() error = %v", err)
+	}
+	if len(facets) != 1 || string(facets["since"]) != "2019" {
+		t.Errorf("GetPairFacets(knows) = %v, want only since=2019", facets)
+	}
+
+	if err := db.DelPairFacet(ctx, alice, []byte("knows"), []byte("since")); err != nil {
+		t.Fatalf("DelPairFacet() error = %v", err)
+	}
+	if value, _ := db.GetPairFacet(ctx, alice, []byte("knows"), []byte("since")); value != nil {
+		t.Errorf("GetPairFacet() after delete = %q, want nil", value)
+	}
+	if value, _ := db.GetPairFacet(ctx, alice, []byte("likes"), []byte("since")); string(value) != "2021" {
+		t.Errorf("GetPairFacet(likes) after deleting knows = %q, want 2021", value)
+	}
+}
+
+func TestFacet_SetFacets(t *testing.T) {
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	counting := &countingStore{KVStore: store}
+	db, err := OpenWithDB(counting, WithFacets())
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	alice := []byte("alice")
+	assertFacets := func(got map[string][]byte, want map[string]string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("facets = %q, want %q", got, want)
+		}
+		for k, v := range want {
+			if string(got[k]) != v {
+				t.Errorf("facet %q = %q, want %q", k, got[k], v)
+			}
+		}
+	}
+
+	if err := db.SetFacet(ctx, FacetSubject, alice, []byte("nickname"), []byte("al")); err != nil {
+		t.Fatalf("SetFacet() error = %v", err)
+	}
+
+	counting.writes = 0
+	err = db.SetFacets(ctx, FacetSubject, alice, map[string][]byte{
+		"age":   []byte("30"),
+		"city":  []byte("NYC"),
+		"email": []byte("alice@example.com"),
+		"role":  []byte("admin"),
+		"team":  []byte("core"),
+	}, FacetMerge)
+	if err != nil {
+		t.Fatalf("SetFacets() error = %v", err)
+	}
+	if counting.writes != 1 {
+		t.Errorf("SetFacets() made %d writes, want 1", counting.writes)
+	}
+	facets, err := db.GetFacets(ctx, FacetSubject, alice)
+	if err != nil {
+		t.Fatalf("GetFacets() error = %v", err)
+	}
+	assertFacets(facets, map[string]string{
+		"nickname": "al", "age": "30", "city": "NYC", "email": "alice@example.com", "role": "admin", "team": "core",
+	})
+
+	// Replace leaves exactly the new set.
+	err = db.SetFacets(ctx, FacetSubject, alice, map[string][]byte{
+		"age":  []byte("31"),
+		"city": []byte("SF"),
+	}, FacetReplace)
+	if err != nil {
+		t.Fatalf("SetFacets(FacetReplace) error = %v", err)
+	}
+	facets, err = db.GetFacets(ctx, FacetSubject, alice)
+	if err != nil {
+		t.Fatalf("GetFacets() error = %v", err)
+	}
+	assertFacets(facets, map[string]string{"age": "31", "city": "SF"})
+
+	triple := graph.NewTripleFromStrings("alice", "knows", "bob")
+	if err := db.SetTripleFacet(ctx, triple, []byte("since"), []byte("2019")); err != nil {
+		t.Fatalf("SetTripleFacet() error = %v", err)
+	}
+	counting.writes = 0
+	err = db.SetTripleFacets(ctx, triple, map[string][]byte{
+		"since":  []byte("2020"),
+		"weight": []byte("0.8"),
+	}, FacetReplace)
+	if err != nil {
+		t.Fatalf("SetTripleFacets() error = %v", err)
+	}
+	if counting.writes != 1 {
+		t.Errorf("SetTripleFacets() made %d writes, want 1", counting.writes)
+	}
+	facets, err = db.GetTripleFacets(ctx, triple)
+	if err != nil {
+		t.Fatalf("GetTripleFacets() error = %v", err)
+	}
+	assertFacets(facets, map[string]string{"since": "2020", "weight": "0.8"})
+
+	// Facets on other components are untouched.
+	if err := db.SetFacets(ctx, FacetSubject, []byte("bob"), nil, FacetReplace); err != nil {
+		t.Fatalf("SetFacets(empty) error = %v", err)
+	}
+	facets, err = db.GetFacets(ctx, FacetSubject, alice)
+	if err != nil {
+		t.Fatalf("GetFacets() error = %v", err)
+	}
+	assertFacets(facets, map[string]string{"age": "31", "city": "SF"})
+}
+
 func TestFacet_DisabledByDefault(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -2306,92 +3890,409 @@ func TestPattern_VariableFields(t *testing.T) {
 	if fields["subject"] == nil || fields["subject"].Name != "s" {
 		t.Error("subject variable not found")
 	}
-	if fields["object"] == nil || fields["object"].Name != "o" {
-		t.Error("object variable not found")
+	if fields["object"] == nil || fields["object"].Name != "o" {
+		t.Error("object variable not found")
+	}
+	if fields["predicate"] != nil {
+		t.Error("predicate should not be in variable fields")
+	}
+}
+
+func TestPattern_ToTriple(t *testing.T) {
+	// Pattern with all concrete values
+	p1 := &graph.Pattern{Subject: graph.ExactString("a"), Predicate: graph.ExactString("b"), Object: graph.ExactString("c")}
+	triple := p1.ToTriple()
+	if triple == nil {
+		t.Fatal("expected triple, got nil")
+	}
+	if string(triple.Subject) != "a" || string(triple.Predicate) != "b" || string(triple.Object) != "c" {
+		t.Error("triple values don't match pattern")
+	}
+
+	// Pattern with nil field
+	p2 := &graph.Pattern{Subject: graph.ExactString("a"), Predicate: graph.Wildcard(), Object: graph.ExactString("c")}
+	if p2.ToTriple() != nil {
+		t.Error("pattern with nil field should return nil")
+	}
+
+	// Pattern with variable
+	p3 := &graph.Pattern{Subject: graph.ExactString("a"), Predicate: graph.Binding("p"), Object: graph.ExactString("c")}
+	if p3.ToTriple() != nil {
+		t.Error("pattern with variable should return nil")
+	}
+}
+
+func TestDB_V(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	v := db.V("test")
+	if v == nil {
+		t.Fatal("graph.V should return a Variable")
+	}
+	if v.Name != "test" {
+		t.Errorf("expected name 'test', got '%s'", v.Name)
+	}
+}
+
+func TestDB_IsOpen(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+
+	if !db.IsOpen() {
+		t.Error("database should be open")
+	}
+
+	cleanup()
+
+	if db.IsOpen() {
+		t.Error("database should be closed after cleanup")
+	}
+}
+
+func TestDB_GetIterator(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	t1 := graph.NewTripleFromStrings("a", "b", "c")
+	t2 := graph.NewTripleFromStrings("a", "b", "d")
+	db.Put(context.Background(), t1, t2)
+
+	iter, err := db.GetIterator(context.Background(), &graph.Pattern{Subject: graph.ExactString("a")})
+	if err != nil {
+		t.Fatalf("GetIterator failed: %v", err)
+	}
+	defer iter.Release()
+
+	count := 0
+	for iter.Next() {
+		_, err := iter.Triple()
+		if err != nil {
+			t.Fatalf("Triple failed: %v", err)
+		}
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		t.Fatalf("Iterator error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 triples, got %d", count)
+	}
+}
+
+func TestDB_GetDecoded(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTriple([]byte("alice"), []byte("age"), EncodeInt(30)),
+		graph.NewTriple([]byte("bob"), []byte("age"), EncodeInt(100)),
+		graph.NewTriple([]byte("carol"), []byte("age"), EncodeInt(-5)),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// The raw values sort numerically in the POS index, unlike "100" < "30"
+	results, err := db.Get(ctx, graph.NewPattern(nil, "age", nil))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var got []string
+	for _, triple := range results {
+		got = append(got, string(triple.Decoded().Object))
+	}
+	if want := []string{"-5", "30", "100"}; !slices.Equal(got, want) {
+		t.Errorf("decoded objects = %v, want %v", got, want)
+	}
+
+	// Decoding leaves the raw bytes in place
+	if !bytes.Equal(results[1].Object, EncodeInt(30)) {
+		t.Errorf("raw object = %q, want EncodeInt(30)", results[1].Object)
+	}
+	if string(results[1].Decoded().Subject) != "alice" {
+		t.Errorf("decoded subject = %q, want alice", results[1].Decoded().Subject)
+	}
+}
+
+// rangeRecordingStore records the start key of every iterator opened on the
+// wrapped KVStore.
+type rangeRecordingStore struct {
+	KVStore
+	starts [][]byte
+}
+
+func (r *rangeRecordingStore) NewIterator(slice *Range, ro *ReadOptions) Iterator {
+	r.starts = append(r.starts, bytes.Clone(slice.Start))
+	return r.KVStore.NewIterator(slice, ro)
+}
+
+func TestDB_ChooseIndex(t *testing.T) {
+	t.Parallel()
+
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	recording := &rangeRecordingStore{KVStore: store}
+	db, err := OpenWithDB(recording)
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	defer db.Close()
+
+	reversed := graph.NewPattern("alice", nil, nil)
+	reversed.Reverse = true
+	tests := []struct {
+		name        string
+		pattern     *Pattern
+		wantIndex   IndexName
+		wantPrefix  string
+		wantReverse bool
+	}{
+		{"subject only", graph.NewPattern("alice", nil, nil), IndexSOP, "alice::", false},
+		{"predicate and object", graph.NewPattern(nil, "knows", "bob"), IndexPOS, "knows::bob::", false},
+		{"empty", &Pattern{}, IndexOPS, "", false},
+		{"reverse", reversed, IndexSOP, "alice::", true},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		idx, prefix, reverse := db.ChooseIndex(tt.pattern)
+		if idx != tt.wantIndex || string(prefix) != tt.wantPrefix || reverse != tt.wantReverse {
+			t.Errorf("%s: ChooseIndex() = %s, %q, %v, want %s, %q, %v", tt.name,
+				idx, prefix, reverse, tt.wantIndex, tt.wantPrefix, tt.wantReverse)
+		}
+
+		// Get scans the same range.
+		recording.starts = nil
+		if _, err := db.Get(ctx, tt.pattern); err != nil {
+			t.Fatalf("%s: Get() error = %v", tt.name, err)
+		}
+		want := string(idx) + "::" + string(prefix)
+		if len(recording.starts) != 1 || string(recording.starts[0]) != want {
+			t.Errorf("%s: Get() scanned from %q, want %q", tt.name, recording.starts, want)
+		}
+	}
+
+	// An IN clause answered by seeks puts the IN field after the prefix.
+	in := graph.NewPattern(nil, "knows", nil)
+	in.SubjectIn = [][]byte{[]byte("alice"), []byte("bob")}
+	idx, prefix, _ := db.ChooseIndex(in)
+	if idx != IndexPSO || string(prefix) != "knows::" {
+		t.Errorf("ChooseIndex(IN) = %s, %q, want pso, \"knows::\"", idx, prefix)
+	}
+}
+
+func TestDB_GetIn(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "livesIn", "NYC"),
+		graph.NewTripleFromStrings("bob", "livesIn", "LA"),
+		graph.NewTripleFromStrings("carol", "livesIn", "NYC"),
+		graph.NewTripleFromStrings("dave", "livesIn", "Paris"),
+		graph.NewTripleFromStrings("eve", "worksIn", "NYC"),
+	)
+
+	key := func(tr *graph.Triple) string {
+		return fmt.Sprintf("%s %s %s", tr.Subject, tr.Predicate, tr.Object)
+	}
+
+	want := make(map[string]bool)
+	for _, city := range []string{"NYC", "LA"} {
+		triples, err := db.Get(ctx, &graph.Pattern{
+			Predicate: graph.ExactString("livesIn"),
+			Object:    graph.ExactString(city),
+		})
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		for _, tr := range triples {
+			want[key(tr)] = true
+		}
+	}
+
+	check := func(name string, pattern *graph.Pattern) {
+		t.Helper()
+		triples, err := db.Get(ctx, pattern)
+		if err != nil {
+			t.Fatalf("%s: Get() error = %v", name, err)
+		}
+		got := make(map[string]bool)
+		for _, tr := range triples {
+			got[key(tr)] = true
+		}
+		if len(triples) != len(want) || len(got) != len(want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+		for k := range want {
+			if !got[k] {
+				t.Errorf("%s: missing %q", name, k)
+			}
+		}
+	}
+
+	// Few values: one seek per value, including a duplicate.
+	check("seek", &graph.Pattern{
+		Predicate: graph.ExactString("livesIn"),
+		ObjectIn:  [][]byte{[]byte("NYC"), []byte("LA"), []byte("NYC")},
+	})
+
+	// Many values on a narrowed range: a single scan with membership checks.
+	objects := [][]byte{[]byte("NYC"), []byte("LA")}
+	for i := 0; i < inSeekThreshold; i++ {
+		objects = append(objects, []byte(fmt.Sprintf("nowhere%d", i)))
+	}
+	check("scan", &graph.Pattern{
+		Predicate: graph.ExactString("livesIn"),
+		ObjectIn:  objects,
+	})
+
+	// Reverse order walks the values backwards.
+	triples, err := db.Get(ctx, &graph.Pattern{
+		Predicate: graph.ExactString("livesIn"),
+		ObjectIn:  [][]byte{[]byte("LA"), []byte("NYC")},
+		Reverse:   true,
+	})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
 	}
-	if fields["predicate"] != nil {
-		t.Error("predicate should not be in variable fields")
+	if len(triples) != 3 || string(triples[0].Object) != "NYC" || string(triples[2].Object) != "LA" {
+		t.Errorf("reverse Get() = %v, want NYC before LA", triples)
 	}
-}
 
-func TestPattern_ToTriple(t *testing.T) {
-	// Pattern with all concrete values
-	p1 := &graph.Pattern{Subject: graph.ExactString("a"), Predicate: graph.ExactString("b"), Object: graph.ExactString("c")}
-	triple := p1.ToTriple()
-	if triple == nil {
-		t.Fatal("expected triple, got nil")
+	// An IN clause on a concrete field also constrains it.
+	triples, err = db.Get(ctx, &graph.Pattern{
+		Subject:   graph.ExactString("dave"),
+		SubjectIn: [][]byte{[]byte("alice")},
+	})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
 	}
-	if string(triple.Subject) != "a" || string(triple.Predicate) != "b" || string(triple.Object) != "c" {
-		t.Error("triple values don't match pattern")
+	if len(triples) != 0 {
+		t.Errorf("Get() with conflicting IN clause = %v, want none", triples)
 	}
 
-	// Pattern with nil field
-	p2 := &graph.Pattern{Subject: graph.ExactString("a"), Predicate: graph.Wildcard(), Object: graph.ExactString("c")}
-	if p2.ToTriple() != nil {
-		t.Error("pattern with nil field should return nil")
+	// An empty set matches nothing.
+	triples, err = db.Get(ctx, &graph.Pattern{ObjectIn: [][]byte{}})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(triples) != 0 {
+		t.Errorf("Get() with empty IN clause = %v, want none", triples)
 	}
 
-	// Pattern with variable
-	p3 := &graph.Pattern{Subject: graph.ExactString("a"), Predicate: graph.Binding("p"), Object: graph.ExactString("c")}
-	if p3.ToTriple() != nil {
-		t.Error("pattern with variable should return nil")
+	_, err = db.Get(ctx, &graph.Pattern{
+		SubjectIn: [][]byte{[]byte("alice")},
+		ObjectIn:  [][]byte{[]byte("NYC")},
+	})
+	if !errors.Is(err, ErrMultipleInClauses) {
+		t.Errorf("Get() with two IN clauses error = %v, want ErrMultipleInClauses", err)
 	}
 }
 
-func TestDB_V(t *testing.T) {
+func TestDB_GetOrderByObject(t *testing.T) {
+	t.Parallel()
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	v := db.V("test")
-	if v == nil {
-		t.Fatal("graph.V should return a Variable")
-	}
-	if v.Name != "test" {
-		t.Errorf("expected name 'test', got '%s'", v.Name)
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "age", "30"),
+		graph.NewTripleFromStrings("bob", "age", "100"),
+		graph.NewTripleFromStrings("carol", "age", "7"),
+		graph.NewTripleFromStrings("dave", "age", "unknown"),
+		graph.NewTripleFromStrings("erin", "age", "2.5"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
 	}
-}
 
-func TestDB_IsOpen(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-
-	if !db.IsOpen() {
-		t.Error("database should be open")
+	tests := []struct {
+		order         ObjectOrder
+		offset, limit int
+		want          []string
+	}{
+		// Lexically "100" sorts before "30"; numerically it sorts after
+		{OrderAsc, 0, 0, []string{"100", "2.5", "30", "7", "unknown"}},
+		{OrderDesc, 0, 0, []string{"unknown", "7", "30", "2.5", "100"}},
+		{OrderNumericAsc, 0, 0, []string{"2.5", "7", "30", "100", "unknown"}},
+		{OrderNumericDesc, 0, 0, []string{"unknown", "100", "30", "7", "2.5"}},
+		{OrderNumericAsc, 1, 2, []string{"7", "30"}},
+		{OrderNumericDesc, 4, 0, []string{"2.5"}},
 	}
-
-	cleanup()
-
-	if db.IsOpen() {
-		t.Error("database should be closed after cleanup")
+	for _, tt := range tests {
+		pattern := NewPattern(nil, "age", nil)
+		pattern.OrderByObject = tt.order
+		pattern.Offset, pattern.Limit = tt.offset, tt.limit
+		triples, err := db.Get(ctx, pattern)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", tt.order, err)
+		}
+		got := make([]string, len(triples))
+		for i, tr := range triples {
+			got[i] = string(tr.Object)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Get(%s, offset %d, limit %d) = %q, want %q", tt.order, tt.offset, tt.limit, got, tt.want)
+		}
 	}
 }
 
-func TestDB_GetIterator(t *testing.T) {
+func TestDB_GetPredicateIn(t *testing.T) {
+	t.Parallel()
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	t1 := graph.NewTripleFromStrings("a", "b", "c")
-	t2 := graph.NewTripleFromStrings("a", "b", "d")
-	db.Put(context.Background(), t1, t2)
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "knows", "carol"),
+		graph.NewTripleFromStrings("alice", "likes", "jazz"),
+		graph.NewTripleFromStrings("alice", "age", "30"),
+		graph.NewTripleFromStrings("bob", "likes", "rock"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
 
-	iter, err := db.GetIterator(context.Background(), &graph.Pattern{Subject: graph.ExactString("a")})
-	if err != nil {
-		t.Fatalf("GetIterator failed: %v", err)
+	keys := func(triples []*graph.Triple) []string {
+		result := make([]string, len(triples))
+		for i, tr := range triples {
+			result[i] = fmt.Sprintf("%s %s %s", tr.Subject, tr.Predicate, tr.Object)
+		}
+		slices.Sort(result)
+		return result
 	}
-	defer iter.Release()
+	predicates := [][]byte{[]byte("knows"), []byte("likes")}
 
-	count := 0
-	for iter.Next() {
-		_, err := iter.Triple()
+	for _, subject := range []string{"alice", ""} {
+		var want []*graph.Triple
+		for _, predicate := range predicates {
+			triples, err := db.Get(ctx, NewPattern(subject, predicate, nil))
+			if err != nil {
+				t.Fatalf("Get(%s) error = %v", predicate, err)
+			}
+			want = append(want, triples...)
+		}
+
+		pattern := NewPattern(subject, nil, nil)
+		pattern.PredicateIn = predicates
+		got, err := db.Get(ctx, pattern)
 		if err != nil {
-			t.Fatalf("Triple failed: %v", err)
+			t.Fatalf("Get(PredicateIn) error = %v", err)
+		}
+		if !slices.Equal(keys(got), keys(want)) {
+			t.Errorf("Get(subject %q, PredicateIn) = %q, want %q", subject, keys(got), keys(want))
 		}
-		count++
-	}
-	if err := iter.Error(); err != nil {
-		t.Fatalf("Iterator error: %v", err)
 	}
-	if count != 2 {
-		t.Errorf("expected 2 triples, got %d", count)
+
+	// With the subject bound, each predicate is one seek into the SPO index
+	pattern := NewPattern("alice", nil, nil)
+	pattern.PredicateIn = predicates
+	if idx, _, _ := db.ChooseIndex(pattern); idx != IndexSPO {
+		t.Errorf("ChooseIndex(alice, PredicateIn) = %s, want %s", idx, IndexSPO)
 	}
 }
 
@@ -2466,6 +4367,280 @@ func TestNavigator_Triples(t *testing.T) {
 	}
 }
 
+func TestNavigator_TriplesAll(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("bob", "knows", "charlie"),
+		graph.NewTripleFromStrings("bob", "knows", "alice"),
+	)
+
+	nav := db.Nav(ctx, nil).Where(NewPattern(db.V("x"), []byte("knows"), db.V("y")))
+	triples, err := nav.TriplesAll(
+		NewPattern(db.V("x"), []byte("relatedTo"), db.V("y")),
+		NewPattern(db.V("y"), []byte("relatedTo"), db.V("x")),
+	)
+	if err != nil {
+		t.Fatalf("TriplesAll failed: %v", err)
+	}
+
+	// Three solutions times two templates, less the duplicates produced by
+	// the alice/bob pair knowing each other.
+	want := map[string]bool{
+		"alice relatedTo bob":   true,
+		"bob relatedTo alice":   true,
+		"bob relatedTo charlie": true,
+		"charlie relatedTo bob": true,
+	}
+	if len(triples) != len(want) {
+		t.Fatalf("expected %d triples, got %d: %v", len(want), len(triples), triples)
+	}
+	for _, triple := range triples {
+		key := fmt.Sprintf("%s %s %s", triple.Subject, triple.Predicate, triple.Object)
+		if !want[key] {
+			t.Errorf("unexpected triple %q", key)
+		}
+		delete(want, key)
+	}
+
+	triples, err = nav.TriplesAll()
+	if err != nil {
+		t.Fatalf("TriplesAll() with no templates failed: %v", err)
+	}
+	if triples != nil {
+		t.Errorf("expected nil triples without templates, got %v", triples)
+	}
+}
+
+func TestNavigator_Paths(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := setupFOAFData(db); err != nil {
+		t.Fatalf("failed to setup data: %v", err)
+	}
+	ctx := context.Background()
+
+	pathString := func(path []*graph.Triple) string {
+		parts := make([]string, len(path))
+		for i, triple := range path {
+			parts[i] = fmt.Sprintf("%s %s %s", triple.Subject, triple.Predicate, triple.Object)
+		}
+		return strings.Join(parts, " / ")
+	}
+
+	nav := db.Nav(ctx, "lucio").ArchOut("friend").As("friend").ArchOut("friend").As("fof")
+	paths, err := nav.Paths()
+	if err != nil {
+		t.Fatalf("Paths() error = %v", err)
+	}
+	solutions, err := nav.Solutions()
+	if err != nil {
+		t.Fatalf("Solutions() error = %v", err)
+	}
+	if len(paths) != len(solutions) {
+		t.Fatalf("Paths() returned %d paths for %d solutions", len(paths), len(solutions))
+	}
+	want := map[string]string{
+		"daniele": "lucio friend matteo / matteo friend daniele",
+		"davide":  "lucio friend marco / marco friend davide",
+	}
+	for i, path := range paths {
+		fof := string(solutions[i]["fof"])
+		if got := pathString(path); got != want[fof] {
+			t.Errorf("Paths()[%d] = %q, want %q", i, got, want[fof])
+		}
+		if !bytes.Equal(path[0].Object, solutions[i]["friend"]) {
+			t.Errorf("Paths()[%d] first edge ends at %q, want friend %q", i, path[0].Object, solutions[i]["friend"])
+		}
+	}
+
+	// Incoming edges are reported as stored; wildcard predicates are filled in
+	paths, err = db.Nav(ctx, "davide").ArchIn("friend").ArchInAny("friend", "age").Paths()
+	if err != nil {
+		t.Fatalf("Paths() error = %v", err)
+	}
+	got := make([]string, len(paths))
+	for i, path := range paths {
+		got[i] = pathString(path)
+	}
+	slices.Sort(got)
+	wantIn := []string{
+		"marco friend davide / daniele friend marco",
+		"marco friend davide / lucio friend marco",
+	}
+	if !slices.Equal(got, wantIn) {
+		t.Errorf("Paths() = %q, want %q", got, wantIn)
+	}
+}
+
+func TestNavigator_Cancel(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// A fan-out tree: every node links to three children, six levels deep
+	var triples []*graph.Triple
+	frontier := []string{"root"}
+	for level := 0; level < 6; level++ {
+		var next []string
+		for _, node := range frontier {
+			for c := 0; c < 3; c++ {
+				child := fmt.Sprintf("%s.%d", node, c)
+				triples = append(triples, graph.NewTripleFromStrings(node, "child", child))
+				next = append(next, child)
+			}
+		}
+		frontier = next
+	}
+	if err := db.Put(context.Background(), triples...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Cancel from inside the traversal, once the second step has started
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	visited := 0
+	nav := db.Nav(ctx, "root").ArchOut("child").ArchOut("child").Filter(func(*graph.Triple) bool {
+		visited++
+		cancel()
+		return true
+	})
+	for i := 0; i < 4; i++ {
+		nav = nav.ArchOut("child")
+	}
+
+	if _, err := nav.Values(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Values() error = %v, want context.Canceled", err)
+	}
+	if visited == 0 || visited >= 9 {
+		t.Errorf("second step visited %d of 9 triples, want the step to stop early", visited)
+	}
+
+	// Every terminal method reports the cancellation
+	terminals := []struct {
+		name string
+		fn   func() error
+	}{
+		{"Solutions", func() error { _, err := nav.Solutions(); return err }},
+		{"Count", func() error { _, err := nav.Count(); return err }},
+		{"Exists", func() error { _, err := nav.Exists(); return err }},
+		{"Triples", func() error { _, err := nav.Triples(graph.NewPattern(V("x0"), "child", V("x1"))); return err }},
+		{"CountUpTo", func() error { _, _, err := nav.CountUpTo(10); return err }},
+		{"Solutions (no steps)", func() error { _, err := db.Nav(ctx, "root").Solutions(); return err }},
+	}
+	for _, tt := range terminals {
+		if err := tt.fn(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s() error = %v, want context.Canceled", tt.name, err)
+		}
+	}
+}
+
+func TestNavigator_NavFrom(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "knows", "carol"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("bob", "knows", "dave"),
+		graph.NewTripleFromStrings("eve", "knows", "frank"),
+	)
+
+	union := make(map[string]bool)
+	for _, start := range []string{"alice", "bob"} {
+		values, err := db.Nav(ctx, []byte(start)).ArchOut("knows").Values()
+		if err != nil {
+			t.Fatalf("Values() error = %v", err)
+		}
+		for _, v := range values {
+			union[string(v)] = true
+		}
+	}
+
+	nav := db.NavFrom(ctx, []byte("alice"), []byte("bob")).ArchOut("knows")
+	values, err := nav.Values()
+	if err != nil {
+		t.Fatalf("Values() error = %v", err)
+	}
+	got := make(map[string]bool)
+	for _, v := range values {
+		if got[string(v)] {
+			t.Errorf("duplicate value %q", v)
+		}
+		got[string(v)] = true
+	}
+	if len(got) != len(union) {
+		t.Errorf("NavFrom values = %v, want %v", got, union)
+	}
+	for v := range union {
+		if !got[v] {
+			t.Errorf("NavFrom values missing %q", v)
+		}
+	}
+
+	// One solution per start and edge.
+	count, err := nav.Count()
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Count() = %d, want 4", count)
+	}
+	n, capped, err := nav.CountUpTo(3)
+	if err != nil {
+		t.Fatalf("CountUpTo() error = %v", err)
+	}
+	if n != 3 || !capped {
+		t.Errorf("CountUpTo(3) = %d, %v, want 3, true", n, capped)
+	}
+
+	// No starts matches nothing.
+	values, err = db.NavFrom(ctx).ArchOut("knows").Values()
+	if err != nil {
+		t.Fatalf("Values() error = %v", err)
+	}
+	if len(values) != 0 {
+		t.Errorf("NavFrom() with no starts = %q, want none", values)
+	}
+}
+
+func TestNavigator_NavFromDefaultLimit(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath, WithDefaultLimit(3))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "knows", "carol"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("bob", "knows", "dave"),
+	)
+
+	// The starts share one join, so the limits apply to the combined result
+	// rather than to each start.
+	nav := db.NavFrom(ctx, []byte("alice"), []byte("bob")).ArchOut("knows")
+	solutions, err := nav.Solutions()
+	if err != nil {
+		t.Fatalf("Solutions() error = %v", err)
+	}
+	if len(solutions) != 3 {
+		t.Errorf("Solutions() = %d solutions, want DefaultLimit 3", len(solutions))
+	}
+}
+
 func TestNavigator_Filter(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -2967,3 +5142,140 @@ func TestWithDefaultLimit(t *testing.T) {
 		t.Errorf("expected 1 result with explicit limit=1, got %d", len(results))
 	}
 }
+
+func TestDB_LogHook(t *testing.T) {
+	t.Parallel()
+
+	var events []LogEvent
+	hook := func(e LogEvent) { events = append(events, e) }
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithLogHook(hook, 0))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("Put() emitted %d events, want 0", len(events))
+	}
+
+	solutions, err := db.Search(ctx, []*Pattern{
+		{Subject: Binding("x"), Predicate: ExactString("knows"), Object: Binding("y")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Search() emitted %d events, want 1", len(events))
+	}
+	e := events[0]
+	if e.Operation != "search" || e.Duration <= 0 || e.Results != len(solutions) || e.Err != nil {
+		t.Errorf("event = %+v, want search with %d results and non-zero duration", e, len(solutions))
+	}
+
+	events = nil
+	solutions, _, err = db.SearchWithPlan(ctx, []*Pattern{
+		{Subject: Binding("x"), Predicate: ExactString("knows"), Object: Binding("y")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("SearchWithPlan() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Operation != "search" || events[0].Results != len(solutions) {
+		t.Errorf("SearchWithPlan() events = %+v, want one search event with %d results", events, len(solutions))
+	}
+
+	// Operations faster than the threshold are not reported.
+	slow, err := Open(filepath.Join(t.TempDir(), "slow.db"), WithLogHook(hook, time.Hour))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer slow.Close()
+	events = nil
+	if _, err := slow.Get(ctx, &graph.Pattern{}); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Get() under threshold emitted %d events, want 0", len(events))
+	}
+}
+
+// flakyStore fails the first fails batch writes to the wrapped KVStore
+// with err.
+type flakyStore struct {
+	KVStore
+	fails  int
+	err    error
+	writes int
+}
+
+func (f *flakyStore) Write(batch *Batch, wo *WriteOptions) error {
+	f.writes++
+	if f.writes <= f.fails {
+		return f.err
+	}
+	return f.KVStore.Write(batch, wo)
+}
+
+func TestDB_WriteRetry(t *testing.T) {
+	t.Parallel()
+
+	transient := errors.New("leveldb: transient failure")
+	tests := []struct {
+		name       string
+		retries    int
+		fails      int
+		err        error
+		wantErr    bool
+		wantWrites int
+	}{
+		{"succeeds after retries", 3, 2, transient, false, 3},
+		{"fails past the cap", 2, 5, transient, true, 3},
+		{"no retries by default", 0, 1, transient, true, 1},
+		{"permanent error is not retried", 5, 5, leveldb.ErrReadOnly, true, 1},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+			if err != nil {
+				t.Fatalf("openLevelDB() error = %v", err)
+			}
+			flaky := &flakyStore{KVStore: store}
+			db, err := OpenWithDB(flaky, WithWriteRetry(tt.retries, time.Millisecond))
+			if err != nil {
+				t.Fatalf("OpenWithDB() error = %v", err)
+			}
+			defer db.Close()
+
+			// Only fail the Put's own writes, not the ones made when opening
+			flaky.writes, flaky.fails, flaky.err = 0, tt.fails, tt.err
+			triple := graph.NewTripleFromStrings("alice", "knows", "bob")
+			err = db.Put(ctx, triple)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Put() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Put() error = %v, want %v", err, tt.err)
+			}
+			if flaky.writes != tt.wantWrites {
+				t.Errorf("Put() made %d write attempts, want %d", flaky.writes, tt.wantWrites)
+			}
+
+			exists, err := db.ExistsMany(ctx, triple)
+			if err != nil {
+				t.Fatalf("ExistsMany() error = %v", err)
+			}
+			if exists[0] == tt.wantErr {
+				t.Errorf("ExistsMany() = %v, want %v", exists, !tt.wantErr)
+			}
+		})
+	}
+}
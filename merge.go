@@ -0,0 +1,391 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// mergeChunkSize is the number of source triples checked and written per Put
+// during a merge.
+const mergeChunkSize = 1000
+
+// MergeConflict chooses what Merge does when a facet or vector exists in both
+// databases.
+type MergeConflict int
+
+const (
+	// MergeKeep keeps the destination's existing value.
+	MergeKeep MergeConflict = iota
+	// MergeOverwrite replaces the destination's value with the source's.
+	MergeOverwrite
+)
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	// Facets also copies component and triple facets from the source.
+	// Requires facets to be enabled on the destination.
+	Facets bool
+
+	// Vectors also copies persisted vectors from the source.
+	// Requires vectors to be enabled on the destination.
+	Vectors bool
+
+	// Conflict chooses how facets and vectors present in both databases are
+	// resolved. Defaults to MergeKeep.
+	Conflict MergeConflict
+}
+
+// Merge adds all triples from src to db (a set union), optionally copying
+// facets and vectors as well. Triples are streamed from src and written in
+// chunks through Put, so journaling and auto-embedding apply as usual.
+// Returns the number of triples that were not already present in db.
+//
+// Example:
+//
+//	// Combine two shards, letting shard facets win
+//	added, err := db.Merge(ctx, shard, levelgraph.MergeOptions{
+//	    Facets:   true,
+//	    Conflict: levelgraph.MergeOverwrite,
+//	})
+func (db *DB) Merge(ctx context.Context, src *DB, opts MergeOptions) (int, error) {
+	if src == db {
+		return 0, nil
+	}
+
+	db.mu.RLock()
+	closed, readOnly := db.closed, db.options.ReadOnly
+	db.mu.RUnlock()
+	if closed {
+		return 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if readOnly {
+		return 0, fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+	if opts.Facets && !db.options.FacetsEnabled {
+		return 0, ErrFacetsDisabled
+	}
+	if opts.Vectors && db.options.VectorIndex == nil {
+		return 0, ErrVectorsDisabled
+	}
+
+	src.mu.RLock()
+	defer src.mu.RUnlock()
+
+	if src.closed {
+		return 0, fmt.Errorf("levelgraph: merge source: %w", ErrClosed)
+	}
+
+	iter := src.store.NewIterator(spoRange(), nil)
+	defer iter.Release()
+
+	added := 0
+	chunk := make([]*graph.Triple, 0, mergeChunkSize)
+	flush := func() error {
+		n, err := db.putMissing(ctx, chunk)
+		added += n
+		chunk = chunk[:0]
+		return err
+	}
+
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			return added, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return added, fmt.Errorf("levelgraph: merge: %w", err)
+		}
+		chunk = append(chunk, triple)
+
+		if len(chunk) == mergeChunkSize {
+			if err := flush(); err != nil {
+				return added, err
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return added, fmt.Errorf("levelgraph: merge: %w", err)
+	}
+	if len(chunk) > 0 {
+		if err := flush(); err != nil {
+			return added, err
+		}
+	}
+
+	if opts.Facets {
+		if err := db.mergeFacets(ctx, src, opts.Conflict); err != nil {
+			return added, err
+		}
+	}
+
+	if opts.Vectors {
+		if err := db.mergeVectors(ctx, src, opts.Conflict); err != nil {
+			return added, err
+		}
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Info("merge", "added", added)
+	}
+
+	return added, nil
+}
+
+// putMissing puts the triples not already present in db and returns how many
+// that was.
+func (db *DB) putMissing(ctx context.Context, triples []*graph.Triple) (int, error) {
+	db.mu.RLock()
+	if db.closed {
+		db.mu.RUnlock()
+		return 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	var missing []*graph.Triple
+	for _, triple := range db.canonicalTriples(triples) {
+		_, err := db.store.Get(index.GenKey(index.IndexSPO, triple), nil)
+		if err == ErrNotFound {
+			missing = append(missing, triple)
+		} else if err != nil {
+			db.mu.RUnlock()
+			return 0, fmt.Errorf("levelgraph: merge: %w", err)
+		}
+	}
+	db.mu.RUnlock()
+
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if err := db.Put(ctx, missing...); err != nil {
+		return 0, err
+	}
+	return len(missing), nil
+}
+
+// mergeFacets copies component, triple and pair facets from src.
+// Caller must hold src's read lock.
+func (db *DB) mergeFacets(ctx context.Context, src *DB, conflict MergeConflict) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	for _, prefix := range [][]byte{facetPrefix, tripleFacetPrefix, pairFacetPrefix} {
+		iter := src.store.NewIterator(&Range{Start: prefix, Limit: prefixLimit(prefix)}, nil)
+		batch := NewBatch()
+		for iter.Next() {
+			select {
+			case <-ctx.Done():
+				iter.Release()
+				return fmt.Errorf("levelgraph: %w", ctx.Err())
+			default:
+			}
+
+			if conflict == MergeKeep {
+				_, err := db.store.Get(iter.Key(), nil)
+				if err == nil {
+					continue
+				}
+				if err != ErrNotFound {
+					iter.Release()
+					return fmt.Errorf("levelgraph: merge facets: %w", err)
+				}
+			}
+			// Batch.Put copies key and value, so the iterator buffers can be reused
+			batch.Put(iter.Key(), iter.Value())
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return fmt.Errorf("levelgraph: merge facets: %w", err)
+		}
+
+		if err := db.writeBatch(batch); err != nil {
+			return fmt.Errorf("levelgraph: merge facets: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeVectors copies persisted vectors from src, adding them to db's index.
+// Caller must hold src's read lock.
+func (db *DB) mergeVectors(ctx context.Context, src *DB, conflict MergeConflict) error {
+	iter := src.store.NewIterator(&Range{Start: vectorPrefix, Limit: prefixLimit(vectorPrefix)}, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		id := iter.Key()[len(vectorPrefix):]
+		vec := decodeVectorValue(iter.Value())
+		if vec == nil {
+			continue
+		}
+
+		if conflict == MergeKeep {
+			exists, err := db.hasPersistedVector(id)
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+		}
+
+		if err := db.SetVector(ctx, append([]byte{}, id...), vec); err != nil {
+			return err
+		}
+	}
+
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("levelgraph: merge vectors: %w", err)
+	}
+	return nil
+}
+
+// hasPersistedVector reports whether a vector with the given ID is stored in db.
+func (db *DB) hasPersistedVector(id []byte) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return false, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	_, err := db.store.Get(makeVectorKey(id), nil)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("levelgraph: merge vectors: %w", err)
+	}
+	return true, nil
+}
+
+// Diff compares the triples in two databases and returns those only in a
+// and those only in b. Both SPO indexes are streamed in key order and
+// merged, so memory use is bounded by the size of the differences rather
+// than of the graphs. To compare two journal checkpoints, replay each into
+// its own database with ReplayJournal and Diff those.
+//
+// Example:
+//
+//	// Check a replica for drift
+//	missing, extra, err := levelgraph.Diff(ctx, primary, replica)
+func Diff(ctx context.Context, a, b *DB) (onlyA, onlyB []*Triple, err error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return nil, nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if a == b {
+		return nil, nil, nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return nil, nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	iterA := a.store.NewIterator(spoRange(), nil)
+	defer iterA.Release()
+	iterB := b.store.NewIterator(spoRange(), nil)
+	defer iterB.Release()
+
+	decode := func(value []byte) (*Triple, error) {
+		triple, err := decodeTripleValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: diff: %w", err)
+		}
+		return triple, nil
+	}
+
+	hasA, hasB := iterA.Next(), iterB.Next()
+	for hasA || hasB {
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		cmp := 0
+		switch {
+		case !hasB:
+			cmp = -1
+		case !hasA:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(iterA.Key(), iterB.Key())
+		}
+
+		switch {
+		case cmp < 0:
+			triple, err := decode(iterA.Value())
+			if err != nil {
+				return nil, nil, err
+			}
+			onlyA = append(onlyA, triple)
+			hasA = iterA.Next()
+		case cmp > 0:
+			triple, err := decode(iterB.Value())
+			if err != nil {
+				return nil, nil, err
+			}
+			onlyB = append(onlyB, triple)
+			hasB = iterB.Next()
+		default:
+			hasA, hasB = iterA.Next(), iterB.Next()
+		}
+	}
+
+	if err := iterA.Error(); err != nil {
+		return nil, nil, fmt.Errorf("levelgraph: diff: %w", err)
+	}
+	if err := iterB.Error(); err != nil {
+		return nil, nil, fmt.Errorf("levelgraph: diff: %w", err)
+	}
+
+	return onlyA, onlyB, nil
+}
+
+// spoRange returns the key range covering every triple in the SPO index,
+// which lists triples in subject, predicate, object order.
+func spoRange() *Range {
+	all := &graph.Pattern{}
+	return &Range{
+		Start: index.GenKeyFromPattern(index.IndexSPO, all),
+		Limit: index.GenKeyWithUpperBound(index.IndexSPO, all),
+	}
+}
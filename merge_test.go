@@ -0,0 +1,201 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/vector"
+)
+
+func setupMergeDB(t *testing.T, name string) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), name), WithFacets(), WithVectors(vector.NewFlatIndex(2)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDB_Merge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name      string
+		conflict  MergeConflict
+		wantAge   string
+		wantColor []float32
+	}{
+		{"Keep", MergeKeep, "30", []float32{1, 0}},
+		{"Overwrite", MergeOverwrite, "31", []float32{0, 1}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dst := setupMergeDB(t, "dst.db")
+			src := setupMergeDB(t, "src.db")
+
+			dst.Put(ctx,
+				graph.NewTripleFromStrings("alice", "knows", "bob"),
+				graph.NewTripleFromStrings("bob", "knows", "carol"),
+			)
+			src.Put(ctx,
+				graph.NewTripleFromStrings("alice", "knows", "bob"), // overlapping
+				graph.NewTripleFromStrings("carol", "knows", "dave"),
+				graph.NewTripleFromStrings("dave", "knows", "erin"),
+			)
+
+			dst.SetFacet(ctx, FacetSubject, []byte("alice"), []byte("age"), []byte("30"))
+			src.SetFacet(ctx, FacetSubject, []byte("alice"), []byte("age"), []byte("31"))
+			src.SetFacet(ctx, FacetSubject, []byte("dave"), []byte("age"), []byte("40"))
+			src.SetTripleFacet(ctx, graph.NewTripleFromStrings("carol", "knows", "dave"), []byte("since"), []byte("2020"))
+
+			dst.SetObjectVector(ctx, []byte("red"), []float32{1, 0})
+			src.SetObjectVector(ctx, []byte("red"), []float32{0, 1})
+			src.SetObjectVector(ctx, []byte("blue"), []float32{1, 1})
+
+			added, err := dst.Merge(ctx, src, MergeOptions{Facets: true, Vectors: true, Conflict: tt.conflict})
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			if added != 2 {
+				t.Errorf("Merge() added = %d, want 2", added)
+			}
+
+			all, _ := dst.Get(ctx, &graph.Pattern{})
+			if len(all) != 4 {
+				t.Errorf("destination has %d triples after merge, want 4", len(all))
+			}
+
+			age, _ := dst.GetFacet(ctx, FacetSubject, []byte("alice"), []byte("age"))
+			if string(age) != tt.wantAge {
+				t.Errorf("conflicting facet = %s, want %s", age, tt.wantAge)
+			}
+			age, _ = dst.GetFacet(ctx, FacetSubject, []byte("dave"), []byte("age"))
+			if string(age) != "40" {
+				t.Errorf("copied facet = %s, want 40", age)
+			}
+			since, _ := dst.GetTripleFacet(ctx, graph.NewTripleFromStrings("carol", "knows", "dave"), []byte("since"))
+			if string(since) != "2020" {
+				t.Errorf("copied triple facet = %s, want 2020", since)
+			}
+
+			red, err := dst.GetVector(ctx, vector.MakeID(vector.IDTypeObject, []byte("red")))
+			if err != nil || red[0] != tt.wantColor[0] || red[1] != tt.wantColor[1] {
+				t.Errorf("conflicting vector = %v, %v, want %v", red, err, tt.wantColor)
+			}
+			if dst.VectorCount() != 2 {
+				t.Errorf("VectorCount() = %d, want 2", dst.VectorCount())
+			}
+
+			// Merging again adds nothing
+			added, err = dst.Merge(ctx, src, MergeOptions{})
+			if err != nil || added != 0 {
+				t.Errorf("second Merge() = %d, %v, want 0, nil", added, err)
+			}
+		})
+	}
+}
+
+func TestDB_MergeErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	dst, cleanup := setupTestDB(t)
+	defer cleanup()
+	src := setupMergeDB(t, "src.db")
+
+	if _, err := dst.Merge(ctx, src, MergeOptions{Facets: true}); !errors.Is(err, ErrFacetsDisabled) {
+		t.Errorf("Merge(Facets) error = %v, want ErrFacetsDisabled", err)
+	}
+	if _, err := dst.Merge(ctx, src, MergeOptions{Vectors: true}); !errors.Is(err, ErrVectorsDisabled) {
+		t.Errorf("Merge(Vectors) error = %v, want ErrVectorsDisabled", err)
+	}
+
+	src.Close()
+	if _, err := dst.Merge(ctx, src, MergeOptions{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Merge() from closed source error = %v, want ErrClosed", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	a, cleanupA := setupTestDB(t)
+	defer cleanupA()
+	b := setupMergeDB(t, "b.db")
+
+	shared := []*graph.Triple{
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("dave", "likes", "tennis"),
+	}
+	a.Put(ctx, shared...)
+	b.Put(ctx, shared...)
+
+	t.Run("Identical", func(t *testing.T) {
+		onlyA, onlyB, err := Diff(ctx, a, b)
+		if err != nil {
+			t.Fatalf("Diff() error = %v", err)
+		}
+		if len(onlyA) != 0 || len(onlyB) != 0 {
+			t.Errorf("Diff() of identical DBs = %v, %v, want empty", onlyA, onlyB)
+		}
+	})
+
+	a.Put(ctx,
+		graph.NewTripleFromStrings("aaron", "knows", "bob"), // sorts before all shared triples
+		graph.NewTripleFromStrings("erin", "knows", "frank"),
+	)
+	b.Put(ctx, graph.NewTripleFromStrings("carol", "knows", "dave"))
+	b.Del(ctx, shared[2])
+
+	t.Run("Differences", func(t *testing.T) {
+		onlyA, onlyB, err := Diff(ctx, a, b)
+		if err != nil {
+			t.Fatalf("Diff() error = %v", err)
+		}
+
+		wantA := []string{"aaron knows bob", "dave likes tennis", "erin knows frank"}
+		if len(onlyA) != len(wantA) {
+			t.Fatalf("Diff() onlyA = %v, want %v", onlyA, wantA)
+		}
+		for i, triple := range onlyA {
+			if triple.String() != wantA[i] {
+				t.Errorf("onlyA[%d] = %q, want %q", i, triple, wantA[i])
+			}
+		}
+
+		if len(onlyB) != 1 || onlyB[0].String() != "carol knows dave" {
+			t.Errorf("Diff() onlyB = %v, want [carol knows dave]", onlyB)
+		}
+	})
+}
@@ -26,8 +26,10 @@ package levelgraph
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
 )
 
 // Navigator provides a fluent API for traversing the graph.
@@ -46,6 +48,11 @@ type Navigator struct {
 	initialSolution graph.Solution
 	lastElement     any // either []byte or *graph.Variable
 	varCounter      int
+
+	// startVar and starts are set by NavFrom: the query is seeded with one
+	// solution per start, with startVar bound to it.
+	startVar *graph.Variable
+	starts   [][]byte
 }
 
 // Nav creates a new Navigator starting from the given vertex.
@@ -62,6 +69,77 @@ func (db *DB) Nav(ctx context.Context, start any) *Navigator {
 	return nav
 }
 
+// NavFrom creates a new Navigator starting from every one of the given
+// vertices at once, so "friends of {alice, bob}" is a single traversal:
+//
+//	friends, err := db.NavFrom(ctx, []byte("alice"), []byte("bob")).ArchOut("knows").Values()
+//
+// Solutions contain one entry per start and path, and Values returns the
+// merged endpoints. With no starts the navigator matches nothing.
+func (db *DB) NavFrom(ctx context.Context, starts ...[]byte) *Navigator {
+	if len(starts) == 1 {
+		return db.Nav(ctx, starts[0])
+	}
+	nav := db.Nav(ctx, nil)
+	nav.startVar = nav.lastElement.(*graph.Variable)
+	nav.starts = starts
+	return nav
+}
+
+// seeds returns the initial solutions the query starts from: a copy of the
+// initial solution, or one copy of it per NavFrom start.
+func (nav *Navigator) seeds() []graph.Solution {
+	if nav.startVar == nil {
+		return []graph.Solution{nav.initialSolution.Clone()}
+	}
+	seeds := make([]graph.Solution, 0, len(nav.starts))
+	for _, start := range nav.starts {
+		seed := make(graph.Solution, len(nav.initialSolution)+1)
+		for k, v := range nav.initialSolution {
+			seed[k] = v
+		}
+		seed[nav.startVar.Name] = start
+		seeds = append(seeds, seed)
+	}
+	return seeds
+}
+
+// search runs the navigator's conditions as one join seeded with every seed,
+// so opts.Limit and DefaultLimit apply to the combined result.
+func (nav *Navigator) search(opts SearchOptions) (result []graph.Solution, err error) {
+	db := nav.db
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search", start, len(result), err) }()
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	return db.searchSeedsUnlocked(nav.ctx, nav.conditions, nil, nav.seeds(), &opts, nil)
+}
+
+// check returns the error a terminal method should fail with before doing
+// any work: the database is closed or the navigator's context is done.
+func (nav *Navigator) check() error {
+	if !nav.db.IsOpen() {
+		return fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if nav.ctx == nil {
+		return nil
+	}
+	select {
+	case <-nav.ctx.Done():
+		return fmt.Errorf("levelgraph: %w", nav.ctx.Err())
+	default:
+	}
+	return nil
+}
+
 // nextVar generates the next anonymous variable for this navigator.
 func (nav *Navigator) nextVar() *graph.Variable {
 	v := graph.V(fmt.Sprintf("x%d", nav.varCounter))
@@ -114,6 +192,55 @@ func (nav *Navigator) ArchIn(predicate any) *Navigator {
 	return nav
 }
 
+// ArchOutAny follows an outgoing edge labelled with any of the given predicates
+// in a single step. Unlike a union of ArchOut navigators, the step is one
+// lookup with the predicates as its IN clause (see Pattern.PredicateIn).
+// An endpoint reachable through several predicates produces one solution per edge;
+// use Values to collect the distinct endpoints.
+func (nav *Navigator) ArchOutAny(predicates ...any) *Navigator {
+	if len(predicates) == 1 {
+		return nav.ArchOut(predicates[0])
+	}
+
+	newVar := nav.nextVar()
+
+	pattern := graph.NewPattern(nav.lastElement, nil, newVar)
+	pattern.PredicateIn = predicateSet(predicates)
+
+	nav.conditions = append(nav.conditions, pattern)
+	nav.lastElement = newVar
+	return nav
+}
+
+// ArchInAny follows an incoming edge labelled with any of the given predicates
+// in a single step. See ArchOutAny for details.
+func (nav *Navigator) ArchInAny(predicates ...any) *Navigator {
+	if len(predicates) == 1 {
+		return nav.ArchIn(predicates[0])
+	}
+
+	newVar := nav.nextVar()
+
+	pattern := graph.NewPattern(newVar, nil, nav.lastElement)
+	pattern.PredicateIn = predicateSet(predicates)
+
+	nav.conditions = append(nav.conditions, pattern)
+	nav.lastElement = newVar
+	return nav
+}
+
+// predicateSet returns the IN clause for the given predicates. It returns nil
+// when no predicates are given, so every edge is followed.
+func predicateSet(predicates []any) [][]byte {
+	var allowed [][]byte
+	for _, p := range predicates {
+		if val := normalizeValue(p); val != nil {
+			allowed = append(allowed, val)
+		}
+	}
+	return allowed
+}
+
 // As names the current position with the given variable name.
 // This allows referencing the position later in the query.
 func (nav *Navigator) As(name string) *Navigator {
@@ -138,16 +265,18 @@ func (nav *Navigator) Bind(value any) *Navigator {
 // Solutions executes the navigation query and returns all solutions.
 // Each solution is a map of variable names to their bound values.
 func (nav *Navigator) Solutions() ([]graph.Solution, error) {
+	if err := nav.check(); err != nil {
+		return nil, err
+	}
+
 	if len(nav.conditions) == 0 {
-		// No conditions means return the initial solution
-		return []graph.Solution{nav.initialSolution}, nil
+		// No conditions means return the initial solutions
+		return nav.seeds(), nil
 	}
 
 	// Pass initial solution to search - patterns will be updated with bound values,
 	// and the initial solution will be included in results
-	return nav.db.Search(nav.ctx, nav.conditions, &SearchOptions{
-		InitialSolution: nav.initialSolution,
-	})
+	return nav.search(SearchOptions{})
 }
 
 // Values returns unique values for the last navigated position.
@@ -190,14 +319,15 @@ func (nav *Navigator) Values() ([][]byte, error) {
 // Triples executes the query and materializes results into triples.
 // The pattern specifies how to construct the result triples from solutions.
 func (nav *Navigator) Triples(pattern *graph.Pattern) ([]*graph.Triple, error) {
+	if err := nav.check(); err != nil {
+		return nil, err
+	}
+
 	if len(nav.conditions) == 0 {
 		return nil, nil
 	}
 
-	solutions, err := nav.db.Search(nav.ctx, nav.conditions, &SearchOptions{
-		InitialSolution: nav.initialSolution,
-		Materialized:    pattern,
-	})
+	solutions, err := nav.search(SearchOptions{Materialized: pattern})
 	if err != nil {
 		return nil, err
 	}
@@ -205,24 +335,125 @@ func (nav *Navigator) Triples(pattern *graph.Pattern) ([]*graph.Triple, error) {
 	// Convert solutions to triples
 	var result []*graph.Triple
 	for _, sol := range solutions {
-		triple := &graph.Triple{}
-		if s, ok := sol["subject"]; ok {
-			triple.Subject = s
-		}
-		if p, ok := sol["predicate"]; ok {
-			triple.Predicate = p
+		if triple := materializedTriple(sol); triple != nil {
+			result = append(result, triple)
 		}
-		if o, ok := sol["object"]; ok {
-			triple.Object = o
+	}
+
+	return result, nil
+}
+
+// TriplesAll executes the query and materializes every solution through each
+// of the templates, so one traversal can produce several derived triples per
+// solution (e.g. both "x relatedTo y" and "y relatedTo x"). Identical output
+// triples are returned once, in order of first occurrence.
+func (nav *Navigator) TriplesAll(templates ...*graph.Pattern) ([]*graph.Triple, error) {
+	if err := nav.check(); err != nil {
+		return nil, err
+	}
+
+	if len(nav.conditions) == 0 || len(templates) == 0 {
+		return nil, nil
+	}
+
+	solutions, err := nav.search(SearchOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var result []*graph.Triple
+	for _, template := range templates {
+		materialized, err := nav.db.materializeSolutions(solutions, template)
+		if err != nil {
+			return nil, err
 		}
-		if triple.Subject != nil && triple.Predicate != nil && triple.Object != nil {
-			result = append(result, triple)
+		for _, sol := range materialized {
+			triple := materializedTriple(sol)
+			if triple == nil {
+				continue
+			}
+			key := string(index.GenKey(index.IndexSPO, triple))
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, triple)
+			}
 		}
 	}
 
 	return result, nil
 }
 
+// Paths executes the query and returns, for each solution, the edges it
+// traversed to reach the final position: one triple per step (ArchOut,
+// ArchIn, their Any forms and Where conditions) in the order the steps were
+// added. This explains why each endpoint was reached:
+//
+//	paths, err := db.Nav(ctx, "alice").ArchOut("knows").ArchOut("likes").Paths()
+//	// paths[i] is [alice knows x, x likes y] for the i-th solution
+//
+// Paths are returned in the same order as Solutions.
+func (nav *Navigator) Paths() ([][]*graph.Triple, error) {
+	if err := nav.check(); err != nil {
+		return nil, err
+	}
+
+	if len(nav.conditions) == 0 {
+		return nil, nil
+	}
+
+	// Wildcard fields, such as the predicate of an ArchOutAny step, are not
+	// bound in the solutions, so bind them to hidden variables for this query.
+	steps := *nav
+	steps.conditions = make([]*graph.Pattern, len(nav.conditions))
+	for i, cond := range nav.conditions {
+		if !cond.Subject.IsWildcard() && !cond.Predicate.IsWildcard() && !cond.Object.IsWildcard() {
+			steps.conditions[i] = cond
+			continue
+		}
+		step := cond.Clone()
+		if step.Subject.IsWildcard() {
+			step.Subject = graph.Binding(fmt.Sprintf("_path%d_s", i))
+		}
+		if step.Predicate.IsWildcard() {
+			step.Predicate = graph.Binding(fmt.Sprintf("_path%d_p", i))
+		}
+		if step.Object.IsWildcard() {
+			step.Object = graph.Binding(fmt.Sprintf("_path%d_o", i))
+		}
+		steps.conditions[i] = step
+	}
+
+	solutions, err := steps.search(SearchOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([][]*graph.Triple, len(solutions))
+	for i, sol := range solutions {
+		path := make([]*graph.Triple, len(steps.conditions))
+		for j, step := range steps.conditions {
+			path[j] = step.UpdateWithSolution(sol).ToTriple()
+		}
+		paths[i] = path
+	}
+	return paths, nil
+}
+
+// materializedTriple converts a materialized solution into a triple, or
+// returns nil if any of its parts is unbound.
+func materializedTriple(sol graph.Solution) *graph.Triple {
+	triple := &graph.Triple{
+		Subject:   sol["subject"],
+		Predicate: sol["predicate"],
+		Object:    sol["object"],
+	}
+	if triple.Subject == nil || triple.Predicate == nil || triple.Object == nil {
+		return nil
+	}
+	return triple
+}
+
 // normalizeValue converts various input types to []byte.
 func normalizeValue(v any) []byte {
 	if v == nil {
@@ -253,16 +484,62 @@ func (nav *Navigator) Count() (int, error) {
 	return len(solutions), nil
 }
 
+// CountUpTo counts solutions, stopping as soon as max is reached. It returns
+// the count capped at max and whether the cap was reached, which answers
+// threshold questions ("more than N friends?") without enumerating every edge.
+func (nav *Navigator) CountUpTo(max int) (int, bool, error) {
+	if err := nav.check(); err != nil {
+		return 0, false, err
+	}
+	if max <= 0 {
+		return 0, true, nil
+	}
+
+	seeds := nav.seeds()
+	if len(nav.conditions) == 0 {
+		// No conditions means the initial solutions are the only ones
+		count := min(len(seeds), max)
+		return count, count >= max, nil
+	}
+
+	count := 0
+	for _, seed := range seeds {
+		iter, err := nav.db.SearchIterator(nav.ctx, nav.conditions, &SearchOptions{
+			InitialSolution: seed,
+			Limit:           max - count,
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		for iter.Next() {
+			count++
+		}
+		err = iter.Error()
+		iter.Close()
+		if err != nil {
+			return 0, false, err
+		}
+		if count >= max {
+			break
+		}
+	}
+	return count, count >= max, nil
+}
+
 // First returns the first solution, or nil if none found.
 func (nav *Navigator) First() (graph.Solution, error) {
+	if err := nav.check(); err != nil {
+		return nil, err
+	}
+
 	if len(nav.conditions) == 0 {
-		return nav.initialSolution, nil
+		if seeds := nav.seeds(); len(seeds) > 0 {
+			return seeds[0], nil
+		}
+		return nil, nil
 	}
 
-	solutions, err := nav.db.Search(nav.ctx, nav.conditions, &SearchOptions{
-		InitialSolution: nav.initialSolution,
-		Limit:           1,
-	})
+	solutions, err := nav.search(SearchOptions{Limit: 1})
 	if err != nil {
 		return nil, err
 	}
@@ -289,6 +566,8 @@ func (nav *Navigator) Clone() *Navigator {
 		initialSolution: make(graph.Solution),
 		lastElement:     nav.lastElement,
 		varCounter:      nav.varCounter,
+		startVar:        nav.startVar,
+		starts:          nav.starts,
 	}
 
 	copy(newNav.conditions, nav.conditions)
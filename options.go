@@ -26,6 +26,7 @@ package levelgraph
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/benbenbenbenbenben/levelgraph/vector"
 )
@@ -52,6 +53,14 @@ type Options struct {
 	// When set, vector operations (SetVector, GetVector, SearchVectors) are enabled.
 	VectorIndex vector.Index
 
+	// VectorIDCodec builds and parses the IDs of subject, predicate, object
+	// and triple vectors. Nil means vector.DefaultIDCodec.
+	VectorIDCodec vector.IDCodec
+
+	// VectorStorageEncoding is the format of the vectors SetVector
+	// persists. Set with WithVectorStorageEncoding.
+	VectorStorageEncoding VectorEncoding
+
 	// JoinAlgorithm specifies which join algorithm to use for searches.
 	// Defaults to JoinAlgorithmSort.
 	JoinAlgorithm JoinAlgorithm
@@ -74,6 +83,16 @@ type Options struct {
 	// Only used when Embedder is set.
 	AutoEmbedTargets AutoEmbedTarget
 
+	// TripleTextFunc builds the text embedded for a triple under
+	// AutoEmbedTriples. Defaults to DefaultTripleText when nil.
+	TripleTextFunc func(*Triple) string
+
+	// AutoEmbedPredicateTargets scopes auto-embedding by predicate: when
+	// non-empty, only triples whose predicate is a key are embedded, using
+	// that key's targets instead of AutoEmbedTargets.
+	// Set with WithAutoEmbedPredicate.
+	AutoEmbedPredicateTargets map[string]AutoEmbedTarget
+
 	// AsyncAutoEmbed enables non-blocking auto-embedding.
 	// When enabled, embedding is performed in a background goroutine instead of
 	// blocking the Put() call. Use WaitForEmbeddings() to wait for pending work.
@@ -82,6 +101,140 @@ type Options struct {
 	// AsyncEmbedBufferSize sets the buffer size for the async embed queue.
 	// Defaults to 100 if not set. Only used when AsyncAutoEmbed is true.
 	AsyncEmbedBufferSize int
+
+	// AsyncEmbedWorkers sets how many goroutines drain the async embed queue.
+	// Defaults to 1 if not set. Only used when AsyncAutoEmbed is true.
+	AsyncEmbedWorkers int
+
+	// EmbedRetries is how many times a failed async embedding is retried,
+	// waiting EmbedRetryBackoff before the first retry and doubling the wait
+	// each time. Defaults to DefaultEmbedRetries. Set with WithEmbedRetry.
+	EmbedRetries      int
+	EmbedRetryBackoff time.Duration
+
+	// EmbedErrorHandler is called for each vector ID whose async embedding
+	// still fails after EmbedRetries, with the text that was being embedded.
+	// Set with WithEmbedErrorHandler.
+	EmbedErrorHandler func(id []byte, text string, err error)
+
+	// VectorDeltaLog enables incremental persistence of the HNSW graph.
+	// Each vector write appends a small delta record, and LoadVectors restores
+	// the last snapshot saved with SaveVectorIndex before replaying the deltas.
+	VectorDeltaLog bool
+
+	// VectorDeltaCompactEvery folds the delta log into a new snapshot once this
+	// many deltas have been recorded. 0 disables automatic compaction.
+	// Only used when VectorDeltaLog is true.
+	VectorDeltaCompactEvery int
+
+	// MaxBatchSize caps the number of triples written in a single batch by
+	// Put and Del. Larger inputs are split into several batches, so the
+	// operation is no longer atomic as a whole: if a later batch fails, the
+	// earlier ones remain committed. Each triple and its journal entry are
+	// always written together. 0 means no limit (one atomic batch).
+	MaxBatchSize int
+
+	// WriteRetries is the number of times a failed write is retried before
+	// its error is returned. Only transient store errors are retried; errors
+	// such as a closed, read-only or corrupted store fail at once.
+	// 0 disables retries.
+	WriteRetries int
+
+	// WriteRetryBackoff is the wait before the first retry. It doubles
+	// before each further retry. Only used when WriteRetries is positive.
+	WriteRetryBackoff time.Duration
+
+	// QueryCacheSize enables an LRU cache of Get results holding up to this
+	// many patterns. Put and Del drop every cached result their triples
+	// could change. Patterns with a Filter are not cached. 0 disables it.
+	QueryCacheSize int
+
+	// AdjacencyCacheSize enables an LRU cache of node edges for joins and
+	// Navigator steps, holding the edges of up to this many (node,
+	// predicate, direction) combinations. Put and Del drop the entries their
+	// triples change. 0 disables it.
+	AdjacencyCacheSize int
+
+	// WalkWeightFacet names the triple facet holding each edge's weight for
+	// RandomWalk, as an EncodeInt or EncodeFloat value or decimal text.
+	// Edges without the facet weigh 1.
+	// When empty, RandomWalk picks edges uniformly.
+	WalkWeightFacet string
+
+	// MaxComponentSize caps the length in bytes of a triple's subject,
+	// predicate and object on Put; larger components fail with
+	// ErrComponentTooLarge. Every index key contains all three components,
+	// so large values are best stored externally and referenced by ID.
+	// Defaults to DefaultMaxComponentSize; 0 means no limit.
+	MaxComponentSize int
+
+	// ValueCompressionThreshold snappy-compresses the triple value stored
+	// under each index key when it is longer than this many bytes, which
+	// speeds up point reads of large, redundant objects such as JSON
+	// documents. Keys stay uncompressed so ordering is unaffected, and
+	// values are decompressed transparently on read. 0 disables compression.
+	ValueCompressionThreshold int
+
+	// ReadOnly opens the database without write access. Put, Del and the
+	// facet, vector and journal-trimming writes return ErrReadOnly.
+	ReadOnly bool
+
+	// TextIndex enables the inverted text index used by SearchText. Objects
+	// are tokenized on Put and their tokens removed again on Del.
+	TextIndex bool
+
+	// TextIndexPredicates limits the text index to objects of these
+	// predicates. When empty, objects of every predicate are indexed.
+	// Only used when TextIndex is true.
+	TextIndexPredicates [][]byte
+
+	// Migrations upgrade databases written in an older FormatVersion when
+	// they are opened. Each function is keyed by the version it migrates
+	// from and brings the store up by exactly one version.
+	Migrations map[int]MigrationFunc
+
+	// InversePredicates maps a predicate to its inverse. Put and Del of a
+	// triple with a listed predicate also write or delete the reversed triple
+	// with the inverse predicate. Set with WithInversePredicates, which
+	// registers each pair in both directions.
+	InversePredicates map[string]string
+
+	// Versioning stamps every Put triple with a version, one higher than
+	// the last version of its (subject, predicate) pair, so GetLatest can
+	// return the most recently put object of each pair. Set with
+	// WithVersioning.
+	Versioning bool
+
+	// Timestamps records when each triple was first and last put, for
+	// GetTimestamps. Set with WithTimestamps.
+	Timestamps bool
+
+	// PredicateAliases maps an alias predicate to its canonical predicate.
+	// Writes store aliased triples under the canonical predicate, and
+	// queries for an alias match the canonical triples. Set with
+	// WithPredicateAlias.
+	PredicateAliases map[string]string
+
+	// LogHook, when set, receives a LogEvent after each Get, Search and
+	// vector search operation. When nil, no events are built.
+	LogHook func(LogEvent)
+
+	// SlowThreshold limits LogHook to operations taking at least this long.
+	// 0 reports every operation. Only used when LogHook is set.
+	SlowThreshold time.Duration
+}
+
+// LogEvent describes a completed read operation reported to Options.LogHook.
+type LogEvent struct {
+	// Operation is the name of the operation, e.g. "get", "search" or
+	// "search_vectors".
+	Operation string
+	// Duration is the wall-clock time the operation took.
+	Duration time.Duration
+	// Results is the number of results returned.
+	Results int
+	// Err is the error returned by the operation, if any.
+	Err error
 }
 
 // Option is a function that configures Options.
@@ -89,19 +242,34 @@ type Option func(*Options)
 
 func defaultOptions() *Options {
 	return &Options{
-		JournalEnabled: false,
-		FacetsEnabled:  false,
-		JoinAlgorithm:  JoinAlgorithmSort,
-		Logger:         nil,
+		JournalEnabled:   false,
+		FacetsEnabled:    false,
+		JoinAlgorithm:    JoinAlgorithmSort,
+		Logger:           nil,
+		MaxComponentSize: DefaultMaxComponentSize,
+
+		EmbedRetries:      DefaultEmbedRetries,
+		EmbedRetryBackoff: DefaultEmbedRetryBackoff,
 	}
 }
 
+// DefaultEmbedRetries and DefaultEmbedRetryBackoff are the default retry
+// policy for failed async embeddings: three retries, 100ms apart at first.
+const (
+	DefaultEmbedRetries      = 3
+	DefaultEmbedRetryBackoff = 100 * time.Millisecond
+)
+
+// DefaultMaxComponentSize is the default Options.MaxComponentSize: 16 MiB.
+const DefaultMaxComponentSize = 16 << 20
+
 // applyOptions applies a list of option functions to an Options struct.
 func applyOptions(opts ...Option) *Options {
 	options := defaultOptions()
 	for _, opt := range opts {
 		opt(options)
 	}
+	canonicalInverses(options)
 	return options
 }
 
@@ -174,6 +342,41 @@ func WithVectors(index vector.Index) Option {
 	}
 }
 
+// WithVectorStorageEncoding sets the format of the vectors SetVector
+// persists: VectorFloat32 (the default), or the smaller but lossy
+// VectorFloat16 or VectorInt8. The index keeps the exact float32 values
+// until the database is reopened; LoadVectors then fills it with the
+// decoded, approximate ones. Vectors written in any encoding stay readable,
+// so the encoding can be changed between opens.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithVectors(vector.NewHNSWIndex(384)),
+//	    levelgraph.WithVectorStorageEncoding(levelgraph.VectorInt8),
+//	)
+func WithVectorStorageEncoding(enc VectorEncoding) Option {
+	return func(o *Options) {
+		o.VectorStorageEncoding = enc
+	}
+}
+
+// WithVectorIDCodec sets the codec used to build and parse vector IDs, for
+// databases whose vectors are shared with a system that has its own ID scheme.
+// The codec must be the same every time the database is opened.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithVectors(vector.NewFlatIndex(384)),
+//	    levelgraph.WithVectorIDCodec(myCodec),
+//	)
+func WithVectorIDCodec(codec vector.IDCodec) Option {
+	return func(o *Options) {
+		o.VectorIDCodec = codec
+	}
+}
+
 // Embedder is an interface for text embedding models.
 // Implementations convert text to vector representations for semantic search.
 type Embedder interface {
@@ -200,10 +403,20 @@ const (
 	AutoEmbedPredicates
 	// AutoEmbedObjects enables automatic embedding of object values.
 	AutoEmbedObjects
+	// AutoEmbedTriples enables automatic embedding of whole triples, stored
+	// under TripleVectorID. The text embedded is built by the
+	// TripleTextFunc (see WithTripleTextFunc).
+	AutoEmbedTriples
 	// AutoEmbedAll enables automatic embedding of all triple components.
 	AutoEmbedAll = AutoEmbedSubjects | AutoEmbedPredicates | AutoEmbedObjects
 )
 
+// DefaultTripleText is the default TripleTextFunc: the subject, predicate
+// and object joined by spaces, e.g. "alice likes tennis".
+func DefaultTripleText(t *Triple) string {
+	return string(t.Subject) + " " + string(t.Predicate) + " " + string(t.Object)
+}
+
 // WithAutoEmbed enables automatic vector embedding when triples are added.
 // Requires both an Embedder and a VectorIndex to be configured.
 //
@@ -220,26 +433,343 @@ func WithAutoEmbed(embedder Embedder, targets AutoEmbedTarget) Option {
 	}
 }
 
-// WithAsyncAutoEmbed enables non-blocking auto-embedding with the specified buffer size.
-// When enabled, embedding is performed in a background goroutine instead of blocking
-// the Put() call. This is useful when using real embedding models that have latency.
+// WithTripleTextFunc sets the function that turns a triple into the text
+// embedded under AutoEmbedTriples, replacing DefaultTripleText.
+func WithTripleTextFunc(fn func(*Triple) string) Option {
+	return func(o *Options) {
+		o.TripleTextFunc = fn
+	}
+}
+
+// WithAutoEmbedPredicate scopes auto-embedding to triples with the given
+// predicate, embedding their targets components. It may be given several
+// times; triples with unlisted predicates are then not embedded at all,
+// which avoids wasting embedding calls on enum-like values. The embedder is
+// still configured with WithAutoEmbed.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithVectors(vector.NewHNSWIndex(192)),
+//	    levelgraph.WithAutoEmbed(myEmbedder, levelgraph.AutoEmbedNone),
+//	    levelgraph.WithAutoEmbedPredicate([]byte("description"), levelgraph.AutoEmbedObjects),
+//	)
+func WithAutoEmbedPredicate(predicate []byte, targets AutoEmbedTarget) Option {
+	return func(o *Options) {
+		if o.AutoEmbedPredicateTargets == nil {
+			o.AutoEmbedPredicateTargets = make(map[string]AutoEmbedTarget)
+		}
+		o.AutoEmbedPredicateTargets[string(predicate)] = targets
+	}
+}
+
+// WithAsyncAutoEmbed enables non-blocking auto-embedding with the specified buffer size
+// and number of workers. When enabled, embedding is performed by background goroutines
+// instead of blocking the Put() call. This is useful when using real embedding models
+// that have latency; with a remote embedder, several workers keep requests in flight
+// concurrently. A value shared by triples handled by different workers is embedded once.
 //
 // Use WaitForEmbeddings() to block until all pending embeddings are complete.
 // The buffer size determines how many embedding requests can be queued before Put()
-// blocks waiting for the queue to drain.
+// blocks waiting for the queue to drain. Workers below 1 mean a single worker.
 //
 // Example:
 //
 //	db, err := levelgraph.Open("/path/to/db",
 //	    levelgraph.WithVectors(vector.NewHNSWIndex(192)),
 //	    levelgraph.WithAutoEmbed(myEmbedder, levelgraph.AutoEmbedObjects),
-//	    levelgraph.WithAsyncAutoEmbed(100),
+//	    levelgraph.WithAsyncAutoEmbed(100, 4),
 //	)
 //	// ... add triples ...
 //	db.WaitForEmbeddings(ctx) // Wait for all embeddings to complete
-func WithAsyncAutoEmbed(bufferSize int) Option {
+func WithAsyncAutoEmbed(bufferSize, workers int) Option {
 	return func(o *Options) {
 		o.AsyncAutoEmbed = true
 		o.AsyncEmbedBufferSize = bufferSize
+		o.AsyncEmbedWorkers = workers
+	}
+}
+
+// WithEmbedRetry sets how often a failed async embedding is retried and the
+// wait before the first retry, which doubles on each further attempt. Zero
+// retries disables retrying.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithAutoEmbed(remoteEmbedder, levelgraph.AutoEmbedObjects),
+//	    levelgraph.WithAsyncAutoEmbed(100, 4),
+//	    levelgraph.WithEmbedRetry(5, time.Second), // wait 1s, 2s, 4s, 8s, 16s
+//	)
+func WithEmbedRetry(retries int, backoff time.Duration) Option {
+	return func(o *Options) {
+		o.EmbedRetries = retries
+		o.EmbedRetryBackoff = backoff
+	}
+}
+
+// WithEmbedErrorHandler sets a dead-letter callback for async embeddings that
+// still fail after the retries configured with WithEmbedRetry. It is called
+// once per vector ID, from a background worker, so the caller can log the
+// failure or retry later by embedding the text and calling SetVector. Without
+// a handler the failure is only logged.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithAutoEmbed(remoteEmbedder, levelgraph.AutoEmbedObjects),
+//	    levelgraph.WithAsyncAutoEmbed(100, 4),
+//	    levelgraph.WithEmbedErrorHandler(func(id []byte, text string, err error) {
+//	        log.Printf("could not embed %q: %v", text, err)
+//	    }),
+//	)
+func WithEmbedErrorHandler(fn func(id []byte, text string, err error)) Option {
+	return func(o *Options) {
+		o.EmbedErrorHandler = fn
+	}
+}
+
+// WithVectorDeltaLog enables incremental persistence for an HNSW vector index.
+// Rather than rebuilding the graph from every stored vector on LoadVectors,
+// the graph is restored from a snapshot and only the vectors changed since
+// are re-applied. compactEvery sets how many deltas may accumulate before a
+// new snapshot is written automatically; 0 leaves compaction to explicit
+// SaveVectorIndex calls.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithVectors(vector.NewHNSWIndex(192)),
+//	    levelgraph.WithVectorDeltaLog(1000),
+//	)
+func WithVectorDeltaLog(compactEvery int) Option {
+	return func(o *Options) {
+		o.VectorDeltaLog = true
+		o.VectorDeltaCompactEvery = compactEvery
+	}
+}
+
+// WithMaxBatchSize caps the number of triples Put and Del write per batch,
+// bounding memory use for very large inputs at the cost of atomicity
+// across batches. 0 (the default) writes each call as one batch.
+func WithMaxBatchSize(n int) Option {
+	return func(o *Options) {
+		o.MaxBatchSize = n
+	}
+}
+
+// WithWriteRetry retries writes that fail with a transient store error up
+// to maxRetries times, waiting backoff before the first retry and doubling
+// the wait each time after. Put, Del and every other batch commit are
+// retried; permanent errors such as a closed store are returned at once.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithWriteRetry(3, 10*time.Millisecond),
+//	)
+func WithWriteRetry(maxRetries int, backoff time.Duration) Option {
+	return func(o *Options) {
+		o.WriteRetries = maxRetries
+		o.WriteRetryBackoff = backoff
+	}
+}
+
+// WithQueryCache caches the results of up to maxEntries Get patterns, least
+// recently used first out, for read workloads that fetch the same patterns
+// repeatedly. A write drops the cached results of every pattern matching one
+// of its triples, so cached results are never stale.
+func WithQueryCache(maxEntries int) Option {
+	return func(o *Options) {
+		o.QueryCacheSize = maxEntries
+	}
+}
+
+// WithAdjacencyCache caches the edges read by join steps that follow one
+// predicate from one node, such as Navigator's ArchOut and ArchIn, for up to
+// maxNodes (node, predicate, direction) combinations. Repeated traversals
+// from the same nodes are then served from memory. A write drops the cached
+// edges of its triples' endpoints, so traversals are never stale.
+func WithAdjacencyCache(maxNodes int) Option {
+	return func(o *Options) {
+		o.AdjacencyCacheSize = maxNodes
+	}
+}
+
+// WithWalkWeightFacet makes RandomWalk pick each outgoing edge with
+// probability proportional to the triple facet key on it, e.g. "weight"
+// set with SetTripleFacet to EncodeFloat(2.5) or "2.5". Edges without the
+// facet weigh 1 and edges weighing 0 or less are never taken.
+func WithWalkWeightFacet(key string) Option {
+	return func(o *Options) {
+		o.WalkWeightFacet = key
+	}
+}
+
+// WithMaxComponentSize sets the largest subject, predicate or object, in
+// bytes, that Put accepts. 0 removes the limit.
+func WithMaxComponentSize(n int) Option {
+	return func(o *Options) {
+		o.MaxComponentSize = n
+	}
+}
+
+// WithValueCompression compresses stored triple values longer than
+// threshold bytes. Compressed and uncompressed values can be mixed, so the
+// option can be turned on or off for an existing database.
+func WithValueCompression(threshold int) Option {
+	return func(o *Options) {
+		o.ValueCompressionThreshold = threshold
+	}
+}
+
+// WithReadOnly opens the database in read-only mode, e.g. for reporting
+// jobs or analytics replicas. Reads work normally; all writes return
+// ErrReadOnly. With Open, the underlying LevelDB is also opened read-only
+// and must already exist.
+func WithReadOnly() Option {
+	return func(o *Options) {
+		o.ReadOnly = true
+	}
+}
+
+// WithLogHook reports Get, Search and vector search operations to fn once
+// they complete, e.g. for slow-query logging or metrics. Only operations
+// taking at least slowThreshold are reported; pass 0 to report all of them.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithLogHook(func(e levelgraph.LogEvent) {
+//	        log.Printf("slow %s: %v (%d results)", e.Operation, e.Duration, e.Results)
+//	    }, 100*time.Millisecond),
+//	)
+func WithLogHook(fn func(LogEvent), slowThreshold time.Duration) Option {
+	return func(o *Options) {
+		o.LogHook = fn
+		o.SlowThreshold = slowThreshold
+	}
+}
+
+// WithTextIndex enables an inverted text index over the objects of the given
+// predicates (or of every predicate when none are given), queried with
+// SearchText. Objects are lowercased and split into tokens on any character
+// that is not a letter or digit. Triples stored before the index was enabled
+// are not indexed.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithTextIndex([]byte("body"), []byte("title")),
+//	)
+func WithTextIndex(predicates ...[]byte) Option {
+	return func(o *Options) {
+		o.TextIndex = true
+		o.TextIndexPredicates = predicates
+	}
+}
+
+// WithMigrations registers migrations that Open runs, in order, on databases
+// written in an older FormatVersion. Each function is keyed by the version it
+// upgrades from, so migrations[2] turns a version 2 database into version 3.
+// Without a migration for every step, Open returns ErrVersionMismatch.
+// Version 1 databases need none; see FormatVersion.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithMigrations(map[int]levelgraph.MigrationFunc{
+//	        2: migrateV2ToV3,
+//	    }),
+//	)
+func WithMigrations(migrations map[int]MigrationFunc) Option {
+	return func(o *Options) {
+		o.Migrations = migrations
+	}
+}
+
+// WithInversePredicates keeps inverse relationships in sync: Put of
+// "alice parentOf bob" with {"parentOf": "childOf"} also stores
+// "bob childOf alice", and Del removes both. Each pair applies in both
+// directions, and a predicate may be its own inverse, as in
+// {"marriedTo": "marriedTo"}.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithInversePredicates(map[string]string{
+//	        "parentOf":  "childOf",
+//	        "marriedTo": "marriedTo",
+//	    }),
+//	)
+func WithInversePredicates(inverses map[string]string) Option {
+	return func(o *Options) {
+		if o.InversePredicates == nil {
+			o.InversePredicates = make(map[string]string)
+		}
+		for predicate, inverse := range inverses {
+			o.InversePredicates[predicate] = inverse
+			o.InversePredicates[inverse] = predicate
+		}
+	}
+}
+
+// WithVersioning treats the objects of a (subject, predicate) pair as
+// versions of one fact, with "latest wins" semantics. Each Put stamps its
+// triples with the next version of their pair, including a Put of a triple
+// that is already stored, which makes it the latest again. The versions and
+// the last version issued for each pair are kept in an internal keyspace,
+// separate from facets. Get still returns every
+// version; GetLatest returns only the latest of each pair. Del removes a
+// triple's version, and the pair's next version is never reused.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db", levelgraph.WithVersioning())
+func WithVersioning() Option {
+	return func(o *Options) {
+		o.Versioning = true
+	}
+}
+
+// WithTimestamps records when each triple was first and last put. Every
+// Put stores the triple's updated time and, unless it already has one, its
+// created time, both as EncodeInt Unix nanosecond values. They are kept
+// apart from facets, so facet calls neither see nor change them. Del
+// removes a triple's timestamps.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db", levelgraph.WithTimestamps())
+//	created, updated, err := db.GetTimestamps(ctx, triple)
+func WithTimestamps() Option {
+	return func(o *Options) {
+		o.Timestamps = true
+	}
+}
+
+// WithPredicateAlias treats aliases as synonyms of canonical. Put, Del and
+// SoftDel rewrite a triple with an aliased predicate to use canonical, so
+// only the canonical form is stored, and Get, GetIterator and Search match
+// canonical triples when a pattern names an alias. Triple facets, pair
+// facets and SetTripleVector also use the canonical predicate. The original
+// predicate is not kept. InversePredicates apply to the canonical predicate,
+// including inverses registered under an alias.
+//
+// Example:
+//
+//	db, err := levelgraph.Open("/path/to/db",
+//	    levelgraph.WithPredicateAlias("knows", "is_friend_of", "friendOf"),
+//	)
+func WithPredicateAlias(canonical string, aliases ...string) Option {
+	return func(o *Options) {
+		if o.PredicateAliases == nil {
+			o.PredicateAliases = make(map[string]string)
+		}
+		for _, alias := range aliases {
+			if alias != canonical {
+				o.PredicateAliases[alias] = canonical
+			}
+		}
 	}
 }
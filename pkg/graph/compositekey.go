@@ -0,0 +1,99 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package graph
+
+// Composite keys pack several parts into one component value, for compound
+// identifiers such as an order line (order 123, line 4). The encoding is a
+// marker byte followed by each part, with every 0x00 byte in a part written
+// as 0x00 0xff and each part ended by 0x00 0x01. Parts may hold any
+// bytes, including ':' and the "::" index separator, and keys with the same
+// leading parts share a byte prefix. The marker differs from the one typed
+// values start with, so a composite key is never mistaken for a number.
+const compositeMarker = 0x01
+
+const (
+	compositeZero   = 0x00 // First byte of an escaped 0x00 or a part end
+	compositeEscape = 0xff // Follows compositeZero for a 0x00 in a part
+	compositeEnd    = 0x01 // Follows compositeZero at the end of a part
+)
+
+// MakeKey returns the composite key holding parts, in order. SplitKey
+// recovers them.
+//
+// Example:
+//
+//	line := graph.MakeKey([]byte("order"), []byte("123"), []byte("line"), []byte("4"))
+//	db.Put(ctx, graph.NewTriple(line, []byte("quantity"), graph.EncodeInt(2)))
+func MakeKey(parts ...[]byte) []byte {
+	size := 1
+	for _, part := range parts {
+		size += len(part) + 2
+	}
+	key := make([]byte, 0, size)
+	key = append(key, compositeMarker)
+	for _, part := range parts {
+		for _, b := range part {
+			key = append(key, b)
+			if b == compositeZero {
+				key = append(key, compositeEscape)
+			}
+		}
+		key = append(key, compositeZero, compositeEnd)
+	}
+	return key
+}
+
+// SplitKey returns the parts of a key made by MakeKey, or nil if key is not
+// a composite key. The parts are new slices, not views into key.
+func SplitKey(key []byte) [][]byte {
+	if len(key) == 0 || key[0] != compositeMarker {
+		return nil
+	}
+	parts := [][]byte{}
+	part := []byte{}
+	for i := 1; i < len(key); i++ {
+		if key[i] != compositeZero {
+			part = append(part, key[i])
+			continue
+		}
+		if i+1 == len(key) {
+			return nil
+		}
+		i++
+		switch key[i] {
+		case compositeEscape:
+			part = append(part, compositeZero)
+		case compositeEnd:
+			parts = append(parts, part)
+			part = []byte{}
+		default:
+			return nil
+		}
+	}
+	if len(part) > 0 {
+		// The last part is not terminated
+		return nil
+	}
+	return parts
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package graph
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompositeKey_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts [][]byte
+	}{
+		{"simple", [][]byte{[]byte("order"), []byte("123"), []byte("line"), []byte("4")}},
+		{"separators", [][]byte{[]byte("a::b"), []byte(":"), []byte(`\::`)}},
+		{"empty parts", [][]byte{{}, []byte("x"), {}}},
+		{"only empty", [][]byte{{}}},
+		{"binary", [][]byte{{0x00}, {0x00, 0xff}, {0x01, 0x00, 0x01}, {0xff}}},
+		{"empty then 0xff", [][]byte{{}, {0xff, 0x00}}},
+		{"typed", [][]byte{EncodeInt(7), EncodeFloat(-1.5)}},
+		{"none", [][]byte{}},
+	}
+	for _, tt := range tests {
+		key := MakeKey(tt.parts...)
+		got := SplitKey(key)
+		if got == nil {
+			t.Errorf("%s: SplitKey(MakeKey(%q)) = nil", tt.name, tt.parts)
+			continue
+		}
+		if len(got) != len(tt.parts) {
+			t.Errorf("%s: SplitKey(MakeKey(%q)) = %q", tt.name, tt.parts, got)
+			continue
+		}
+		for i := range got {
+			if !bytes.Equal(got[i], tt.parts[i]) {
+				t.Errorf("%s: part %d = %q, want %q", tt.name, i, got[i], tt.parts[i])
+			}
+		}
+		if IsTyped(key) {
+			t.Errorf("%s: IsTyped(MakeKey(...)) = true", tt.name)
+		}
+	}
+}
+
+func TestCompositeKey_Distinct(t *testing.T) {
+	// Splitting the same bytes differently gives different keys.
+	a := MakeKey([]byte("a:"), []byte("b"))
+	b := MakeKey([]byte("a"), []byte(":b"))
+	c := MakeKey([]byte("a::b"))
+	if bytes.Equal(a, b) || bytes.Equal(a, c) || bytes.Equal(b, c) {
+		t.Errorf("MakeKey collision: %q %q %q", a, b, c)
+	}
+
+	// Keys sharing leading parts share a prefix.
+	order := MakeKey([]byte("order"), []byte("123"))
+	line := MakeKey([]byte("order"), []byte("123"), []byte("line"), []byte("4"))
+	if !bytes.HasPrefix(line, order) {
+		t.Errorf("MakeKey(order, 123, ...) = %q does not start with %q", line, order)
+	}
+}
+
+func TestSplitKey_NotComposite(t *testing.T) {
+	for _, key := range [][]byte{
+		nil,
+		[]byte("order:123"),
+		EncodeInt(1),
+		{compositeMarker, 'a'},
+		{compositeMarker, 'a', 0x00},
+		{compositeMarker, 'a', 0x00, 0x02},
+	} {
+		if got := SplitKey(key); got != nil {
+			t.Errorf("SplitKey(%q) = %q, want nil", key, got)
+		}
+	}
+}
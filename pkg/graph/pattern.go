@@ -26,6 +26,11 @@ package graph
 
 import (
 	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"strconv"
 )
 
@@ -50,13 +55,19 @@ func Wildcard() PatternValue {
 }
 
 // Exact creates a PatternValue that matches exactly the given bytes.
+// Empty or nil data matches only an empty component, never acting as a
+// wildcard; use Wildcard for that.
 func Exact(data []byte) PatternValue {
+	if data == nil {
+		data = []byte{}
+	}
 	return PatternValue{kind: patternValueExact, data: data}
 }
 
 // ExactString creates a PatternValue that matches exactly the given string.
+// ExactString("") matches only an empty component.
 func ExactString(s string) PatternValue {
-	return PatternValue{kind: patternValueExact, data: []byte(s)}
+	return Exact([]byte(s))
 }
 
 // Binding creates a PatternValue that binds the matched value to a named variable.
@@ -110,6 +121,59 @@ func (pv PatternValue) ToInterface() any {
 	}
 }
 
+// MarshalJSON implements json.Marshaler for PatternValue.
+// Wildcards are written as "*", bindings as "?name" and exact values as
+// plain strings. Exact values that would be read back as a wildcard or
+// binding, or that are binary, use the {"base64": "..."} form of Triple JSON.
+func (pv PatternValue) MarshalJSON() ([]byte, error) {
+	switch pv.kind {
+	case patternValueBinding:
+		return json.Marshal("?" + pv.VariableName())
+	case patternValueExact:
+		s := string(pv.data)
+		if s == "" || s == "*" || (len(s) > 1 && s[0] == '?') {
+			encoded := base64.StdEncoding.EncodeToString(pv.data)
+			return json.Marshal(jsonBinary{Base64: &encoded})
+		}
+		return jsonBytes(pv.data).MarshalJSON()
+	default:
+		return json.Marshal("*")
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler for PatternValue.
+// A string starting with "?" becomes a binding, "*", "" or null becomes a
+// wildcard, and anything else (including {"base64": "..."}) an exact value.
+func (pv *PatternValue) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*pv = Wildcard()
+		return nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		var b jsonBytes
+		if err := b.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		*pv = Exact(b)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch {
+	case s == "" || s == "*":
+		*pv = Wildcard()
+	case len(s) > 1 && s[0] == '?':
+		*pv = Binding(s[1:])
+	default:
+		*pv = ExactString(s)
+	}
+	return nil
+}
+
 // Pattern represents a query pattern that can match triples.
 // It uses PatternValue for type-safe field matching.
 type Pattern struct {
@@ -120,6 +184,13 @@ type Pattern struct {
 	// Object defines the match criteria for the triple object
 	Object PatternValue
 
+	// SubjectIn, PredicateIn and ObjectIn restrict a field to a set of
+	// allowed values (an IN clause). A nil set is no restriction; an empty,
+	// non-nil set matches nothing. At most one of them may be set.
+	SubjectIn   [][]byte
+	PredicateIn [][]byte
+	ObjectIn    [][]byte
+
 	// Filter is an optional function to filter results
 	Filter func(*Triple) bool
 
@@ -129,10 +200,190 @@ type Pattern struct {
 	Offset int
 	// Reverse iterates in reverse lexicographical order
 	Reverse bool
+	// OrderByObject sorts Get results by object value instead of index key
+	// order. Sorting needs every match, so Get buffers all of them before
+	// applying Offset and Limit. GetIterator ignores it.
+	OrderByObject ObjectOrder
+}
+
+// ObjectOrder is the order in which Get returns triples; see
+// Pattern.OrderByObject.
+type ObjectOrder int
+
+const (
+	// OrderNone keeps index key order.
+	OrderNone ObjectOrder = iota
+	// OrderAsc sorts by object bytes, ascending.
+	OrderAsc
+	// OrderDesc sorts by object bytes, descending.
+	OrderDesc
+	// OrderNumericAsc sorts objects that parse as numbers with
+	// strconv.ParseFloat by value, ascending, followed by the other objects
+	// in ascending byte order.
+	OrderNumericAsc
+	// OrderNumericDesc is the exact reverse of OrderNumericAsc.
+	OrderNumericDesc
+)
+
+var objectOrderNames = []string{"none", "asc", "desc", "numericAsc", "numericDesc"}
+
+// String returns the order's name, as used in JSON.
+func (o ObjectOrder) String() string {
+	if o < 0 || int(o) >= len(objectOrderNames) {
+		return "ObjectOrder(" + strconv.Itoa(int(o)) + ")"
+	}
+	return objectOrderNames[o]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (o ObjectOrder) MarshalText() ([]byte, error) {
+	if o < 0 || int(o) >= len(objectOrderNames) {
+		return nil, fmt.Errorf("graph: unknown object order %d", int(o))
+	}
+	return []byte(objectOrderNames[o]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (o *ObjectOrder) UnmarshalText(text []byte) error {
+	i := slices.Index(objectOrderNames, string(text))
+	if i < 0 {
+		return fmt.Errorf("graph: unknown object order %q", text)
+	}
+	*o = ObjectOrder(i)
+	return nil
+}
+
+// Compare compares the objects of a and b in order o, returning a negative
+// number, zero or a positive number as a sorts before, with or after b.
+// OrderNone treats all objects as equal.
+func (o ObjectOrder) Compare(a, b *Triple) int {
+	switch o {
+	case OrderAsc:
+		return bytes.Compare(a.Object, b.Object)
+	case OrderDesc:
+		return bytes.Compare(b.Object, a.Object)
+	case OrderNumericAsc:
+		return compareNumeric(a.Object, b.Object)
+	case OrderNumericDesc:
+		return compareNumeric(b.Object, a.Object)
+	}
+	return 0
+}
+
+// compareNumeric orders numbers by value before non-numeric values, which
+// are ordered by bytes.
+func compareNumeric(a, b []byte) int {
+	x, errA := strconv.ParseFloat(string(a), 64)
+	y, errB := strconv.ParseFloat(string(b), 64)
+	switch {
+	case errA == nil && errB == nil:
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+		return bytes.Compare(a, b)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return bytes.Compare(a, b)
+}
+
+// patternJSON is used for JSON marshaling/unmarshaling of patterns.
+// Filter cannot be serialized and is ignored.
+type patternJSON struct {
+	Subject   PatternValue `json:"subject"`
+	Predicate PatternValue `json:"predicate"`
+	Object    PatternValue `json:"object"`
+	Limit     int          `json:"limit,omitempty"`
+	Offset    int          `json:"offset,omitempty"`
+	Reverse   bool         `json:"reverse,omitempty"`
+
+	OrderByObject ObjectOrder `json:"orderByObject,omitempty"`
+
+	// IN clauses are pointers so that an empty set, which matches nothing,
+	// survives a round trip while an absent one is omitted.
+	SubjectIn   *[]jsonBytes `json:"subjectIn,omitempty"`
+	PredicateIn *[]jsonBytes `json:"predicateIn,omitempty"`
+	ObjectIn    *[]jsonBytes `json:"objectIn,omitempty"`
+}
+
+// inToJSON converts an IN clause for patternJSON.
+func inToJSON(values [][]byte) *[]jsonBytes {
+	if values == nil {
+		return nil
+	}
+	out := make([]jsonBytes, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return &out
+}
+
+// inFromJSON converts an IN clause from patternJSON.
+func inFromJSON(values *[]jsonBytes) [][]byte {
+	if values == nil {
+		return nil
+	}
+	out := make([][]byte, len(*values))
+	for i, v := range *values {
+		out[i] = v
+	}
+	return out
+}
+
+// MarshalJSON implements json.Marshaler for Pattern.
+// The Filter function is not serialized.
+//
+// Example:
+//
+//	{"subject":"?person","predicate":"*","object":"tennis","limit":10}
+func (p *Pattern) MarshalJSON() ([]byte, error) {
+	return json.Marshal(patternJSON{
+		Subject:   p.Subject,
+		Predicate: p.Predicate,
+		Object:    p.Object,
+		Limit:     p.Limit,
+		Offset:    p.Offset,
+		Reverse:   p.Reverse,
+
+		OrderByObject: p.OrderByObject,
+
+		SubjectIn:   inToJSON(p.SubjectIn),
+		PredicateIn: inToJSON(p.PredicateIn),
+		ObjectIn:    inToJSON(p.ObjectIn),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Pattern.
+// Missing fields are wildcards.
+func (p *Pattern) UnmarshalJSON(data []byte) error {
+	var pj patternJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+
+	*p = Pattern{
+		Subject:   pj.Subject,
+		Predicate: pj.Predicate,
+		Object:    pj.Object,
+		Limit:     pj.Limit,
+		Offset:    pj.Offset,
+		Reverse:   pj.Reverse,
+
+		OrderByObject: pj.OrderByObject,
+
+		SubjectIn:   inFromJSON(pj.SubjectIn),
+		PredicateIn: inFromJSON(pj.PredicateIn),
+		ObjectIn:    inFromJSON(pj.ObjectIn),
+	}
+	return nil
 }
 
 // NewPattern creates a new pattern from interface values.
 // Values can be nil, []byte, string (converted to []byte), or *Variable.
+// nil and empty strings or byte slices are wildcards; pass Exact([]byte{})
+// or ExactString("") to match an empty component.
 func NewPattern(subject, predicate, object any) *Pattern {
 	return &Pattern{
 		Subject:   normalizePatternValue(subject),
@@ -227,6 +478,35 @@ func (p *Pattern) ConcreteFields() []string {
 	return fields
 }
 
+// InFields returns the names of fields that have an IN clause set.
+func (p *Pattern) InFields() []string {
+	var fields []string
+	if p.SubjectIn != nil {
+		fields = append(fields, "subject")
+	}
+	if p.PredicateIn != nil {
+		fields = append(fields, "predicate")
+	}
+	if p.ObjectIn != nil {
+		fields = append(fields, "object")
+	}
+	return fields
+}
+
+// InValues returns the IN clause for a field, or nil if it has none.
+func (p *Pattern) InValues(field string) [][]byte {
+	switch field {
+	case "subject":
+		return p.SubjectIn
+	case "predicate":
+		return p.PredicateIn
+	case "object":
+		return p.ObjectIn
+	default:
+		return nil
+	}
+}
+
 // VariableFields returns a map of field names to their Variable objects.
 func (p *Pattern) VariableFields() map[string]*Variable {
 	result := make(map[string]*Variable)
@@ -277,9 +557,64 @@ func (p *Pattern) Matches(triple *Triple) bool {
 			return false
 		}
 	}
+	for _, field := range p.InFields() {
+		if !slices.ContainsFunc(p.InValues(field), func(v []byte) bool {
+			return bytes.Equal(v, triple.Get(field))
+		}) {
+			return false
+		}
+	}
 	return true
 }
 
+// Clone returns a deep copy of the pattern. Exact values, bound variables
+// and IN sets are copied, so changing the clone's byte slices leaves p
+// unchanged; Filter is a function and is shared. Clone of nil is nil.
+func (p *Pattern) Clone() *Pattern {
+	if p == nil {
+		return nil
+	}
+	return &Pattern{
+		Subject:   p.Subject.clone(),
+		Predicate: p.Predicate.clone(),
+		Object:    p.Object.clone(),
+		Filter:    p.Filter,
+		Limit:     p.Limit,
+		Offset:    p.Offset,
+		Reverse:   p.Reverse,
+
+		OrderByObject: p.OrderByObject,
+
+		SubjectIn:   cloneValues(p.SubjectIn),
+		PredicateIn: cloneValues(p.PredicateIn),
+		ObjectIn:    cloneValues(p.ObjectIn),
+	}
+}
+
+// clone returns a copy of pv that shares no memory with it.
+func (pv PatternValue) clone() PatternValue {
+	if pv.data != nil {
+		pv.data = bytes.Clone(pv.data)
+	}
+	if pv.variable != nil {
+		v := *pv.variable
+		pv.variable = &v
+	}
+	return pv
+}
+
+// cloneValues deep-copies an IN set, keeping nil and empty sets distinct.
+func cloneValues(values [][]byte) [][]byte {
+	if values == nil {
+		return nil
+	}
+	clone := make([][]byte, len(values))
+	for i, v := range values {
+		clone[i] = bytes.Clone(v)
+	}
+	return clone
+}
+
 // UpdateWithSolution returns a new pattern with variables replaced by their bound values.
 func (p *Pattern) UpdateWithSolution(solution Solution) *Pattern {
 	newPattern := &Pattern{
@@ -290,6 +625,12 @@ func (p *Pattern) UpdateWithSolution(solution Solution) *Pattern {
 		Limit:     p.Limit,
 		Offset:    p.Offset,
 		Reverse:   p.Reverse,
+
+		OrderByObject: p.OrderByObject,
+
+		SubjectIn:   p.SubjectIn,
+		PredicateIn: p.PredicateIn,
+		ObjectIn:    p.ObjectIn,
 	}
 
 	// Replace variables with bound values
@@ -7,6 +7,7 @@ package graph
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
 )
 
@@ -72,6 +73,33 @@ func TestPatternValue_ExactString(t *testing.T) {
 	}
 }
 
+func TestPatternValue_ExactEmpty(t *testing.T) {
+	for name, pv := range map[string]PatternValue{
+		"Exact(nil)":      Exact(nil),
+		"Exact([]byte{})": Exact([]byte{}),
+		`ExactString("")`: ExactString(""),
+	} {
+		if !pv.IsExact() || pv.Data() == nil || len(pv.Data()) != 0 {
+			t.Errorf("%s = %+v, want exact empty value", name, pv)
+		}
+		p := &Pattern{Subject: ExactString("s"), Object: pv}
+		if got := p.ConcreteFields(); len(got) != 2 {
+			t.Errorf("%s ConcreteFields() = %v, want subject and object", name, got)
+		}
+		if p.Matches(NewTripleFromStrings("s", "p", "o")) {
+			t.Errorf("%s matched a non-empty object", name)
+		}
+		if !p.Matches(NewTriple([]byte("s"), []byte("p"), []byte{})) {
+			t.Errorf("%s did not match an empty object", name)
+		}
+	}
+
+	// Raw empty values remain wildcards.
+	if p := NewPattern("s", "", []byte{}); !p.Predicate.IsWildcard() || !p.Object.IsWildcard() {
+		t.Errorf("NewPattern with empty values = %+v, want wildcards", p)
+	}
+}
+
 func TestPatternValue_Binding(t *testing.T) {
 	name := "x"
 	pv := Binding(name)
@@ -232,6 +260,50 @@ func TestPattern_ConcreteFields(t *testing.T) {
 	}
 }
 
+func TestPattern_InClause(t *testing.T) {
+	p := &Pattern{
+		Predicate: ExactString("livesIn"),
+		ObjectIn:  [][]byte{[]byte("NYC"), []byte("LA")},
+	}
+	if fields := p.InFields(); len(fields) != 1 || fields[0] != "object" {
+		t.Errorf("InFields() = %v, want [object]", fields)
+	}
+	if !p.Matches(NewTripleFromStrings("alice", "livesIn", "LA")) {
+		t.Error("expected LA to match")
+	}
+	if p.Matches(NewTripleFromStrings("alice", "livesIn", "Paris")) {
+		t.Error("expected Paris not to match")
+	}
+	if got := p.UpdateWithSolution(Solution{}); len(got.ObjectIn) != 2 {
+		t.Errorf("UpdateWithSolution() dropped the IN clause: %v", got.ObjectIn)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	var decoded Pattern
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+	if len(decoded.ObjectIn) != 2 || string(decoded.ObjectIn[1]) != "LA" || decoded.SubjectIn != nil {
+		t.Errorf("round trip of %s = %+v", data, decoded)
+	}
+
+	// An empty set survives a round trip and still matches nothing.
+	data, err = json.Marshal(&Pattern{SubjectIn: [][]byte{}})
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	decoded = Pattern{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+	if decoded.SubjectIn == nil || decoded.Matches(NewTripleFromStrings("a", "b", "c")) {
+		t.Errorf("empty IN clause lost in round trip of %s", data)
+	}
+}
+
 func TestPattern_VariableFields(t *testing.T) {
 	p := NewPattern(V("x"), []byte("knows"), V("y"))
 	vars := p.VariableFields()
@@ -551,3 +623,140 @@ func TestPattern_BindTripleFast_SameAsBindTriple(t *testing.T) {
 		}
 	}
 }
+
+func TestPattern_UnmarshalJSON(t *testing.T) {
+	var p Pattern
+	data := `{"subject":"?person","predicate":"*","object":"tennis","limit":5}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+
+	if p.Subject.VariableName() != "person" {
+		t.Errorf("Subject = %v, want binding ?person", p.Subject.ToInterface())
+	}
+	if !p.Predicate.IsWildcard() {
+		t.Errorf("Predicate = %v, want wildcard", p.Predicate.ToInterface())
+	}
+	if !bytes.Equal(p.Object.Data(), []byte("tennis")) {
+		t.Errorf("Object = %v, want exact tennis", p.Object.ToInterface())
+	}
+	if p.Limit != 5 {
+		t.Errorf("Limit = %d, want 5", p.Limit)
+	}
+
+	// Empty strings, null and missing fields are wildcards
+	if err := json.Unmarshal([]byte(`{"subject":"","predicate":null}`), &p); err != nil {
+		t.Fatalf("UnmarshalJSON failed: %v", err)
+	}
+	if !p.Subject.IsWildcard() || !p.Predicate.IsWildcard() || !p.Object.IsWildcard() {
+		t.Error("expected empty, null and missing fields to be wildcards")
+	}
+	if p.Limit != 0 {
+		t.Errorf("Limit = %d, want 0 after decoding a new pattern", p.Limit)
+	}
+
+	if err := json.Unmarshal([]byte(`{"subject":42}`), &p); err == nil {
+		t.Error("expected error for non-string field")
+	}
+}
+
+func TestPattern_JSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern *Pattern
+	}{
+		{"mixed", &Pattern{Subject: Binding("x"), Predicate: Wildcard(), Object: ExactString("bob"), Offset: 2, Reverse: true}},
+		{"ambiguous exact values", &Pattern{Subject: ExactString("*"), Predicate: ExactString("?notvar"), Object: Exact([]byte{})}},
+		{"binary", &Pattern{Subject: Exact([]byte{0x00, 0xff}), Predicate: ExactString("p"), Object: Binding("o")}},
+		{"ordered", &Pattern{Predicate: ExactString("age"), OrderByObject: OrderNumericDesc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.pattern)
+			if err != nil {
+				t.Fatalf("MarshalJSON failed: %v", err)
+			}
+
+			var restored Pattern
+			if err := json.Unmarshal(data, &restored); err != nil {
+				t.Fatalf("UnmarshalJSON(%s) failed: %v", data, err)
+			}
+
+			wants := []PatternValue{tt.pattern.Subject, tt.pattern.Predicate, tt.pattern.Object}
+			gots := []PatternValue{restored.Subject, restored.Predicate, restored.Object}
+			for i, field := range []string{"subject", "predicate", "object"} {
+				want, got := wants[i], gots[i]
+				if want.kind != got.kind || !bytes.Equal(want.Data(), got.Data()) || want.VariableName() != got.VariableName() {
+					t.Errorf("%s: %s = %v, want %v", data, field, got.ToInterface(), want.ToInterface())
+				}
+			}
+			if restored.Offset != tt.pattern.Offset || restored.Reverse != tt.pattern.Reverse || restored.OrderByObject != tt.pattern.OrderByObject {
+				t.Errorf("options not preserved: got %+v", restored)
+			}
+		})
+	}
+}
+
+func TestObjectOrder_JSON(t *testing.T) {
+	data, err := json.Marshal(&Pattern{OrderByObject: OrderNumericAsc})
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"orderByObject":"numericAsc"`)) {
+		t.Errorf("MarshalJSON() = %s, want orderByObject numericAsc", data)
+	}
+	if data, _ := json.Marshal(&Pattern{}); bytes.Contains(data, []byte("orderByObject")) {
+		t.Errorf("MarshalJSON() = %s, want no orderByObject for OrderNone", data)
+	}
+
+	var p Pattern
+	if err := json.Unmarshal([]byte(`{"orderByObject":"sideways"}`), &p); err == nil {
+		t.Error("UnmarshalJSON() accepted an unknown order")
+	}
+}
+
+func TestPattern_Clone(t *testing.T) {
+	subject := []byte("alice")
+	p := &Pattern{
+		Subject:   Exact(subject),
+		Predicate: Binding("p"),
+		ObjectIn:  [][]byte{[]byte("bob"), []byte("carol")},
+		Filter:    func(*Triple) bool { return true },
+		Limit:     5,
+		Reverse:   true,
+
+		OrderByObject: OrderDesc,
+	}
+
+	clone := p.Clone()
+	clone.Subject.Data()[0] = 'X'
+	clone.ObjectIn[0][0] = 'X'
+	clone.ObjectIn = append(clone.ObjectIn, []byte("dave"))
+	clone.GetVariable("predicate").Name = "q"
+
+	if !bytes.Equal(p.Subject.Data(), []byte("alice")) {
+		t.Errorf("original Subject = %q after mutating clone, want alice", p.Subject.Data())
+	}
+	if len(p.ObjectIn) != 2 || !bytes.Equal(p.ObjectIn[0], []byte("bob")) {
+		t.Errorf("original ObjectIn = %q after mutating clone, want [bob carol]", p.ObjectIn)
+	}
+	if p.Predicate.VariableName() != "p" {
+		t.Errorf("original Predicate variable = %q after mutating clone, want p", p.Predicate.VariableName())
+	}
+	if clone.Filter == nil || clone.Limit != 5 || !clone.Reverse || clone.OrderByObject != OrderDesc {
+		t.Errorf("Clone() lost Filter, Limit, Reverse or OrderByObject: %+v", clone)
+	}
+	if !clone.Object.IsWildcard() {
+		t.Error("Clone() Object should stay a wildcard")
+	}
+
+	// Empty IN sets match nothing and must stay distinct from nil.
+	empty := (&Pattern{SubjectIn: [][]byte{}}).Clone()
+	if empty.SubjectIn == nil || empty.PredicateIn != nil {
+		t.Errorf("Clone() IN sets = %v, %v, want empty and nil", empty.SubjectIn, empty.PredicateIn)
+	}
+	if (*Pattern)(nil).Clone() != nil {
+		t.Error("Clone() of nil pattern should be nil")
+	}
+}
@@ -28,10 +28,15 @@ package graph
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"unicode/utf8"
 )
 
 // Triple represents a subject-predicate-object triple in the graph database.
@@ -69,6 +74,39 @@ func (t *Triple) Clone() *Triple {
 	}
 }
 
+// Reverse returns a new triple with the subject and object swapped.
+// The parts are shared with t, not copied.
+func (t *Triple) Reverse() *Triple {
+	return &Triple{
+		Subject:   t.Object,
+		Predicate: t.Predicate,
+		Object:    t.Subject,
+	}
+}
+
+// ErrInvalidTriple is returned by Validate for a triple without a subject,
+// predicate or object.
+var ErrInvalidTriple = errors.New("graph: invalid triple")
+
+// Validate checks that t has a subject, predicate and object. The error
+// names the first missing part, e.g. "graph: invalid triple: predicate is
+// nil", and wraps ErrInvalidTriple. Empty components are valid and can be
+// queried with Exact([]byte{}); only nil components are rejected. Validate
+// may be called on a nil triple.
+func (t *Triple) Validate() error {
+	switch {
+	case t == nil:
+		return fmt.Errorf("%w: triple is nil", ErrInvalidTriple)
+	case t.Subject == nil:
+		return fmt.Errorf("%w: subject is nil", ErrInvalidTriple)
+	case t.Predicate == nil:
+		return fmt.Errorf("%w: predicate is nil", ErrInvalidTriple)
+	case t.Object == nil:
+		return fmt.Errorf("%w: object is nil", ErrInvalidTriple)
+	}
+	return nil
+}
+
 // Equal returns true if two triples have identical subject, predicate, and object.
 func (t *Triple) Equal(other *Triple) bool {
 	if other == nil {
@@ -79,48 +117,130 @@ func (t *Triple) Equal(other *Triple) bool {
 		bytes.Equal(t.Object, other.Object)
 }
 
+// Hash returns a canonical SHA-256 of the triple, the same for equal triples
+// however they were built. It hashes the components as they appear in an SPO
+// index key, each ':' and '\' preceded by a backslash, joined with "::",
+// so component boundaries are unambiguous even for binary values.
+func (t *Triple) Hash() [32]byte {
+	h := sha256.New()
+	writeEscaped(h, t.Subject)
+	h.Write(hashSeparator)
+	writeEscaped(h, t.Predicate)
+	h.Write(hashSeparator)
+	writeEscaped(h, t.Object)
+
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// HashHex returns Hash as a lowercase hex string.
+func (t *Triple) HashHex() string {
+	sum := t.Hash()
+	return hex.EncodeToString(sum[:])
+}
+
+// hashSeparator joins components in Hash, matching index.KeySeparator.
+var hashSeparator = []byte("::")
+
+// writeEscaped writes value to w escaped like index.Escape, which this
+// package cannot import.
+func writeEscaped(w io.Writer, value []byte) {
+	start := 0
+	for i, b := range value {
+		if b == '\\' || b == ':' {
+			w.Write(value[start:i])
+			w.Write([]byte{'\\'})
+			start = i
+		}
+	}
+	w.Write(value[start:])
+}
+
 // String returns a human-readable representation of the triple.
 func (t *Triple) String() string {
 	return string(t.Subject) + " " + string(t.Predicate) + " " + string(t.Object)
 }
 
-// tripleJSON is used for JSON marshaling/unmarshaling with base64 support
+// tripleJSON is used for JSON marshaling/unmarshaling of triples.
 type tripleJSON struct {
-	Subject   string `json:"subject"`
-	Predicate string `json:"predicate"`
-	Object    string `json:"object"`
+	Subject   jsonBytes `json:"subject"`
+	Predicate jsonBytes `json:"predicate"`
+	Object    jsonBytes `json:"object"`
+}
+
+// jsonBytes is a triple component in its canonical JSON form: a plain string
+// for text, or {"base64": "..."} for binary values (invalid UTF-8 or
+// containing NUL bytes), so that every byte value round-trips.
+type jsonBytes []byte
+
+// jsonBinary is the explicitly marked encoding used for binary components.
+type jsonBinary struct {
+	Base64 *string `json:"base64"`
+}
+
+// MarshalJSON implements json.Marshaler for jsonBytes.
+func (b jsonBytes) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) && bytes.IndexByte(b, 0) < 0 {
+		return json.Marshal(string(b))
+	}
+	encoded := base64.StdEncoding.EncodeToString(b)
+	return json.Marshal(jsonBinary{Base64: &encoded})
+}
+
+// UnmarshalJSON implements json.Unmarshaler for jsonBytes.
+func (b *jsonBytes) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var bin jsonBinary
+		if err := json.Unmarshal(data, &bin); err != nil {
+			return err
+		}
+		if bin.Base64 == nil {
+			return errors.New("graph: binary triple component requires a base64 field")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(*bin.Base64)
+		if err != nil {
+			return err
+		}
+		*b = decoded
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	*b = jsonBytes(str)
+	return nil
 }
 
 // MarshalJSON implements json.Marshaler for Triple.
-// Uses base64 encoding for binary data to preserve all byte values.
+// Text components are written as plain strings; binary components are
+// written as {"base64": "..."} to preserve all byte values.
+//
+// Example:
+//
+//	{"subject":"alice","predicate":"knows","object":{"base64":"AP8="}}
 func (t *Triple) MarshalJSON() ([]byte, error) {
 	return json.Marshal(tripleJSON{
-		Subject:   base64.StdEncoding.EncodeToString(t.Subject),
-		Predicate: base64.StdEncoding.EncodeToString(t.Predicate),
-		Object:    base64.StdEncoding.EncodeToString(t.Object),
+		Subject:   t.Subject,
+		Predicate: t.Predicate,
+		Object:    t.Object,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler for Triple.
+// It accepts the form written by MarshalJSON.
 func (t *Triple) UnmarshalJSON(data []byte) error {
 	var tj tripleJSON
 	if err := json.Unmarshal(data, &tj); err != nil {
 		return err
 	}
 
-	var err error
-	t.Subject, err = base64.StdEncoding.DecodeString(tj.Subject)
-	if err != nil {
-		return err
-	}
-	t.Predicate, err = base64.StdEncoding.DecodeString(tj.Predicate)
-	if err != nil {
-		return err
-	}
-	t.Object, err = base64.StdEncoding.DecodeString(tj.Object)
-	if err != nil {
-		return err
-	}
+	t.Subject = tj.Subject
+	t.Predicate = tj.Predicate
+	t.Object = tj.Object
 	return nil
 }
 
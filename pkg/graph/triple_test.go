@@ -8,6 +8,8 @@ package graph
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -95,6 +97,86 @@ func TestTriple_Equal(t *testing.T) {
 	}
 }
 
+func TestTriple_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		triple *Triple
+		field  string
+	}{
+		{"nil triple", nil, "triple is nil"},
+		{"nil subject", &Triple{Predicate: []byte("p"), Object: []byte("o")}, "subject is nil"},
+		{"nil predicate", &Triple{Subject: []byte("s"), Object: []byte("o")}, "predicate is nil"},
+		{"nil object", &Triple{Subject: []byte("s"), Predicate: []byte("p")}, "object is nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.triple.Validate()
+			if !errors.Is(err, ErrInvalidTriple) {
+				t.Fatalf("Validate() error = %v, want ErrInvalidTriple", err)
+			}
+			if !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("Validate() error = %q, want it to mention %q", err, tt.field)
+			}
+		})
+	}
+
+	if err := NewTripleFromStrings("s", "p", "o").Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := NewTriple([]byte("s"), []byte("p"), []byte{}).Validate(); err != nil {
+		t.Errorf("Validate() with empty object error = %v, want nil", err)
+	}
+}
+
+func TestTriple_Reverse(t *testing.T) {
+	triple := NewTripleFromStrings("alice", "knows", "bob")
+	reversed := triple.Reverse()
+
+	if !reversed.Equal(NewTripleFromStrings("bob", "knows", "alice")) {
+		t.Errorf("Reverse() = %v, want bob knows alice", reversed)
+	}
+	if !triple.Equal(NewTripleFromStrings("alice", "knows", "bob")) {
+		t.Errorf("Reverse() modified the original: %v", triple)
+	}
+}
+
+func TestTriple_Hash(t *testing.T) {
+	a := NewTripleFromStrings("alice", "knows", "bob")
+	b := &Triple{Subject: []byte("alice"), Predicate: []byte("knows"), Object: []byte("bob")}
+	if a.Hash() != b.Hash() || a.HashHex() != b.HashHex() {
+		t.Error("equal triples hash differently")
+	}
+	if len(a.HashHex()) != 64 {
+		t.Errorf("HashHex() = %q, want 64 hex digits", a.HashHex())
+	}
+
+	// Components that would collide if simply concatenated or joined.
+	distinct := []*Triple{
+		a,
+		NewTripleFromStrings("bob", "knows", "alice"),
+		NewTripleFromStrings("alice:", ":knows", "bob"),
+		NewTripleFromStrings("alice::knows", "", "bob"),
+		NewTripleFromStrings("alice", "::knows::", "bob"),
+		NewTripleFromStrings("alice\\", "knows", "bob"),
+		NewTripleFromStrings("alice", "\\knows", "bob"),
+		NewTriple([]byte{0x00, 0xFF}, []byte("knows"), []byte{0xFF, 0x00}),
+		NewTriple([]byte{0x00}, []byte{0xFF, 'k'}, []byte{0xFF, 0x00}),
+	}
+	seen := make(map[[32]byte]string)
+	for _, tr := range distinct {
+		h := tr.Hash()
+		if prev, ok := seen[h]; ok {
+			t.Errorf("%q and %q hash the same", prev, tr.String())
+		}
+		seen[h] = tr.String()
+	}
+
+	binary := NewTriple([]byte{0x00, 0xFF}, []byte("knows"), []byte{0xFF, 0x00})
+	if binary.Hash() != binary.Clone().Hash() {
+		t.Error("cloned binary triple hashes differently")
+	}
+}
+
 func TestTriple_String(t *testing.T) {
 	triple := NewTripleFromStrings("alice", "knows", "bob")
 	expected := "alice knows bob"
@@ -118,7 +200,7 @@ func TestTriple_MarshalJSON(t *testing.T) {
 		t.Fatalf("Result should be valid JSON: %v", err)
 	}
 
-	// Should have subject, predicate, object fields (base64 encoded)
+	// Should have subject, predicate, object fields
 	if _, ok := result["subject"]; !ok {
 		t.Error("JSON should have subject field")
 	}
@@ -181,19 +263,73 @@ func TestTriple_UnmarshalJSON_Invalid(t *testing.T) {
 	}
 
 	// Invalid base64 in subject
-	if err := triple.UnmarshalJSON([]byte(`{"subject":"!!!","predicate":"","object":""}`)); err == nil {
+	if err := triple.UnmarshalJSON([]byte(`{"subject":{"base64":"!!!"},"predicate":"","object":""}`)); err == nil {
 		t.Error("Should fail on invalid base64 in subject")
 	}
 
 	// Invalid base64 in predicate
-	if err := triple.UnmarshalJSON([]byte(`{"subject":"YWxpY2U=","predicate":"!!!","object":""}`)); err == nil {
+	if err := triple.UnmarshalJSON([]byte(`{"subject":"alice","predicate":{"base64":"!!!"},"object":""}`)); err == nil {
 		t.Error("Should fail on invalid base64 in predicate")
 	}
 
 	// Invalid base64 in object
-	if err := triple.UnmarshalJSON([]byte(`{"subject":"YWxpY2U=","predicate":"a25vd3M=","object":"!!!"}`)); err == nil {
+	if err := triple.UnmarshalJSON([]byte(`{"subject":"alice","predicate":"knows","object":{"base64":"!!!"}}`)); err == nil {
 		t.Error("Should fail on invalid base64 in object")
 	}
+
+	// Binary marker without a base64 field
+	if err := triple.UnmarshalJSON([]byte(`{"subject":{"hex":"00"},"predicate":"","object":""}`)); err == nil {
+		t.Error("Should fail on binary component without base64 field")
+	}
+
+	// Component of the wrong JSON type
+	if err := triple.UnmarshalJSON([]byte(`{"subject":42,"predicate":"","object":""}`)); err == nil {
+		t.Error("Should fail on non-string component")
+	}
+}
+
+func TestTriple_JSON_CanonicalForm(t *testing.T) {
+	tests := []struct {
+		name   string
+		triple *Triple
+		want   string
+	}{
+		{
+			name:   "text",
+			triple: NewTripleFromStrings("alice", "knows", "bob ☃"),
+			want:   `{"subject":"alice","predicate":"knows","object":"bob ☃"}`,
+		},
+		{
+			name:   "null bytes",
+			triple: &Triple{Subject: []byte("alice"), Predicate: []byte("hash"), Object: []byte{0x00, 'a', 0x00}},
+			want:   `{"subject":"alice","predicate":"hash","object":{"base64":"AGEA"}}`,
+		},
+		{
+			name:   "invalid utf8",
+			triple: &Triple{Subject: []byte{0xff, 0xfe}, Predicate: []byte("p"), Object: []byte("o")},
+			want:   `{"subject":{"base64":"//4="},"predicate":"p","object":"o"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.triple)
+			if err != nil {
+				t.Fatalf("MarshalJSON failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("MarshalJSON = %s, want %s", data, tt.want)
+			}
+
+			var restored Triple
+			if err := json.Unmarshal(data, &restored); err != nil {
+				t.Fatalf("UnmarshalJSON failed: %v", err)
+			}
+			if !tt.triple.Equal(&restored) {
+				t.Errorf("round-trip = %v, want %v", restored, tt.triple)
+			}
+		})
+	}
 }
 
 func TestTriple_MarshalBinary(t *testing.T) {
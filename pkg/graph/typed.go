@@ -0,0 +1,150 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package graph
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strconv"
+)
+
+// Typed values are component values that encode a number so that byte order
+// matches numeric order, letting range scans and index order follow the
+// numbers. The encoding is a marker byte, a type byte and the value as 16
+// lowercase hex digits. Hex keeps the bytes clear of ':' and '\', which key
+// escaping would otherwise reorder.
+//
+// Values of different types do not interleave: all floats sort after all
+// integers.
+const typedMarker = 0x00
+
+const (
+	typeInt   = 'i'
+	typeFloat = 'f'
+)
+
+// typedLen is the length of an encoded typed value.
+const typedLen = 2 + 16
+
+// EncodeInt returns the order-preserving encoding of v.
+func EncodeInt(v int64) []byte {
+	// Flipping the sign bit makes negative numbers sort first
+	return encodeTyped(typeInt, uint64(v)^(1<<63))
+}
+
+// EncodeFloat returns the order-preserving encoding of v.
+func EncodeFloat(v float64) []byte {
+	bits := math.Float64bits(v)
+	if bits&(1<<63) != 0 {
+		// Negative: invert everything so larger magnitudes sort first
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+	return encodeTyped(typeFloat, bits)
+}
+
+func encodeTyped(typ byte, bits uint64) []byte {
+	var raw [8]byte
+	binary.BigEndian.PutUint64(raw[:], bits)
+	buf := make([]byte, typedLen)
+	buf[0] = typedMarker
+	buf[1] = typ
+	hex.Encode(buf[2:], raw[:])
+	return buf
+}
+
+// decodeTyped returns the type byte and bits of a typed value, or ok=false
+// if value is not one.
+func decodeTyped(value []byte) (typ byte, bits uint64, ok bool) {
+	if len(value) != typedLen || value[0] != typedMarker {
+		return 0, 0, false
+	}
+	var raw [8]byte
+	if _, err := hex.Decode(raw[:], value[2:]); err != nil {
+		return 0, 0, false
+	}
+	// Only the lowercase form is canonical
+	if !bytes.Equal(value[2:], []byte(hex.EncodeToString(raw[:]))) {
+		return 0, 0, false
+	}
+	return value[1], binary.BigEndian.Uint64(raw[:]), true
+}
+
+// DecodeInt returns the integer encoded by EncodeInt, or ok=false if value
+// is not an encoded integer.
+func DecodeInt(value []byte) (int64, bool) {
+	typ, bits, ok := decodeTyped(value)
+	if !ok || typ != typeInt {
+		return 0, false
+	}
+	return int64(bits ^ (1 << 63)), true
+}
+
+// DecodeFloat returns the float encoded by EncodeFloat, or ok=false if value
+// is not an encoded float.
+func DecodeFloat(value []byte) (float64, bool) {
+	typ, bits, ok := decodeTyped(value)
+	if !ok || typ != typeFloat {
+		return 0, false
+	}
+	if bits&(1<<63) != 0 {
+		bits &^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits), true
+}
+
+// IsTyped reports whether value is a typed value.
+func IsTyped(value []byte) bool {
+	_, _, ok := decodeTyped(value)
+	return ok
+}
+
+// DecodeValue returns the human-readable form of value: the decimal text of
+// a typed number, or value itself otherwise.
+func DecodeValue(value []byte) []byte {
+	if i, ok := DecodeInt(value); ok {
+		return strconv.AppendInt(nil, i, 10)
+	}
+	if f, ok := DecodeFloat(value); ok {
+		return strconv.AppendFloat(nil, f, 'g', -1, 64)
+	}
+	return value
+}
+
+// Decoded returns a new triple with t's typed components replaced by their
+// human-readable form (see DecodeValue); other components share t's bytes.
+// t itself keeps the raw bytes.
+func (t *Triple) Decoded() *Triple {
+	return &Triple{
+		Subject:   DecodeValue(t.Subject),
+		Predicate: DecodeValue(t.Predicate),
+		Object:    DecodeValue(t.Object),
+	}
+}
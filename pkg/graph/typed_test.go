@@ -0,0 +1,103 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package graph
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestTyped_IntOrdering(t *testing.T) {
+	values := []int64{math.MinInt64, -1000, -5, -1, 0, 1, 9, 10, 30, 100, 58 /* ':' */, 92 /* '\' */, math.MaxInt64}
+	slices.Sort(values)
+
+	for i, v := range values {
+		enc := EncodeInt(v)
+		if got, ok := DecodeInt(enc); !ok || got != v {
+			t.Errorf("DecodeInt(EncodeInt(%d)) = %d, %v", v, got, ok)
+		}
+		if bytes.ContainsAny(enc, `:\`) {
+			t.Errorf("EncodeInt(%d) = %q contains a byte that needs escaping", v, enc)
+		}
+		if i > 0 && bytes.Compare(EncodeInt(values[i-1]), enc) >= 0 {
+			t.Errorf("EncodeInt(%d) does not sort before EncodeInt(%d)", values[i-1], v)
+		}
+	}
+}
+
+func TestTyped_FloatOrdering(t *testing.T) {
+	values := []float64{math.Inf(-1), -1e10, -2.5, -0.1, 0, 0.1, 1, 2.5, 30, 100, 1e10, math.Inf(1)}
+
+	for i, v := range values {
+		enc := EncodeFloat(v)
+		if got, ok := DecodeFloat(enc); !ok || got != v {
+			t.Errorf("DecodeFloat(EncodeFloat(%g)) = %g, %v", v, got, ok)
+		}
+		if i > 0 && bytes.Compare(EncodeFloat(values[i-1]), enc) >= 0 {
+			t.Errorf("EncodeFloat(%g) does not sort before EncodeFloat(%g)", values[i-1], v)
+		}
+	}
+}
+
+func TestTyped_DecodeValue(t *testing.T) {
+	tests := []struct {
+		value []byte
+		want  string
+	}{
+		{EncodeInt(30), "30"},
+		{EncodeInt(-7), "-7"},
+		{EncodeFloat(2.5), "2.5"},
+		{[]byte("alice"), "alice"},
+		{[]byte("30"), "30"},
+	}
+	for _, tt := range tests {
+		if got := DecodeValue(tt.value); string(got) != tt.want {
+			t.Errorf("DecodeValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	// Wrong type and non-canonical hex are not typed values
+	if _, ok := DecodeFloat(EncodeInt(1)); ok {
+		t.Error("DecodeFloat accepted an encoded int")
+	}
+	upper := bytes.ToUpper(EncodeInt(-1))
+	upper[1] = 'i'
+	if IsTyped(upper) {
+		t.Errorf("IsTyped(%q) = true, want false for uppercase hex", upper)
+	}
+}
+
+func TestTriple_Decoded(t *testing.T) {
+	raw := NewTriple([]byte("alice"), []byte("age"), EncodeInt(30))
+	decoded := raw.Decoded()
+
+	if string(decoded.Object) != "30" || string(decoded.Subject) != "alice" {
+		t.Errorf("Decoded() = %s, want alice age 30", decoded)
+	}
+	if !bytes.Equal(raw.Object, EncodeInt(30)) {
+		t.Errorf("Decoded() modified the raw triple: %q", raw.Object)
+	}
+}
@@ -24,7 +24,11 @@
 
 package graph
 
-import "bytes"
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
 
 // Variable represents a named query placeholder used in pattern matching.
 // When used in a Pattern, a Variable will match any value and capture it
@@ -160,6 +164,32 @@ func (s Solution) Equal(other Solution) bool {
 	return true
 }
 
+// SortedKeys returns the variable names bound in the solution in sorted order,
+// for deterministic iteration.
+func (s Solution) SortedKeys() []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String returns the bindings as "name=value" pairs in sorted variable order,
+// e.g. "x=alice, y=bob".
+func (s Solution) String() string {
+	var sb strings.Builder
+	for i, k := range s.SortedKeys() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.Write(s[k])
+	}
+	return sb.String()
+}
+
 // IsVariable checks if the given value is a *Variable or a PatternValue of kind binding.
 func IsVariable(v any) bool {
 	if _, ok := v.(*Variable); ok {
@@ -313,3 +313,36 @@ func TestSolution_ShallowClone(t *testing.T) {
 		t.Error("adding to shallow clone shouldn't affect original map")
 	}
 }
+
+func TestSolution_String(t *testing.T) {
+	s := Solution{
+		"y":     []byte("bob"),
+		"x":     []byte("alice"),
+		"z":     []byte("carol"),
+		"a":     []byte("dave"),
+		"knows": []byte("eve"),
+	}
+
+	keys := s.SortedKeys()
+	wantKeys := []string{"a", "knows", "x", "y", "z"}
+	if len(keys) != len(wantKeys) {
+		t.Fatalf("SortedKeys() = %v, want %v", keys, wantKeys)
+	}
+	for i := range keys {
+		if keys[i] != wantKeys[i] {
+			t.Fatalf("SortedKeys() = %v, want %v", keys, wantKeys)
+		}
+	}
+
+	want := "a=dave, knows=eve, x=alice, y=bob, z=carol"
+	// Map iteration order is randomized, so repeat to catch instability.
+	for i := 0; i < 50; i++ {
+		if got := s.String(); got != want {
+			t.Fatalf("String() = %q, want %q", got, want)
+		}
+	}
+
+	if got := (Solution{}).String(); got != "" {
+		t.Errorf("empty String() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// WritePlan describes what a Put would store, as computed by PlanPut.
+type WritePlan struct {
+	// Triples holds the distinct triples the Put stores, with predicate
+	// aliases and inverse predicates applied.
+	Triples []*graph.Triple
+	// Added holds the triples in Triples that are not stored yet, or are
+	// soft-deleted and would be restored.
+	Added []*graph.Triple
+}
+
+// PlanPut reports what Put(ctx, triples...) would store without writing
+// anything. Triples are validated and expanded exactly as Put does, so
+// Added holds the triples Put would add. Unlike Put, PlanPut
+// works on a read-only database, which suits dry runs.
+//
+// Example:
+//
+//	plan, err := db.PlanPut(ctx, triples...)
+//	fmt.Printf("%d triples would be added\n", len(plan.Added))
+func (db *DB) PlanPut(ctx context.Context, triples ...*graph.Triple) (*WritePlan, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	prepared, err := db.prepareTriples(triples, "put")
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &WritePlan{}
+	seen := make(map[string]bool, len(prepared))
+	for _, triple := range prepared {
+		key := index.GenKey(index.IndexSPO, triple)
+		if seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+		plan.Triples = append(plan.Triples, triple)
+
+		stored, err := db.storedVisible(key, triple)
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: plan put: %w", err)
+		}
+		if !stored {
+			plan.Added = append(plan.Added, triple)
+		}
+	}
+	return plan, nil
+}
+
+// storedVisible reports whether the triple with SPO key key is stored and
+// not soft-deleted.
+// Caller must hold at least a read lock.
+func (db *DB) storedVisible(key []byte, triple *graph.Triple) (bool, error) {
+	if _, err := db.store.Get(key, nil); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	deleted, err := db.softDeleted(triple)
+	return !deleted, err
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package levelgraph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_PlanPut(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	opts := []Option{
+		WithInversePredicates(map[string]string{"parentOf": "childOf"}),
+		WithPredicateAlias("parentOf", "parent"),
+	}
+	db, err := Open(dbPath, opts...)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	ctx := context.Background()
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "parentOf", "bob")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	db.Close()
+
+	db, err = Open(dbPath, append(opts, WithReadOnly())...)
+	if err != nil {
+		t.Fatalf("Open(WithReadOnly) error = %v", err)
+	}
+	defer db.Close()
+
+	plan, err := db.PlanPut(ctx,
+		graph.NewTripleFromStrings("alice", "parent", "bob"),
+		graph.NewTripleFromStrings("alice", "parent", "carol"),
+		graph.NewTripleFromStrings("alice", "parentOf", "carol"),
+	)
+	if err != nil {
+		t.Fatalf("PlanPut() error = %v", err)
+	}
+	// alice parentOf bob and its inverse are stored; carol's pair is new.
+	if len(plan.Triples) != 4 {
+		t.Errorf("PlanPut() Triples = %d, want 4", len(plan.Triples))
+	}
+	var added []string
+	for _, triple := range plan.Added {
+		added = append(added, string(triple.Subject)+" "+string(triple.Predicate)+" "+string(triple.Object))
+	}
+	if len(added) != 2 || added[0] != "alice parentOf carol" || added[1] != "carol childOf alice" {
+		t.Errorf("PlanPut() Added = %v, want [alice parentOf carol carol childOf alice]", added)
+	}
+
+	if _, err := db.PlanPut(ctx, &graph.Triple{}); err == nil {
+		t.Error("PlanPut() of an invalid triple should fail")
+	}
+}
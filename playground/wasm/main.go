@@ -28,6 +28,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"syscall/js"
 
 	"github.com/benbenbenbenbenben/levelgraph"
@@ -38,17 +39,23 @@ var db *levelgraph.DB
 func main() {
 	// Create the in-memory database
 	store := levelgraph.NewMemStore()
-	db = levelgraph.OpenWithStore(store)
+	var err error
+	db, err = levelgraph.OpenWithDB(store)
+	if err != nil {
+		panic(err)
+	}
 
 	// Register functions for JavaScript
 	js.Global().Set("levelgraph", js.ValueOf(map[string]any{
-		"put":     js.FuncOf(put),
-		"del":     js.FuncOf(del),
-		"get":     js.FuncOf(get),
-		"search":  js.FuncOf(search),
-		"nav":     js.FuncOf(nav),
-		"reset":   js.FuncOf(reset),
-		"isReady": js.FuncOf(isReady),
+		"put":       js.FuncOf(put),
+		"del":       js.FuncOf(del),
+		"get":       js.FuncOf(get),
+		"search":    js.FuncOf(search),
+		"nav":       js.FuncOf(nav),
+		"loadGraph": js.FuncOf(loadGraph),
+		"dumpGraph": js.FuncOf(dumpGraph),
+		"reset":     js.FuncOf(reset),
+		"isReady":   js.FuncOf(isReady),
 	}))
 
 	// Signal that WASM is ready
@@ -69,32 +76,64 @@ func reset(this js.Value, args []js.Value) any {
 		db.Close()
 	}
 	store := levelgraph.NewMemStore()
-	db = levelgraph.OpenWithStore(store)
+	fresh, err := levelgraph.OpenWithDB(store)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	db = fresh
 	return nil
 }
 
 // put inserts triples into the database.
 // Args: triplesJSON (array of {subject, predicate, object})
 // Returns: {error?: string}
-func put(this js.Value, args []js.Value) any {
+// loadGraph replaces the database contents with a graph snapshot: a JSON
+// array of triples as returned by dumpGraph.
+func loadGraph(this js.Value, args []js.Value) any {
 	if len(args) < 1 {
-		return map[string]any{"error": "put requires a triples argument"}
+		return map[string]any{"error": "loadGraph requires a graph argument"}
 	}
 
-	triplesJSON := args[0].String()
-	var triplesData []struct {
-		Subject   string `json:"subject"`
-		Predicate string `json:"predicate"`
-		Object    string `json:"object"`
+	fresh, err := levelgraph.OpenWithDB(levelgraph.NewMemStore())
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	ctx := context.Background()
+	count, err := fresh.ImportJSON(ctx, strings.NewReader(args[0].String()))
+	if err != nil {
+		fresh.Close()
+		return map[string]any{"error": err.Error()}
 	}
 
-	if err := json.Unmarshal([]byte(triplesJSON), &triplesData); err != nil {
-		return map[string]any{"error": "invalid JSON: " + err.Error()}
+	if db != nil {
+		db.Close()
+	}
+	db = fresh
+	return map[string]any{"count": count}
+}
+
+// dumpGraph returns every triple in the database as a JSON array, in the
+// canonical triple form that loadGraph accepts.
+func dumpGraph(this js.Value, args []js.Value) any {
+	var buf strings.Builder
+	ctx := context.Background()
+	count, err := db.ExportJSON(ctx, &buf)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+
+	return map[string]any{"graph": buf.String(), "count": count}
+}
+
+func put(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return map[string]any{"error": "put requires a triples argument"}
 	}
 
-	triples := make([]*levelgraph.Triple, len(triplesData))
-	for i, t := range triplesData {
-		triples[i] = levelgraph.NewTripleFromStrings(t.Subject, t.Predicate, t.Object)
+	triplesJSON := args[0].String()
+	var triples []*levelgraph.Triple
+	if err := json.Unmarshal([]byte(triplesJSON), &triples); err != nil {
+		return map[string]any{"error": "invalid JSON: " + err.Error()}
 	}
 
 	ctx := context.Background()
@@ -114,21 +153,11 @@ func del(this js.Value, args []js.Value) any {
 	}
 
 	triplesJSON := args[0].String()
-	var triplesData []struct {
-		Subject   string `json:"subject"`
-		Predicate string `json:"predicate"`
-		Object    string `json:"object"`
-	}
-
-	if err := json.Unmarshal([]byte(triplesJSON), &triplesData); err != nil {
+	var triples []*levelgraph.Triple
+	if err := json.Unmarshal([]byte(triplesJSON), &triples); err != nil {
 		return map[string]any{"error": "invalid JSON: " + err.Error()}
 	}
 
-	triples := make([]*levelgraph.Triple, len(triplesData))
-	for i, t := range triplesData {
-		triples[i] = levelgraph.NewTripleFromStrings(t.Subject, t.Predicate, t.Object)
-	}
-
 	ctx := context.Background()
 	if err := db.Del(ctx, triples...); err != nil {
 		return map[string]any{"error": err.Error()}
@@ -146,31 +175,12 @@ func get(this js.Value, args []js.Value) any {
 	}
 
 	patternJSON := args[0].String()
-	var patternData struct {
-		Subject   string `json:"subject,omitempty"`
-		Predicate string `json:"predicate,omitempty"`
-		Object    string `json:"object,omitempty"`
-		Limit     int    `json:"limit,omitempty"`
-		Offset    int    `json:"offset,omitempty"`
-	}
-
-	if err := json.Unmarshal([]byte(patternJSON), &patternData); err != nil {
+	var pattern *levelgraph.Pattern
+	if err := json.Unmarshal([]byte(patternJSON), &pattern); err != nil {
 		return map[string]any{"error": "invalid JSON: " + err.Error()}
 	}
-
-	pattern := &levelgraph.Pattern{
-		Limit:  patternData.Limit,
-		Offset: patternData.Offset,
-	}
-
-	if patternData.Subject != "" {
-		pattern.Subject = levelgraph.ExactString(patternData.Subject)
-	}
-	if patternData.Predicate != "" {
-		pattern.Predicate = levelgraph.ExactString(patternData.Predicate)
-	}
-	if patternData.Object != "" {
-		pattern.Object = levelgraph.ExactString(patternData.Object)
+	if pattern == nil {
+		pattern = &levelgraph.Pattern{}
 	}
 
 	ctx := context.Background()
@@ -200,25 +210,11 @@ func search(this js.Value, args []js.Value) any {
 	}
 
 	patternsJSON := args[0].String()
-	var patternsData []struct {
-		Subject   any `json:"subject,omitempty"`
-		Predicate any `json:"predicate,omitempty"`
-		Object    any `json:"object,omitempty"`
-	}
-
-	if err := json.Unmarshal([]byte(patternsJSON), &patternsData); err != nil {
+	var patterns []*levelgraph.Pattern
+	if err := json.Unmarshal([]byte(patternsJSON), &patterns); err != nil {
 		return map[string]any{"error": "invalid JSON: " + err.Error()}
 	}
 
-	patterns := make([]*levelgraph.Pattern, len(patternsData))
-	for i, p := range patternsData {
-		patterns[i] = levelgraph.NewPattern(
-			parsePatternField(p.Subject),
-			parsePatternField(p.Predicate),
-			parsePatternField(p.Object),
-		)
-	}
-
 	var opts *levelgraph.SearchOptions
 	var filterNotEqual []struct {
 		Var   string `json:"var"`   // Variable name (without ?)
@@ -293,23 +289,6 @@ func search(this js.Value, args []js.Value) any {
 	return map[string]any{"solutions": results}
 }
 
-// parsePatternField parses a pattern field value.
-// If it's a string starting with "?", it's a variable.
-// Otherwise, it's a concrete value.
-func parsePatternField(v any) any {
-	if v == nil {
-		return nil
-	}
-	str, ok := v.(string)
-	if !ok {
-		return nil
-	}
-	if len(str) > 1 && str[0] == '?' {
-		return levelgraph.V(str[1:])
-	}
-	return []byte(str)
-}
-
 // nav executes a navigation query.
 // Args: navJSON ({start, steps: [{type: "out"|"in", predicate}]})
 // Returns: {values: [string], error?: string}
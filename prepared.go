@@ -0,0 +1,229 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// ErrUnknownBinding is returned by PreparedQuery.Run when a binding names a
+// variable that none of the prepared patterns use.
+var ErrUnknownBinding = errors.New("levelgraph: binding for unknown variable")
+
+// PreparedQuery is a search compiled once by PreparePattern and run many
+// times with different constants. The join order and the index each lookup
+// uses are worked out on first use for each set of bound variables and
+// cached, so repeated runs skip planning.
+//
+// A PreparedQuery is safe for concurrent use.
+type PreparedQuery struct {
+	db        *DB
+	patterns  []*Pattern
+	variables map[string]bool
+
+	mu    sync.Mutex
+	plans map[string]*queryPlan // keyed by the sorted bound variable names
+}
+
+// queryPlan is the join order and per-lookup index for a prepared query.
+type queryPlan struct {
+	order   []int       // positions in PreparedQuery.patterns, in join order
+	indexes []IndexName // index for each pattern in join order, "" to choose per lookup
+}
+
+// PreparePattern compiles patterns for repeated execution with
+// PreparedQuery.Run. The patterns are the same as for Search; variables
+// that change between runs are left as variables and supplied as bindings.
+//
+// Example:
+//
+//	pq, err := db.PreparePattern([]*Pattern{
+//	    NewPattern(V("person"), "worksAt", V("company")),
+//	    NewPattern(V("company"), "locatedIn", V("city")),
+//	})
+//	solutions, err := pq.Run(ctx, map[string][]byte{"city": []byte("london")}, nil)
+func (db *DB) PreparePattern(patterns []*Pattern) (*PreparedQuery, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	// Copy the patterns so that later changes by the caller do not leak into
+	// the prepared query.
+	clones := make([]*Pattern, len(patterns))
+	variables := make(map[string]bool)
+	for i, pattern := range patterns {
+		if len(pattern.InFields()) > 1 {
+			return nil, ErrMultipleInClauses
+		}
+		for _, v := range pattern.VariableFields() {
+			variables[v.Name] = true
+		}
+		clones[i] = pattern.Clone()
+	}
+
+	return &PreparedQuery{
+		db:        db,
+		patterns:  clones,
+		variables: variables,
+		plans:     make(map[string]*queryPlan),
+	}, nil
+}
+
+// Run executes the prepared query. Each binding replaces its variable with a
+// constant in every pattern, exactly as if the constant had been written
+// into the patterns, so bound variables do not appear in the solutions.
+// opts are as for Search.
+//
+// The patterns may be joined in a different order than they were given, so
+// solutions can come back in a different order than Search returns them.
+func (pq *PreparedQuery) Run(ctx context.Context, bindings map[string][]byte, opts *SearchOptions) (result []Solution, err error) {
+	db := pq.db
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search", start, len(result), err) }()
+	}
+
+	for name := range bindings {
+		if !pq.variables[name] {
+			return nil, ErrUnknownBinding
+		}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	// Variables bound before the join starts shape the plan
+	var initial Solution
+	if opts != nil {
+		initial = opts.InitialSolution
+	}
+	plan := pq.plan(bindings, initial)
+
+	patterns := make([]*Pattern, len(plan.order))
+	for i, pos := range plan.order {
+		patterns[i] = pq.patterns[pos]
+		if len(bindings) > 0 {
+			patterns[i] = patterns[i].UpdateWithSolution(bindings)
+		}
+	}
+
+	return db.searchUnlocked(ctx, patterns, plan.indexes, opts)
+}
+
+// plan returns the cached plan for the variables bound by bindings and
+// initial, computing it on first use.
+func (pq *PreparedQuery) plan(bindings map[string][]byte, initial Solution) *queryPlan {
+	bound := make(map[string]bool, len(bindings)+len(initial))
+	for name := range bindings {
+		bound[name] = true
+	}
+	for name := range initial {
+		if pq.variables[name] {
+			bound[name] = true
+		}
+	}
+	names := make([]string, 0, len(bound))
+	for name := range bound {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	key := strings.Join(names, "\x00")
+
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if plan, ok := pq.plans[key]; ok {
+		return plan
+	}
+	plan := planJoin(pq.patterns, bound)
+	pq.plans[key] = plan
+	return plan
+}
+
+// planJoin orders patterns so that each step has as many fields fixed as
+// possible, by a constant or by a variable bound in bound or by an earlier
+// step, keeping the given order among equals. It also picks the index for
+// each step's lookups from those fields.
+func planJoin(patterns []*Pattern, bound map[string]bool) *queryPlan {
+	bound = maps.Clone(bound)
+	remaining := make([]int, len(patterns))
+	for i := range remaining {
+		remaining[i] = i
+	}
+
+	plan := &queryPlan{}
+	for len(remaining) > 0 {
+		best := 0
+		for i := 1; i < len(remaining); i++ {
+			if len(fixedFields(patterns[remaining[i]], bound)) > len(fixedFields(patterns[remaining[best]], bound)) {
+				best = i
+			}
+		}
+
+		pattern := patterns[remaining[best]]
+		var idx IndexName
+		if len(pattern.InFields()) == 0 {
+			// IN clauses pick their index per lookup
+			idx = index.FindIndex(fixedFields(pattern, bound), "")
+		}
+		plan.order = append(plan.order, remaining[best])
+		plan.indexes = append(plan.indexes, idx)
+
+		for _, v := range pattern.VariableFields() {
+			bound[v.Name] = true
+		}
+		remaining = slices.Delete(remaining, best, best+1)
+	}
+	return plan
+}
+
+// fixedFields returns the fields of pattern, in subject, predicate, object
+// order, that are constants or variables named in bound.
+func fixedFields(pattern *Pattern, bound map[string]bool) []string {
+	var fields []string
+	for _, field := range []string{"subject", "predicate", "object"} {
+		if pattern.GetConcreteValue(field) != nil {
+			fields = append(fields, field)
+		} else if v := pattern.GetVariable(field); v != nil && bound[v.Name] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
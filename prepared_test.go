@@ -0,0 +1,153 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// solutionKeys renders solutions as sorted strings for order-insensitive
+// comparison.
+func solutionKeys(solutions []Solution) []string {
+	keys := make([]string, 0, len(solutions))
+	for _, sol := range solutions {
+		names := make([]string, 0, len(sol))
+		for name := range sol {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		var b strings.Builder
+		for _, name := range names {
+			b.WriteString(name + "=" + string(sol[name]) + ";")
+		}
+		keys = append(keys, b.String())
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestDB_PreparePatternCopiesPatterns(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "worksAt", "acme")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	pattern := graph.NewPattern(V("person"), "worksAt", V("company"))
+	pq, err := db.PreparePattern([]*Pattern{pattern})
+	if err != nil {
+		t.Fatalf("PreparePattern() error = %v", err)
+	}
+	// Changing the caller's pattern afterwards does not change the query.
+	pattern.Predicate.Data()[0] = 'X'
+
+	got, err := pq.Run(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("Run() returned %d solutions, want 1", len(got))
+	}
+}
+
+func TestDB_PreparePattern(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "worksAt", "acme"),
+		graph.NewTripleFromStrings("bob", "worksAt", "acme"),
+		graph.NewTripleFromStrings("carol", "worksAt", "globex"),
+		graph.NewTripleFromStrings("dave", "worksAt", "initech"),
+		graph.NewTripleFromStrings("acme", "locatedIn", "london"),
+		graph.NewTripleFromStrings("globex", "locatedIn", "paris"),
+		graph.NewTripleFromStrings("initech", "locatedIn", "london"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	pq, err := db.PreparePattern([]*Pattern{
+		graph.NewPattern(V("person"), "worksAt", V("company")),
+		graph.NewPattern(V("company"), "locatedIn", V("city")),
+	})
+	if err != nil {
+		t.Fatalf("PreparePattern() error = %v", err)
+	}
+
+	for _, city := range []string{"london", "paris", "tokyo"} {
+		got, err := pq.Run(ctx, map[string][]byte{"city": []byte(city)}, nil)
+		if err != nil {
+			t.Fatalf("Run(%s) error = %v", city, err)
+		}
+		want, err := db.Search(ctx, []*Pattern{
+			graph.NewPattern(V("person"), "worksAt", V("company")),
+			graph.NewPattern(V("company"), "locatedIn", city),
+		}, nil)
+		if err != nil {
+			t.Fatalf("Search(%s) error = %v", city, err)
+		}
+		if g, w := solutionKeys(got), solutionKeys(want); !slices.Equal(g, w) {
+			t.Errorf("Run(%s) = %v, want %v", city, g, w)
+		}
+	}
+
+	// The plan for a set of bound variables is computed once
+	if len(pq.plans) != 1 {
+		t.Errorf("cached %d plans, want 1", len(pq.plans))
+	}
+
+	// The bound pattern is joined first, using the POS index
+	plan := pq.plan(map[string][]byte{"city": nil}, nil)
+	if plan.order[0] != 1 || plan.indexes[0] != IndexPOS {
+		t.Errorf("plan starts with pattern %d on %s, want pattern 1 on pos", plan.order[0], plan.indexes[0])
+	}
+
+	// With no bindings it matches Search over the same patterns
+	got, err := pq.Run(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want, err := db.Search(ctx, pq.patterns, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if g, w := solutionKeys(got), solutionKeys(want); !slices.Equal(g, w) {
+		t.Errorf("Run() = %v, want %v", g, w)
+	}
+
+	if _, err := pq.Run(ctx, map[string][]byte{"nope": []byte("x")}, nil); !errors.Is(err, ErrUnknownBinding) {
+		t.Errorf("Run(unknown binding) error = %v, want ErrUnknownBinding", err)
+	}
+}
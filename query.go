@@ -0,0 +1,360 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// ErrInvalidQuery is returned by Query when the path expression cannot be parsed
+// or evaluated.
+var ErrInvalidQuery = errors.New("levelgraph: invalid query")
+
+// pathQuantifier is the repetition applied to a path step's predicate.
+type pathQuantifier int
+
+const (
+	quantOne        pathQuantifier = iota // exactly one edge
+	quantOneOrMore                        // p+
+	quantZeroOrMore                       // p*
+)
+
+// pathTerm is a node or predicate in a path expression: either a variable
+// name or a literal value.
+type pathTerm struct {
+	variable string
+	value    []byte
+}
+
+// pathStep is a single edge of a path expression: subject, predicate, object.
+type pathStep struct {
+	subject, predicate, object pathTerm
+	quant                      pathQuantifier
+}
+
+// Query evaluates a compact path expression and returns its solutions.
+//
+// A path alternates nodes and predicates, starting and ending with a node:
+//
+//	path      := node (predicate node)+
+//	node      := ?name | value
+//	predicate := ?name | value | value+ | value*
+//
+// Tokens are separated by whitespace. ?name is a variable; any other token is
+// a literal value, and values containing spaces or starting with '?' can be
+// written as Go-quoted strings ("a b"). A predicate followed by + matches a
+// chain of one or more such edges and * matches zero or more; repeated
+// predicates cannot be variables, and at least one end of a repeated step
+// must be a literal or a variable bound by an earlier step.
+//
+// Example:
+//
+//	// Everything alice reaches through one or more "knows" edges
+//	solutions, err := db.Query(ctx, "alice knows+ ?x")
+//
+//	// Things liked by people alice knows
+//	solutions, err = db.Query(ctx, "alice knows ?friend likes ?thing")
+func (db *DB) Query(ctx context.Context, dsl string) ([]Solution, error) {
+	steps, err := parsePath(dsl)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	solutions := []Solution{make(Solution)}
+	for _, step := range steps {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		var next []Solution
+		for _, solution := range solutions {
+			var matched []Solution
+			var err error
+			if step.quant == quantOne {
+				matched, err = db.joinPatterns(ctx, []*Pattern{step.pattern()}, nil, []Solution{solution}, nil, nil, nil)
+			} else {
+				matched, err = db.evalRepeated(ctx, step, solution)
+			}
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, matched...)
+		}
+		solutions = next
+		if len(solutions) == 0 {
+			break
+		}
+	}
+
+	return solutions, nil
+}
+
+// pattern returns the single-edge pattern for a step.
+func (s pathStep) pattern() *Pattern {
+	return &Pattern{
+		Subject:   s.subject.patternValue(),
+		Predicate: s.predicate.patternValue(),
+		Object:    s.object.patternValue(),
+	}
+}
+
+func (t pathTerm) patternValue() graph.PatternValue {
+	if t.variable != "" {
+		return graph.Binding(t.variable)
+	}
+	return graph.Exact(t.value)
+}
+
+// resolve returns the term's value under solution, or nil if it is an
+// unbound variable.
+func (t pathTerm) resolve(solution Solution) []byte {
+	if t.variable != "" {
+		return solution[t.variable]
+	}
+	return t.value
+}
+
+// evalRepeated evaluates a p+ or p* step for one solution by walking edges
+// from whichever end is bound. Caller must hold at least a read lock.
+func (db *DB) evalRepeated(ctx context.Context, step pathStep, solution Solution) ([]Solution, error) {
+	from, to := step.subject, step.object
+	forward := true
+	start := from.resolve(solution)
+	if start == nil {
+		from, to = to, from
+		forward = false
+		start = from.resolve(solution)
+	}
+	if start == nil {
+		return nil, fmt.Errorf("%w: repeated predicate %q needs a bound subject or object", ErrInvalidQuery, step.predicate.value)
+	}
+
+	reached, err := db.reachable(ctx, start, step.predicate.value, forward, step.quant == quantZeroOrMore, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Solution
+	if end := to.resolve(solution); end != nil {
+		for _, node := range reached {
+			if string(node) == string(end) {
+				return []Solution{solution}, nil
+			}
+		}
+		return nil, nil
+	}
+	for _, node := range reached {
+		s := solution.ShallowClone()
+		s[to.variable] = node
+		results = append(results, s)
+	}
+	return results, nil
+}
+
+// reachable returns the distinct nodes reachable from start by following one
+// or more predicate edges (outgoing if forward, incoming otherwise), in
+// breadth-first order. With includeStart, start itself is the first result.
+// maxHops limits the number of edges followed; 0 means no limit. A nil
+// predicate follows edges of any predicate.
+// Caller must hold at least a read lock.
+func (db *DB) reachable(ctx context.Context, start, predicate []byte, forward, includeStart bool, maxHops int) ([][]byte, error) {
+	edge := graph.Exact(predicate)
+	if predicate == nil {
+		edge = graph.Wildcard()
+	}
+
+	seen := map[string]bool{}
+	var result [][]byte
+	if includeStart {
+		seen[string(start)] = true
+		result = append(result, start)
+	}
+
+	frontier := [][]byte{start}
+	for hop := 0; len(frontier) > 0 && (maxHops <= 0 || hop < maxHops); hop++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		var next [][]byte
+		for _, node := range frontier {
+			pattern := &Pattern{Predicate: edge}
+			if forward {
+				pattern.Subject = graph.Exact(node)
+			} else {
+				pattern.Object = graph.Exact(node)
+			}
+			triples, err := db.getUnlocked(pattern, "")
+			if err != nil {
+				return nil, err
+			}
+			for _, triple := range triples {
+				n := triple.Object
+				if !forward {
+					n = triple.Subject
+				}
+				if !seen[string(n)] {
+					seen[string(n)] = true
+					result = append(result, n)
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	return result, nil
+}
+
+// parsePath parses a path expression into its steps.
+func parsePath(dsl string) ([]pathStep, error) {
+	tokens, err := tokenizePath(dsl)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) < 3 || len(tokens)%2 == 0 {
+		return nil, fmt.Errorf("%w: expected node (predicate node)+, got %d tokens", ErrInvalidQuery, len(tokens))
+	}
+
+	nodes := make([]pathTerm, 0, len(tokens)/2+1)
+	for i := 0; i < len(tokens); i += 2 {
+		term, quant, err := tokens[i].term()
+		if err != nil {
+			return nil, err
+		}
+		if quant != quantOne {
+			return nil, fmt.Errorf("%w: quantifier on node %q", ErrInvalidQuery, tokens[i].text)
+		}
+		nodes = append(nodes, term)
+	}
+
+	steps := make([]pathStep, 0, len(tokens)/2)
+	for i := 1; i < len(tokens); i += 2 {
+		predicate, quant, err := tokens[i].term()
+		if err != nil {
+			return nil, err
+		}
+		if quant != quantOne && predicate.variable != "" {
+			return nil, fmt.Errorf("%w: repeated predicate %q cannot be a variable", ErrInvalidQuery, tokens[i].text)
+		}
+		steps = append(steps, pathStep{
+			subject:   nodes[i/2],
+			predicate: predicate,
+			object:    nodes[i/2+1],
+			quant:     quant,
+		})
+	}
+	return steps, nil
+}
+
+// pathToken is a raw token of a path expression.
+type pathToken struct {
+	text   string // the token as written
+	value  string // unquoted value, without quantifier
+	quoted bool
+	quant  pathQuantifier
+}
+
+// term converts the token to a node or predicate term.
+func (tok pathToken) term() (pathTerm, pathQuantifier, error) {
+	if !tok.quoted && strings.HasPrefix(tok.value, "?") {
+		if len(tok.value) == 1 {
+			return pathTerm{}, 0, fmt.Errorf("%w: variable without a name", ErrInvalidQuery)
+		}
+		return pathTerm{variable: tok.value[1:]}, tok.quant, nil
+	}
+	if tok.value == "" {
+		return pathTerm{}, 0, fmt.Errorf("%w: empty value in %q", ErrInvalidQuery, tok.text)
+	}
+	return pathTerm{value: []byte(tok.value)}, tok.quant, nil
+}
+
+// tokenizePath splits a path expression on whitespace, honouring Go-quoted
+// strings and a trailing + or * quantifier.
+func tokenizePath(dsl string) ([]pathToken, error) {
+	var tokens []pathToken
+	rest := strings.TrimLeftFunc(dsl, unicode.IsSpace)
+	for rest != "" {
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+
+		tok := pathToken{text: rest[:end]}
+		suffix := tok.text
+		if rest[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad quoted string in %q", ErrInvalidQuery, rest)
+			}
+			tok.value, _ = strconv.Unquote(quoted)
+			tok.quoted = true
+			suffix = rest[len(quoted):]
+			if end = strings.IndexFunc(suffix, unicode.IsSpace); end < 0 {
+				end = len(suffix)
+			}
+			suffix = suffix[:end]
+			tok.text = quoted + suffix
+			end = len(tok.text)
+		}
+
+		switch {
+		case strings.HasSuffix(suffix, "+"):
+			tok.quant = quantOneOrMore
+			suffix = suffix[:len(suffix)-1]
+		case strings.HasSuffix(suffix, "*"):
+			tok.quant = quantZeroOrMore
+			suffix = suffix[:len(suffix)-1]
+		}
+		if tok.quoted {
+			if suffix != "" {
+				return nil, fmt.Errorf("%w: unexpected %q after quoted string", ErrInvalidQuery, suffix)
+			}
+		} else {
+			tok.value = suffix
+		}
+
+		tokens = append(tokens, tok)
+		rest = strings.TrimLeftFunc(rest[end:], unicode.IsSpace)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// queryValues runs a query and returns the sorted values bound to name.
+func queryValues(t *testing.T, db *DB, dsl, name string) []string {
+	t.Helper()
+	solutions, err := db.Query(context.Background(), dsl)
+	if err != nil {
+		t.Fatalf("Query(%q) error = %v", dsl, err)
+	}
+	var values []string
+	for _, sol := range solutions {
+		values = append(values, string(sol[name]))
+	}
+	sort.Strings(values)
+	return values
+}
+
+func TestDB_Query(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.Put(context.Background(),
+		graph.NewTripleFromStrings("dog", "subclassOf", "mammal"),
+		graph.NewTripleFromStrings("cat", "subclassOf", "mammal"),
+		graph.NewTripleFromStrings("mammal", "subclassOf", "animal"),
+		graph.NewTripleFromStrings("animal", "subclassOf", "livingThing"),
+		graph.NewTripleFromStrings("plant", "subclassOf", "livingThing"),
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("carol", "knows", "alice"),
+		graph.NewTripleFromStrings("bob", "likes", "tennis"),
+		graph.NewTripleFromStrings("carol", "likes", "chess"),
+		graph.NewTripleFromStrings("ice cream", "subclassOf", "dessert"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tests := []struct {
+		dsl  string
+		name string
+		want []string
+	}{
+		{"dog subclassOf+ ?x", "x", []string{"animal", "livingThing", "mammal"}},
+		{"?x subclassOf+ animal", "x", []string{"cat", "dog", "mammal"}},
+		{"dog subclassOf* ?x", "x", []string{"animal", "dog", "livingThing", "mammal"}},
+		{"?x subclassOf ?y subclassOf animal", "x", []string{"cat", "dog"}},
+		{"alice knows ?friend likes ?thing", "thing", []string{"tennis"}},
+		{"alice knows+ ?x", "x", []string{"alice", "bob", "carol"}},
+		{"alice knows+ ?x likes ?thing", "thing", []string{"chess", "tennis"}},
+		{`"ice cream" subclassOf ?x`, "x", []string{"dessert"}},
+		{"dog subclassOf+ livingThing", "", []string{""}},
+		{"plant subclassOf+ animal", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dsl, func(t *testing.T) {
+			got := queryValues(t, db, tt.dsl, tt.name)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query(%q) %s = %v, want %v", tt.dsl, tt.name, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Query(%q) %s = %v, want %v", tt.dsl, tt.name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDB_QueryErrors(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, dsl := range []string{
+		"",
+		"alice",
+		"alice knows",
+		"alice knows+ ?x likes",
+		"alice ?p+ ?x",
+		"alice+ knows ?x",
+		"?x knows+ ?y",
+		"alice knows ?",
+		`"unterminated knows ?x`,
+		`"alice"x knows ?y`,
+	} {
+		if _, err := db.Query(ctx, dsl); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Query(%q) error = %v, want ErrInvalidQuery", dsl, err)
+		}
+	}
+
+	db.Close()
+	if _, err := db.Query(ctx, "alice knows ?x"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Query() after Close error = %v, want ErrClosed", err)
+	}
+}
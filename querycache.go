@@ -0,0 +1,193 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// queryCacheBulkWrite is the number of triples in one write above which the
+// query cache is cleared outright instead of checked entry by entry.
+const queryCacheBulkWrite = 256
+
+// queryCache is an LRU cache of triple lookups keyed by pattern, holding Get
+// results for WithQueryCache and node edges for WithAdjacencyCache.
+//
+// Writes invalidate every entry whose pattern matches a written triple, after
+// the triple is stored. A Get that read the store before such a write may
+// finish after the invalidation, so each write also bumps a generation
+// counter and a Get only caches its result if no write happened meanwhile.
+type queryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	generation uint64
+	entries    map[string]*list.Element
+	lru        *list.List // Front is most recently used
+}
+
+// queryCacheEntry is a cached lookup result.
+type queryCacheEntry struct {
+	key     string
+	pattern *graph.Pattern
+	triples []*graph.Triple
+}
+
+// newQueryCache returns a cache holding up to maxEntries results, or nil if
+// maxEntries is not positive.
+func newQueryCache(maxEntries int) *queryCache {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return &queryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// queryCacheKey returns the cache key for pattern. Patterns with a Filter
+// cannot be keyed and are never cached.
+func queryCacheKey(pattern *graph.Pattern) (string, bool) {
+	if pattern.Filter != nil {
+		return "", false
+	}
+	data, err := pattern.MarshalJSON()
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// get returns a copy of the cached result for key and the current
+// generation, to be passed to add.
+func (c *queryCache) get(key string) ([]*graph.Triple, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, c.generation
+	}
+	c.lru.MoveToFront(elem)
+	return cloneTriples(elem.Value.(*queryCacheEntry).triples), true, c.generation
+}
+
+// add caches a copy of triples as the result of pattern, unless a write has
+// happened since generation was read.
+func (c *queryCache) add(key string, generation uint64, pattern *graph.Pattern, triples []*graph.Triple) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&queryCacheEntry{
+		key:     key,
+		pattern: pattern.Clone(),
+		triples: cloneTriples(triples),
+	})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+	}
+}
+
+// invalidate drops every entry whose pattern matches one of triples.
+func (c *queryCache) invalidate(triples []*graph.Triple) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if len(triples) > queryCacheBulkWrite {
+		c.clearLocked()
+		return
+	}
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*queryCacheEntry)
+		for _, triple := range triples {
+			if entry.pattern.Matches(triple) {
+				c.lru.Remove(elem)
+				delete(c.entries, entry.key)
+				break
+			}
+		}
+		elem = next
+	}
+}
+
+// clear drops every entry.
+func (c *queryCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.clearLocked()
+}
+
+func (c *queryCache) clearLocked() {
+	clear(c.entries)
+	c.lru.Init()
+}
+
+// cloneTriples returns deep copies of triples.
+func cloneTriples(triples []*graph.Triple) []*graph.Triple {
+	if triples == nil {
+		return nil
+	}
+	clones := make([]*graph.Triple, len(triples))
+	for i, t := range triples {
+		clones[i] = t.Clone()
+	}
+	return clones
+}
+
+// cachedGet serves Get from the query cache, filling it on a miss.
+// Caller must hold at least a read lock.
+func (db *DB) cachedGet(pattern *graph.Pattern) ([]*graph.Triple, error) {
+	pattern = db.canonicalPattern(pattern)
+	key, ok := queryCacheKey(pattern)
+	if !ok {
+		return db.getUnlocked(pattern, "")
+	}
+	triples, hit, generation := db.queryCache.get(key)
+	if hit {
+		return triples, nil
+	}
+	triples, err := db.getUnlocked(pattern, "")
+	if err != nil {
+		return nil, err
+	}
+	db.queryCache.add(key, generation, pattern, triples)
+	return triples, nil
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func setupQueryCacheDB(t *testing.T, maxEntries int) (*DB, *iteratorCountingStore) {
+	t.Helper()
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	counting := &iteratorCountingStore{KVStore: store}
+	db, err := OpenWithDB(counting, WithQueryCache(maxEntries))
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, counting
+}
+
+func TestQueryCache_ServesRepeatedGet(t *testing.T) {
+	t.Parallel()
+	db, store := setupQueryCacheDB(t, 10)
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "likes", "tennis"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	pattern := graph.NewPattern("alice", nil, nil)
+	first, err := db.Get(ctx, pattern)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	before := store.total
+	second, err := db.Get(ctx, graph.NewPattern("alice", nil, nil))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if n := store.total - before; n != 0 {
+		t.Errorf("repeated Get() opened %d iterators, want 0 (cached)", n)
+	}
+	if len(second) != len(first) || len(second) != 2 {
+		t.Fatalf("cached Get() returned %d triples, want %d", len(second), len(first))
+	}
+
+	// Callers cannot corrupt the cache by changing a result.
+	second[0].Object[0] = 'X'
+	third, _ := db.Get(ctx, pattern)
+	if string(third[0].Object) != string(first[0].Object) {
+		t.Errorf("cached result changed to %q after mutating a returned triple", third[0].Object)
+	}
+
+	// Patterns with a filter are never cached.
+	filtered := graph.NewPattern("alice", nil, nil)
+	filtered.Filter = func(*graph.Triple) bool { return true }
+	db.Get(ctx, filtered)
+	before = store.total
+	db.Get(ctx, filtered)
+	if store.total == before {
+		t.Error("Get() with a Filter was served from the cache")
+	}
+}
+
+func TestQueryCache_InvalidatedByWrites(t *testing.T) {
+	t.Parallel()
+	db, store := setupQueryCacheDB(t, 10)
+
+	ctx := context.Background()
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	get := func(p *graph.Pattern) int {
+		t.Helper()
+		triples, err := db.Get(ctx, p)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return len(triples)
+	}
+	aliceKnows := graph.NewPattern("alice", "knows", nil)
+	bobAny := graph.NewPattern("bob", nil, nil)
+	get(aliceKnows)
+	get(bobAny)
+
+	// A matching Put is visible at once.
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "knows", "carol")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if n := get(aliceKnows); n != 2 {
+		t.Errorf("Get() after matching Put = %d triples, want 2", n)
+	}
+
+	// A non-matching Put leaves other entries cached.
+	before := store.total
+	if n := get(bobAny); n != 0 {
+		t.Errorf("Get(bob) = %d triples, want 0", n)
+	}
+	if store.total != before {
+		t.Error("Get(bob) after an unrelated Put was not served from the cache")
+	}
+
+	// A matching Del is visible at once.
+	if err := db.Del(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	if n := get(aliceKnows); n != 1 {
+		t.Errorf("Get() after matching Del = %d triples, want 1", n)
+	}
+}
+
+func TestQueryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	db, store := setupQueryCacheDB(t, 2)
+
+	ctx := context.Background()
+	a := graph.NewPattern("a", nil, nil)
+	b := graph.NewPattern("b", nil, nil)
+	c := graph.NewPattern("c", nil, nil)
+	db.Get(ctx, a)
+	db.Get(ctx, b)
+	db.Get(ctx, a) // b is now least recently used
+	db.Get(ctx, c) // evicts b
+
+	cached := func(p *graph.Pattern) bool {
+		before := store.total
+		db.Get(ctx, p)
+		return store.total == before
+	}
+	if !cached(a) {
+		t.Error("pattern a was evicted, want b evicted")
+	}
+	if cached(b) {
+		t.Error("pattern b is still cached, want it evicted")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// RawIter iterates the keys of a single hexastore index in key order, for
+// building custom range scans on top of the store.
+//
+// Keys have the form "spo::subject::predicate::object" (components in the
+// index's order), where each component is escaped with index.Escape so that
+// ':' and '\' inside values cannot be confused with the separator. Build seek
+// targets the same way, for example to visit every triple with subject alice:
+//
+//	it, err := db.RawIterator(ctx, levelgraph.IndexSPO)
+//	defer it.Release()
+//	prefix := append(index.Escape([]byte("alice")), index.KeySeparator...)
+//	for ok := it.Seek(prefix); ok && bytes.HasPrefix(it.Key(), prefix); ok = it.Next() {
+//	    triple, err := it.ParseTriple()
+//	}
+//
+// A RawIter must be released when done.
+type RawIter struct {
+	iter   Iterator
+	prefix []byte
+}
+
+// RawIterator returns a RawIter over the given index, positioned before its
+// first key.
+func (db *DB) RawIterator(ctx context.Context, idx IndexName) (*RawIter, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	if _, ok := index.IndexDefs[idx]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownIndex, idx)
+	}
+
+	all := &graph.Pattern{}
+	prefix := index.GenKeyFromPattern(idx, all)
+	return &RawIter{
+		iter:   db.store.NewIterator(&Range{Start: prefix, Limit: index.GenKeyWithUpperBound(idx, all)}, nil),
+		prefix: prefix,
+	}, nil
+}
+
+// Seek moves to the first key at or after key, given relative to the index,
+// i.e. without the leading "spo::". It returns false if there is no such key
+// in the index.
+func (it *RawIter) Seek(key []byte) bool {
+	target := make([]byte, 0, len(it.prefix)+len(key))
+	target = append(target, it.prefix...)
+	return it.iter.Seek(append(target, key...))
+}
+
+// Next moves to the next key, returning false at the end of the index. The
+// first call without a preceding Seek moves to the first key.
+func (it *RawIter) Next() bool {
+	return it.iter.Next()
+}
+
+// Key returns the current key relative to the index, without the leading
+// "spo::". The slice is only valid until the next call to Seek or Next.
+func (it *RawIter) Key() []byte {
+	return it.iter.Key()[len(it.prefix):]
+}
+
+// Value returns the current encoded value. The slice is only valid until the
+// next call to Seek or Next.
+func (it *RawIter) Value() []byte {
+	return it.iter.Value()
+}
+
+// ParseKey decodes the current key into its unescaped components, in the
+// index's order (subject, predicate, object for SPO).
+func (it *RawIter) ParseKey() [][]byte {
+	_, values := index.ParseKey(it.iter.Key())
+	return values
+}
+
+// ParseTriple decodes the triple stored at the current key.
+func (it *RawIter) ParseTriple() (*Triple, error) {
+	return decodeTripleValue(it.iter.Value())
+}
+
+// Error returns any error encountered during iteration.
+func (it *RawIter) Error() error {
+	return it.iter.Error()
+}
+
+// Release releases the iterator's resources.
+func (it *RawIter) Release() {
+	it.iter.Release()
+}
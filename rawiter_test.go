@@ -0,0 +1,103 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+func TestDB_RawIterator(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("bob", "likes", "a:b"),
+		graph.NewTripleFromStrings("carol", "knows", "alice"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	it, err := db.RawIterator(ctx, IndexSPO)
+	if err != nil {
+		t.Fatalf("RawIterator() error = %v", err)
+	}
+	defer it.Release()
+
+	prefix := append(index.Escape([]byte("bob")), index.KeySeparator...)
+	var got []string
+	for ok := it.Seek(prefix); ok && bytes.HasPrefix(it.Key(), prefix); ok = it.Next() {
+		triple, err := it.ParseTriple()
+		if err != nil {
+			t.Fatalf("ParseTriple() error = %v", err)
+		}
+		if string(triple.Subject) != "bob" {
+			t.Errorf("ParseTriple() subject = %q, want bob", triple.Subject)
+		}
+		parts := it.ParseKey()
+		if len(parts) != 3 || string(parts[1]) != string(triple.Predicate) {
+			t.Errorf("ParseKey() = %q, want predicate %q", parts, triple.Predicate)
+		}
+		got = append(got, string(triple.Predicate)+" "+string(triple.Object))
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("Error() = %v", err)
+	}
+	if len(got) != 2 || got[0] != "knows carol" || got[1] != "likes a:b" {
+		t.Errorf("triples with subject bob = %q, want [knows carol, likes a:b]", got)
+	}
+
+	// Seeking past the last key stays inside the index.
+	if it.Seek([]byte{0xFF}) {
+		t.Errorf("Seek() past the end found key %q", it.Key())
+	}
+
+	// Without a Seek, Next walks the whole index.
+	pos, err := db.RawIterator(ctx, IndexPOS)
+	if err != nil {
+		t.Fatalf("RawIterator() error = %v", err)
+	}
+	defer pos.Release()
+	count := 0
+	for pos.Next() {
+		count++
+	}
+	if count != 4 {
+		t.Errorf("POS index has %d keys, want 4", count)
+	}
+
+	if _, err := db.RawIterator(ctx, IndexName("xyz")); !errors.Is(err, ErrUnknownIndex) {
+		t.Errorf("RawIterator(xyz) error = %v, want ErrUnknownIndex", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// Predicates linking a statement ID to the parts of the triple it reifies.
+const (
+	RDFSubject   = "rdf:subject"
+	RDFPredicate = "rdf:predicate"
+	RDFObject    = "rdf:object"
+)
+
+// BlankNodePrefix starts a blank node label, as in N-Triples and Turtle.
+const BlankNodePrefix = "_:"
+
+// MintBlankNode returns a new blank node ID that is unique across loads and
+// databases: BlankNodePrefix followed by a random (version 4) UUID. Loaders
+// rewrite each document-local label such as "_:b1" to a minted ID so that
+// blank nodes from different documents never collide.
+func (db *DB) MintBlankNode() []byte {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Appendf(nil, "%s%x-%x-%x-%x-%x", BlankNodePrefix, u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// StatementID returns the stable statement ID Reify uses for a triple: "stmt:"
+// followed by the triple's HashHex.
+func StatementID(t *Triple) []byte {
+	return []byte("stmt:" + t.HashHex())
+}
+
+// Reify creates a statement node for a triple so that other triples can be
+// made about it, such as provenance:
+//
+//	stmt, err := db.Reify(ctx, levelgraph.NewTripleFromStrings("bob", "age", "42"))
+//	err = db.Put(ctx, levelgraph.NewTriple(stmt, []byte("saidBy"), []byte("alice")))
+//
+// It stores the rdf:subject, rdf:predicate and rdf:object triples linking
+// the statement ID to the triple's parts and returns the ID, which is the
+// same every time for the same triple. The triple itself is not stored.
+func (db *DB) Reify(ctx context.Context, t *Triple) ([]byte, error) {
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("levelgraph: %w", err)
+	}
+
+	id := StatementID(t)
+	err := db.Put(ctx,
+		graph.NewTriple(id, []byte(RDFSubject), t.Subject),
+		graph.NewTriple(id, []byte(RDFPredicate), t.Predicate),
+		graph.NewTriple(id, []byte(RDFObject), t.Object),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// Statements returns the IDs of all statement nodes reifying the triple,
+// whether created by Reify or written by hand with the rdf:subject,
+// rdf:predicate and rdf:object predicates.
+func (db *DB) Statements(ctx context.Context, t *Triple) ([][]byte, error) {
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("levelgraph: %w", err)
+	}
+
+	stmt := graph.Binding("stmt")
+	solutions, err := db.Search(ctx, []*Pattern{
+		{Subject: stmt, Predicate: graph.ExactString(RDFSubject), Object: graph.Exact(t.Subject)},
+		{Subject: stmt, Predicate: graph.ExactString(RDFPredicate), Object: graph.Exact(t.Predicate)},
+		{Subject: stmt, Predicate: graph.ExactString(RDFObject), Object: graph.Exact(t.Object)},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([][]byte, 0, len(solutions))
+	for _, sol := range solutions {
+		ids = append(ids, sol["stmt"])
+	}
+	return ids, nil
+}
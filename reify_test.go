@@ -0,0 +1,127 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_Reify(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	fact := graph.NewTripleFromStrings("bob", "age", "42")
+
+	stmt, err := db.Reify(ctx, fact)
+	if err != nil {
+		t.Fatalf("Reify() error = %v", err)
+	}
+	again, err := db.Reify(ctx, fact.Clone())
+	if err != nil {
+		t.Fatalf("Reify() error = %v", err)
+	}
+	if !bytes.Equal(stmt, again) {
+		t.Errorf("Reify() IDs differ for the same triple: %s, %s", stmt, again)
+	}
+	if got, want := StatementID(fact), "stmt:"+fact.HashHex(); string(got) != want || !bytes.Equal(stmt, got) {
+		t.Errorf("StatementID() = %s, Reify() = %s, want %s", got, stmt, want)
+	}
+	other := StatementID(graph.NewTripleFromStrings("bob", "age", "43"))
+	if bytes.Equal(stmt, other) {
+		t.Errorf("StatementID() collides for different triples: %s", stmt)
+	}
+
+	if err := db.Put(ctx, graph.NewTriple(stmt, []byte("saidBy"), []byte("alice"))); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	ids, err := db.Statements(ctx, fact)
+	if err != nil {
+		t.Fatalf("Statements() error = %v", err)
+	}
+	if len(ids) != 1 || !bytes.Equal(ids[0], stmt) {
+		t.Fatalf("Statements() = %s, want [%s]", ids, stmt)
+	}
+
+	// Who said bob is 42?
+	solutions, err := db.Search(ctx, []*Pattern{
+		{Subject: Binding("stmt"), Predicate: ExactString(RDFSubject), Object: ExactString("bob")},
+		{Subject: Binding("stmt"), Predicate: ExactString(RDFPredicate), Object: ExactString("age")},
+		{Subject: Binding("stmt"), Predicate: ExactString(RDFObject), Object: ExactString("42")},
+		{Subject: Binding("stmt"), Predicate: ExactString("saidBy"), Object: Binding("who")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(solutions) != 1 || string(solutions[0]["who"]) != "alice" {
+		t.Errorf("Search() = %v, want who=alice", solutions)
+	}
+
+	// Reification does not assert the triple itself.
+	triples, err := db.Get(ctx, &graph.Pattern{Subject: ExactString("bob")})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(triples) != 0 {
+		t.Errorf("Get() = %v, want no asserted triples", triples)
+	}
+
+	ids, err = db.Statements(ctx, graph.NewTripleFromStrings("bob", "age", "43"))
+	if err != nil || len(ids) != 0 {
+		t.Errorf("Statements() for unreified triple = %s, %v, want none", ids, err)
+	}
+
+	if _, err := db.Reify(ctx, &graph.Triple{Subject: []byte("bob")}); !errors.Is(err, ErrInvalidTriple) {
+		t.Errorf("Reify() invalid triple error = %v, want ErrInvalidTriple", err)
+	}
+}
+
+func TestDB_MintBlankNode(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := db.MintBlankNode()
+		if !bytes.HasPrefix(id, []byte(BlankNodePrefix)) {
+			t.Fatalf("MintBlankNode() = %s, want %q prefix", id, BlankNodePrefix)
+		}
+		if len(id) != len(BlankNodePrefix)+36 {
+			t.Errorf("MintBlankNode() = %s, want a UUID after the prefix", id)
+		}
+		if seen[string(id)] {
+			t.Fatalf("MintBlankNode() repeated %s", id)
+		}
+		seen[string(id)] = true
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+)
+
+// Sample returns up to n distinct triples picked from random positions in the
+// SPO index, without scanning the whole graph. It seeks to random keys shaped
+// like the first and last SPO keys and takes the triple at or after each one.
+//
+// The sample is approximate, not uniform: triples following a sparse region
+// of the key space are more likely to be picked, and consecutive triples
+// may be returned together. Fewer than n triples are returned when the
+// database holds fewer, or when repeated seeks keep landing on triples
+// already sampled.
+func (db *DB) Sample(ctx context.Context, n int) ([]*Triple, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	iter := db.store.NewIterator(spoRange(), nil)
+	defer iter.Release()
+
+	if !iter.First() {
+		return nil, iter.Error()
+	}
+	lo := bytes.Clone(iter.Key())
+	iter.Last()
+	hi := bytes.Clone(iter.Key())
+
+	seen := make(map[string]bool, n)
+	var result []*Triple
+	for attempt := 0; attempt < 4*n && len(result) < n; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		if !iter.Seek(randomKeyBetween(lo, hi)) {
+			iter.First()
+		}
+		// Walk forward (wrapping once) to the nearest unsampled triple.
+		for step := 0; step < n; step++ {
+			if !seen[string(iter.Key())] {
+				seen[string(iter.Key())] = true
+				triple, err := decodeTripleValue(iter.Value())
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, triple)
+				break
+			}
+			if !iter.Next() && !iter.First() {
+				break
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("levelgraph: sample: %w", err)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("sample", "requested", n, "count", len(result))
+	}
+	return result, nil
+}
+
+// randomKeyBetween returns a random key near the range [lo, hi]. After their
+// common prefix, each byte is chosen uniformly between the bytes lo and hi
+// hold at that position, which follows the shape of typical text and
+// numeric IDs far better than interpolating the keys as numbers.
+func randomKeyBetween(lo, hi []byte) []byte {
+	key := make([]byte, 0, max(len(lo), len(hi)))
+	for i := 0; i < len(lo) || i < len(hi); i++ {
+		a, b := byteAt(lo, i, hi), byteAt(hi, i, lo)
+		if a > b {
+			a, b = b, a
+		}
+		key = append(key, a+byte(rand.Intn(int(b-a)+1)))
+	}
+	return key
+}
+
+// byteAt returns key[i], or other[i] when key is too short.
+func byteAt(key []byte, i int, other []byte) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return other[i]
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_Sample(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	triples := make([]*graph.Triple, 200)
+	for i := range triples {
+		triples[i] = graph.NewTripleFromStrings(fmt.Sprintf("node%03d", i), "links", fmt.Sprintf("node%03d", (i*7)%200))
+	}
+	if err := db.Put(ctx, triples...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	stored := make(map[string]bool, len(triples))
+	for _, tr := range triples {
+		stored[tr.String()] = true
+	}
+
+	sample, err := db.Sample(ctx, 5)
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if len(sample) == 0 || len(sample) > 5 {
+		t.Fatalf("Sample(5) returned %d triples, want 1-5", len(sample))
+	}
+	seen := make(map[string]bool)
+	for _, tr := range sample {
+		if !stored[tr.String()] {
+			t.Errorf("Sample() returned unknown triple %v", tr)
+		}
+		if seen[tr.String()] {
+			t.Errorf("Sample() returned duplicate triple %v", tr)
+		}
+		seen[tr.String()] = true
+	}
+
+	// Asking for more than exist never returns duplicates.
+	small, cleanupSmall := setupTestDB(t)
+	defer cleanupSmall()
+	if err := small.Put(ctx, triples[:3]...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	sample, err = small.Sample(ctx, 10)
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if len(sample) == 0 || len(sample) > 3 {
+		t.Errorf("Sample(10) on 3 triples returned %d", len(sample))
+	}
+
+	empty, cleanupEmpty := setupTestDB(t)
+	defer cleanupEmpty()
+	if sample, err := empty.Sample(ctx, 5); err != nil || len(sample) != 0 {
+		t.Errorf("Sample() on empty db = %v, %v, want none", sample, err)
+	}
+}
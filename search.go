@@ -25,8 +25,13 @@
 package levelgraph
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
 	"github.com/benbenbenbenbenben/levelgraph/vector"
@@ -96,14 +101,27 @@ type VectorFilter struct {
 	TopK int
 
 	// MinScore filters out solutions where the similarity score is below this threshold.
-	// Score is in range [0, 1] for cosine similarity (after normalization).
+	// Scores use the vector index's distance function, normalized with
+	// vector.NormalizeScore, and lie in [0, 1].
 	MinScore float32
 
 	// IDType specifies the type of vector ID to look up (e.g., IDTypeObject).
 	// If empty, defaults to IDTypeObject.
 	IDType vector.IDType
+
+	// VectorFirst runs the vector search before the graph join: the patterns
+	// are only evaluated with Variable bound to the nearest candidates, which
+	// is much faster when the vector space is large and TopK is small.
+	// Results are the same as without VectorFirst (subject to the index's
+	// accuracy, e.g. HNSW is approximate). Requires TopK > 0; otherwise the
+	// regular join-then-rank plan is used.
+	VectorFirst bool
 }
 
+// ErrQueryTooExpensive is returned by Search when the join produces more
+// intermediate solutions than SearchOptions.MaxIntermediate allows.
+var ErrQueryTooExpensive = errors.New("levelgraph: query exceeds intermediate solution limit")
+
 // SearchOptions configures search behavior.
 type SearchOptions struct {
 	// Limit restricts the number of results (0 means no limit)
@@ -112,6 +130,12 @@ type SearchOptions struct {
 	Offset int
 	// Filter is an optional function to filter solutions
 	Filter func(Solution) bool
+	// IncrementalFilter, when set, is called during the join each time a
+	// pattern binds a new variable, with the partial solution so far and the
+	// name of the variable just bound. Returning false drops the partial
+	// solution before the remaining patterns expand it, pruning selective
+	// constraints early instead of after the full join.
+	IncrementalFilter func(partial Solution, justBound string) bool
 	// AsyncFilter is an optional async filter (returns solution or nil)
 	AsyncFilter func(Solution, func(Solution, error))
 	// Materialized is a pattern to transform solutions into triples
@@ -121,11 +145,147 @@ type SearchOptions struct {
 	// VectorFilter enables hybrid search by filtering/ranking solutions based
 	// on vector similarity of a bound variable.
 	VectorFilter *VectorFilter
+	// MaxIntermediate caps the number of partial solutions the join may
+	// produce across all patterns (0 means no limit). When exceeded, Search
+	// returns ErrQueryTooExpensive and no results; a SolutionIterator stops
+	// with Error() returning ErrQueryTooExpensive.
+	MaxIntermediate int
+	// UnorderedPairs names two variables, as "a,b", whose bindings form an
+	// unordered pair. Symmetric patterns (such as mutual friends) match each
+	// pair twice, once per ordering; with UnorderedPairs set only the
+	// canonical ordering, where a's value sorts at or before b's, is kept.
+	UnorderedPairs string
+	// Timeout, when positive, caps how long the search may run. The search
+	// runs under a context derived from the caller's with this timeout, so
+	// whichever deadline comes first stops the join with an error wrapping
+	// context.DeadlineExceeded. For SearchIterator the timeout runs from the
+	// call until Close.
+	Timeout time.Duration
+	// Explain records the plan Search actually ran, with the index and
+	// counts of each join step, and logs it at debug level to the Logger.
+	// SearchWithPlan returns the plan instead.
+	Explain bool
+}
+
+// ErrInvalidUnorderedPairs is returned by Search and SearchIterator when
+// SearchOptions.UnorderedPairs does not name two distinct variables.
+var ErrInvalidUnorderedPairs = errors.New("levelgraph: UnorderedPairs must name two distinct variables as \"a,b\"")
+
+// solutionFilter returns the solution-level filter for o: Filter combined
+// with the UnorderedPairs canonical-order check.
+func (o *SearchOptions) solutionFilter() (func(Solution) bool, error) {
+	if o.UnorderedPairs == "" {
+		return o.Filter, nil
+	}
+	a, b, ok := strings.Cut(o.UnorderedPairs, ",")
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if !ok || a == "" || b == "" || a == b || strings.Contains(b, ",") {
+		return nil, ErrInvalidUnorderedPairs
+	}
+	filter := o.Filter
+	return func(sol Solution) bool {
+		if bytes.Compare(sol[a], sol[b]) > 0 {
+			return false
+		}
+		return filter == nil || filter(sol)
+	}, nil
+}
+
+// Clone returns a deep copy of the options. The Materialized pattern,
+// InitialSolution and VectorFilter are copied, so changing the clone leaves
+// o unchanged; the filter functions are shared. Clone of nil is nil.
+func (o *SearchOptions) Clone() *SearchOptions {
+	if o == nil {
+		return nil
+	}
+	clone := *o
+	clone.Materialized = o.Materialized.Clone()
+	clone.InitialSolution = o.InitialSolution.Clone()
+	if o.VectorFilter != nil {
+		vf := *o.VectorFilter
+		vf.Query = slices.Clone(vf.Query)
+		clone.VectorFilter = &vf
+	}
+	return &clone
+}
+
+// SearchTable runs Search and returns the solutions as a table, convenient
+// for CSV/TSV writers and reports. columns is the sorted union of the
+// patterns' variable names and any other names bound in the solutions; each
+// row holds one solution's values in column order, with nil for variables
+// the solution leaves unbound.
+func (db *DB) SearchTable(ctx context.Context, patterns []*Pattern, opts *SearchOptions) (columns []string, rows [][][]byte, err error) {
+	solutions, err := db.Search(ctx, patterns, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make(map[string]bool)
+	if opts == nil || opts.Materialized == nil {
+		for _, pattern := range patterns {
+			for _, v := range pattern.VariableFields() {
+				names[v.Name] = true
+			}
+		}
+	}
+	for _, sol := range solutions {
+		for name := range sol {
+			names[name] = true
+		}
+	}
+	columns = make([]string, 0, len(names))
+	for name := range names {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+
+	rows = make([][][]byte, len(solutions))
+	for i, sol := range solutions {
+		row := make([][]byte, len(columns))
+		for j, name := range columns {
+			row[j] = sol[name]
+		}
+		rows[i] = row
+	}
+	return columns, rows, nil
+}
+
+// joinBudget counts the partial solutions produced by a join against
+// SearchOptions.MaxIntermediate. A nil budget is unlimited.
+type joinBudget struct {
+	max  int
+	used int
+}
+
+// newJoinBudget returns a budget for max partial solutions, or nil if max
+// is not positive.
+func newJoinBudget(max int) *joinBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &joinBudget{max: max}
+}
+
+// spend records n more partial solutions, failing once the budget is exceeded.
+func (b *joinBudget) spend(n int) error {
+	if b == nil {
+		return nil
+	}
+	b.used += n
+	if b.used > b.max {
+		return ErrQueryTooExpensive
+	}
+	return nil
 }
 
 // Search executes a search query with one or more patterns.
 // It performs joins across patterns, binding variables as it matches triples.
-func (db *DB) Search(ctx context.Context, patterns []*Pattern, opts *SearchOptions) ([]Solution, error) {
+func (db *DB) Search(ctx context.Context, patterns []*Pattern, opts *SearchOptions) (result []Solution, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search", start, len(result), err) }()
+	}
+
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
@@ -133,43 +293,261 @@ func (db *DB) Search(ctx context.Context, patterns []*Pattern, opts *SearchOptio
 		return nil, ErrClosed
 	}
 
-	if len(patterns) == 0 {
-		return []Solution{}, nil
-	}
+	return db.searchUnlocked(ctx, patterns, nil, opts)
+}
 
-	if opts == nil {
-		opts = &SearchOptions{}
+// searchUnlocked runs Search. indexes, if non-nil, gives the index each
+// pattern's lookups use in the regular join (see joinPatterns).
+// Caller must hold at least a read lock.
+func (db *DB) searchUnlocked(ctx context.Context, patterns []*Pattern, indexes []IndexName, opts *SearchOptions) ([]Solution, error) {
+	if opts == nil || !opts.Explain {
+		return db.searchPlanUnlocked(ctx, patterns, indexes, opts, nil)
 	}
+	plan := &QueryPlan{}
+	solutions, err := db.explainUnlocked(ctx, patterns, indexes, opts, plan)
+	if err == nil {
+		db.logPlan(plan)
+	}
+	return solutions, err
+}
 
+// searchPlanUnlocked is searchUnlocked recording the realized plan in plan,
+// which may be nil.
+// Caller must hold at least a read lock.
+func (db *DB) searchPlanUnlocked(ctx context.Context, patterns []*Pattern, indexes []IndexName, opts *SearchOptions, plan *QueryPlan) ([]Solution, error) {
 	// Start with initial solution or empty solution
 	var startSolution Solution
-	if opts.InitialSolution != nil {
+	if opts != nil && opts.InitialSolution != nil {
 		startSolution = opts.InitialSolution.Clone()
 	} else {
 		startSolution = make(Solution)
 	}
-	solutions := []Solution{startSolution}
+	return db.searchSeedsUnlocked(ctx, patterns, indexes, []Solution{startSolution}, opts, plan)
+}
+
+// searchSeedsUnlocked is searchPlanUnlocked joining from every one of seeds
+// in a single join instead of from opts.InitialSolution. Limit, Offset and
+// DefaultLimit apply to the combined result, and VectorFirst is only used
+// for a single seed.
+// Caller must hold at least a read lock.
+func (db *DB) searchSeedsUnlocked(ctx context.Context, patterns []*Pattern, indexes []IndexName, seeds []Solution, opts *SearchOptions, plan *QueryPlan) ([]Solution, error) {
+	if len(patterns) == 0 {
+		return []Solution{}, nil
+	}
+
+	if opts == nil {
+		opts = &SearchOptions{}
+	}
+	filter, err := opts.solutionFilter()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var solutions []Solution
+	budget := newJoinBudget(opts.MaxIntermediate)
+	vectorFirst := false
+	if vf := opts.VectorFilter; vf != nil && vf.VectorFirst && db.options.VectorIndex != nil && len(seeds) == 1 {
+		solutions, vectorFirst, err = db.searchVectorFirst(ctx, patterns, seeds[0], opts, filter, budget, plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !vectorFirst {
+		budget = newJoinBudget(opts.MaxIntermediate)
+		plan.reset()
+		solutions, err = db.joinPatterns(ctx, patterns, indexes, seeds, budget, opts.IncrementalFilter, plan)
+		if err != nil {
+			return nil, err
+		}
+		solutions = filterSolutions(solutions, filter)
+	}
+
+	// Apply vector filter for hybrid search
+	if opts.VectorFilter != nil && db.options.VectorIndex != nil {
+		var err error
+		solutions, err = db.applyVectorFilter(ctx, solutions, opts.VectorFilter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply offset
+	if opts.Offset > 0 {
+		if opts.Offset >= len(solutions) {
+			solutions = []graph.Solution{}
+		} else {
+			solutions = solutions[opts.Offset:]
+		}
+	}
+
+	// Apply limit (use default limit if no explicit limit provided)
+	limit := opts.Limit
+	if limit <= 0 && db.options.DefaultLimit > 0 {
+		limit = db.options.DefaultLimit
+	}
+	if limit > 0 && limit < len(solutions) {
+		solutions = solutions[:limit]
+	}
+
+	if plan != nil {
+		plan.VectorFirst = vectorFirst
+		plan.Solutions = len(solutions)
+	}
+
+	// Apply materialization if requested
+	if opts.Materialized != nil {
+		return db.materializeSolutions(solutions, opts.Materialized)
+	}
+
+	return solutions, nil
+}
+
+// SearchOne returns the first solution of a search and whether there was
+// one. The join is streamed and stops at the first match, so it is cheaper
+// than Search with Limit 1. opts are as for Search; with a VectorFilter the
+// best-ranked solution is returned, which requires the full Search.
+func (db *DB) SearchOne(ctx context.Context, patterns []*Pattern, opts *SearchOptions) (Solution, bool, error) {
+	if opts != nil && opts.VectorFilter != nil {
+		limited := *opts
+		limited.Limit = 1
+		solutions, err := db.Search(ctx, patterns, &limited)
+		if err != nil || len(solutions) == 0 {
+			return nil, false, err
+		}
+		return solutions[0], true, nil
+	}
+
+	if len(patterns) == 0 {
+		if !db.IsOpen() {
+			return nil, false, ErrClosed
+		}
+		return nil, false, nil
+	}
+
+	iter, err := db.SearchIterator(ctx, patterns, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		return nil, false, iter.Error()
+	}
+	return iter.Solution(), true, nil
+}
+
+// StopIteration can be returned by a SearchEach callback to stop the search
+// early without an error.
+var StopIteration = errors.New("levelgraph: stop iteration")
+
+// SearchEach calls fn with each solution of the search as the streaming
+// join finds it, without collecting the results, so a caller can forward
+// each one (for example to a server-sent events stream) as soon as it is
+// available. If fn returns StopIteration, SearchEach stops and returns nil;
+// any other error stops the search and is returned as is. opts are as for
+// Search. VectorFilter and Materialized need every solution before the
+// first can be produced, so with either set the full Search runs first and
+// fn is called for each of its results.
+//
+// Example:
+//
+//	err := db.SearchEach(ctx, patterns, nil, func(sol levelgraph.Solution) error {
+//	    fmt.Fprintf(w, "data: %s\n\n", sol["friend"])
+//	    flusher.Flush()
+//	    return nil
+//	})
+func (db *DB) SearchEach(ctx context.Context, patterns []*Pattern, opts *SearchOptions, fn func(Solution) error) error {
+	if opts != nil && (opts.VectorFilter != nil || opts.Materialized != nil) {
+		solutions, err := db.Search(ctx, patterns, opts)
+		if err != nil {
+			return err
+		}
+		for _, sol := range solutions {
+			if err := fn(sol); err != nil {
+				if errors.Is(err, StopIteration) {
+					return nil
+				}
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(patterns) == 0 {
+		if !db.IsOpen() {
+			return ErrClosed
+		}
+		return nil
+	}
+
+	iter, err := db.SearchIterator(ctx, patterns, opts)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		if err := fn(iter.Solution()); err != nil {
+			if errors.Is(err, StopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+// joinPatterns performs the nested-loop join of patterns, starting from
+// the seed solutions, charging every partial solution to budget (which may be
+// nil) and dropping those rejected by the optional incremental filter.
+// indexes, if non-nil, holds the index to use for each pattern's lookups;
+// otherwise each lookup picks its own.
+// Each step's lookups and results are added to plan, which may be nil.
+// Caller must hold at least a read lock.
+func (db *DB) joinPatterns(ctx context.Context, patterns []*Pattern, indexes []IndexName, seeds []Solution, budget *joinBudget, incremental func(Solution, string) bool, plan *QueryPlan) ([]Solution, error) {
+	solutions := seeds
+	patterns = db.canonicalPatterns(patterns)
+	plan.addSteps(patterns)
 
 	// Process each pattern in sequence, joining with previous solutions
-	for _, pattern := range patterns {
+	for i, pattern := range patterns {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
 		}
 
+		var idx IndexName
+		if indexes != nil {
+			idx = indexes[i]
+		}
+
 		// Pre-allocate with estimated capacity to reduce slice growth
 		newSolutions := make([]graph.Solution, 0, len(solutions)*4)
 
 		for _, solution := range solutions {
+			// A single step can expand many solutions, so check between them
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+
 			// Update the pattern with bound variables from the current solution
 			updatedPattern := pattern.UpdateWithSolution(solution)
 
 			// Get matching triples (use internal method that doesn't re-lock)
-			triples, err := db.getUnlocked(updatedPattern)
+			triples, err := db.joinLookup(updatedPattern, idx)
 			if err != nil {
 				return nil, err
 			}
+			plan.recordLookup(i, db.canonicalPattern(updatedPattern), idx, len(triples))
 
 			// Bind each matching triple to the solution
 			for _, triple := range triples {
@@ -178,6 +556,12 @@ func (db *DB) Search(ctx context.Context, patterns []*Pattern, opts *SearchOptio
 				if newSolution != nil {
 					// Apply pattern-level filter if present
 					if pattern.Filter == nil || pattern.Filter(triple) {
+						if !acceptPartial(incremental, pattern, solution, newSolution) {
+							continue
+						}
+						if err := budget.spend(1); err != nil {
+							return nil, err
+						}
 						newSolutions = append(newSolutions, newSolution)
 					}
 				}
@@ -185,55 +569,135 @@ func (db *DB) Search(ctx context.Context, patterns []*Pattern, opts *SearchOptio
 		}
 
 		solutions = newSolutions
+		if plan != nil {
+			plan.Steps[i].Solutions += len(solutions)
+		}
 		if len(solutions) == 0 {
 			break
 		}
 	}
 
-	// Apply solution-level filter
-	if opts.Filter != nil {
-		var filtered []graph.Solution
-		for _, s := range solutions {
-			if opts.Filter(s) {
-				filtered = append(filtered, s)
-			}
+	return solutions, nil
+}
+
+// acceptPartial reports whether the incremental filter, if any, accepts
+// after: the solution produced by binding pattern onto before. The filter is
+// called once for each variable after binds that before did not, in
+// subject, predicate, object order.
+func acceptPartial(incremental func(Solution, string) bool, pattern *Pattern, before, after Solution) bool {
+	if incremental == nil {
+		return true
+	}
+	var called []string
+	for _, field := range []string{"subject", "predicate", "object"} {
+		v := pattern.GetVariable(field)
+		if v == nil || slices.Contains(called, v.Name) {
+			continue
+		}
+		if _, bound := before[v.Name]; bound {
+			continue
+		}
+		called = append(called, v.Name)
+		if !incremental(after, v.Name) {
+			return false
 		}
-		solutions = filtered
 	}
+	return true
+}
 
-	// Apply vector filter for hybrid search
-	if opts.VectorFilter != nil && db.options.VectorIndex != nil {
-		var err error
-		solutions, err = db.applyVectorFilter(ctx, solutions, opts.VectorFilter)
-		if err != nil {
-			return nil, err
+// filterSolutions applies a solution-level filter, if any.
+func filterSolutions(solutions []Solution, filter func(Solution) bool) []Solution {
+	if filter == nil {
+		return solutions
+	}
+	var filtered []graph.Solution
+	for _, s := range solutions {
+		if filter(s) {
+			filtered = append(filtered, s)
 		}
 	}
+	return filtered
+}
 
-	// Apply offset
-	if opts.Offset > 0 {
-		if opts.Offset >= len(solutions) {
-			solutions = []graph.Solution{}
-		} else {
-			solutions = solutions[opts.Offset:]
-		}
+// searchVectorFirst implements VectorFilter.VectorFirst. It walks the vector
+// index in similarity order and joins the patterns with the filter variable
+// pre-bound to each candidate, stopping once the candidates seen so far are
+// guaranteed to contain the TopK solutions. The returned solutions still need
+// applyVectorFilter for scoring and ranking.
+//
+// Returns ok=false when the vector-first plan cannot guarantee the same
+// results as the regular plan (no TopK, the variable is already bound, or
+// the index ran out of candidates); the caller then runs the regular join.
+// The joins for all candidates share budget. Caller must hold at least a
+// read lock.
+func (db *DB) searchVectorFirst(ctx context.Context, patterns []*Pattern, startSolution Solution, opts *SearchOptions, filter func(Solution) bool, budget *joinBudget, plan *QueryPlan) ([]Solution, bool, error) {
+	vf := opts.VectorFilter
+	if vf.TopK <= 0 {
+		return nil, false, nil
+	}
+	if _, bound := startSolution[vf.Variable]; bound {
+		return nil, false, nil
 	}
 
-	// Apply limit (use default limit if no explicit limit provided)
-	limit := opts.Limit
-	if limit <= 0 && db.options.DefaultLimit > 0 {
-		limit = db.options.DefaultLimit
+	queryVec, err := db.vectorFilterQuery(vf)
+	if err != nil {
+		return nil, false, err
 	}
-	if limit > 0 && limit < len(solutions) {
-		solutions = solutions[:limit]
+	if queryVec == nil {
+		return nil, false, nil
 	}
 
-	// Apply materialization if requested
-	if opts.Materialized != nil {
-		return db.materializeSolutions(solutions, opts.Materialized)
+	idType := vf.IDType
+	if idType == "" {
+		idType = vector.IDTypeObject
 	}
 
-	return solutions, nil
+	var solutions []Solution
+	processed := 0
+	fetch := vf.TopK * 2
+	for {
+		matches, err := db.options.VectorIndex.Search(queryVec, fetch)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, m := range matches[min(processed, len(matches)):] {
+			processed++
+
+			// Candidates arrive in descending score order, so nothing after
+			// this one can pass MinScore either.
+			if m.Score < vf.MinScore {
+				return solutions, true, nil
+			}
+
+			typ, parts := db.vectorIDCodec().ParseID(m.ID)
+			if typ != idType || len(parts) != 1 {
+				continue
+			}
+
+			candidate := startSolution.Clone()
+			candidate[vf.Variable] = parts[0]
+			joined, err := db.joinPatterns(ctx, patterns, nil, []Solution{candidate}, budget, opts.IncrementalFilter, plan)
+			if err != nil {
+				return nil, false, err
+			}
+			solutions = append(solutions, filterSolutions(joined, filter)...)
+
+			if len(solutions) >= vf.TopK {
+				return solutions, true, nil
+			}
+		}
+
+		if len(matches) < fetch {
+			// Index exhausted. Values without vectors score 0 in the
+			// regular plan and could still fill the remaining slots.
+			if vf.MinScore > 0 {
+				return solutions, true, nil
+			}
+			return nil, false, nil
+		}
+		fetch *= 2
+	}
 }
 
 // materializeSolutions transforms solutions into triples based on a pattern.
@@ -279,13 +743,27 @@ func (db *DB) materializeSolutions(solutions []graph.Solution, pattern *graph.Pa
 
 // SearchIterator returns an iterator for search results.
 //
+// The join is evaluated lazily as a pipeline of nested store iterators, one
+// per pattern: the iterator for a pattern only advances once every deeper
+// pattern is exhausted for its current binding. Memory use is proportional
+// to the number of patterns, not the number of solutions, so it suits
+// streaming over very large results.
+//
 // Note: VectorFilter is not supported with SearchIterator. If you need
 // vector-filtered search results, use Search() instead which returns all
 // results at once after applying vector filtering and sorting.
 func (db *DB) SearchIterator(ctx context.Context, patterns []*graph.Pattern, opts *SearchOptions) (*SolutionIterator, error) {
+	if !db.IsOpen() {
+		return nil, ErrClosed
+	}
+
 	if opts == nil {
 		opts = &SearchOptions{}
 	}
+	filter, err := opts.solutionFilter()
+	if err != nil {
+		return nil, err
+	}
 
 	var startSolution graph.Solution
 	if opts.InitialSolution != nil {
@@ -294,11 +772,19 @@ func (db *DB) SearchIterator(ctx context.Context, patterns []*graph.Pattern, opt
 		startSolution = make(graph.Solution)
 	}
 
+	cancel := context.CancelFunc(func() {})
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+
 	si := &SolutionIterator{
 		ctx:       ctx,
+		cancel:    cancel,
 		db:        db,
-		patterns:  patterns,
+		patterns:  db.canonicalPatterns(patterns),
 		opts:      opts,
+		filter:    filter,
+		budget:    newJoinBudget(opts.MaxIntermediate),
 		iters:     make([]*TripleIterator, len(patterns)),
 		solutions: make([]graph.Solution, len(patterns)+1),
 	}
@@ -310,9 +796,12 @@ func (db *DB) SearchIterator(ctx context.Context, patterns []*graph.Pattern, opt
 // SolutionIterator iterates over search solutions.
 type SolutionIterator struct {
 	ctx       context.Context
+	cancel    context.CancelFunc // Releases the SearchOptions.Timeout context
 	db        *DB
 	patterns  []*graph.Pattern
 	opts      *SearchOptions
+	filter    func(graph.Solution) bool
+	budget    *joinBudget
 	iters     []*TripleIterator
 	solutions []graph.Solution // solutions[i] is the solution before pattern[i]
 	current   graph.Solution
@@ -347,7 +836,7 @@ func (si *SolutionIterator) Next() bool {
 		}
 
 		// Apply solution-level filter
-		if si.opts.Filter != nil && !si.opts.Filter(solution) {
+		if si.filter != nil && !si.filter(solution) {
 			continue
 		}
 
@@ -420,6 +909,15 @@ func (si *SolutionIterator) advance() graph.Solution {
 				continue
 			}
 
+			if !acceptPartial(si.opts.IncrementalFilter, si.patterns[level], si.solutions[level], newSolution) {
+				continue
+			}
+
+			if err := si.budget.spend(1); err != nil {
+				si.err = err
+				return nil
+			}
+
 			if level == len(si.patterns)-1 {
 				// We found a full solution!
 				return newSolution
@@ -436,7 +934,11 @@ func (si *SolutionIterator) advance() graph.Solution {
 			}
 			si.iters[level] = iter
 		} else {
-			// Backtrack
+			// Backtrack, surfacing any error that ended the scan early
+			if err := si.iters[level].Error(); err != nil {
+				si.err = err
+				return nil
+			}
 			si.iters[level].Release()
 			si.iters[level] = nil
 			level--
@@ -472,6 +974,7 @@ func (si *SolutionIterator) Close() {
 		return
 	}
 	si.closed = true
+	si.cancel()
 	for i, iter := range si.iters {
 		if iter != nil {
 			iter.Release()
@@ -505,6 +1008,18 @@ type scoredSolution struct {
 	score    float32
 }
 
+// vectorFilterQuery returns the filter's query vector, embedding QueryText
+// if no precomputed Query is set. Returns nil if neither is set.
+func (db *DB) vectorFilterQuery(vf *VectorFilter) ([]float32, error) {
+	if vf.Query != nil || vf.QueryText == "" {
+		return vf.Query, nil
+	}
+	if db.options.Embedder == nil {
+		return nil, ErrEmbedderRequired
+	}
+	return db.options.Embedder.Embed(vf.QueryText)
+}
+
 // applyVectorFilter filters and ranks solutions based on vector similarity.
 func (db *DB) applyVectorFilter(ctx context.Context, solutions []graph.Solution, vf *VectorFilter) ([]graph.Solution, error) {
 	if len(solutions) == 0 {
@@ -512,16 +1027,9 @@ func (db *DB) applyVectorFilter(ctx context.Context, solutions []graph.Solution,
 	}
 
 	// Get the query vector
-	queryVec := vf.Query
-	if queryVec == nil && vf.QueryText != "" {
-		if db.options.Embedder == nil {
-			return nil, ErrEmbedderRequired
-		}
-		var err error
-		queryVec, err = db.options.Embedder.Embed(vf.QueryText)
-		if err != nil {
-			return nil, err
-		}
+	queryVec, err := db.vectorFilterQuery(vf)
+	if err != nil {
+		return nil, err
 	}
 
 	if queryVec == nil {
@@ -534,7 +1042,9 @@ func (db *DB) applyVectorFilter(ctx context.Context, solutions []graph.Solution,
 		idType = vector.IDTypeObject
 	}
 
-	// Score each solution based on vector similarity
+	// Score each solution with the index's own distance so the ranking
+	// matches the index lookup below and the VectorFirst plan.
+	distanceFunc := vector.IndexDistance(db.options.VectorIndex)
 	scored := make([]scoredSolution, 0, len(solutions))
 	scoreCache := make(map[string]float32) // Cache scores by vector ID string
 
@@ -561,8 +1071,8 @@ func (db *DB) applyVectorFilter(ctx context.Context, solutions []graph.Solution,
 			if err == nil {
 				foundCount := 0
 				for _, m := range matches {
-					// Extract value from ID (assumes MakeID format)
-					_, parts := vector.ParseID(m.ID)
+					// Extract value from ID
+					_, parts := db.vectorIDCodec().ParseID(m.ID)
 					if len(parts) == 0 {
 						continue
 					}
@@ -599,7 +1109,7 @@ func (db *DB) applyVectorFilter(ctx context.Context, solutions []graph.Solution,
 		}
 
 		// Create vector ID for this value
-		vecID := vector.MakeID(idType, varValue)
+		vecID := db.vectorIDCodec().MakeID(idType, varValue)
 		vecIDStr := string(vecID)
 
 		// Check if we've already scored this value
@@ -625,7 +1135,7 @@ func (db *DB) applyVectorFilter(ctx context.Context, solutions []graph.Solution,
 		}
 
 		// Compute similarity and normalize to [0, 1] range
-		distance := vector.Cosine(queryVec, vec)
+		distance := distanceFunc(queryVec, vec)
 		normalizedScore := vector.NormalizeScore(distance)
 
 		// Cache and store the score
@@ -0,0 +1,160 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// importChunkSize is the number of triples ImportJSON writes per Put.
+const importChunkSize = 1000
+
+// ExportJSON writes every triple in the database to w as a JSON array in
+// the canonical triple form, one triple per line, in SPO order. Text
+// components are JSON strings and binary ones are {"base64": "..."}, so
+// ImportJSON restores the graph exactly. Triples soft-deleted with SoftDel
+// are left out. Returns the number of triples written.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	n, err := db.ExportJSON(ctx, &buf)
+//	// later, into a fresh database
+//	n, err = restored.ImportJSON(ctx, &buf)
+func (db *DB) ExportJSON(ctx context.Context, w io.Writer) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	iter := db.store.NewIterator(spoRange(), nil)
+	defer iter.Release()
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[")
+	count := 0
+	skipDeleted := db.tombstones.Load()
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			return count, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return count, fmt.Errorf("levelgraph: export: %w", err)
+		}
+		if skipDeleted {
+			if _, err := db.store.Get(genTombstoneKey(triple), nil); err == nil {
+				continue
+			}
+		}
+		data, err := json.Marshal(triple)
+		if err != nil {
+			return count, fmt.Errorf("levelgraph: export: %w", err)
+		}
+		if count > 0 {
+			bw.WriteString(",")
+		}
+		bw.WriteString("\n")
+		bw.Write(data)
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		return count, fmt.Errorf("levelgraph: export: %w", err)
+	}
+	if count > 0 {
+		bw.WriteString("\n")
+	}
+	bw.WriteString("]\n")
+	if err := bw.Flush(); err != nil {
+		return count, fmt.Errorf("levelgraph: export: %w", err)
+	}
+	return count, nil
+}
+
+// ImportJSON reads a JSON array of triples in the canonical triple form,
+// as written by ExportJSON, and puts them into the database. The array is
+// decoded as a stream and written in chunks through Put, so large
+// snapshots do not have to fit in memory and journalling and
+// auto-embedding apply as usual. Returns the number of triples read;
+// triples that were already present are counted too. On error, the chunks
+// written before it remain in the database.
+func (db *DB) ImportJSON(ctx context.Context, r io.Reader) (int, error) {
+	if !db.IsOpen() {
+		return 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, fmt.Errorf("levelgraph: import: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return 0, fmt.Errorf("levelgraph: import: expected a JSON array of triples, got %v", tok)
+	}
+
+	count := 0
+	chunk := make([]*graph.Triple, 0, importChunkSize)
+	flush := func() error {
+		if err := db.Put(ctx, chunk...); err != nil {
+			return err
+		}
+		count += len(chunk)
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for dec.More() {
+		var triple graph.Triple
+		if err := dec.Decode(&triple); err != nil {
+			return count, fmt.Errorf("levelgraph: import triple %d: %w", count+len(chunk), err)
+		}
+		chunk = append(chunk, &triple)
+		if len(chunk) == importChunkSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return count, fmt.Errorf("levelgraph: import: %w", err)
+	}
+	if len(chunk) > 0 {
+		if err := flush(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
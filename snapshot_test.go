@@ -0,0 +1,120 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_ExportImportJSON(t *testing.T) {
+	t.Parallel()
+	src, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	triples := []*graph.Triple{
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("alice", "says", `"quoted" \ back:slash`),
+		graph.NewTriple([]byte("blob"), []byte("data"), []byte{0x00, 0xff, ':', '\\', 0x80}),
+		graph.NewTriple([]byte("empty"), []byte("value"), []byte{}),
+	}
+	deleted := graph.NewTripleFromStrings("alice", "knows", "mallory")
+	if err := src.Put(ctx, append(triples, deleted)...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := src.SoftDel(ctx, deleted); err != nil {
+		t.Fatalf("SoftDel() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := src.ExportJSON(ctx, &buf)
+	if err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+	if n != len(triples) {
+		t.Errorf("ExportJSON() = %d, want %d", n, len(triples))
+	}
+	if !strings.Contains(buf.String(), `"base64"`) {
+		t.Errorf("ExportJSON() output %s has no base64 component for the binary object", buf.String())
+	}
+
+	dst, cleanupDst := setupTestDB(t)
+	defer cleanupDst()
+	n, err = dst.ImportJSON(ctx, &buf)
+	if err != nil {
+		t.Fatalf("ImportJSON() error = %v", err)
+	}
+	if n != len(triples) {
+		t.Errorf("ImportJSON() = %d, want %d", n, len(triples))
+	}
+
+	got, err := dst.Get(ctx, &graph.Pattern{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(got) != len(triples) {
+		t.Fatalf("imported %d triples, want %d: %v", len(got), len(triples), got)
+	}
+	for _, want := range triples {
+		exists, err := dst.ExistsMany(ctx, want)
+		if err != nil {
+			t.Fatalf("ExistsMany() error = %v", err)
+		}
+		if !exists[0] {
+			t.Errorf("imported graph is missing %q", want)
+		}
+	}
+}
+
+func TestDB_ExportImportJSONEmpty(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if n, err := db.ExportJSON(ctx, &buf); err != nil || n != 0 {
+		t.Fatalf("ExportJSON() = %d, %v, want 0, nil", n, err)
+	}
+	if n, err := db.ImportJSON(ctx, &buf); err != nil || n != 0 {
+		t.Errorf("ImportJSON(empty export) = %d, %v, want 0, nil", n, err)
+	}
+
+	for _, input := range []string{`{"subject": "a"}`, `[{"subject": "a", "predicate": "b", "object": 1}]`, `[`} {
+		if _, err := db.ImportJSON(ctx, strings.NewReader(input)); err == nil {
+			t.Errorf("ImportJSON(%s) succeeded, want an error", input)
+		}
+	}
+	_, err := db.ImportJSON(ctx, strings.NewReader(`[{"subject": "a", "predicate": "b"}]`))
+	if !errors.Is(err, ErrInvalidTriple) {
+		t.Errorf("ImportJSON(missing object) error = %v, want ErrInvalidTriple", err)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+var (
+	// tombstonePrefix is the prefix for soft-delete tombstones. Each key
+	// holds the deleted triple in SPO order and its value is the deletion time.
+	tombstonePrefix = []byte("tombstone::")
+
+	// softDeleteMarkerKey is written by the first SoftDel, so databases that
+	// never soft-deleted anything skip the tombstone lookups on reads
+	softDeleteMarkerKey = []byte("meta::soft_deletes")
+)
+
+// genTombstoneKey generates the tombstone key for a triple.
+// Format: tombstone::<subject>::<predicate>::<object>
+func genTombstoneKey(triple *graph.Triple) []byte {
+	var buf bytes.Buffer
+	buf.Write(tombstonePrefix)
+	buf.Write(index.Escape(triple.Subject))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(triple.Predicate))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(triple.Object))
+	return buf.Bytes()
+}
+
+// parseTombstoneKey returns the triple a tombstone key marks.
+func parseTombstoneKey(key []byte) (*graph.Triple, bool) {
+	parts := bytes.Split(key[len(tombstonePrefix):], index.KeySeparator)
+	if len(parts) != 3 {
+		return nil, false
+	}
+	return graph.NewTriple(index.Unescape(parts[0]), index.Unescape(parts[1]), index.Unescape(parts[2])), true
+}
+
+// loadTombstones records whether SoftDel was ever used on the store. Until
+// it is, reads skip the tombstone lookups.
+func (db *DB) loadTombstones() error {
+	_, err := db.store.Get(softDeleteMarkerKey, nil)
+	switch {
+	case err == nil:
+		db.tombstones.Store(true)
+	case !errors.Is(err, ErrNotFound):
+		return err
+	}
+	return nil
+}
+
+// softDeleted reports whether triple has a SoftDel tombstone.
+// Caller must hold at least a read lock.
+func (db *DB) softDeleted(triple *graph.Triple) (bool, error) {
+	if !db.tombstones.Load() {
+		return false, nil
+	}
+	_, err := db.store.Get(genTombstoneKey(triple), nil)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// SoftDel marks triples as deleted without removing them. A soft-deleted
+// triple no longer appears in Get, GetIterator, Search, SearchText,
+// ExistsMany or the Navigator, but GetIncludingDeleted still returns it until PurgeDeleted removes it
+// for good. Triples that are not stored are ignored, and soft-deleting a
+// triple again keeps its original deletion time. Putting or deleting a
+// soft-deleted triple clears its tombstone.
+//
+// Each tombstone records the deletion time with the order-preserving typed
+// encoding used for time.Time values (see PutTyped).
+func (db *DB) SoftDel(ctx context.Context, triples ...*graph.Triple) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.ReadOnly {
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	for _, triple := range triples {
+		if err := triple.Validate(); err != nil {
+			return fmt.Errorf("levelgraph: %w", err)
+		}
+	}
+	triples = db.withInverses(db.canonicalTriples(triples))
+
+	deletedAt := graph.EncodeInt(time.Now().UnixNano())
+	batch := NewBatch()
+	marked := triples[:0:0]
+	for _, triple := range triples {
+		if _, err := db.store.Get(index.GenKey(index.IndexSPO, triple), nil); err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return fmt.Errorf("levelgraph: %w", err)
+		}
+		key := genTombstoneKey(triple)
+		if _, err := db.store.Get(key, nil); err == nil {
+			continue
+		} else if err != ErrNotFound {
+			return fmt.Errorf("levelgraph: %w", err)
+		}
+		batch.Put(key, deletedAt)
+		marked = append(marked, triple)
+	}
+	if len(marked) == 0 {
+		return nil
+	}
+
+	// Set the flag first, so readers check tombstones once they exist
+	batch.Put(softDeleteMarkerKey, nil)
+	db.tombstones.Store(true)
+	if db.queryCache != nil {
+		defer db.queryCache.invalidate(marked)
+	}
+	if db.adjacency != nil {
+		defer db.adjacency.invalidate(marked)
+	}
+	if err := db.writeBatch(batch); err != nil {
+		return fmt.Errorf("levelgraph: write batch: %w", err)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("soft del", "count", len(marked))
+	}
+	return nil
+}
+
+// GetIncludingDeleted retrieves triples matching the given pattern like
+// Get, including triples soft-deleted with SoftDel.
+func (db *DB) GetIncludingDeleted(ctx context.Context, pattern *graph.Pattern) ([]*graph.Triple, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	iter, err := db.getIteratorUnlocked(pattern, "")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+	iter.skipDeleted = false
+
+	var results []*graph.Triple
+	for iter.Next() {
+		triple, err := iter.Triple()
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: parse triple: %w", err)
+		}
+		results = append(results, triple)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// PurgeDeleted permanently deletes the triples soft-deleted before the
+// cutoff, as Del would, and returns how many it removed. Triples
+// soft-deleted at or after before stay soft-deleted.
+func (db *DB) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.ReadOnly {
+		return 0, fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
+	if !db.tombstones.Load() {
+		return 0, nil
+	}
+
+	cutoff := before.UnixNano()
+	var expired []*graph.Triple
+	iter := db.store.NewIterator(&Range{Start: tombstonePrefix, Limit: append(bytes.Clone(tombstonePrefix), 0xFF)}, nil)
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			iter.Release()
+			return 0, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		deletedAt, ok := graph.DecodeInt(iter.Value())
+		if !ok {
+			iter.Release()
+			return 0, fmt.Errorf("levelgraph: tombstone %q: %w", iter.Key(), ErrCorrupted)
+		}
+		if deletedAt >= cutoff {
+			continue
+		}
+		triple, ok := parseTombstoneKey(bytes.Clone(iter.Key()))
+		if !ok {
+			iter.Release()
+			return 0, fmt.Errorf("levelgraph: tombstone %q: %w", iter.Key(), ErrCorrupted)
+		}
+		expired = append(expired, triple)
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return 0, fmt.Errorf("levelgraph: %w", err)
+	}
+
+	if len(expired) > 0 {
+		// writeTriples clears the tombstones along with the triples
+		if err := db.writeTriples(expired, "del", true); err != nil {
+			return 0, err
+		}
+	}
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("purge deleted", "count", len(expired))
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_SoftDel(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	kept := NewTripleFromStrings("alice", "knows", "bob")
+	gone := NewTripleFromStrings("alice", "knows", "carol")
+	if err := db.Put(ctx, kept, gone, NewTripleFromStrings("carol", "knows", "dave")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := db.SoftDel(ctx, gone); err != nil {
+		t.Fatalf("SoftDel() error = %v", err)
+	}
+
+	alice := &Pattern{Subject: graph.ExactString("alice")}
+	results, err := db.Get(ctx, alice)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Equal(kept) {
+		t.Errorf("Get() = %v, want only %v", results, kept)
+	}
+
+	solutions, err := db.Search(ctx, []*Pattern{
+		{Subject: graph.ExactString("alice"), Predicate: graph.ExactString("knows"), Object: graph.Binding("x")},
+		{Subject: graph.Binding("x"), Predicate: graph.ExactString("knows"), Object: graph.Binding("y")},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(solutions) != 0 {
+		t.Errorf("Search() = %v, want no friends of friends through the deleted triple", solutions)
+	}
+
+	all, err := db.GetIncludingDeleted(ctx, alice)
+	if err != nil {
+		t.Fatalf("GetIncludingDeleted() error = %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("GetIncludingDeleted() returned %d triples, want 2", len(all))
+	}
+
+	// Putting the triple again restores it
+	if err := db.Put(ctx, gone); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	results, err = db.Get(ctx, alice)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Get() after re-Put returned %d triples, want 2", len(results))
+	}
+
+	// Triples that are not stored are ignored
+	if err := db.SoftDel(ctx, NewTripleFromStrings("x", "y", "z")); err != nil {
+		t.Fatalf("SoftDel(missing) error = %v", err)
+	}
+	if err := db.Put(ctx, NewTripleFromStrings("x", "y", "z")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	results, err = db.Get(ctx, &Pattern{Subject: graph.ExactString("x")})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Get(x) = %v, %v, want 1 triple", results, err)
+	}
+}
+
+func TestDB_SoftDelTextAndExists(t *testing.T) {
+	t.Parallel()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithTextIndex([]byte("title")))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	kept := NewTripleFromStrings("post1", "title", "hello world")
+	gone := NewTripleFromStrings("post2", "title", "hello there")
+	if err := db.Put(ctx, kept, gone); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := db.SoftDel(ctx, gone); err != nil {
+		t.Fatalf("SoftDel() error = %v", err)
+	}
+
+	results, err := db.SearchText(ctx, []byte("title"), "hello")
+	if err != nil {
+		t.Fatalf("SearchText() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Equal(kept) {
+		t.Errorf("SearchText() = %v, want only %v", results, kept)
+	}
+
+	exists, err := db.ExistsMany(ctx, kept, gone)
+	if err != nil {
+		t.Fatalf("ExistsMany() error = %v", err)
+	}
+	if !exists[0] || exists[1] {
+		t.Errorf("ExistsMany() = %v, want [true false]", exists)
+	}
+}
+
+func TestDB_PurgeDeleted(t *testing.T) {
+	t.Parallel()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	ctx := context.Background()
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	old := NewTripleFromStrings("a", "p", "old")
+	recent := NewTripleFromStrings("a", "p", "recent")
+	if err := db.Put(ctx, old, recent, NewTripleFromStrings("a", "p", "live")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := db.SoftDel(ctx, old); err != nil {
+		t.Fatalf("SoftDel() error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	cutoff := time.Now()
+	if err := db.SoftDel(ctx, recent); err != nil {
+		t.Fatalf("SoftDel() error = %v", err)
+	}
+	db.Close()
+
+	// Tombstones survive a reopen
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	pattern := &Pattern{Subject: graph.ExactString("a")}
+	results, err := db.Get(ctx, pattern)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Get() after reopen = %v, %v, want only the live triple", results, err)
+	}
+
+	n, err := db.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PurgeDeleted() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("PurgeDeleted() = %d, want 1", n)
+	}
+	all, err := db.GetIncludingDeleted(ctx, pattern)
+	if err != nil {
+		t.Fatalf("GetIncludingDeleted() error = %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("GetIncludingDeleted() after purge = %v, want live and recent", all)
+	}
+	for _, triple := range all {
+		if triple.Equal(old) {
+			t.Errorf("GetIncludingDeleted() still returns purged %v", old)
+		}
+	}
+
+	n, err = db.PurgeDeleted(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("PurgeDeleted() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("PurgeDeleted() = %d, want 1", n)
+	}
+	all, err = db.GetIncludingDeleted(ctx, pattern)
+	if err != nil || len(all) != 1 {
+		t.Errorf("GetIncludingDeleted() after second purge = %v, %v, want only the live triple", all, err)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// ErrUnsortablePattern is returned by SortedIterator when the pattern
+// cannot be read in the order of the sort variable.
+var ErrUnsortablePattern = errors.New("levelgraph: pattern cannot be iterated in sort variable order")
+
+// SortedIterator returns an iterator over the solutions of pattern in
+// ascending byte order of the value bound to sortVar, or descending with
+// pattern.Reverse. It scans the index whose key starts with the pattern's
+// concrete fields followed by sortVar's field, so the order comes from the
+// index and nothing is sorted in memory: for (?s, "knows", ?o) sorted by s,
+// the PSO index. Streams from several SortedIterators on a shared variable
+// can be merge-joined in one pass.
+//
+// sortVar must be bound by one of the pattern's fields; otherwise, or when
+// the pattern has an IN clause, the error wraps ErrUnsortablePattern. Limit,
+// Offset and Filter apply as for GetIterator. The iterator must be closed.
+//
+// Example:
+//
+//	it, err := db.SortedIterator(ctx, levelgraph.NewPattern(levelgraph.V("s"), "knows", levelgraph.V("o")), "s")
+//	defer it.Close()
+//	for it.Next() {
+//	    fmt.Printf("%s knows %s\n", it.Value(), it.Solution()["o"])
+//	}
+func (db *DB) SortedIterator(ctx context.Context, pattern *Pattern, sortVar string) (*SortedIter, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	if len(pattern.InFields()) > 0 {
+		return nil, fmt.Errorf("%w: IN clauses are not supported", ErrUnsortablePattern)
+	}
+	idx, ok := sortedIndex(pattern, sortVar)
+	if !ok {
+		return nil, fmt.Errorf("%w: no field binds %q", ErrUnsortablePattern, sortVar)
+	}
+
+	iter, err := db.getIteratorUnlocked(pattern, idx)
+	if err != nil {
+		return nil, fmt.Errorf("levelgraph: %w", err)
+	}
+	return &SortedIter{ctx: ctx, iter: iter, pattern: pattern, sortVar: sortVar}, nil
+}
+
+// sortedIndex returns the index whose key starts with pattern's concrete
+// fields followed by a field bound to sortVar.
+func sortedIndex(pattern *graph.Pattern, sortVar string) (IndexName, bool) {
+	concrete := pattern.ConcreteFields()
+	for field, v := range pattern.VariableFields() {
+		if v.Name != sortVar {
+			continue
+		}
+		for _, idx := range index.AllIndexes {
+			def := index.IndexDefs[idx]
+			if def[len(concrete)] == field && index.FindIndex(concrete, idx) == idx {
+				return idx, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SortedIter iterates over solutions in sort variable order; see
+// DB.SortedIterator.
+type SortedIter struct {
+	ctx      context.Context
+	iter     *TripleIterator
+	pattern  *graph.Pattern
+	sortVar  string
+	current  graph.Solution
+	err      error
+	released bool
+}
+
+// Next advances to the next solution. It returns false when the iterator is
+// exhausted, closed or has failed; check Error.
+func (it *SortedIter) Next() bool {
+	if it.released || it.err != nil {
+		return false
+	}
+	for it.iter.Next() {
+		select {
+		case <-it.ctx.Done():
+			it.err = it.ctx.Err()
+			return false
+		default:
+		}
+
+		triple, err := it.iter.Triple()
+		if err != nil {
+			it.err = fmt.Errorf("levelgraph: parse triple: %w", err)
+			return false
+		}
+		// A variable repeated across fields must bind the same value
+		if sol := it.pattern.BindTripleFast(nil, triple); sol != nil {
+			it.current = sol
+			return true
+		}
+	}
+	it.err = it.iter.Error()
+	return false
+}
+
+// Solution returns the current solution.
+func (it *SortedIter) Solution() graph.Solution {
+	return it.current
+}
+
+// Value returns the current value of the sort variable.
+func (it *SortedIter) Value() []byte {
+	return it.current[it.sortVar]
+}
+
+// Error returns any error encountered during iteration.
+func (it *SortedIter) Error() error {
+	return it.err
+}
+
+// Close releases the iterator's resources. It is safe to call more than once.
+func (it *SortedIter) Close() {
+	if it.released {
+		return
+	}
+	it.released = true
+	it.iter.Release()
+}
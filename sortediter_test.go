@@ -0,0 +1,123 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_SortedIterator(t *testing.T) {
+	t.Parallel()
+
+	store, err := openLevelDB(filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("openLevelDB() error = %v", err)
+	}
+	recording := &rangeRecordingStore{KVStore: store}
+	db, err := OpenWithDB(recording)
+	if err != nil {
+		t.Fatalf("OpenWithDB() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	// In POS order, the default for a bound predicate, the subjects would
+	// come out as zed, amy, carl, bob, amy.
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("zed", "knows", "amy"),
+		graph.NewTripleFromStrings("amy", "knows", "zed"),
+		graph.NewTripleFromStrings("bob", "knows", "carl"),
+		graph.NewTripleFromStrings("carl", "knows", "bob"),
+		graph.NewTripleFromStrings("amy", "knows", "bob"),
+		graph.NewTripleFromStrings("amy", "likes", "tea"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	recording.starts = nil
+	it, err := db.SortedIterator(ctx, NewPattern(V("s"), "knows", V("o")), "s")
+	if err != nil {
+		t.Fatalf("SortedIterator() error = %v", err)
+	}
+	defer it.Close()
+
+	var subjects []string
+	for it.Next() {
+		if !bytes.Equal(it.Value(), it.Solution()["s"]) {
+			t.Errorf("Value() = %s, Solution()[s] = %s", it.Value(), it.Solution()["s"])
+		}
+		subjects = append(subjects, string(it.Value()))
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("Error() = %v", err)
+	}
+	want := []string{"amy", "amy", "bob", "carl", "zed"}
+	if !slices.Equal(subjects, want) {
+		t.Errorf("SortedIterator() subjects = %v, want %v", subjects, want)
+	}
+	if len(recording.starts) != 1 || !strings.HasPrefix(string(recording.starts[0]), "pso::knows::") {
+		t.Errorf("SortedIterator() scanned %q, want the PSO index", recording.starts)
+	}
+
+	// Sorting by the object reads POS; Reverse gives descending order.
+	reversed := NewPattern(V("s"), "knows", V("o"))
+	reversed.Reverse = true
+	it2, err := db.SortedIterator(ctx, reversed, "o")
+	if err != nil {
+		t.Fatalf("SortedIterator() error = %v", err)
+	}
+	defer it2.Close()
+	var objects []string
+	for it2.Next() {
+		objects = append(objects, string(it2.Value()))
+	}
+	if want := []string{"zed", "carl", "bob", "bob", "amy"}; !slices.Equal(objects, want) {
+		t.Errorf("SortedIterator(reverse) objects = %v, want %v", objects, want)
+	}
+}
+
+func TestDB_SortedIteratorErrors(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := db.SortedIterator(ctx, NewPattern(V("s"), "knows", V("o")), "x"); !errors.Is(err, ErrUnsortablePattern) {
+		t.Errorf("SortedIterator(unbound variable) error = %v, want ErrUnsortablePattern", err)
+	}
+	in := NewPattern(V("s"), nil, V("o"))
+	in.PredicateIn = [][]byte{[]byte("knows"), []byte("likes")}
+	if _, err := db.SortedIterator(ctx, in, "s"); !errors.Is(err, ErrUnsortablePattern) {
+		t.Errorf("SortedIterator(IN clause) error = %v, want ErrUnsortablePattern", err)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// PredicateStat describes how one predicate is used.
+type PredicateStat struct {
+	// Predicate is the predicate value.
+	Predicate []byte
+	// Triples is the number of triples with this predicate.
+	Triples int
+	// DistinctSubjects is the number of different subjects it links from.
+	DistinctSubjects int
+	// DistinctObjects is the number of different objects it links to.
+	DistinctObjects int
+}
+
+// PredicateStats returns, for every predicate in the database, its triple
+// count and the number of distinct subjects and objects it connects, in
+// predicate key order. Triples and objects are counted in one pass over the
+// POS index, decoding a single triple per distinct object; subjects are
+// counted by skip-scanning the PSO index, seeking past each subject's
+// triples instead of reading them.
+func (db *DB) PredicateStats(ctx context.Context) ([]PredicateStat, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	all := &graph.Pattern{}
+	pos := db.store.NewIterator(&Range{
+		Start: index.GenKeyFromPattern(index.IndexPOS, all),
+		Limit: index.GenKeyWithUpperBound(index.IndexPOS, all),
+	}, nil)
+	defer pos.Release()
+	pso := db.store.NewIterator(&Range{
+		Start: index.GenKeyFromPattern(index.IndexPSO, all),
+		Limit: index.GenKeyWithUpperBound(index.IndexPSO, all),
+	}, nil)
+	defer pso.Release()
+
+	var stats []PredicateStat
+	for ok := pos.First(); ok; {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		triple, err := decodeTripleValue(pos.Value())
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: predicate stats: %w", err)
+		}
+		stat := PredicateStat{Predicate: triple.Predicate}
+		byPredicate := &graph.Pattern{Predicate: graph.Exact(triple.Predicate)}
+		prefix := index.GenKeyFromPattern(index.IndexPOS, byPredicate)
+
+		for ok && bytes.HasPrefix(pos.Key(), prefix) {
+			if stat.DistinctObjects > 0 {
+				if triple, err = decodeTripleValue(pos.Value()); err != nil {
+					return nil, fmt.Errorf("levelgraph: predicate stats: %w", err)
+				}
+			}
+			stat.DistinctObjects++
+			objectPrefix := index.GenKeyFromPattern(index.IndexPOS, &graph.Pattern{
+				Predicate: byPredicate.Predicate,
+				Object:    graph.Exact(triple.Object),
+			})
+			for ok && bytes.HasPrefix(pos.Key(), objectPrefix) {
+				stat.Triples++
+				ok = pos.Next()
+			}
+		}
+
+		subjects, err := countDistinctSubjects(ctx, pso, byPredicate)
+		if err != nil {
+			return nil, err
+		}
+		stat.DistinctSubjects = subjects
+		stats = append(stats, stat)
+	}
+	if err := pos.Error(); err != nil {
+		return nil, fmt.Errorf("levelgraph: predicate stats: %w", err)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("predicate stats", "predicates", len(stats))
+	}
+	return stats, nil
+}
+
+// countDistinctSubjects counts the subjects of a predicate by seeking iter,
+// a PSO iterator, from each subject straight to the next.
+func countDistinctSubjects(ctx context.Context, iter Iterator, byPredicate *graph.Pattern) (int, error) {
+	prefix := index.GenKeyFromPattern(index.IndexPSO, byPredicate)
+	count := 0
+	for ok := iter.Seek(prefix); ok && bytes.HasPrefix(iter.Key(), prefix); {
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, fmt.Errorf("levelgraph: %w", err)
+			}
+		}
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return 0, fmt.Errorf("levelgraph: predicate stats: %w", err)
+		}
+		count++
+		ok = iter.Seek(index.GenKeyWithUpperBound(index.IndexPSO, &graph.Pattern{
+			Predicate: byPredicate.Predicate,
+			Subject:   graph.Exact(triple.Subject),
+		}))
+	}
+	if err := iter.Error(); err != nil {
+		return 0, fmt.Errorf("levelgraph: predicate stats: %w", err)
+	}
+	return count, nil
+}
+
+// SubjectSummary describes the edges leaving one subject.
+type SubjectSummary struct {
+	// Subject is the subject value.
+	Subject []byte
+	// Triples is the number of triples with this subject.
+	Triples int
+	// DistinctPredicates is the number of different predicates it uses.
+	DistinctPredicates int
+	// DistinctObjects is the number of different objects it links to,
+	// across all of its predicates.
+	DistinctObjects int
+}
+
+// SubjectSummaries returns, for every subject in the database, its triple
+// count and the number of distinct predicates and objects it uses, in
+// subject key order. The counts come from one pass over the SPO index: a
+// subject's triples are adjacent and sorted by predicate, so only the
+// objects of the current subject are held in memory.
+func (db *DB) SubjectSummaries(ctx context.Context) ([]SubjectSummary, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	all := &graph.Pattern{}
+	iter := db.store.NewIterator(&Range{
+		Start: index.GenKeyFromPattern(index.IndexSPO, all),
+		Limit: index.GenKeyWithUpperBound(index.IndexSPO, all),
+	}, nil)
+	defer iter.Release()
+
+	var summaries []SubjectSummary
+	var current *SubjectSummary
+	var predicate []byte
+	objects := make(map[string]struct{})
+	for ok := iter.First(); ok; ok = iter.Next() {
+		if current != nil && current.Triples%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("levelgraph: %w", err)
+			}
+		}
+
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: subject summaries: %w", err)
+		}
+		if current == nil || !bytes.Equal(current.Subject, triple.Subject) {
+			summaries = append(summaries, SubjectSummary{Subject: triple.Subject})
+			current = &summaries[len(summaries)-1]
+			predicate = nil
+			clear(objects)
+		}
+
+		current.Triples++
+		if predicate == nil || !bytes.Equal(predicate, triple.Predicate) {
+			predicate = triple.Predicate
+			current.DistinctPredicates++
+		}
+		if _, seen := objects[string(triple.Object)]; !seen {
+			objects[string(triple.Object)] = struct{}{}
+			current.DistinctObjects++
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("levelgraph: subject summaries: %w", err)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("subject summaries", "subjects", len(summaries))
+	}
+	return summaries, nil
+}
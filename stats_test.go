@@ -0,0 +1,153 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_PredicateStats(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupSocialGraph(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	stats, err := db.PredicateStats(ctx)
+	if err != nil {
+		t.Fatalf("PredicateStats() error = %v", err)
+	}
+
+	want := []PredicateStat{
+		{Predicate: []byte("age"), Triples: 5, DistinctSubjects: 5, DistinctObjects: 5},
+		{Predicate: []byte("knows"), Triples: 6, DistinctSubjects: 4, DistinctObjects: 4},
+		{Predicate: []byte("likes"), Triples: 9, DistinctSubjects: 5, DistinctObjects: 4},
+		{Predicate: []byte("livesIn"), Triples: 5, DistinctSubjects: 5, DistinctObjects: 2},
+		{Predicate: []byte("type"), Triples: 5, DistinctSubjects: 5, DistinctObjects: 1},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("PredicateStats() returned %d predicates, want %d: %+v", len(stats), len(want), stats)
+	}
+	for i, w := range want {
+		got := stats[i]
+		if string(got.Predicate) != string(w.Predicate) || got.Triples != w.Triples ||
+			got.DistinctSubjects != w.DistinctSubjects || got.DistinctObjects != w.DistinctObjects {
+			t.Errorf("PredicateStats()[%d] = %s %d/%d/%d, want %s %d/%d/%d", i,
+				got.Predicate, got.Triples, got.DistinctSubjects, got.DistinctObjects,
+				w.Predicate, w.Triples, w.DistinctSubjects, w.DistinctObjects)
+		}
+	}
+}
+
+func TestDB_PredicateStatsEscaping(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Predicates and values containing the key separator stay apart.
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("a", "p", "x"),
+		graph.NewTripleFromStrings("a:b", "p", "x:y"),
+		graph.NewTripleFromStrings("a", "p:q", "x"),
+		graph.NewTripleFromStrings("a", "p", "x::"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	stats, err := db.PredicateStats(ctx)
+	if err != nil {
+		t.Fatalf("PredicateStats() error = %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("PredicateStats() returned %d predicates, want 2: %+v", len(stats), stats)
+	}
+	if p := stats[0]; string(p.Predicate) != "p" || p.Triples != 3 || p.DistinctSubjects != 2 || p.DistinctObjects != 3 {
+		t.Errorf("PredicateStats()[0] = %+v, want p 3/2/3", p)
+	}
+	if p := stats[1]; string(p.Predicate) != "p:q" || p.Triples != 1 || p.DistinctSubjects != 1 || p.DistinctObjects != 1 {
+		t.Errorf("PredicateStats()[1] = %+v, want p:q 1/1/1", p)
+	}
+
+	empty, cleanupEmpty := setupTestDB(t)
+	defer cleanupEmpty()
+	if stats, err := empty.PredicateStats(ctx); err != nil || len(stats) != 0 {
+		t.Errorf("PredicateStats() on empty database = %v, %v, want none", stats, err)
+	}
+}
+
+func TestDB_SubjectSummaries(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupSocialGraph(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	summaries, err := db.SubjectSummaries(ctx)
+	if err != nil {
+		t.Fatalf("SubjectSummaries() error = %v", err)
+	}
+	if len(summaries) != 5 {
+		t.Fatalf("SubjectSummaries() returned %d subjects, want 5: %+v", len(summaries), summaries)
+	}
+	alice := summaries[0]
+	if string(alice.Subject) != "alice" || alice.Triples != 7 || alice.DistinctPredicates != 5 || alice.DistinctObjects != 7 {
+		t.Errorf("SubjectSummaries()[0] = %s %d/%d/%d, want alice 7/5/7",
+			alice.Subject, alice.Triples, alice.DistinctPredicates, alice.DistinctObjects)
+	}
+	for i := 1; i < len(summaries); i++ {
+		if string(summaries[i-1].Subject) >= string(summaries[i].Subject) {
+			t.Errorf("SubjectSummaries() not in subject order: %s before %s", summaries[i-1].Subject, summaries[i].Subject)
+		}
+	}
+
+	// An object shared by two predicates is counted once.
+	shared, cleanupShared := setupTestDB(t)
+	defer cleanupShared()
+	if err := shared.Put(ctx,
+		graph.NewTripleFromStrings("a", "p", "x"),
+		graph.NewTripleFromStrings("a", "q", "x"),
+		graph.NewTripleFromStrings("a:b", "p", "x"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	summaries, err = shared.SubjectSummaries(ctx)
+	if err != nil {
+		t.Fatalf("SubjectSummaries() error = %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("SubjectSummaries() returned %d subjects, want 2: %+v", len(summaries), summaries)
+	}
+	if s := summaries[0]; string(s.Subject) != "a" || s.Triples != 2 || s.DistinctPredicates != 2 || s.DistinctObjects != 1 {
+		t.Errorf("SubjectSummaries()[0] = %+v, want a 2/2/1", s)
+	}
+
+	empty, cleanupEmpty := setupTestDB(t)
+	defer cleanupEmpty()
+	if summaries, err := empty.SubjectSummaries(ctx); err != nil || len(summaries) != 0 {
+		t.Errorf("SubjectSummaries() on empty database = %v, %v, want none", summaries, err)
+	}
+}
@@ -26,7 +26,10 @@
 package levelgraph
 
 import (
+	"errors"
+
 	"github.com/syndtr/goleveldb/leveldb"
+	lerrors "github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/util"
@@ -53,9 +56,16 @@ func NewBatch() *Batch {
 }
 
 // openLevelDB opens a LevelDB database at the given path.
-func openLevelDB(path string) (KVStore, error) {
-	return leveldb.OpenFile(path, &opt.Options{})
+// A read-only database must already exist.
+func openLevelDB(path string, readOnly bool) (KVStore, error) {
+	return leveldb.OpenFile(path, &opt.Options{ReadOnly: readOnly, ErrorIfMissing: readOnly})
 }
 
 // ErrNotFound is returned when a key is not found.
 var ErrNotFound = leveldb.ErrNotFound
+
+// isPermanentStoreError reports whether a store error cannot go away by
+// retrying: the store is closed or read-only, or its data is corrupted.
+func isPermanentStoreError(err error) bool {
+	return errors.Is(err, leveldb.ErrClosed) || errors.Is(err, leveldb.ErrReadOnly) || lerrors.IsCorrupted(err)
+}
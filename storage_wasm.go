@@ -82,6 +82,12 @@ type Batch struct {
 	ops []batchOp
 }
 
+// isPermanentStoreError reports whether a store error cannot go away by
+// retrying: the store is closed.
+func isPermanentStoreError(err error) bool {
+	return errors.Is(err, errStoreClosed)
+}
+
 // NewBatch creates a new batch.
 func NewBatch() *Batch {
 	return &Batch{}
@@ -354,12 +360,14 @@ func (it *memIterator) SetReleaser(r Releaser) {
 }
 
 // openLevelDB is not available in WASM builds - returns an error.
-func openLevelDB(path string) (KVStore, error) {
-	return nil, errors.New("levelgraph: file-based storage not available in WASM, use OpenWithStore with NewMemStore()")
+func openLevelDB(path string, readOnly bool) (KVStore, error) {
+	return nil, errors.New("levelgraph: file-based storage not available in WASM, use OpenWithDB with NewMemStore()")
 }
 
 // OpenWithStore creates a new DB with the given KVStore.
-// This is the primary way to create a database in WASM builds.
+//
+// Deprecated: use OpenWithDB, which also validates the options and checks
+// the store's format version.
 func OpenWithStore(store KVStore, opts ...Option) *DB {
 	options := applyOptions(opts...)
 	return &DB{
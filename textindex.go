@@ -0,0 +1,200 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+var (
+	// textPrefix is the prefix for text index postings
+	textPrefix = []byte("text::")
+
+	// ErrTextIndexDisabled is returned by SearchText when the text index is not
+	// enabled for the requested predicate.
+	ErrTextIndexDisabled = errors.New("levelgraph: text index is not enabled")
+)
+
+// tokenizeText splits s into its distinct lowercase tokens, breaking on any
+// character that is not a letter or digit.
+func tokenizeText(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(fields))
+	tokens := fields[:0]
+	for _, f := range fields {
+		if !seen[f] {
+			seen[f] = true
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
+
+// genTextPrefix generates a prefix for iterating the postings of a token.
+// Format: text::<predicate>::<token>::
+func genTextPrefix(predicate []byte, token string) []byte {
+	var buf bytes.Buffer
+	buf.Write(textPrefix)
+	buf.Write(index.Escape(predicate))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape([]byte(token)))
+	buf.Write(index.KeySeparator)
+	return buf.Bytes()
+}
+
+// genTextKey generates the posting key of a token for a triple.
+// Format: text::<predicate>::<token>::<subject>::<object>
+func genTextKey(triple *graph.Triple, token string) []byte {
+	key := genTextPrefix(triple.Predicate, token)
+	key = append(key, index.Escape(triple.Subject)...)
+	key = append(key, index.KeySeparator...)
+	return append(key, index.Escape(triple.Object)...)
+}
+
+// textIndexed reports whether objects of predicate are text indexed.
+func (db *DB) textIndexed(predicate []byte) bool {
+	if !db.options.TextIndex {
+		return false
+	}
+	if len(db.options.TextIndexPredicates) == 0 {
+		return true
+	}
+	for _, p := range db.options.TextIndexPredicates {
+		if bytes.Equal(p, predicate) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTextIndex adds (or, for "del", removes) the text postings of a
+// triple to the batch.
+func (db *DB) recordTextIndex(batch *Batch, action string, triple *graph.Triple) {
+	if !db.textIndexed(triple.Predicate) {
+		return
+	}
+
+	tokens := tokenizeText(string(triple.Object))
+	if len(tokens) == 0 {
+		return
+	}
+	value, _ := db.encodeTripleValue(triple)
+	for _, token := range tokens {
+		if action == "put" {
+			batch.Put(genTextKey(triple, token), value)
+		} else {
+			batch.Delete(genTextKey(triple, token))
+		}
+	}
+}
+
+// SearchText returns the triples with the given predicate whose object
+// contains every token of query, using the index enabled by WithTextIndex.
+// The query is tokenized the same way as objects, so matching is
+// case-insensitive and ignores punctuation and token order. A query without
+// tokens matches nothing. Triples soft-deleted with SoftDel are skipped.
+func (db *DB) SearchText(ctx context.Context, predicate []byte, query string) ([]*Triple, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if !db.textIndexed(predicate) {
+		return nil, fmt.Errorf("%w: predicate %q", ErrTextIndexDisabled, predicate)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	tokens := tokenizeText(query)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	// Scan the postings of the first token and check the others against
+	// each candidate's object.
+	prefix := genTextPrefix(predicate, tokens[0])
+	iter := db.store.NewIterator(&Range{Start: prefix, Limit: prefixLimit(prefix)}, nil)
+	defer iter.Release()
+
+	var results []*Triple
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		if !containsTokens(tokenizeText(string(triple.Object)), tokens[1:]) {
+			continue
+		}
+		deleted, err := db.softDeleted(triple)
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: search text: %w", err)
+		}
+		if !deleted {
+			results = append(results, triple)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("levelgraph: search text: %w", err)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("search text", "predicate", string(predicate), "tokens", len(tokens), "results", len(results))
+	}
+	return results, nil
+}
+
+// containsTokens reports whether have includes every token in want.
+func containsTokens(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
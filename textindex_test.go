@@ -0,0 +1,131 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestTokenizeText(t *testing.T) {
+	got := tokenizeText("The quick, quick brown-fox! Jumped over 2 dogs.")
+	want := []string{"the", "quick", "brown", "fox", "jumped", "over", "2", "dogs"}
+	if len(got) != len(want) {
+		t.Fatalf("tokenizeText() = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("tokenizeText() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDB_SearchText(t *testing.T) {
+	t.Parallel()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithTextIndex([]byte("body")))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("doc1", "body", "The quick brown fox jumps over the lazy dog."),
+		graph.NewTripleFromStrings("doc2", "body", "A quick brown dog outpaces a quick fox"),
+		graph.NewTripleFromStrings("doc3", "body", "Lazy afternoons are for reading."),
+		graph.NewTripleFromStrings("doc4", "title", "The quick brown fox"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		triples, err := db.SearchText(ctx, []byte("body"), query)
+		if err != nil {
+			t.Fatalf("SearchText(%q) error = %v", query, err)
+		}
+		var subjects []string
+		for _, tr := range triples {
+			if string(tr.Predicate) != "body" {
+				t.Errorf("SearchText(%q) returned %v", query, tr)
+			}
+			subjects = append(subjects, string(tr.Subject))
+		}
+		sort.Strings(subjects)
+		return subjects
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"fox", []string{"doc1", "doc2"}},
+		{"QUICK Fox", []string{"doc1", "doc2"}},
+		{"lazy", []string{"doc1", "doc3"}},
+		{"lazy dog", []string{"doc1"}},
+		{"fox reading", nil},
+		{"cat", nil},
+		{"...", nil},
+	}
+	for _, tt := range tests {
+		got := search(tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("SearchText(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("SearchText(%q) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+
+	// Deleting a triple removes its postings.
+	if err := db.Del(ctx, graph.NewTripleFromStrings("doc1", "body", "The quick brown fox jumps over the lazy dog.")); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	if got := search("lazy"); len(got) != 1 || got[0] != "doc3" {
+		t.Errorf("SearchText(lazy) after Del = %v, want [doc3]", got)
+	}
+
+	if _, err := db.SearchText(ctx, []byte("title"), "fox"); !errors.Is(err, ErrTextIndexDisabled) {
+		t.Errorf("SearchText() on unindexed predicate error = %v, want ErrTextIndexDisabled", err)
+	}
+}
+
+func TestDB_SearchTextDisabled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.SearchText(context.Background(), []byte("body"), "fox"); !errors.Is(err, ErrTextIndexDisabled) {
+		t.Errorf("SearchText() error = %v, want ErrTextIndexDisabled", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+var (
+	// createdAtPrefix and updatedAtPrefix are the prefixes for the Unix
+	// nanosecond times WithTimestamps records for each triple's first and
+	// last put. They are kept apart from facets so they never show up as
+	// facets or collide with user facet keys.
+	createdAtPrefix = []byte("timestamp::created::")
+	updatedAtPrefix = []byte("timestamp::updated::")
+)
+
+// genTimestampKey generates the key under prefix for a triple's timestamp.
+// Format: <prefix><subject>::<predicate>::<object>
+func genTimestampKey(prefix []byte, triple *graph.Triple) []byte {
+	var buf bytes.Buffer
+	buf.Write(prefix)
+	buf.Write(index.Escape(triple.Subject))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(triple.Predicate))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(triple.Object))
+	return buf.Bytes()
+}
+
+// recordTimestamps adds triple's updatedAt time to batch, and its createdAt
+// time unless it already has one. stamp is the put's time in Unix
+// nanoseconds.
+// Caller must hold timestampMu.
+func (db *DB) recordTimestamps(batch *Batch, triple *graph.Triple, stamp int64) error {
+	encoded := graph.EncodeInt(stamp)
+	createdKey := genTimestampKey(createdAtPrefix, triple)
+	_, err := db.store.Get(createdKey, nil)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		batch.Put(createdKey, encoded)
+	case err != nil:
+		return err
+	}
+	batch.Put(genTimestampKey(updatedAtPrefix, triple), encoded)
+	return nil
+}
+
+// deleteTimestamps adds deletes of triple's timestamps to batch.
+func deleteTimestamps(batch *Batch, triple *graph.Triple) {
+	batch.Delete(genTimestampKey(createdAtPrefix, triple))
+	batch.Delete(genTimestampKey(updatedAtPrefix, triple))
+}
+
+// GetTimestamps returns when triple was first and last put, as recorded
+// with WithTimestamps. Both are the zero time if the triple has no
+// timestamps, for example because it is not stored or was put before
+// timestamps were enabled.
+//
+// Example:
+//
+//	created, updated, err := db.GetTimestamps(ctx, triple)
+//	if updated.After(lastSync) {
+//	    // triple changed since the last sync
+//	}
+func (db *DB) GetTimestamps(ctx context.Context, triple *graph.Triple) (created, updated time.Time, err error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return time.Time{}, time.Time{}, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return time.Time{}, time.Time{}, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	triple = db.canonicalTriple(triple)
+	if created, err = db.tripleTimestamp(triple, createdAtPrefix); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if updated, err = db.tripleTimestamp(triple, updatedAtPrefix); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return created, updated, nil
+}
+
+// tripleTimestamp reads triple's timestamp under prefix, or returns the
+// zero time if it is not set.
+// Caller must hold at least a read lock.
+func (db *DB) tripleTimestamp(triple *graph.Triple, prefix []byte) (time.Time, error) {
+	key := genTimestampKey(prefix, triple)
+	value, err := db.store.Get(key, nil)
+	if errors.Is(err, ErrNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("levelgraph: read %s: %w", key, err)
+	}
+	ns, ok := graph.DecodeInt(value)
+	if !ok {
+		return time.Time{}, fmt.Errorf("levelgraph: read %s: %w", key, ErrCorrupted)
+	}
+	return time.Unix(0, ns), nil
+}
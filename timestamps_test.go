@@ -0,0 +1,166 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithTimestamps(), WithFacets())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	triple := graph.NewTripleFromStrings("alice", "knows", "bob")
+	timestamps := func() (time.Time, time.Time) {
+		t.Helper()
+		created, updated, err := db.GetTimestamps(ctx, triple)
+		if err != nil {
+			t.Fatalf("GetTimestamps() error = %v", err)
+		}
+		return created, updated
+	}
+
+	before := time.Now()
+	if err := db.Put(ctx, triple); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	created, updated := timestamps()
+	if created.Before(before) || !created.Equal(updated) {
+		t.Fatalf("GetTimestamps() after first Put = %v, %v, want equal times after %v", created, updated, before)
+	}
+
+	// A re-Put keeps createdAt and advances updatedAt
+	time.Sleep(2 * time.Millisecond)
+	if err := db.Put(ctx, triple); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	created2, updated2 := timestamps()
+	if !created2.Equal(created) {
+		t.Errorf("createdAt after re-Put = %v, want %v", created2, created)
+	}
+	if !updated2.After(updated) {
+		t.Errorf("updatedAt after re-Put = %v, want after %v", updated2, updated)
+	}
+
+	// Timestamps are not facets, and facets of any name leave them alone
+	facets, err := db.GetTripleFacets(ctx, triple)
+	if err != nil {
+		t.Fatalf("GetTripleFacets() error = %v", err)
+	}
+	if len(facets) != 0 {
+		t.Errorf("GetTripleFacets() = %v, want none", facets)
+	}
+	if err := db.SetTripleFacet(ctx, triple, []byte("_createdAt"), []byte("x")); err != nil {
+		t.Fatalf("SetTripleFacet(_createdAt) error = %v", err)
+	}
+	if err := db.DelTripleFacet(ctx, triple, []byte("_createdAt")); err != nil {
+		t.Fatalf("DelTripleFacet(_createdAt) error = %v", err)
+	}
+
+	// Replacing or deleting the user facets keeps the timestamps
+	if err := db.SetTripleFacets(ctx, triple, map[string][]byte{"since": []byte("2020")}, FacetReplace); err != nil {
+		t.Fatalf("SetTripleFacets() error = %v", err)
+	}
+	if err := db.DelAllTripleFacets(ctx, triple); err != nil {
+		t.Fatalf("DelAllTripleFacets() error = %v", err)
+	}
+	if created3, updated3 := timestamps(); !created3.Equal(created) || !updated3.Equal(updated2) {
+		t.Errorf("GetTimestamps() after facet changes = %v, %v, want %v, %v", created3, updated3, created, updated2)
+	}
+
+	// Del removes the timestamps, so a new Put starts afresh
+	if err := db.Del(ctx, triple); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	if created, updated := timestamps(); !created.IsZero() || !updated.IsZero() {
+		t.Errorf("GetTimestamps() after Del = %v, %v, want zero times", created, updated)
+	}
+	if err := db.Put(ctx, triple); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if created4, _ := timestamps(); !created4.After(created) {
+		t.Errorf("createdAt after Del and Put = %v, want after %v", created4, created)
+	}
+}
+
+func TestDB_TimestampsWithoutFacets(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithTimestamps())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	triple := graph.NewTripleFromStrings("alice", "knows", "bob")
+	if err := db.Put(ctx, triple); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if created, _, err := db.GetTimestamps(ctx, triple); err != nil || created.IsZero() {
+		t.Fatalf("GetTimestamps() = %v, %v, want a created time", created, err)
+	}
+
+	if err := db.Del(ctx, triple); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	created, updated, err := db.GetTimestamps(ctx, triple)
+	if err != nil {
+		t.Fatalf("GetTimestamps() error = %v", err)
+	}
+	if !created.IsZero() || !updated.IsZero() {
+		t.Errorf("GetTimestamps() after Del = %v, %v, want zero times", created, updated)
+	}
+}
+
+func TestDB_TimestampsDisabled(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	triple := graph.NewTripleFromStrings("alice", "knows", "bob")
+	if err := db.Put(ctx, triple); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	created, updated, err := db.GetTimestamps(ctx, triple)
+	if err != nil {
+		t.Fatalf("GetTimestamps() error = %v", err)
+	}
+	if !created.IsZero() || !updated.IsZero() {
+		t.Errorf("GetTimestamps() = %v, %v, want zero times", created, updated)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+var (
+	// ErrNoCodec is returned by PutTyped and GetTyped for a type without a
+	// registered codec.
+	ErrNoCodec = errors.New("levelgraph: no codec registered for type")
+
+	// ErrTypeMismatch is returned by GetTyped when the stored value cannot
+	// be decoded as the requested type.
+	ErrTypeMismatch = errors.New("levelgraph: stored value does not match type")
+)
+
+// typedCodec converts values of one type to and from object bytes.
+type typedCodec struct {
+	encode func(any) []byte
+	decode func([]byte) (any, bool)
+}
+
+var (
+	typedCodecsMu sync.RWMutex
+	typedCodecs   = make(map[reflect.Type]typedCodec)
+)
+
+// RegisterCodec sets how PutTyped and GetTyped store values of type T.
+// decode reports false for bytes that are not a valid encoding. Codecs for
+// int, int64, float64, bool, string and time.Time are built in; registering
+// one of those types again replaces the built-in codec.
+func RegisterCodec[T any](encode func(T) []byte, decode func([]byte) (T, bool)) {
+	typedCodecsMu.Lock()
+	defer typedCodecsMu.Unlock()
+	typedCodecs[reflect.TypeFor[T]()] = typedCodec{
+		encode: func(v any) []byte { return encode(v.(T)) },
+		decode: func(b []byte) (any, bool) { return decode(b) },
+	}
+}
+
+func init() {
+	// Numbers and times use the order-preserving typed encoding, so that
+	// range scans over them follow numeric and chronological order.
+	RegisterCodec(func(v int) []byte { return graph.EncodeInt(int64(v)) },
+		func(b []byte) (int, bool) {
+			v, ok := graph.DecodeInt(b)
+			return int(v), ok
+		})
+	RegisterCodec(graph.EncodeInt, graph.DecodeInt)
+	RegisterCodec(graph.EncodeFloat, graph.DecodeFloat)
+	RegisterCodec(func(v time.Time) []byte { return graph.EncodeInt(v.UnixNano()) },
+		func(b []byte) (time.Time, bool) {
+			ns, ok := graph.DecodeInt(b)
+			return time.Unix(0, ns).UTC(), ok
+		})
+	RegisterCodec(func(v bool) []byte { return strconv.AppendBool(nil, v) },
+		func(b []byte) (bool, bool) {
+			v, err := strconv.ParseBool(string(b))
+			return v, err == nil
+		})
+	RegisterCodec(func(v string) []byte { return []byte(v) },
+		func(b []byte) (string, bool) { return string(b), true })
+}
+
+// codecFor returns the codec registered for T.
+func codecFor[T any]() (typedCodec, error) {
+	typedCodecsMu.RLock()
+	defer typedCodecsMu.RUnlock()
+	typ := reflect.TypeFor[T]()
+	codec, ok := typedCodecs[typ]
+	if !ok {
+		return typedCodec{}, fmt.Errorf("%w: %v", ErrNoCodec, typ)
+	}
+	return codec, nil
+}
+
+// PutTyped sets the value of predicate p on subject s to v, encoded with
+// the codec registered for T, replacing any other values the pair had:
+//
+//	err := levelgraph.PutTyped(ctx, db, "alice", "age", 30)
+//	age, ok, err := levelgraph.GetTyped[int](ctx, db, "alice", "age")
+//
+// The old values are deleted before the new one is put, in two writes.
+// Times are stored to the nanosecond in UTC; their location is not kept.
+func PutTyped[T any](ctx context.Context, db *DB, s, p string, v T) error {
+	codec, err := codecFor[T]()
+	if err != nil {
+		return err
+	}
+	triple := graph.NewTriple([]byte(s), []byte(p), codec.encode(v))
+
+	old, err := db.Get(ctx, &graph.Pattern{
+		Subject:   graph.Exact(triple.Subject),
+		Predicate: graph.Exact(triple.Predicate),
+	})
+	if err != nil {
+		return err
+	}
+	var stale []*graph.Triple
+	for _, t := range old {
+		if !bytes.Equal(t.Object, triple.Object) {
+			stale = append(stale, t)
+		}
+	}
+	if len(stale) > 0 {
+		if err := db.Del(ctx, stale...); err != nil {
+			return err
+		}
+	}
+	return db.Put(ctx, triple)
+}
+
+// GetTyped returns the value of predicate p on subject s decoded as T, and
+// whether there was one. It fails with ErrTypeMismatch if the stored value
+// is not a valid encoding of T.
+func GetTyped[T any](ctx context.Context, db *DB, s, p string) (T, bool, error) {
+	var zero T
+	codec, err := codecFor[T]()
+	if err != nil {
+		return zero, false, err
+	}
+
+	triples, err := db.Get(ctx, &graph.Pattern{
+		Subject:   graph.ExactString(s),
+		Predicate: graph.ExactString(p),
+		Limit:     1,
+	})
+	if err != nil || len(triples) == 0 {
+		return zero, false, err
+	}
+	v, ok := codec.decode(triples[0].Object)
+	if !ok {
+		return zero, false, fmt.Errorf("%w: %q is not a %v", ErrTypeMismatch, triples[0].Object, reflect.TypeFor[T]())
+	}
+	return v.(T), true, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestPutGetTyped(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := PutTyped(ctx, db, "alice", "age", 30); err != nil {
+		t.Fatalf("PutTyped(int) error = %v", err)
+	}
+	if err := PutTyped(ctx, db, "alice", "active", true); err != nil {
+		t.Fatalf("PutTyped(bool) error = %v", err)
+	}
+	joined := time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.FixedZone("CET", 3600))
+	if err := PutTyped(ctx, db, "alice", "joined", joined); err != nil {
+		t.Fatalf("PutTyped(time.Time) error = %v", err)
+	}
+
+	age, ok, err := GetTyped[int](ctx, db, "alice", "age")
+	if err != nil || !ok || age != 30 {
+		t.Errorf("GetTyped[int]() = %d, %v, %v, want 30, true, nil", age, ok, err)
+	}
+	active, ok, err := GetTyped[bool](ctx, db, "alice", "active")
+	if err != nil || !ok || !active {
+		t.Errorf("GetTyped[bool]() = %v, %v, %v, want true, true, nil", active, ok, err)
+	}
+	got, ok, err := GetTyped[time.Time](ctx, db, "alice", "joined")
+	if err != nil || !ok || !got.Equal(joined) {
+		t.Errorf("GetTyped[time.Time]() = %v, %v, %v, want %v", got, ok, err, joined)
+	}
+
+	// Putting again replaces the value.
+	if err := PutTyped(ctx, db, "alice", "age", 31); err != nil {
+		t.Fatalf("PutTyped(int) error = %v", err)
+	}
+	if age, _, _ := GetTyped[int](ctx, db, "alice", "age"); age != 31 {
+		t.Errorf("GetTyped[int]() after update = %d, want 31", age)
+	}
+	triples, _ := db.Get(ctx, graph.NewPattern("alice", "age", nil))
+	if len(triples) != 1 {
+		t.Errorf("alice has %d age triples after update, want 1", len(triples))
+	}
+
+	// Missing values, mismatched types and unregistered types.
+	if _, ok, err := GetTyped[int](ctx, db, "bob", "age"); ok || err != nil {
+		t.Errorf("GetTyped[int](bob) = %v, %v, want false, nil", ok, err)
+	}
+	if _, _, err := GetTyped[bool](ctx, db, "alice", "age"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("GetTyped[bool](age) error = %v, want ErrTypeMismatch", err)
+	}
+	type point struct{ X, Y int }
+	if err := PutTyped(ctx, db, "alice", "home", point{1, 2}); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("PutTyped(point) error = %v, want ErrNoCodec", err)
+	}
+}
+
+func TestPutTyped_OrderPreserving(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for i, age := range []int{42, -5, 7, 1000} {
+		if err := PutTyped(ctx, db, string(rune('a'+i)), "age", age); err != nil {
+			t.Fatalf("PutTyped() error = %v", err)
+		}
+	}
+	triples, err := db.Get(ctx, graph.NewPattern(nil, "age", nil))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	want := []int64{-5, 7, 42, 1000}
+	for i, triple := range triples {
+		if v, _ := DecodeInt(triple.Object); v != want[i] {
+			t.Errorf("Get()[%d] age = %d, want %d", i, v, want[i])
+		}
+	}
+}
+
+func TestCompositeKeySubjects(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	line := MakeKey([]byte("order::123"), []byte("line:4"))
+	other := MakeKey([]byte("order"), []byte("123::line"), []byte("4"))
+	if err := db.Put(ctx,
+		NewTriple(line, []byte("quantity"), EncodeInt(2)),
+		NewTriple(other, []byte("quantity"), EncodeInt(5)),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	triples, err := db.Get(ctx, &Pattern{Subject: Exact(line)})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(triples) != 1 {
+		t.Fatalf("Get(line) returned %d triples, want 1", len(triples))
+	}
+	parts := SplitKey(triples[0].Subject)
+	if len(parts) != 2 || string(parts[0]) != "order::123" || string(parts[1]) != "line:4" {
+		t.Errorf("SplitKey(subject) = %q, want [order::123 line:4]", parts)
+	}
+	if n, _ := DecodeInt(triples[0].Object); n != 2 {
+		t.Errorf("quantity = %d, want 2", n)
+	}
+}
@@ -25,6 +25,8 @@ package vector
 
 import (
 	"container/heap"
+	"math"
+	"reflect"
 	"sync"
 )
 
@@ -34,10 +36,18 @@ import (
 type FlatIndex struct {
 	dimensions int
 	distance   DistanceFunc
-	vectors    map[string][]float32
+	normalize  bool // set by WithAutoNormalize for Cosine or DotProduct
+	vectors    map[string]flatEntry
 	mu         sync.RWMutex
 }
 
+// flatEntry is a stored vector with its squared L2 norm, cached so cosine
+// searches only compute the query's norm.
+type flatEntry struct {
+	vector []float32
+	norm   float32
+}
+
 // FlatOption configures a FlatIndex.
 type FlatOption func(*FlatIndex)
 
@@ -49,17 +59,28 @@ func WithDistance(fn DistanceFunc) FlatOption {
 	}
 }
 
+// WithAutoNormalize normalizes vectors to unit length on Add and query
+// vectors on search, when the distance is Cosine or DotProduct. Only the
+// index's own copy is normalized; the caller's slices are left unchanged.
+// With any other distance, such as Euclidean, it has no effect.
+func WithAutoNormalize() FlatOption {
+	return func(f *FlatIndex) {
+		f.normalize = true
+	}
+}
+
 // NewFlatIndex creates a new brute-force vector index.
 // This provides exact nearest neighbor search with O(n) query time.
 func NewFlatIndex(dimensions int, opts ...FlatOption) *FlatIndex {
 	f := &FlatIndex{
 		dimensions: dimensions,
 		distance:   Cosine,
-		vectors:    make(map[string][]float32),
+		vectors:    make(map[string]flatEntry),
 	}
 	for _, opt := range opts {
 		opt(f)
 	}
+	f.normalize = f.normalize && normalizable(f.distance)
 	return f
 }
 
@@ -75,9 +96,12 @@ func (f *FlatIndex) Add(id []byte, vector []float32) error {
 	// Make a copy to avoid external modification
 	v := make([]float32, len(vector))
 	copy(v, vector)
+	if f.normalize {
+		Normalize(v)
+	}
 
 	f.mu.Lock()
-	f.vectors[string(id)] = v
+	f.vectors[string(id)] = flatEntry{vector: v, norm: squaredNorm(v)}
 	f.mu.Unlock()
 
 	return nil
@@ -101,46 +125,107 @@ func (f *FlatIndex) Get(id []byte) ([]float32, error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
-	v, exists := f.vectors[string(id)]
+	entry, exists := f.vectors[string(id)]
 	if !exists {
 		return nil, ErrNotFound
 	}
 
 	// Return a copy
-	result := make([]float32, len(v))
-	copy(result, v)
+	result := make([]float32, len(entry.vector))
+	copy(result, entry.vector)
 	return result, nil
 }
 
-// Search finds the k nearest vectors to the query.
+// Search finds the k nearest vectors to the query. Vectors at the same
+// distance are ordered by ID.
 func (f *FlatIndex) Search(query []float32, k int) ([]Match, error) {
+	return f.search(query, k, float32(math.MaxFloat32))
+}
+
+// SearchMinScore finds up to k nearest vectors with a Score of at least minScore.
+// Vectors beyond the threshold are never added to the candidate heap.
+func (f *FlatIndex) SearchMinScore(query []float32, k int, minScore float32) ([]Match, error) {
+	results, err := f.search(query, k, MaxDistanceForScore(minScore))
+	if err != nil {
+		return nil, err
+	}
+	return filterMinScore(results, minScore), nil
+}
+
+// search finds the k nearest vectors within maxDistance of the query.
+func (f *FlatIndex) search(query []float32, k int, maxDistance float32) ([]Match, error) {
 	if k <= 0 {
 		return nil, ErrInvalidK
 	}
 	if len(query) != f.dimensions {
 		return nil, ErrDimensionMismatch
 	}
+	if f.normalize {
+		query = NormalizeCopy(query)
+	}
 
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
+	return f.searchLocked(query, k, maxDistance), nil
+}
+
+// SearchBatch finds the k nearest vectors to each query, holding the read
+// lock once for the whole batch. See BatchSearcher.
+func (f *FlatIndex) SearchBatch(queries [][]float32, k int) ([][]Match, error) {
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	if err := checkBatchDimensions(queries, f.dimensions); err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	results := make([][]Match, len(queries))
+	for i, query := range queries {
+		if f.normalize {
+			query = NormalizeCopy(query)
+		}
+		results[i] = f.searchLocked(query, k, float32(math.MaxFloat32))
+	}
+	return results, nil
+}
+
+// searchLocked finds the k nearest vectors within maxDistance of the query.
+// The caller must hold f.mu and have checked k and the query's dimensions.
+func (f *FlatIndex) searchLocked(query []float32, k int, maxDistance float32) []Match {
 	if len(f.vectors) == 0 {
-		return []Match{}, nil
+		return []Match{}
 	}
 
 	// Use a max-heap to keep track of the k smallest distances
 	h := &matchHeap{}
 	heap.Init(h)
 
-	for idStr, vec := range f.vectors {
-		dist := f.distance(query, vec)
+	// For cosine, the stored vectors' norms are cached, so only the query's
+	// norm and the dot products are computed here
+	cosine := IsCosine(f.distance)
+	queryNorm := squaredNorm(query)
+
+	for idStr, entry := range f.vectors {
+		var dist float32
+		if cosine {
+			dist = 1 - cosineWithNorms(query, entry.vector, queryNorm, entry.norm)
+		} else {
+			dist = f.distance(query, entry.vector)
+		}
+		if dist > maxDistance {
+			continue
+		}
 
 		if h.Len() < k {
 			heap.Push(h, matchEntry{
 				id:       idStr,
 				distance: dist,
 			})
-		} else if dist < (*h)[0].distance {
+		} else if (matchEntry{id: idStr, distance: dist}).closerThan((*h)[0]) {
 			heap.Pop(h)
 			heap.Push(h, matchEntry{
 				id:       idStr,
@@ -160,7 +245,7 @@ func (f *FlatIndex) Search(query []float32, k int) ([]Match, error) {
 		}
 	}
 
-	return results, nil
+	return results
 }
 
 // Len returns the number of vectors in the index.
@@ -175,19 +260,70 @@ func (f *FlatIndex) Dimensions() int {
 	return f.dimensions
 }
 
+// Distance returns the distance function set with WithDistance.
+func (f *FlatIndex) Distance() DistanceFunc {
+	return f.distance
+}
+
+// EstimatedBytes returns the approximate memory used by the stored vectors.
+// Each entry accounts for its float32 data, cached norm, ID, and map overhead.
+func (f *FlatIndex) EstimatedBytes() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	total := 0
+	for id, entry := range f.vectors {
+		total += mapEntryBytes + stringHeaderBytes + len(id) + sliceHeaderBytes + len(entry.vector)*4 + 4
+	}
+	return total
+}
+
+// normalizable reports whether fn is Cosine or DotProduct, the distances
+// WithAutoNormalize applies to.
+func normalizable(fn DistanceFunc) bool {
+	return sameDistance(fn, Cosine) || sameDistance(fn, DotProduct)
+}
+
+// sameDistance reports whether a and b are the same function.
+func sameDistance(a, b DistanceFunc) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// filterMinScore drops matches whose Score is below minScore.
+// Score is recomputed by NormalizeScore, so distances just inside the bound
+// but rounding to a lower score are excluded.
+func filterMinScore(matches []Match, minScore float32) []Match {
+	filtered := matches[:0]
+	for _, m := range matches {
+		if m.Score >= minScore {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
 // matchEntry is an internal type for the heap.
 type matchEntry struct {
 	id       string
 	distance float32
 }
 
-// matchHeap is a max-heap of match entries (by distance).
+// closerThan orders entries by distance, breaking ties by ID so that
+// equal-distance results come back in a deterministic order.
+func (e matchEntry) closerThan(other matchEntry) bool {
+	if e.distance != other.distance {
+		return e.distance < other.distance
+	}
+	return e.id < other.id
+}
+
+// matchHeap is a max-heap of match entries (by distance, then ID).
 // We use a max-heap so we can efficiently remove the farthest entry
 // when we find a closer one.
 type matchHeap []matchEntry
 
 func (h matchHeap) Len() int           { return len(h) }
-func (h matchHeap) Less(i, j int) bool { return h[i].distance > h[j].distance } // Max-heap
+func (h matchHeap) Less(i, j int) bool { return h[j].closerThan(h[i]) } // Max-heap
 func (h matchHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
 
 func (h *matchHeap) Push(x any) {
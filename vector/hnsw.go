@@ -27,6 +27,8 @@ import (
 	"container/heap"
 	"math"
 	"math/rand"
+	"slices"
+	"strings"
 	"sync"
 )
 
@@ -113,6 +115,7 @@ import (
 type HNSWIndex struct {
 	dimensions int
 	distance   DistanceFunc
+	normalize  bool // set by WithHNSWAutoNormalize for Cosine or DotProduct
 
 	// HNSW parameters
 	m              int     // Number of connections per layer
@@ -180,6 +183,13 @@ func WithHNSWDistance(fn DistanceFunc) HNSWOption {
 	}
 }
 
+// WithHNSWAutoNormalize is WithAutoNormalize for the HNSW index.
+func WithHNSWAutoNormalize() HNSWOption {
+	return func(h *HNSWIndex) {
+		h.normalize = true
+	}
+}
+
 // WithSeed sets the random seed for reproducible level generation.
 func WithSeed(seed int64) HNSWOption {
 	return func(h *HNSWIndex) {
@@ -205,6 +215,7 @@ func NewHNSWIndex(dimensions int, opts ...HNSWOption) *HNSWIndex {
 	for _, opt := range opts {
 		opt(h)
 	}
+	h.normalize = h.normalize && normalizable(h.distance)
 
 	// Calculate level multiplier: 1/ln(M)
 	h.levelMult = 1.0 / math.Log(float64(h.m))
@@ -224,6 +235,9 @@ func (h *HNSWIndex) Add(id []byte, vector []float32) error {
 	// Make a copy
 	v := make([]float32, len(vector))
 	copy(v, vector)
+	if h.normalize {
+		Normalize(v)
+	}
 
 	idStr := string(id)
 
@@ -455,20 +469,68 @@ func (h *HNSWIndex) Search(query []float32, k int) ([]Match, error) {
 	return h.SearchWithEf(query, k, h.efSearch)
 }
 
+// SearchMinScore finds up to k nearest vectors with a Score of at least minScore.
+// The layer 0 beam search does not expand nodes beyond the threshold, so the
+// search stops early once the neighborhood of the query is exhausted.
+func (h *HNSWIndex) SearchMinScore(query []float32, k int, minScore float32) ([]Match, error) {
+	results, err := h.searchWithEf(query, k, h.efSearch, MaxDistanceForScore(minScore))
+	if err != nil {
+		return nil, err
+	}
+	return filterMinScore(results, minScore), nil
+}
+
 // SearchWithEf finds the k nearest vectors with a custom ef parameter.
 func (h *HNSWIndex) SearchWithEf(query []float32, k int, ef int) ([]Match, error) {
+	return h.searchWithEf(query, k, ef, float32(math.MaxFloat32))
+}
+
+// searchWithEf finds the k nearest vectors within maxDistance of the query.
+func (h *HNSWIndex) searchWithEf(query []float32, k int, ef int, maxDistance float32) ([]Match, error) {
 	if k <= 0 {
 		return nil, ErrInvalidK
 	}
 	if len(query) != h.dimensions {
 		return nil, ErrDimensionMismatch
 	}
+	if h.normalize {
+		query = NormalizeCopy(query)
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.searchLocked(query, k, ef, maxDistance), nil
+}
+
+// SearchBatch finds the k nearest vectors to each query, holding the read
+// lock once for the whole batch. See BatchSearcher.
+func (h *HNSWIndex) SearchBatch(queries [][]float32, k int) ([][]Match, error) {
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	if err := checkBatchDimensions(queries, h.dimensions); err != nil {
+		return nil, err
+	}
 
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
+	results := make([][]Match, len(queries))
+	for i, query := range queries {
+		if h.normalize {
+			query = NormalizeCopy(query)
+		}
+		results[i] = h.searchLocked(query, k, h.efSearch, float32(math.MaxFloat32))
+	}
+	return results, nil
+}
+
+// searchLocked finds the k nearest vectors within maxDistance of the query.
+// The caller must hold h.mu and have checked k and the query's dimensions.
+func (h *HNSWIndex) searchLocked(query []float32, k int, ef int, maxDistance float32) []Match {
 	if h.entryPoint == nil {
-		return []Match{}, nil
+		return []Match{}
 	}
 
 	// Start from entry point
@@ -480,7 +542,7 @@ func (h *HNSWIndex) SearchWithEf(query []float32, k int, ef int) ([]Match, error
 	}
 
 	// Search layer 0 with ef candidates
-	candidates := h.searchLayer(query, ep, max(ef, k), 0)
+	candidates := h.searchLayerBounded(query, ep, max(ef, k), 0, maxDistance)
 
 	// Return top k
 	results := make([]Match, 0, min(k, len(candidates)))
@@ -493,7 +555,7 @@ func (h *HNSWIndex) SearchWithEf(query []float32, k int, ef int) ([]Match, error
 		})
 	}
 
-	return results, nil
+	return results
 }
 
 // Len returns the number of vectors in the index.
@@ -508,6 +570,32 @@ func (h *HNSWIndex) Dimensions() int {
 	return h.dimensions
 }
 
+// Distance returns the distance function set with WithHNSWDistance.
+func (h *HNSWIndex) Distance() DistanceFunc {
+	return h.distance
+}
+
+// EstimatedBytes returns the approximate memory used by the graph.
+// In addition to vector data and IDs, this includes each node's per-level
+// friend maps, which dominate memory for low-dimensional vectors.
+func (h *HNSWIndex) EstimatedBytes() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	const nodeBytes = stringHeaderBytes + sliceHeaderBytes + pointerBytes + sliceHeaderBytes // id, vector, level, friends
+
+	total := 0
+	for id, node := range h.nodes {
+		total += mapEntryBytes + stringHeaderBytes + pointerBytes // h.nodes entry
+		total += nodeBytes + len(id) + len(node.vector)*4
+		for _, friends := range node.friends {
+			total += pointerBytes + mapEntryBytes // friend map header
+			total += len(friends) * (mapEntryBytes + stringHeaderBytes + pointerBytes)
+		}
+	}
+	return total
+}
+
 // randomLevel generates a random level for a new node.
 func (h *HNSWIndex) randomLevel() int {
 	h.rngMu.Lock()
@@ -542,6 +630,13 @@ func (h *HNSWIndex) searchLayerClosest(query []float32, entry *hnswNode, level i
 
 // searchLayer performs a beam search in a layer, returning ef closest nodes.
 func (h *HNSWIndex) searchLayer(query []float32, entry *hnswNode, ef int, level int) []*hnswNode {
+	return h.searchLayerBounded(query, entry, ef, level, float32(math.MaxFloat32))
+}
+
+// searchLayerBounded is searchLayer restricted to nodes within maxDistance.
+// Neighbors farther than maxDistance are neither returned nor expanded;
+// the entry node is always expanded.
+func (h *HNSWIndex) searchLayerBounded(query []float32, entry *hnswNode, ef int, level int, maxDistance float32) []*hnswNode {
 	visited := make(map[string]bool)
 	visited[entry.id] = true
 
@@ -584,6 +679,9 @@ func (h *HNSWIndex) searchLayer(query []float32, entry *hnswNode, ef int, level
 			visited[neighbor.id] = true
 
 			dist := h.distance(query, neighbor.vector)
+			if dist > maxDistance {
+				continue
+			}
 			farthestDist = results.dists[0]
 
 			if dist < farthestDist || results.Len() < ef {
@@ -665,7 +763,9 @@ type nodeEntry struct {
 	dist float32
 }
 
-// nodeHeap is a heap of nodes, configurable as min or max heap.
+// nodeHeap is a heap of nodes, configurable as min or max heap. Nodes are
+// ordered by distance, then by ID, so that equal-distance results come back
+// in a deterministic order.
 type nodeHeap struct {
 	nodes   []*hnswNode
 	dists   []float32
@@ -676,9 +776,12 @@ func (h *nodeHeap) Len() int { return len(h.nodes) }
 
 func (h *nodeHeap) Less(i, j int) bool {
 	if h.maxHeap {
-		return h.dists[i] > h.dists[j]
+		i, j = j, i
+	}
+	if h.dists[i] != h.dists[j] {
+		return h.dists[i] < h.dists[j]
 	}
-	return h.dists[i] < h.dists[j]
+	return h.nodes[i].id < h.nodes[j].id
 }
 
 func (h *nodeHeap) Swap(i, j int) {
@@ -733,7 +836,8 @@ type HNSWNodeData struct {
 
 // Export exports the HNSW index state for persistence.
 // The returned HNSWData can be serialized (e.g., with encoding/gob or JSON)
-// and later restored with Import.
+// and later restored with Import. Nodes and each level's friends are sorted
+// by ID, so exporting the same graph always yields identical data.
 func (h *HNSWIndex) Export() *HNSWData {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
@@ -769,10 +873,14 @@ func (h *HNSWIndex) Export() *HNSWData {
 			for friendID := range friends {
 				nodeData.Friends[level] = append(nodeData.Friends[level], friendID)
 			}
+			slices.Sort(nodeData.Friends[level])
 		}
 
 		data.Nodes = append(data.Nodes, nodeData)
 	}
+	slices.SortFunc(data.Nodes, func(a, b HNSWNodeData) int {
+		return strings.Compare(a.ID, b.ID)
+	})
 
 	return data
 }
@@ -100,6 +100,7 @@ package vector
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"math"
 )
 
@@ -129,6 +130,11 @@ type Index interface {
 	// Returns results sorted by distance (ascending).
 	Search(query []float32, k int) ([]Match, error)
 
+	// SearchMinScore finds up to k nearest vectors whose Score is at least
+	// minScore. Fewer than k results are returned when not enough vectors
+	// meet the threshold.
+	SearchMinScore(query []float32, k int, minScore float32) ([]Match, error)
+
 	// Get retrieves a vector by ID.
 	// Returns ErrNotFound if the ID doesn't exist.
 	Get(id []byte) ([]float32, error)
@@ -138,6 +144,74 @@ type Index interface {
 
 	// Dimensions returns the vector dimensionality.
 	Dimensions() int
+
+	// EstimatedBytes returns an approximation of the memory held by the index,
+	// including vector data, IDs, and bookkeeping structures.
+	EstimatedBytes() int
+}
+
+// BatchSearcher is implemented by indexes that can answer several searches
+// at once more cheaply than one Search call per query, e.g. by taking their
+// lock once for the whole batch. SearchBatch uses it when available.
+type BatchSearcher interface {
+	// SearchBatch finds the k nearest vectors to each query. results[i]
+	// holds the matches for queries[i], as Search would return them.
+	SearchBatch(queries [][]float32, k int) ([][]Match, error)
+}
+
+// DistanceReporter is implemented by indexes that report the distance
+// function they search with. FlatIndex and HNSWIndex implement it.
+type DistanceReporter interface {
+	// Distance returns the index's distance function.
+	Distance() DistanceFunc
+}
+
+// IndexDistance returns the distance function idx searches with, or Cosine
+// if idx does not implement DistanceReporter.
+func IndexDistance(idx Index) DistanceFunc {
+	if r, ok := idx.(DistanceReporter); ok {
+		return r.Distance()
+	}
+	return Cosine
+}
+
+// SearchBatch finds the k nearest vectors in idx to each query, returning
+// the results aligned with queries. Every query must have idx's
+// dimensions; otherwise nothing is searched and an error wrapping
+// ErrDimensionMismatch names the first bad query. Indexes implementing
+// BatchSearcher answer the whole batch at once; others are searched one
+// query at a time.
+func SearchBatch(idx Index, queries [][]float32, k int) ([][]Match, error) {
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	if err := checkBatchDimensions(queries, idx.Dimensions()); err != nil {
+		return nil, err
+	}
+	if bs, ok := idx.(BatchSearcher); ok {
+		return bs.SearchBatch(queries, k)
+	}
+
+	results := make([][]Match, len(queries))
+	for i, query := range queries {
+		matches, err := idx.Search(query, k)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		results[i] = matches
+	}
+	return results, nil
+}
+
+// checkBatchDimensions returns an error wrapping ErrDimensionMismatch for
+// the first query without the given dimensions.
+func checkBatchDimensions(queries [][]float32, dimensions int) error {
+	for i, query := range queries {
+		if len(query) != dimensions {
+			return fmt.Errorf("%w: query %d has %d dimensions, want %d", ErrDimensionMismatch, i, len(query), dimensions)
+		}
+	}
+	return nil
 }
 
 // Match represents a search result with ID and similarity score.
@@ -154,6 +228,15 @@ type Match struct {
 	Distance float32
 }
 
+// Approximate sizes used by EstimatedBytes implementations. These assume a
+// 64-bit platform and are intended for monitoring, not exact accounting.
+const (
+	sliceHeaderBytes  = 24 // pointer + len + cap
+	stringHeaderBytes = 16 // pointer + len
+	mapEntryBytes     = 48 // amortized bucket, tophash, and load-factor slack per entry
+	pointerBytes      = 8
+)
+
 // NormalizeScore converts a cosine distance to a normalized [0, 1] score.
 // Cosine distance is in range [0, 2], so score = (2 - distance) / 2 = 1 - distance/2.
 func NormalizeScore(distance float32) float32 {
@@ -167,6 +250,20 @@ func NormalizeScore(distance float32) float32 {
 	return 1 - distance/2
 }
 
+// MaxDistanceForScore returns the largest distance whose NormalizeScore is
+// still at least minScore. It is the inverse of NormalizeScore. A minScore
+// of 0 or less imposes no bound, since NormalizeScore clamps every distance
+// above 2 to a score of 0.
+func MaxDistanceForScore(minScore float32) float32 {
+	if minScore <= 0 {
+		return math.MaxFloat32
+	}
+	if minScore >= 1 {
+		return 0
+	}
+	return 2 * (1 - minScore)
+}
+
 // DistanceFunc computes the distance between two vectors.
 // Lower values indicate more similar vectors.
 type DistanceFunc func(a, b []float32) float32
@@ -177,6 +274,11 @@ func Cosine(a, b []float32) float32 {
 	return 1 - CosineSimilarity(a, b)
 }
 
+// IsCosine reports whether fn is the package's Cosine distance.
+func IsCosine(fn DistanceFunc) bool {
+	return sameDistance(fn, Cosine)
+}
+
 // CosineSimilarity computes the cosine similarity between two vectors.
 // Returns a value in range [-1, 1], where 1 means identical direction.
 func CosineSimilarity(a, b []float32) float32 {
@@ -191,6 +293,28 @@ func CosineSimilarity(a, b []float32) float32 {
 		normB += b[i] * b[i]
 	}
 
+	return similarityFromDot(dot, normA, normB)
+}
+
+// cosineWithNorms is CosineSimilarity for vectors whose squared norms are
+// already known, computing only the dot product. The result is identical to
+// CosineSimilarity(a, b).
+func cosineWithNorms(a, b []float32, normA, normB float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+
+	return similarityFromDot(dot, normA, normB)
+}
+
+// similarityFromDot finishes a cosine similarity from the dot product and
+// squared norms.
+func similarityFromDot(dot, normA, normB float32) float32 {
 	if normA == 0 || normB == 0 {
 		return 0
 	}
@@ -198,6 +322,16 @@ func CosineSimilarity(a, b []float32) float32 {
 	return dot / float32(math.Sqrt(float64(normA)*float64(normB)))
 }
 
+// squaredNorm returns the sum of squares of v, accumulated in the same order
+// as CosineSimilarity so cached norms give identical results.
+func squaredNorm(v []float32) float32 {
+	var norm float32
+	for _, x := range v {
+		norm += x * x
+	}
+	return norm
+}
+
 // Euclidean computes the squared Euclidean distance.
 // Using squared distance avoids the sqrt for performance.
 func Euclidean(a, b []float32) float32 {
@@ -311,6 +445,28 @@ func MakeID(idType IDType, parts ...[]byte) []byte {
 	return result
 }
 
+// IDCodec builds and parses the vector IDs a DB stores for graph elements.
+// MakeID called with no parts must return a prefix shared by every ID of
+// that type, so that deleting vectors by type keeps working. ParseID must
+// invert MakeID; IDs it does not recognise should parse as IDTypeCustom.
+type IDCodec interface {
+	MakeID(idType IDType, parts ...[]byte) []byte
+	ParseID(id []byte) (IDType, [][]byte)
+}
+
+// DefaultIDCodec is the IDCodec built on MakeID and ParseID.
+var DefaultIDCodec IDCodec = defaultIDCodec{}
+
+type defaultIDCodec struct{}
+
+func (defaultIDCodec) MakeID(idType IDType, parts ...[]byte) []byte {
+	return MakeID(idType, parts...)
+}
+
+func (defaultIDCodec) ParseID(id []byte) (IDType, [][]byte) {
+	return ParseID(id)
+}
+
 // ParseID extracts the type and value parts from a vector ID.
 // Handles both new length-prefixed format and legacy colon-separated format.
 func ParseID(id []byte) (IDType, [][]byte) {
@@ -25,8 +25,11 @@ package vector
 
 import (
 	"bytes"
+	"encoding/gob"
+	"fmt"
 	"math"
 	"math/rand"
+	"sort"
 	"sync"
 	"testing"
 )
@@ -643,6 +646,91 @@ func TestFlatIndexWithDistance(t *testing.T) {
 	}
 }
 
+// autoNormalizeIndexes builds a flat and an HNSW index with auto-normalize
+// and the given distance.
+func autoNormalizeIndexes(distance DistanceFunc) map[string]Index {
+	return map[string]Index{
+		"flat": NewFlatIndex(3, WithDistance(distance), WithAutoNormalize()),
+		"hnsw": NewHNSWIndex(3, WithSeed(42), WithM(4), WithEfConstruction(50),
+			WithHNSWDistance(distance), WithHNSWAutoNormalize()),
+	}
+}
+
+func TestAutoNormalize(t *testing.T) {
+	vectors := map[string][]float32{
+		"v1": {3, 4, 0},
+		"v2": {0, 0, 10},
+		"v3": {0.5, 0.5, 0.5},
+	}
+	query := []float32{2, 1, 0}
+
+	for _, tc := range []struct {
+		name     string
+		distance DistanceFunc
+	}{
+		{"Cosine", Cosine},
+		{"DotProduct", DotProduct},
+	} {
+		raw := autoNormalizeIndexes(tc.distance)
+		normalized := autoNormalizeIndexes(tc.distance)
+		for kind := range raw {
+			t.Run(tc.name+"/"+kind, func(t *testing.T) {
+				for id, v := range vectors {
+					raw[kind].Add([]byte(id), v)
+					normalized[kind].Add([]byte(id), NormalizeCopy(v))
+				}
+
+				got, err := raw[kind].Search(query, 3)
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+				want, err := normalized[kind].Search(NormalizeCopy(query), 3)
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+				if len(got) != len(want) {
+					t.Fatalf("Search() returned %d results, want %d", len(got), len(want))
+				}
+				for i := range got {
+					if string(got[i].ID) != string(want[i].ID) || math.Abs(float64(got[i].Score-want[i].Score)) > 1e-6 {
+						t.Errorf("result %d = %s (%v), want %s (%v)", i, got[i].ID, got[i].Score, want[i].ID, want[i].Score)
+					}
+				}
+
+				// The caller's slices are not modified
+				if query[0] != 2 || vectors["v1"][0] != 3 {
+					t.Errorf("caller's vectors modified: query = %v, v1 = %v", query, vectors["v1"])
+				}
+			})
+		}
+	}
+}
+
+func TestAutoNormalizeEuclidean(t *testing.T) {
+	for kind, idx := range autoNormalizeIndexes(Euclidean) {
+		t.Run(kind, func(t *testing.T) {
+			idx.Add([]byte("v1"), []float32{3, 4, 0})
+			idx.Add([]byte("v2"), []float32{1, 0, 0})
+
+			got, err := idx.Get([]byte("v1"))
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got[0] != 3 || got[1] != 4 {
+				t.Errorf("Get() = %v, want the vector unnormalized", got)
+			}
+
+			results, err := idx.Search([]float32{0, 0, 0}, 2)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(results) != 2 || string(results[0].ID) != "v2" || results[1].Distance != 25 {
+				t.Errorf("Search() = %v, want v2 then v1 at distance 25", results)
+			}
+		})
+	}
+}
+
 func TestFlatIndexBasicOperations(t *testing.T) {
 	idx := NewFlatIndex(3)
 
@@ -686,6 +774,125 @@ func TestFlatIndexBasicOperations(t *testing.T) {
 	}
 }
 
+func TestIndexEstimatedBytes(t *testing.T) {
+	indexes := map[string]Index{
+		"flat": NewFlatIndex(8),
+		"hnsw": NewHNSWIndex(8, WithSeed(42)),
+	}
+
+	for name, idx := range indexes {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+
+			if got := idx.EstimatedBytes(); got != 0 {
+				t.Errorf("EstimatedBytes() on empty index = %d, want 0", got)
+			}
+
+			prev := 0
+			for i := 0; i < 20; i++ {
+				if err := idx.Add([]byte{byte('a' + i)}, randomVector(8, rng)); err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+				got := idx.EstimatedBytes()
+				if got <= prev {
+					t.Fatalf("EstimatedBytes() after Add #%d = %d, want > %d", i, got, prev)
+				}
+				prev = got
+			}
+
+			// Must account for at least the raw vector data
+			if prev < 20*8*4 {
+				t.Errorf("EstimatedBytes() = %d, want >= %d", prev, 20*8*4)
+			}
+
+			for i := 0; i < 20; i++ {
+				if err := idx.Delete([]byte{byte('a' + i)}); err != nil {
+					t.Fatalf("Delete() error = %v", err)
+				}
+				got := idx.EstimatedBytes()
+				if got >= prev {
+					t.Fatalf("EstimatedBytes() after Delete #%d = %d, want < %d", i, got, prev)
+				}
+				prev = got
+			}
+
+			if prev != 0 {
+				t.Errorf("EstimatedBytes() after deleting everything = %d, want 0", prev)
+			}
+		})
+	}
+}
+
+func TestMaxDistanceForScore(t *testing.T) {
+	for _, score := range []float32{0, 0.25, 0.5, 0.8, 1} {
+		if got := NormalizeScore(MaxDistanceForScore(score)); got != score {
+			t.Errorf("NormalizeScore(MaxDistanceForScore(%v)) = %v", score, got)
+		}
+	}
+	if got := MaxDistanceForScore(-1); got != math.MaxFloat32 {
+		t.Errorf("MaxDistanceForScore(-1) = %v, want math.MaxFloat32", got)
+	}
+	if got := MaxDistanceForScore(2); got != 0 {
+		t.Errorf("MaxDistanceForScore(2) = %v, want 0", got)
+	}
+
+	// Euclidean distances above 2 still meet a zero minScore.
+	idx := NewFlatIndex(2, WithDistance(Euclidean))
+	idx.Add([]byte("far"), []float32{10, 10})
+	results, err := idx.SearchMinScore([]float32{0, 0}, 1, 0)
+	if err != nil {
+		t.Fatalf("SearchMinScore() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("SearchMinScore(minScore=0) returned %d results, want 1", len(results))
+	}
+}
+
+func TestIndexSearchMinScore(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	flat := NewFlatIndex(16)
+	hnsw := NewHNSWIndex(16, WithSeed(11))
+
+	query := randomNormalizedVector(16, rng)
+	for i := 0; i < 300; i++ {
+		v := randomNormalizedVector(16, rng)
+		id := []byte{byte(i >> 8), byte(i)}
+		flat.Add(id, v)
+		hnsw.Add(id, v)
+	}
+
+	want, err := flat.SearchMinScore(query, 300, 0.7)
+	if err != nil {
+		t.Fatalf("FlatIndex.SearchMinScore() error = %v", err)
+	}
+	all, _ := flat.Search(query, 300)
+	expected := 0
+	for _, m := range all {
+		if m.Score >= 0.7 {
+			expected++
+		}
+	}
+	if len(want) != expected {
+		t.Fatalf("FlatIndex.SearchMinScore() returned %d, want %d", len(want), expected)
+	}
+
+	got, err := hnsw.SearchMinScore(query, 300, 0.7)
+	if err != nil {
+		t.Fatalf("HNSWIndex.SearchMinScore() error = %v", err)
+	}
+	for _, m := range got {
+		if m.Score < 0.7 {
+			t.Errorf("HNSW result with score %v below threshold", m.Score)
+		}
+	}
+	if len(got) > len(want) {
+		t.Errorf("HNSW returned %d results, exact search only has %d", len(got), len(want))
+	}
+	if len(want) > 0 && len(got) == 0 {
+		t.Error("HNSW returned no results above threshold")
+	}
+}
+
 func TestFlatIndexErrors(t *testing.T) {
 	idx := NewFlatIndex(3)
 
@@ -790,6 +997,37 @@ func TestFlatIndexSearchKLargerThanN(t *testing.T) {
 	}
 }
 
+func TestSearchTieBreakByID(t *testing.T) {
+	for name, idx := range map[string]Index{
+		"flat": NewFlatIndex(3),
+		"hnsw": NewHNSWIndex(3, WithSeed(7)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, id := range []string{"e", "b", "d", "a", "c"} {
+				if err := idx.Add([]byte(id), []float32{1, 0, 0}); err != nil {
+					t.Fatalf("Add(%s) error = %v", id, err)
+				}
+			}
+
+			for k, want := range map[int]string{3: "abc", 5: "abcde"} {
+				for run := 0; run < 10; run++ {
+					results, err := idx.Search([]float32{1, 0, 0}, k)
+					if err != nil {
+						t.Fatalf("Search() error = %v", err)
+					}
+					var got string
+					for _, m := range results {
+						got += string(m.ID)
+					}
+					if got != want {
+						t.Fatalf("Search(k=%d) run %d order = %q, want %q", k, run, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestFlatIndexUpdate(t *testing.T) {
 	idx := NewFlatIndex(3)
 
@@ -806,6 +1044,90 @@ func TestFlatIndexUpdate(t *testing.T) {
 	}
 }
 
+// uncachedCosine is Cosine behind a different function value, so FlatIndex
+// cannot use its cached norms.
+func uncachedCosine(a, b []float32) float32 {
+	return Cosine(a, b)
+}
+
+func TestFlatIndexCachedNorms(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	cached := NewFlatIndex(32)
+	uncached := NewFlatIndex(32, WithDistance(uncachedCosine))
+	for i := 0; i < 500; i++ {
+		vec := randomVector(32, rng)
+		if i%50 == 0 {
+			vec = make([]float32, 32) // zero vectors have no direction
+		}
+		id := []byte(fmt.Sprintf("v%03d", i))
+		cached.Add(id, vec)
+		uncached.Add(id, vec)
+	}
+
+	// Updates and deletes keep the cached norms in step
+	for i := 0; i < 500; i += 7 {
+		id := []byte(fmt.Sprintf("v%03d", i))
+		vec := randomVector(32, rng)
+		cached.Add(id, vec)
+		uncached.Add(id, vec)
+	}
+	for i := 3; i < 500; i += 11 {
+		id := []byte(fmt.Sprintf("v%03d", i))
+		cached.Delete(id)
+		uncached.Delete(id)
+	}
+
+	for q := 0; q < 20; q++ {
+		query := randomVector(32, rng)
+		got, err := cached.Search(query, 25)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		want, err := uncached.Search(query, 25)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Search() returned %d matches, want %d", len(got), len(want))
+		}
+		for i := range got {
+			if !bytes.Equal(got[i].ID, want[i].ID) || got[i].Distance != want[i].Distance {
+				t.Fatalf("match %d = %s (%v), want %s (%v)", i, got[i].ID, got[i].Distance, want[i].ID, want[i].Distance)
+			}
+		}
+	}
+}
+
+func TestFlatIndexCachedNormsFaster(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	cached := NewFlatIndex(128)
+	uncached := NewFlatIndex(128, WithDistance(uncachedCosine))
+	for i := 0; i < 10000; i++ {
+		vec := randomVector(128, rng)
+		id := []byte(fmt.Sprintf("v%05d", i))
+		cached.Add(id, vec)
+		uncached.Add(id, vec)
+	}
+	query := randomVector(128, rng)
+
+	bench := func(idx *FlatIndex) testing.BenchmarkResult {
+		return testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				idx.Search(query, 10)
+			}
+		})
+	}
+	withCache, withoutCache := bench(cached), bench(uncached)
+	t.Logf("10k FlatIndex search: cached norms %v/op, uncached %v/op", withCache.NsPerOp(), withoutCache.NsPerOp())
+	if withCache.NsPerOp() >= withoutCache.NsPerOp() {
+		t.Errorf("search with cached norms took %dns/op, not faster than %dns/op", withCache.NsPerOp(), withoutCache.NsPerOp())
+	}
+}
+
 func TestFlatIndexConcurrency(t *testing.T) {
 	idx := NewFlatIndex(32)
 
@@ -1457,6 +1779,59 @@ func TestHNSWIndexExportImport(t *testing.T) {
 	}
 }
 
+// TestHNSWIndexExportDeterministic tests that Export emits nodes and
+// friends in sorted order, so repeated exports serialize identically.
+func TestHNSWIndexExportDeterministic(t *testing.T) {
+	idx := NewHNSWIndex(4, WithSeed(7), WithM(4), WithEfConstruction(50))
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 50; i++ {
+		vec := make([]float32, 4)
+		for j := range vec {
+			vec[j] = rng.Float32()
+		}
+		idx.Add([]byte{byte('a' + i%26), byte('0' + i/26)}, vec)
+	}
+
+	encode := func(data *HNSWData) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	data := idx.Export()
+	for i := 1; i < len(data.Nodes); i++ {
+		if data.Nodes[i-1].ID >= data.Nodes[i].ID {
+			t.Fatalf("nodes not sorted: %q before %q", data.Nodes[i-1].ID, data.Nodes[i].ID)
+		}
+	}
+	for _, node := range data.Nodes {
+		for level, friends := range node.Friends {
+			if !sort.StringsAreSorted(friends) {
+				t.Fatalf("node %q level %d friends not sorted: %v", node.ID, level, friends)
+			}
+		}
+	}
+
+	want := encode(data)
+	for i := 0; i < 5; i++ {
+		if got := encode(idx.Export()); !bytes.Equal(got, want) {
+			t.Fatal("repeated Export() produced different bytes")
+		}
+	}
+
+	// An imported index exports the same bytes as its source.
+	idx2 := NewHNSWIndex(4)
+	if err := idx2.Import(data); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if got := encode(idx2.Export()); !bytes.Equal(got, want) {
+		t.Error("Export() after Import() produced different bytes")
+	}
+}
+
 // TestHNSWIndexExportImportSearchQuality tests that imported index
 // has the same search quality as the original.
 func TestHNSWIndexExportImportSearchQuality(t *testing.T) {
@@ -1716,3 +2091,18 @@ func BenchmarkCosineSimilarity(b *testing.B) {
 		CosineSimilarity(a, vecB)
 	}
 }
+
+func TestIndexDistance(t *testing.T) {
+	if !IsCosine(IndexDistance(NewFlatIndex(2))) {
+		t.Error("IndexDistance(FlatIndex) should default to Cosine")
+	}
+	if !sameDistance(IndexDistance(NewFlatIndex(2, WithDistance(Euclidean))), Euclidean) {
+		t.Error("IndexDistance(FlatIndex) should report WithDistance")
+	}
+	if !sameDistance(IndexDistance(NewHNSWIndex(2, WithHNSWDistance(DotProduct))), DotProduct) {
+		t.Error("IndexDistance(HNSWIndex) should report WithHNSWDistance")
+	}
+	if IsCosine(Euclidean) {
+		t.Error("IsCosine(Euclidean) = true, want false")
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/benbenbenbenbenben/levelgraph/vector"
+)
+
+// VectorEncoding is the on-disk format of the vectors SetVector persists.
+// It never affects the in-memory index, which always holds float32 values.
+type VectorEncoding int
+
+const (
+	// VectorFloat32 stores each value as a float32, 4 bytes per dimension,
+	// without loss. It is the default.
+	VectorFloat32 VectorEncoding = iota
+	// VectorFloat16 stores each value as an IEEE 754 half-precision float,
+	// 2 bytes per dimension. Values keep about 3 significant decimal
+	// digits; magnitudes above 65504 become infinite and below about 6e-8
+	// become zero.
+	VectorFloat16
+	// VectorInt8 stores each value as a signed byte scaled by the vector's
+	// largest magnitude, 1 byte per dimension plus a 4 byte scale. Each
+	// value is off by at most half a step of that magnitude / 127, so small
+	// components of a vector with one large component lose the most.
+	VectorInt8
+)
+
+// Encoded vector values start with a byte holding the encoding in its high
+// nibble and the number of trailing padding bytes in its low nibble. The
+// padding makes the value one longer than a multiple of 4, so it can never
+// be mistaken for a float32 value, whose length is a multiple of 4.
+const (
+	vectorFloat16Marker = 0x10
+	vectorInt8Marker    = 0x20
+)
+
+// encodeVectorValue returns the stored form of vec in encoding enc.
+func encodeVectorValue(vec []float32, enc VectorEncoding) []byte {
+	var marker byte
+	var payload []byte
+	switch enc {
+	case VectorFloat16:
+		marker = vectorFloat16Marker
+		payload = make([]byte, 0, len(vec)*2+2)
+		for _, v := range vec {
+			payload = binary.LittleEndian.AppendUint16(payload, float32ToFloat16(v))
+		}
+	case VectorInt8:
+		marker = vectorInt8Marker
+		var maxAbs float32
+		for _, v := range vec {
+			maxAbs = max(maxAbs, float32(math.Abs(float64(v))))
+		}
+		scale := maxAbs / 127
+		payload = make([]byte, 4, 4+len(vec)+3)
+		binary.LittleEndian.PutUint32(payload, math.Float32bits(scale))
+		for _, v := range vec {
+			var q float64
+			if scale > 0 {
+				q = math.Round(float64(v / scale))
+			}
+			payload = append(payload, byte(int8(max(-127, min(127, q)))))
+		}
+	default:
+		return vector.VectorToBytes(vec)
+	}
+
+	pad := (4 - len(payload)%4) % 4
+	value := make([]byte, 0, 1+len(payload)+pad)
+	value = append(value, marker|byte(pad))
+	value = append(value, payload...)
+	return append(value, make([]byte, pad)...)
+}
+
+// decodeVectorValue decodes a value written by encodeVectorValue in any
+// encoding, so vectors stay readable whatever the current options. It
+// returns nil for a malformed value.
+func decodeVectorValue(value []byte) []float32 {
+	if len(value)%4 != 1 {
+		return vector.BytesToVector(value)
+	}
+	pad := int(value[0] & 0x0f)
+	if pad > 3 {
+		return nil
+	}
+	payload := value[1 : len(value)-pad]
+
+	switch value[0] & 0xf0 {
+	case vectorFloat16Marker:
+		if len(payload)%2 != 0 {
+			return nil
+		}
+		vec := make([]float32, len(payload)/2)
+		for i := range vec {
+			vec[i] = float16ToFloat32(binary.LittleEndian.Uint16(payload[i*2:]))
+		}
+		return vec
+	case vectorInt8Marker:
+		if len(payload) < 4 {
+			return nil
+		}
+		scale := math.Float32frombits(binary.LittleEndian.Uint32(payload))
+		vec := make([]float32, len(payload)-4)
+		for i, q := range payload[4:] {
+			vec[i] = float32(int8(q)) * scale
+		}
+		return vec
+	}
+	return nil
+}
+
+// float32ToFloat16 converts f to the nearest IEEE 754 half-precision value,
+// rounding ties to even.
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp == 0xff: // Inf or NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp-127+15 >= 0x1f: // Too large: infinity
+		return sign | 0x7c00
+	case exp-127+15 <= 0: // Subnormal or zero
+		shift := uint32(14 - (exp - 127 + 15))
+		if shift > 24 {
+			return sign
+		}
+		mant |= 0x800000
+		half := mant >> shift
+		rem := mant & (1<<shift - 1)
+		midpoint := uint32(1) << (shift - 1)
+		if rem > midpoint || (rem == midpoint && half&1 == 1) {
+			half++
+		}
+		return sign | uint16(half)
+	}
+
+	half := uint32(exp-127+15)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && half&1 == 1) {
+		half++ // may carry into the exponent, up to infinity
+	}
+	return sign | uint16(half)
+}
+
+// float16ToFloat32 converts an IEEE 754 half-precision value to float32,
+// which represents it exactly.
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0x1f: // Inf or NaN
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Subnormal: normalize it for float32
+		exp = 127 - 15 + 1
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		return math.Float32frombits(sign | exp<<23 | (mant&0x3ff)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
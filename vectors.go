@@ -24,9 +24,15 @@
 package levelgraph
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/gob"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"time"
 
 	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
 	"github.com/benbenbenbenbenben/levelgraph/vector"
@@ -34,6 +40,9 @@ import (
 
 const defaultAsyncEmbedBufferSize = 100
 
+// reEmbedChunkSize is the number of triples ReEmbedAll embeds at a time.
+const reEmbedChunkSize = 1000
+
 var (
 	// ErrVectorsDisabled is returned when vector operations are called without
 	// a configured vector index.
@@ -46,11 +55,27 @@ var (
 	// ErrVectorDimensionMismatch is returned when loading a persisted vector
 	// whose dimensions don't match the configured index dimensions.
 	ErrVectorDimensionMismatch = errors.New("levelgraph: persisted vector dimensions do not match index dimensions")
+
+	// ErrVectorSnapshotUnsupported is returned when snapshot persistence is
+	// requested for a vector index that cannot export its structure.
+	ErrVectorSnapshotUnsupported = errors.New("levelgraph: vector index snapshots require an HNSW index")
+
+	// ErrInvalidMultiMode is returned by SearchVectorsMulti for an unknown MultiMode.
+	ErrInvalidMultiMode = errors.New("levelgraph: invalid multi mode")
+
+	// ErrInvalidOffset is returned by SearchVectorsWindow for a negative offset.
+	ErrInvalidOffset = errors.New("levelgraph: offset must not be negative")
 )
 
 // Key prefixes for vector storage in KVStore
 var (
 	vectorPrefix = []byte("vector::")
+
+	// vectorSnapshotKey holds the last full HNSW export.
+	vectorSnapshotKey = []byte("vector_snapshot::hnsw")
+
+	// vectorDeltaPrefix is the prefix for delta records written since the snapshot.
+	vectorDeltaPrefix = []byte("vector_delta::")
 )
 
 // VectorMatch represents a vector search result with graph context.
@@ -65,11 +90,17 @@ type VectorMatch struct {
 	IDType vector.IDType
 	// Parts contains the parsed ID components.
 	Parts [][]byte
+	// Triples holds the triples the matched value appears in. It is only
+	// filled by SearchSimilarObjectsWithTriples and
+	// SearchSimilarSubjectsWithTriples.
+	Triples []*graph.Triple
 }
 
 // SetVector associates a vector embedding with an ID.
 // The ID can be created using vector.MakeID to associate vectors with
 // graph elements (subjects, objects, predicates, triples, or facets).
+// With a custom WithVectorIDCodec, build IDs with that codec's MakeID
+// instead, so they match the IDs the DB builds itself.
 //
 // Example:
 //
@@ -87,6 +118,10 @@ func (db *DB) SetVector(ctx context.Context, id []byte, vec []float32) error {
 		return fmt.Errorf("levelgraph: %w", ErrClosed)
 	}
 
+	if db.options.ReadOnly {
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
 	if db.options.VectorIndex == nil {
 		return ErrVectorsDisabled
 	}
@@ -97,18 +132,8 @@ func (db *DB) SetVector(ctx context.Context, id []byte, vec []float32) error {
 	default:
 	}
 
-	// Add to vector index
-	if err := db.options.VectorIndex.Add(id, vec); err != nil {
-		return fmt.Errorf("levelgraph: set vector: %w", err)
-	}
-
-	// Persist to KVStore for durability
-	key := makeVectorKey(id)
-	value := vector.VectorToBytes(vec)
-	if err := db.store.Put(key, value, nil); err != nil {
-		// Try to rollback from index
-		db.options.VectorIndex.Delete(id)
-		return fmt.Errorf("levelgraph: persist vector: %w", err)
+	if err := db.addVector(id, vec); err != nil {
+		return fmt.Errorf("levelgraph: %w", err)
 	}
 
 	if db.options.Logger != nil {
@@ -149,6 +174,10 @@ func (db *DB) DeleteVector(ctx context.Context, id []byte) error {
 		return fmt.Errorf("levelgraph: %w", ErrClosed)
 	}
 
+	if db.options.ReadOnly {
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
 	if db.options.VectorIndex == nil {
 		return ErrVectorsDisabled
 	}
@@ -159,15 +188,8 @@ func (db *DB) DeleteVector(ctx context.Context, id []byte) error {
 	default:
 	}
 
-	// Delete from index
-	if err := db.options.VectorIndex.Delete(id); err != nil {
-		return fmt.Errorf("levelgraph: delete vector: %w", err)
-	}
-
-	// Delete from KVStore
-	key := makeVectorKey(id)
-	if err := db.store.Delete(key, nil); err != nil {
-		return fmt.Errorf("levelgraph: delete persisted vector: %w", err)
+	if err := db.removeVector(id, false); err != nil {
+		return fmt.Errorf("levelgraph: %w", err)
 	}
 
 	if db.options.Logger != nil {
@@ -188,7 +210,12 @@ func (db *DB) DeleteVector(ctx context.Context, id []byte) error {
 //	for _, r := range results {
 //	    fmt.Printf("%s: %.3f\n", r.Parts[0], r.Score)
 //	}
-func (db *DB) SearchVectors(ctx context.Context, query []float32, k int) ([]VectorMatch, error) {
+func (db *DB) SearchVectors(ctx context.Context, query []float32, k int) (result []VectorMatch, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search_vectors", start, len(result), err) }()
+	}
+
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
@@ -208,12 +235,307 @@ func (db *DB) SearchVectors(ctx context.Context, query []float32, k int) ([]Vect
 
 	matches, err := db.options.VectorIndex.Search(query, k)
 	if err != nil {
-		return nil, fmt.Errorf("levelgraph: search vectors: %w", err)
+		return nil, vectorError("levelgraph: search vectors", err)
+	}
+
+	results := db.toVectorMatches(matches)
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("search vectors", "k", k, "results", len(results))
+	}
+
+	return results, nil
+}
+
+// SearchVectorsWindow returns the window [offset, offset+k) of the vectors
+// nearest to query: it searches for the top offset+k matches and drops the
+// first offset, so successive windows page through one ranking. With a flat
+// index the windows are exact. With HNSW the search is approximate and a
+// larger offset+k widens it, so a later window may rank a vector that an
+// earlier, smaller search missed.
+//
+// Example:
+//
+//	// Second page of 20 "more like this" results
+//	page, _ := db.SearchVectorsWindow(ctx, queryVec, 20, 20)
+func (db *DB) SearchVectorsWindow(ctx context.Context, query []float32, offset, k int) (result []VectorMatch, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search_vectors_window", start, len(result), err) }()
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.VectorIndex == nil {
+		return nil, ErrVectorsDisabled
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidOffset, offset)
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("levelgraph: search vectors: %w", vector.ErrInvalidK)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	matches, err := db.options.VectorIndex.Search(query, offset+k)
+	if err != nil {
+		return nil, vectorError("levelgraph: search vectors", err)
+	}
+	matches = matches[min(offset, len(matches)):]
+
+	results := db.toVectorMatches(matches)
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("search vectors window", "offset", offset, "k", k, "results", len(results))
+	}
+
+	return results, nil
+}
+
+// SearchVectorsBatch runs SearchVectors for each of queries in one call and
+// returns the results aligned with queries: result[i] holds the k nearest
+// vectors to queries[i]. Indexes implementing vector.BatchSearcher, such as
+// the built-in flat and HNSW indexes, take their lock once for the whole
+// batch. All queries must match the index's dimensions; otherwise nothing
+// is searched and an error wrapping ErrDimensionMismatch is returned.
+//
+// Example:
+//
+//	results, _ := db.SearchVectorsBatch(ctx, [][]float32{q1, q2, q3}, 10)
+//	for i, matches := range results {
+//	    // matches are the neighbors of query i
+//	}
+func (db *DB) SearchVectorsBatch(ctx context.Context, queries [][]float32, k int) (result [][]VectorMatch, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search_vectors_batch", start, len(result), err) }()
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.VectorIndex == nil {
+		return nil, ErrVectorsDisabled
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	batches, err := vector.SearchBatch(db.options.VectorIndex, queries, k)
+	if err != nil {
+		return nil, vectorError("levelgraph: search vectors batch", err)
+	}
+
+	results := make([][]VectorMatch, len(batches))
+	for i, matches := range batches {
+		results[i] = db.toVectorMatches(matches)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("search vectors batch", "queries", len(queries), "k", k)
+	}
+
+	return results, nil
+}
+
+// SearchVectorsMinScore finds up to k vectors whose similarity score is at
+// least minScore. Unlike SearchVectors, fewer than k results are returned
+// when the remaining neighbors are too dissimilar. Scores follow
+// vector.NormalizeScore, so minScore is in range [0, 1].
+//
+// Example:
+//
+//	// Only close neighbors, at most 10
+//	results, _ := db.SearchVectorsMinScore(ctx, queryVec, 10, 0.8)
+func (db *DB) SearchVectorsMinScore(ctx context.Context, query []float32, k int, minScore float32) (result []VectorMatch, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search_vectors_min_score", start, len(result), err) }()
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.VectorIndex == nil {
+		return nil, ErrVectorsDisabled
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	matches, err := db.options.VectorIndex.SearchMinScore(query, k, minScore)
+	if err != nil {
+		return nil, vectorError("levelgraph: search vectors", err)
+	}
+
+	results := db.toVectorMatches(matches)
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("search vectors", "k", k, "min_score", minScore, "results", len(results))
+	}
+
+	return results, nil
+}
+
+// MultiMode specifies how SearchVectorsMulti combines several query vectors.
+type MultiMode int
+
+const (
+	// MultiModeCentroid averages the queries and searches once. With a Cosine
+	// index the queries are unit-normalized before averaging. Use it to find
+	// items similar to the examples as a group.
+	MultiModeCentroid MultiMode = iota
+	// MultiModeAnyOf searches each query separately and merges the results,
+	// keeping each vector's best score. Use it to find items similar to any example.
+	MultiModeAnyOf
+)
+
+// SearchVectorsMulti finds the k vectors most similar to a set of example
+// vectors, combined according to mode. All queries must match the index dimensions.
+//
+// Example:
+//
+//	// Recommend items like the ones a user has liked
+//	results, _ := db.SearchVectorsMulti(ctx, likedVecs, 10, levelgraph.MultiModeCentroid)
+func (db *DB) SearchVectorsMulti(ctx context.Context, queries [][]float32, k int, mode MultiMode) (result []VectorMatch, err error) {
+	if db.options.LogHook != nil {
+		start := time.Now()
+		defer func() { db.emitLogEvent("search_vectors_multi", start, len(result), err) }()
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.VectorIndex == nil {
+		return nil, ErrVectorsDisabled
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("levelgraph: search vectors: %w", vector.ErrEmptyVector)
+	}
+	dims := db.options.VectorIndex.Dimensions()
+	for i, q := range queries {
+		if len(q) != dims {
+			return nil, fmt.Errorf("levelgraph: search vectors: query %d has %d dimensions, index expects %d: %w: %w",
+				i, len(q), dims, ErrDimensionMismatch, vector.ErrDimensionMismatch)
+		}
+	}
+
+	var matches []vector.Match
+	switch mode {
+	case MultiModeCentroid:
+		// Cosine ignores magnitude, so each query is normalized first to
+		// weigh the examples equally; other distances use the raw vectors.
+		cosine := vector.IsCosine(vector.IndexDistance(db.options.VectorIndex))
+		centroid := make([]float32, dims)
+		for _, q := range queries {
+			if cosine {
+				q = vector.NormalizeCopy(q)
+			}
+			for i, val := range q {
+				centroid[i] += val
+			}
+		}
+		for i := range centroid {
+			centroid[i] /= float32(len(queries))
+		}
+
+		var err error
+		matches, err = db.options.VectorIndex.Search(centroid, k)
+		if err != nil {
+			return nil, vectorError("levelgraph: search vectors", err)
+		}
+
+	case MultiModeAnyOf:
+		best := make(map[string]vector.Match)
+		for _, q := range queries {
+			found, err := db.options.VectorIndex.Search(q, k)
+			if err != nil {
+				return nil, vectorError("levelgraph: search vectors", err)
+			}
+			for _, m := range found {
+				if prev, ok := best[string(m.ID)]; !ok || m.Score > prev.Score {
+					best[string(m.ID)] = m
+				}
+			}
+		}
+
+		matches = make([]vector.Match, 0, len(best))
+		for _, m := range best {
+			matches = append(matches, m)
+		}
+		sort.Slice(matches, func(i, j int) bool {
+			if matches[i].Score != matches[j].Score {
+				return matches[i].Score > matches[j].Score
+			}
+			return bytes.Compare(matches[i].ID, matches[j].ID) < 0
+		})
+		if len(matches) > k {
+			matches = matches[:k]
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrInvalidMultiMode, mode)
+	}
+
+	results := db.toVectorMatches(matches)
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("search vectors multi", "queries", len(queries), "k", k, "results", len(results))
+	}
+
+	return results, nil
+}
+
+// vectorIDCodec returns the configured vector ID codec, or the default.
+func (db *DB) vectorIDCodec() vector.IDCodec {
+	if db.options.VectorIDCodec != nil {
+		return db.options.VectorIDCodec
 	}
+	return vector.DefaultIDCodec
+}
 
+// toVectorMatches converts index matches into VectorMatch results with parsed IDs.
+func (db *DB) toVectorMatches(matches []vector.Match) []VectorMatch {
+	codec := db.vectorIDCodec()
 	results := make([]VectorMatch, len(matches))
 	for i, m := range matches {
-		idType, parts := vector.ParseID(m.ID)
+		idType, parts := codec.ParseID(m.ID)
 		results[i] = VectorMatch{
 			ID:       m.ID,
 			Score:    m.Score,
@@ -222,21 +544,92 @@ func (db *DB) SearchVectors(ctx context.Context, query []float32, k int) ([]Vect
 			Parts:    parts,
 		}
 	}
+	return results
+}
 
-	if db.options.Logger != nil {
-		db.options.Logger.Debug("search vectors", "k", k, "results", len(results))
+// SearchVectorsWithinHops finds the k object vectors closest to query among
+// the nodes reachable from start by following at most hops outgoing predicate
+// edges (edges of any predicate when predicate is nil). Nodes further away
+// are never returned, however similar, which suits recommendations such as
+// "items like X within two hops of X". start itself and reached nodes
+// without an object vector are skipped. Distances use the index's distance
+// function, as SearchVectors does.
+//
+// Example:
+//
+//	// Products similar to the query among those related to product 42
+//	results, _ := db.SearchVectorsWithinHops(ctx, []byte("product:42"), []byte("relatedTo"), 2, queryVec, 10)
+func (db *DB) SearchVectorsWithinHops(ctx context.Context, start, predicate []byte, hops int, query []float32, k int) ([]VectorMatch, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
 	}
 
-	return results, nil
+	if db.options.VectorIndex == nil {
+		return nil, ErrVectorsDisabled
+	}
+
+	if k <= 0 {
+		return nil, fmt.Errorf("levelgraph: search vectors within hops: %w", vector.ErrInvalidK)
+	}
+	if len(query) != db.options.VectorIndex.Dimensions() {
+		return nil, fmt.Errorf("levelgraph: search vectors within hops: %w", ErrDimensionMismatch)
+	}
+	if hops <= 0 {
+		return []VectorMatch{}, nil
+	}
+
+	reached, err := db.reachable(ctx, start, predicate, true, false, hops)
+	if err != nil {
+		return nil, fmt.Errorf("levelgraph: %w", err)
+	}
+
+	distanceFunc := vector.IndexDistance(db.options.VectorIndex)
+	var matches []vector.Match
+	for _, node := range reached {
+		if bytes.Equal(node, start) {
+			continue
+		}
+		id := db.vectorIDCodec().MakeID(vector.IDTypeObject, node)
+		vec, err := db.options.VectorIndex.Get(id)
+		if err != nil {
+			continue // No vector for this node
+		}
+		distance := distanceFunc(query, vec)
+		matches = append(matches, vector.Match{
+			ID:       id,
+			Distance: distance,
+			Score:    vector.NormalizeScore(distance),
+		})
+	}
+
+	// Closest first, ties broken by ID as in the vector indexes
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return bytes.Compare(matches[i].ID, matches[j].ID) < 0
+	})
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+
+	return db.toVectorMatches(matches), nil
 }
 
 // SearchVectorsByText searches for similar vectors using text input.
 // Requires an Embedder to be configured (via WithAutoEmbed).
+// If idTypes are given, only matches of those types are returned.
 //
 // Example:
 //
 //	results, _ := db.SearchVectorsByText(ctx, "racket sports", 10)
-func (db *DB) SearchVectorsByText(ctx context.Context, text string, k int) ([]VectorMatch, error) {
+//
+//	// Only object matches
+//	objects, _ := db.SearchVectorsByText(ctx, "racket sports", 10, vector.IDTypeObject)
+func (db *DB) SearchVectorsByText(ctx context.Context, text string, k int, idTypes ...vector.IDType) ([]VectorMatch, error) {
 	db.mu.RLock()
 
 	if db.closed {
@@ -272,11 +665,27 @@ func (db *DB) SearchVectorsByText(ctx context.Context, text string, k int) ([]Ve
 	// This avoids potential deadlock and double-unlock issues.
 	db.mu.RUnlock()
 
+	if len(idTypes) > 0 {
+		return db.searchVectorsOfType(ctx, queryVec, k, idTypes...)
+	}
 	return db.SearchVectors(ctx, queryVec, k)
 }
 
-// EmbedAndSetVector embeds text and stores the resulting vector.
+// SearchSimilarObjectsByText searches for objects similar to the given text.
+// Requires an Embedder to be configured.
+func (db *DB) SearchSimilarObjectsByText(ctx context.Context, text string, k int) ([]VectorMatch, error) {
+	return db.SearchVectorsByText(ctx, text, k, vector.IDTypeObject)
+}
+
+// SearchSimilarSubjectsByText searches for subjects similar to the given text.
 // Requires an Embedder to be configured.
+func (db *DB) SearchSimilarSubjectsByText(ctx context.Context, text string, k int) ([]VectorMatch, error) {
+	return db.SearchVectorsByText(ctx, text, k, vector.IDTypeSubject)
+}
+
+// EmbedAndSetVector embeds text and stores the resulting vector.
+// Requires an Embedder to be configured. As with SetVector, build id with
+// the custom codec's MakeID when WithVectorIDCodec is set.
 //
 // Example:
 //
@@ -289,6 +698,11 @@ func (db *DB) EmbedAndSetVector(ctx context.Context, id []byte, text string) err
 		return fmt.Errorf("levelgraph: %w", ErrClosed)
 	}
 
+	if db.options.ReadOnly {
+		db.mu.RUnlock()
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
 	if db.options.VectorIndex == nil {
 		db.mu.RUnlock()
 		return ErrVectorsDisabled
@@ -340,6 +754,20 @@ func (db *DB) VectorDimensions() int {
 	return db.options.VectorIndex.Dimensions()
 }
 
+// VectorMemoryBytes returns the approximate memory held by the in-memory
+// vector index. Returns 0 if vectors are not enabled.
+// This is an estimate intended for monitoring and eviction policies.
+func (db *DB) VectorMemoryBytes() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.options.VectorIndex == nil {
+		return 0
+	}
+
+	return db.options.VectorIndex.EstimatedBytes()
+}
+
 // VectorsEnabled returns true if vector operations are available.
 func (db *DB) VectorsEnabled() bool {
 	db.mu.RLock()
@@ -368,6 +796,18 @@ func (db *DB) LoadVectors(ctx context.Context) error {
 	default:
 	}
 
+	// With the delta log enabled, restore the HNSW graph from its snapshot
+	// and replay the deltas recorded since, instead of rebuilding it.
+	if db.options.VectorDeltaLog {
+		loaded, err := db.loadVectorSnapshot(ctx)
+		if err != nil {
+			return err
+		}
+		if loaded {
+			return nil
+		}
+	}
+
 	// Iterate over all vector keys
 	start := vectorPrefix
 	end := append([]byte{}, vectorPrefix...)
@@ -390,7 +830,7 @@ func (db *DB) LoadVectors(ctx context.Context) error {
 		id := key[len(vectorPrefix):]
 
 		// Parse vector from value
-		vec := vector.BytesToVector(iter.Value())
+		vec := decodeVectorValue(iter.Value())
 		if vec == nil {
 			continue
 		}
@@ -419,76 +859,371 @@ func (db *DB) LoadVectors(ctx context.Context) error {
 	return nil
 }
 
-// makeVectorKey creates a storage key for a vector ID.
-func makeVectorKey(id []byte) []byte {
-	key := make([]byte, len(vectorPrefix)+len(id))
-	copy(key, vectorPrefix)
-	copy(key[len(vectorPrefix):], id)
-	return key
-}
+// addVector adds a vector to the index and persists it to the KVStore.
+// If the KVStore write fails, the vector is rolled back from the index.
+// Caller must hold at least a read lock.
+func (db *DB) addVector(id []byte, vec []float32) error {
+	if db.options.VectorDeltaLog {
+		db.vectorDeltaMu.Lock()
+		defer db.vectorDeltaMu.Unlock()
+	}
 
-// SetSubjectVector is a convenience method to set a vector for a subject value.
-func (db *DB) SetSubjectVector(ctx context.Context, subject []byte, vec []float32) error {
-	id := vector.MakeID(vector.IDTypeSubject, subject)
-	return db.SetVector(ctx, id, vec)
-}
+	if err := db.options.VectorIndex.Add(id, vec); err != nil {
+		return vectorError("set vector", err)
+	}
 
-// SetObjectVector is a convenience method to set a vector for an object value.
+	batch := NewBatch()
+	batch.Put(makeVectorKey(id), encodeVectorValue(vec, db.options.VectorStorageEncoding))
+	if db.options.VectorDeltaLog {
+		db.recordVectorDelta(batch, vectorDeltaAdd, id, vec)
+	}
+
+	if err := db.writeBatch(batch); err != nil {
+		// Try to rollback from index
+		db.options.VectorIndex.Delete(id)
+		return fmt.Errorf("persist vector: %w", err)
+	}
+
+	return db.maybeCompactVectorIndex()
+}
+
+// removeVector deletes a vector from the index and from the KVStore. With
+// missingOK, an ID the index does not hold is not an error, as for a vector
+// persisted but not yet loaded with LoadVectors.
+// Caller must hold at least a read lock.
+func (db *DB) removeVector(id []byte, missingOK bool) error {
+	if db.options.VectorDeltaLog {
+		db.vectorDeltaMu.Lock()
+		defer db.vectorDeltaMu.Unlock()
+	}
+
+	if err := db.options.VectorIndex.Delete(id); err != nil && !(missingOK && errors.Is(err, vector.ErrNotFound)) {
+		return fmt.Errorf("delete vector: %w", err)
+	}
+
+	batch := NewBatch()
+	batch.Delete(makeVectorKey(id))
+	if db.options.VectorDeltaLog {
+		db.recordVectorDelta(batch, vectorDeltaDelete, id, nil)
+	}
+
+	if err := db.writeBatch(batch); err != nil {
+		return fmt.Errorf("delete persisted vector: %w", err)
+	}
+
+	return db.maybeCompactVectorIndex()
+}
+
+// removeTripleVectors deletes the persisted triple vectors of triples,
+// including any still stored under their legacy IDs.
+// Caller must hold at least a read lock.
+func (db *DB) removeTripleVectors(triples []*graph.Triple) error {
+	for _, triple := range triples {
+		for _, id := range [][]byte{db.tripleVectorID(triple), db.legacyTripleVectorID(triple)} {
+			if _, err := db.store.Get(makeVectorKey(id), nil); err != nil {
+				if errors.Is(err, ErrNotFound) {
+					continue
+				}
+				return fmt.Errorf("read vector: %w", err)
+			}
+			if err := db.removeVector(id, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteVectorsByType removes every vector whose ID has the given type,
+// for example all object vectors. Returns the number of vectors deleted.
+//
+// Example:
+//
+//	// Drop all object embeddings before re-embedding with a new model
+//	n, _ := db.DeleteVectorsByType(ctx, vector.IDTypeObject)
+func (db *DB) DeleteVectorsByType(ctx context.Context, idType vector.IDType) (int, error) {
+	return db.DeleteVectorsByPrefix(ctx, db.vectorIDCodec().MakeID(idType))
+}
+
+// DeleteVectorsByPrefix removes every vector whose ID starts with prefix,
+// from both the KVStore and the in-memory index. Returns the number of
+// vectors deleted.
+func (db *DB) DeleteVectorsByPrefix(ctx context.Context, prefix []byte) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.ReadOnly {
+		return 0, fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
+	if db.options.VectorIndex == nil {
+		return 0, ErrVectorsDisabled
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	if db.options.VectorDeltaLog {
+		db.vectorDeltaMu.Lock()
+		defer db.vectorDeltaMu.Unlock()
+	}
+
+	start := makeVectorKey(prefix)
+	end := append([]byte{}, vectorPrefix...)
+	end[len(end)-1]++
+	if limit := prefixLimit(start); limit != nil {
+		end = limit
+	}
+
+	iter := db.store.NewIterator(&Range{Start: start, Limit: end}, nil)
+	var ids [][]byte
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			iter.Release()
+			return 0, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+		ids = append(ids, append([]byte{}, iter.Key()[len(vectorPrefix):]...))
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return 0, fmt.Errorf("levelgraph: iterate vectors: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	batch := NewBatch()
+	for _, id := range ids {
+		batch.Delete(makeVectorKey(id))
+		if db.options.VectorDeltaLog {
+			db.recordVectorDelta(batch, vectorDeltaDelete, id, nil)
+		}
+	}
+	if err := db.writeBatch(batch); err != nil {
+		return 0, fmt.Errorf("levelgraph: delete persisted vectors: %w", err)
+	}
+
+	// The index may not hold every persisted vector (e.g. before LoadVectors),
+	// so a missing entry is not an error here.
+	for _, id := range ids {
+		if err := db.options.VectorIndex.Delete(id); err != nil && !errors.Is(err, vector.ErrNotFound) {
+			return 0, fmt.Errorf("levelgraph: delete vector: %w", err)
+		}
+	}
+
+	if err := db.maybeCompactVectorIndex(); err != nil {
+		return 0, fmt.Errorf("levelgraph: %w", err)
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("delete vectors by prefix", "prefix", string(prefix), "count", len(ids))
+	}
+
+	return len(ids), nil
+}
+
+// prefixLimit returns the smallest key greater than every key starting with
+// prefix, or nil if no such key exists (prefix is all 0xff bytes).
+func prefixLimit(prefix []byte) []byte {
+	limit := append([]byte{}, prefix...)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// vectorError wraps an error from the vector index with op. Dimension
+// mismatches also wrap ErrDimensionMismatch so callers can check either
+// sentinel with errors.Is.
+func vectorError(op string, err error) error {
+	if errors.Is(err, vector.ErrDimensionMismatch) && !errors.Is(err, ErrDimensionMismatch) {
+		return fmt.Errorf("%s: %w: %w", op, ErrDimensionMismatch, err)
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// makeVectorKey creates a storage key for a vector ID.
+func makeVectorKey(id []byte) []byte {
+	key := make([]byte, len(vectorPrefix)+len(id))
+	copy(key, vectorPrefix)
+	copy(key[len(vectorPrefix):], id)
+	return key
+}
+
+// SetSubjectVector is a convenience method to set a vector for a subject value.
+func (db *DB) SetSubjectVector(ctx context.Context, subject []byte, vec []float32) error {
+	id := db.vectorIDCodec().MakeID(vector.IDTypeSubject, subject)
+	return db.SetVector(ctx, id, vec)
+}
+
+// SetObjectVector is a convenience method to set a vector for an object value.
 func (db *DB) SetObjectVector(ctx context.Context, object []byte, vec []float32) error {
-	id := vector.MakeID(vector.IDTypeObject, object)
+	id := db.vectorIDCodec().MakeID(vector.IDTypeObject, object)
 	return db.SetVector(ctx, id, vec)
 }
 
-// SetTripleVector is a convenience method to set a vector for a triple.
+// SetTripleVector is a convenience method to set a vector for a triple,
+// stored under TripleVectorID(triple), or its equivalent under a custom
+// VectorIDCodec. An aliased predicate is first replaced by its canonical
+// predicate, as Put does.
 func (db *DB) SetTripleVector(ctx context.Context, triple *graph.Triple, vec []float32) error {
-	id := vector.MakeID(vector.IDTypeTriple, triple.Subject, triple.Predicate, triple.Object)
-	return db.SetVector(ctx, id, vec)
+	return db.SetVector(ctx, db.tripleVectorID(db.canonicalTriple(triple)), vec)
+}
+
+// TripleVectorID returns the vector ID for a whole triple, used by
+// SetTripleVector and AutoEmbedTriples: IDTypeTriple with the triple's
+// HashHex as its only part. The ID has a fixed size however long the
+// components are; to get from a search result back to the triple, keep the
+// hash alongside it (for example as a triple or facet).
+func TripleVectorID(triple *graph.Triple) []byte {
+	return vector.MakeID(vector.IDTypeTriple, []byte(triple.HashHex()))
+}
+
+// tripleVectorID is TripleVectorID built with the DB's VectorIDCodec.
+func (db *DB) tripleVectorID(triple *graph.Triple) []byte {
+	return db.vectorIDCodec().MakeID(vector.IDTypeTriple, []byte(triple.HashHex()))
+}
+
+// legacyTripleVectorID returns the ID triple vectors were stored under
+// before they were keyed by hash: IDTypeTriple with the subject, predicate
+// and object as parts. Del and GC still remove vectors stored under it.
+func (db *DB) legacyTripleVectorID(triple *graph.Triple) []byte {
+	return db.vectorIDCodec().MakeID(vector.IDTypeTriple, triple.Subject, triple.Predicate, triple.Object)
 }
 
 // SearchSimilarObjects searches for objects similar to a query vector.
 // Only returns matches with IDTypeObject.
 func (db *DB) SearchSimilarObjects(ctx context.Context, query []float32, k int) ([]VectorMatch, error) {
-	results, err := db.SearchVectors(ctx, query, k*2) // Fetch more to filter
+	return db.searchVectorsOfType(ctx, query, k, vector.IDTypeObject)
+}
+
+// SearchSimilarSubjects searches for subjects similar to a query vector.
+// Only returns matches with IDTypeSubject.
+func (db *DB) SearchSimilarSubjects(ctx context.Context, query []float32, k int) ([]VectorMatch, error) {
+	return db.searchVectorsOfType(ctx, query, k, vector.IDTypeSubject)
+}
+
+// SearchSimilarObjectsWithTriples is SearchSimilarObjects that also fills
+// each match's Triples with the triples having the matched object and, if
+// predicate is non-nil, that predicate. A match the graph has no such
+// triples for is still returned, with no Triples.
+//
+// Example:
+//
+//	// Sports like tennis, and who likes them
+//	matches, err := db.SearchSimilarObjectsWithTriples(ctx, []byte("likes"), tennisVec, 5)
+//	for _, m := range matches {
+//	    for _, t := range m.Triples {
+//	        fmt.Printf("%s likes %s\n", t.Subject, t.Object)
+//	    }
+//	}
+func (db *DB) SearchSimilarObjectsWithTriples(ctx context.Context, predicate []byte, query []float32, k int) ([]VectorMatch, error) {
+	matches, err := db.SearchSimilarObjects(ctx, query, k)
 	if err != nil {
 		return nil, err
 	}
+	return db.attachTriples(ctx, matches, predicate, "object")
+}
 
-	filtered := make([]VectorMatch, 0, k)
-	for _, r := range results {
-		if r.IDType == vector.IDTypeObject {
-			filtered = append(filtered, r)
-			if len(filtered) >= k {
-				break
-			}
-		}
+// SearchSimilarSubjectsWithTriples is SearchSimilarSubjects that also fills
+// each match's Triples with the triples having the matched subject and, if
+// predicate is non-nil, that predicate.
+func (db *DB) SearchSimilarSubjectsWithTriples(ctx context.Context, predicate []byte, query []float32, k int) ([]VectorMatch, error) {
+	matches, err := db.SearchSimilarSubjects(ctx, query, k)
+	if err != nil {
+		return nil, err
 	}
+	return db.attachTriples(ctx, matches, predicate, "subject")
+}
 
-	return filtered, nil
+// attachTriples sets the Triples of each match to the triples whose field
+// (subject or object) is the match's value, restricted to predicate if it
+// is non-nil.
+func (db *DB) attachTriples(ctx context.Context, matches []VectorMatch, predicate []byte, field string) ([]VectorMatch, error) {
+	for i := range matches {
+		if len(matches[i].Parts) != 1 {
+			continue
+		}
+		pattern := &graph.Pattern{}
+		if predicate != nil {
+			pattern.Predicate = graph.Exact(predicate)
+		}
+		if field == "subject" {
+			pattern.Subject = graph.Exact(matches[i].Parts[0])
+		} else {
+			pattern.Object = graph.Exact(matches[i].Parts[0])
+		}
+		triples, err := db.Get(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+		matches[i].Triples = triples
+	}
+	return matches, nil
 }
 
-// SearchSimilarSubjects searches for subjects similar to a query vector.
-// Only returns matches with IDTypeSubject.
-func (db *DB) SearchSimilarSubjects(ctx context.Context, query []float32, k int) ([]VectorMatch, error) {
-	results, err := db.SearchVectors(ctx, query, k*2) // Fetch more to filter
-	if err != nil {
-		return nil, err
+// searchVectorsOfType returns the k nearest matches whose IDType is one of
+// idTypes. The index has no type filter, so it over-fetches and widens the
+// search until k matches are found or the index is exhausted.
+func (db *DB) searchVectorsOfType(ctx context.Context, query []float32, k int, idTypes ...vector.IDType) ([]VectorMatch, error) {
+	if k <= 0 {
+		return nil, nil
 	}
 
-	filtered := make([]VectorMatch, 0, k)
-	for _, r := range results {
-		if r.IDType == vector.IDTypeSubject {
-			filtered = append(filtered, r)
-			if len(filtered) >= k {
-				break
+	fetch := k * 2 // Fetch more to filter
+	for {
+		results, err := db.SearchVectors(ctx, query, fetch)
+		if err != nil {
+			return nil, err
+		}
+
+		filtered := make([]VectorMatch, 0, k)
+		for _, r := range results {
+			if slices.Contains(idTypes, r.IDType) {
+				filtered = append(filtered, r)
+				if len(filtered) >= k {
+					break
+				}
 			}
 		}
+
+		if len(filtered) >= k || len(results) < fetch {
+			return filtered, nil
+		}
+		fetch *= 2
 	}
+}
+
+// autoEmbedConfigured reports whether any triple components are set up for
+// auto-embedding, globally or per predicate.
+func (db *DB) autoEmbedConfigured() bool {
+	return db.options.AutoEmbedTargets != AutoEmbedNone || len(db.options.AutoEmbedPredicateTargets) > 0
+}
 
-	return filtered, nil
+// autoEmbedTargets returns the components of triple to auto-embed.
+func (db *DB) autoEmbedTargets(triple *graph.Triple) AutoEmbedTarget {
+	if len(db.options.AutoEmbedPredicateTargets) > 0 {
+		return db.options.AutoEmbedPredicateTargets[string(triple.Predicate)]
+	}
+	return db.options.AutoEmbedTargets
 }
 
 // autoEmbedTriples generates and stores vector embeddings for triple components
-// based on the configured AutoEmbedTargets. This is called automatically during Put()
+// based on the configured AutoEmbedTargets (or AutoEmbedPredicateTargets). This is called automatically during Put()
 // when both an Embedder and VectorIndex are configured.
 //
 // If AsyncAutoEmbed is enabled, this queues the work for background processing.
@@ -512,20 +1247,22 @@ func (db *DB) autoEmbedTriples(ctx context.Context, triples []*graph.Triple) err
 	}
 
 	// Synchronous embedding
-	return db.doAutoEmbedTriples(ctx, triples)
+	return db.doAutoEmbedTriples(ctx, triples, false)
 }
 
 // doAutoEmbedTriples performs the actual embedding work.
-// This is called either synchronously from autoEmbedTriples or from the background worker.
-func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple) error {
+// This is called either synchronously from autoEmbedTriples or, with async set,
+// from a background worker, where failed embeddings are retried with backoff
+// and finally handed to Options.EmbedErrorHandler.
+func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple, async bool) error {
 	// Collect unique values to embed by type
 	subjects := make(map[string][]byte)
 	predicates := make(map[string][]byte)
 	objects := make(map[string][]byte)
-
-	targets := db.options.AutoEmbedTargets
+	wholeTriples := make(map[string]*graph.Triple)
 
 	for _, triple := range triples {
+		targets := db.autoEmbedTargets(triple)
 		if targets&AutoEmbedSubjects != 0 {
 			key := string(triple.Subject)
 			if _, exists := subjects[key]; !exists {
@@ -544,15 +1281,19 @@ func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple) e
 				objects[key] = triple.Object
 			}
 		}
+		if targets&AutoEmbedTriples != 0 {
+			wholeTriples[string(db.tripleVectorID(triple))] = triple
+		}
 	}
 
 	// Batch embed all texts
 	var texts []string
 	var ids [][]byte
+	defer func() { db.releaseEmbedIDs(ids) }()
 
 	for _, val := range subjects {
 		// Skip if vector already exists
-		id := vector.MakeID(vector.IDTypeSubject, val)
+		id := db.vectorIDCodec().MakeID(vector.IDTypeSubject, val)
 		if _, err := db.options.VectorIndex.Get(id); err == nil {
 			continue
 		}
@@ -560,7 +1301,7 @@ func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple) e
 		ids = append(ids, id)
 	}
 	for _, val := range predicates {
-		id := vector.MakeID(vector.IDTypePredicate, val)
+		id := db.vectorIDCodec().MakeID(vector.IDTypePredicate, val)
 		if _, err := db.options.VectorIndex.Get(id); err == nil {
 			continue
 		}
@@ -568,7 +1309,7 @@ func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple) e
 		ids = append(ids, id)
 	}
 	for _, val := range objects {
-		id := vector.MakeID(vector.IDTypeObject, val)
+		id := db.vectorIDCodec().MakeID(vector.IDTypeObject, val)
 		if _, err := db.options.VectorIndex.Get(id); err == nil {
 			continue
 		}
@@ -576,11 +1317,68 @@ func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple) e
 		ids = append(ids, id)
 	}
 
+	tripleText := db.options.TripleTextFunc
+	if tripleText == nil {
+		tripleText = DefaultTripleText
+	}
+	for id, triple := range wholeTriples {
+		if _, err := db.options.VectorIndex.Get([]byte(id)); err == nil {
+			continue
+		}
+		texts = append(texts, tripleText(triple))
+		ids = append(ids, []byte(id))
+	}
+
+	texts, ids = db.claimEmbedIDs(texts, ids)
 	if len(texts) == 0 {
 		return nil // Nothing new to embed
 	}
 
-	// Embed all texts
+	if async {
+		return db.embedWithRetry(ctx, texts, ids)
+	}
+	return db.embedAndStore(ctx, texts, ids)
+}
+
+// embedWithRetry calls embedAndStore, retrying failures up to
+// Options.EmbedRetries times with exponential backoff. If every attempt
+// fails, Options.EmbedErrorHandler is called for each ID.
+func (db *DB) embedWithRetry(ctx context.Context, texts []string, ids [][]byte) error {
+	err := db.embedAndStore(ctx, texts, ids)
+	if err == nil {
+		return nil
+	}
+
+	db.embedRetrying.Add(1)
+	backoff := db.options.EmbedRetryBackoff
+	for attempt := 0; attempt < db.options.EmbedRetries && err != nil; attempt++ {
+		if db.options.Logger != nil {
+			db.options.Logger.Debug("retrying auto-embed", "attempt", attempt+1, "error", err)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		backoff *= 2
+		err = db.embedAndStore(ctx, texts, ids)
+	}
+	db.embedRetrying.Add(-1)
+
+	if err != nil && db.options.EmbedErrorHandler != nil {
+		for i, id := range ids {
+			db.options.EmbedErrorHandler(id, texts[i], err)
+		}
+	}
+	return err
+}
+
+// embedAndStore embeds texts in one batch and stores each vector under the
+// matching ID.
+func (db *DB) embedAndStore(ctx context.Context, texts []string, ids [][]byte) error {
 	embeddings, err := db.options.Embedder.EmbedBatch(texts)
 	if err != nil {
 		return fmt.Errorf("embed batch: %w", err)
@@ -596,18 +1394,8 @@ func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple) e
 		default:
 		}
 
-		// Add to index
-		if err := db.options.VectorIndex.Add(id, embeddings[i]); err != nil {
-			return fmt.Errorf("add vector: %w", err)
-		}
-
-		// Persist to KVStore
-		key := makeVectorKey(id)
-		value := vector.VectorToBytes(embeddings[i])
-		if err := db.store.Put(key, value, nil); err != nil {
-			// Try to rollback from index
-			db.options.VectorIndex.Delete(id)
-			return fmt.Errorf("persist vector: %w", err)
+		if err := db.addVector(id, embeddings[i]); err != nil {
+			return err
 		}
 	}
 
@@ -618,7 +1406,45 @@ func (db *DB) doAutoEmbedTriples(ctx context.Context, triples []*graph.Triple) e
 	return nil
 }
 
-// startEmbedWorker starts the background embedding worker if async embedding is enabled.
+// claimEmbedIDs marks ids as being embedded and returns the texts and ids not
+// already claimed or stored, so concurrent workers never embed the same ID
+// twice. The index is checked again under the lock because another worker may
+// have stored the vector since the caller's check.
+func (db *DB) claimEmbedIDs(texts []string, ids [][]byte) ([]string, [][]byte) {
+	db.embedMu.Lock()
+	defer db.embedMu.Unlock()
+
+	if db.embedInFlight == nil {
+		db.embedInFlight = make(map[string]bool)
+	}
+	n := 0
+	for i, id := range ids {
+		if db.embedInFlight[string(id)] {
+			continue
+		}
+		if _, err := db.options.VectorIndex.Get(id); err == nil {
+			continue
+		}
+		db.embedInFlight[string(id)] = true
+		texts[n], ids[n] = texts[i], id
+		n++
+	}
+	return texts[:n], ids[:n]
+}
+
+// releaseEmbedIDs clears the claims taken by claimEmbedIDs.
+func (db *DB) releaseEmbedIDs(ids [][]byte) {
+	if len(ids) == 0 {
+		return
+	}
+	db.embedMu.Lock()
+	defer db.embedMu.Unlock()
+	for _, id := range ids {
+		delete(db.embedInFlight, string(id))
+	}
+}
+
+// startEmbedWorker starts the background embedding workers if async embedding is enabled.
 func (db *DB) startEmbedWorker() {
 	if !db.options.AsyncAutoEmbed {
 		return
@@ -629,39 +1455,43 @@ func (db *DB) startEmbedWorker() {
 		bufSize = defaultAsyncEmbedBufferSize
 	}
 
+	workers := max(db.options.AsyncEmbedWorkers, 1)
+
 	db.embedQueue = make(chan []*graph.Triple, bufSize)
-	db.embedDone = make(chan struct{})
 	db.embedStarted = true
 
-	go db.embedWorker()
+	db.embedWorkers.Add(workers)
+	for range workers {
+		go db.embedWorker()
+	}
 }
 
-// stopEmbedWorker stops the background embedding worker and waits for it to finish.
+// stopEmbedWorker stops the background embedding workers and waits for them to finish.
 func (db *DB) stopEmbedWorker() {
 	if !db.embedStarted {
 		return
 	}
 
-	// Close the queue to signal worker to stop
+	// Close the queue to signal workers to stop
 	close(db.embedQueue)
 
-	// Wait for worker to finish processing all items
-	<-db.embedDone
+	// Wait for workers to finish processing all items
+	db.embedWorkers.Wait()
 
 	if db.options.Logger != nil {
 		db.options.Logger.Debug("embed worker stopped")
 	}
 }
 
-// embedWorker is the background goroutine that processes embedding requests.
+// embedWorker is a background goroutine that processes embedding requests.
 func (db *DB) embedWorker() {
-	defer close(db.embedDone)
+	defer db.embedWorkers.Done()
 
 	ctx := context.Background()
 
 	for triples := range db.embedQueue {
 		// Process the embedding request
-		if err := db.doAutoEmbedTriples(ctx, triples); err != nil {
+		if err := db.doAutoEmbedTriples(ctx, triples, true); err != nil {
 			if db.options.Logger != nil {
 				db.options.Logger.Warn("async auto-embed failed", "error", err)
 			}
@@ -712,11 +1542,327 @@ func (db *DB) WaitForEmbeddings(ctx context.Context) error {
 	}
 }
 
-// PendingEmbeddings returns the number of pending async embedding operations.
+// ReEmbedAll runs auto-embedding over every stored triple, embedding the
+// configured components that have no vector yet. Use it after a bulk load
+// with PutOptions.SkipAutoEmbed, or after enabling WithAutoEmbed on an
+// existing database. It embeds synchronously, even with async embedding
+// enabled, in chunks of 1000 triples.
+func (db *DB) ReEmbedAll(ctx context.Context) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if db.options.ReadOnly {
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+	if db.options.VectorIndex == nil {
+		return ErrVectorsDisabled
+	}
+	if db.options.Embedder == nil {
+		return ErrEmbedderRequired
+	}
+	if !db.autoEmbedConfigured() {
+		return nil
+	}
+
+	iter := db.store.NewIterator(spoRange(), nil)
+	defer iter.Release()
+
+	chunk := make([]*graph.Triple, 0, reEmbedChunkSize)
+	flush := func() error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("levelgraph: %w", err)
+		}
+		if err := db.doAutoEmbedTriples(ctx, chunk, false); err != nil {
+			return fmt.Errorf("levelgraph: re-embed: %w", err)
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+	for iter.Next() {
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return fmt.Errorf("levelgraph: re-embed: %w", err)
+		}
+		chunk = append(chunk, triple)
+		if len(chunk) == reEmbedChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("levelgraph: re-embed: %w", err)
+	}
+	if len(chunk) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// PendingEmbeddings returns the number of pending async embedding operations,
+// including those waiting to be retried after a failure.
 // Returns 0 if async embedding is not enabled.
 func (db *DB) PendingEmbeddings() int {
 	if !db.embedStarted || db.embedQueue == nil {
 		return 0
 	}
-	return len(db.embedQueue)
+	return len(db.embedQueue) + int(db.embedRetrying.Load())
+}
+
+// ============================================================================
+// Incremental HNSW persistence
+// ============================================================================
+
+// Delta record operations.
+const (
+	vectorDeltaAdd    byte = 1
+	vectorDeltaDelete byte = 0
+)
+
+// SaveVectorIndex writes a full snapshot of the HNSW graph and clears the
+// delta log. LoadVectors restores this snapshot instead of rebuilding the
+// graph from every stored vector. Requires WithVectorDeltaLog.
+//
+// Snapshots are also written automatically when the delta log reaches the
+// compactEvery threshold passed to WithVectorDeltaLog.
+func (db *DB) SaveVectorIndex(ctx context.Context) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	if db.options.ReadOnly {
+		return fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
+	if db.options.VectorIndex == nil {
+		return ErrVectorsDisabled
+	}
+
+	if !db.options.VectorDeltaLog {
+		return ErrVectorSnapshotUnsupported
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	db.vectorDeltaMu.Lock()
+	defer db.vectorDeltaMu.Unlock()
+
+	if err := db.saveVectorSnapshotUnlocked(); err != nil {
+		return fmt.Errorf("levelgraph: %w", err)
+	}
+	return nil
+}
+
+// saveVectorSnapshotUnlocked exports the HNSW graph and atomically replaces
+// the previous snapshot and delta log with it.
+// Caller must hold db.vectorDeltaMu.
+func (db *DB) saveVectorSnapshotUnlocked() error {
+	hnsw := db.options.VectorIndex.(*vector.HNSWIndex)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(hnsw.Export()); err != nil {
+		return fmt.Errorf("encode vector snapshot: %w", err)
+	}
+
+	batch := NewBatch()
+	batch.Put(vectorSnapshotKey, buf.Bytes())
+
+	iter := db.store.NewIterator(vectorDeltaRange(), nil)
+	for iter.Next() {
+		keyCopy := make([]byte, len(iter.Key()))
+		copy(keyCopy, iter.Key())
+		batch.Delete(keyCopy)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("iterate vector deltas: %w", err)
+	}
+
+	if err := db.writeBatch(batch); err != nil {
+		return fmt.Errorf("write vector snapshot: %w", err)
+	}
+
+	db.vectorDeltaCount = 0
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("saved vector snapshot", "vectors", hnsw.Len(), "bytes", buf.Len())
+	}
+
+	return nil
+}
+
+// maybeCompactVectorIndex writes a new snapshot once the configured number
+// of deltas has accumulated.
+// Caller must hold db.vectorDeltaMu.
+func (db *DB) maybeCompactVectorIndex() error {
+	if !db.options.VectorDeltaLog || db.options.VectorDeltaCompactEvery <= 0 {
+		return nil
+	}
+	if db.vectorDeltaCount < db.options.VectorDeltaCompactEvery {
+		return nil
+	}
+	return db.saveVectorSnapshotUnlocked()
+}
+
+// recordVectorDelta adds a delta record to the batch.
+// Key format: vector_delta::<timestamp_ns>::<seq>
+// Value format: [Op][IDLen (uvarint)][ID][Vector bytes]
+// As with journal keys, timestamps never go backwards and seq increases by
+// one per delta, so replay follows write order across clock steps and
+// reopens.
+// Caller must hold db.vectorDeltaMu.
+func (db *DB) recordVectorDelta(batch *Batch, op byte, id []byte, vec []float32) {
+	if !db.vectorDeltaSeeded {
+		db.seedVectorDeltaSeq()
+	}
+	nsec := max(time.Now().UnixNano(), db.vectorDeltaLastNs)
+	db.vectorDeltaLastNs = nsec
+	db.vectorDeltaSeq++
+
+	key := make([]byte, len(vectorDeltaPrefix)+16)
+	copy(key, vectorDeltaPrefix)
+	binary.BigEndian.PutUint64(key[len(vectorDeltaPrefix):], uint64(nsec))
+	binary.BigEndian.PutUint64(key[len(vectorDeltaPrefix)+8:], db.vectorDeltaSeq)
+
+	value := make([]byte, 0, 1+binary.MaxVarintLen64+len(id)+len(vec)*4)
+	value = append(value, op)
+	value = binary.AppendUvarint(value, uint64(len(id)))
+	value = append(value, id...)
+	value = append(value, vector.VectorToBytes(vec)...)
+
+	batch.Put(key, value)
+	db.vectorDeltaCount++
+}
+
+// seedVectorDeltaSeq continues the sequence and clock from the newest delta,
+// so deltas written after a reopen replay after the existing ones.
+// Caller must hold db.vectorDeltaMu.
+func (db *DB) seedVectorDeltaSeq() {
+	iter := db.store.NewIterator(vectorDeltaRange(), nil)
+	defer iter.Release()
+
+	if iter.Last() {
+		key := iter.Key()
+		if len(key) == len(vectorDeltaPrefix)+16 {
+			db.vectorDeltaLastNs = int64(binary.BigEndian.Uint64(key[len(vectorDeltaPrefix):]))
+			db.vectorDeltaSeq = binary.BigEndian.Uint64(key[len(vectorDeltaPrefix)+8:])
+		}
+	}
+	db.vectorDeltaSeeded = true
+}
+
+// loadVectorSnapshot imports the saved HNSW snapshot and replays the delta
+// log on top of it. Returns false if no snapshot has been saved yet.
+// Caller must hold at least a read lock.
+func (db *DB) loadVectorSnapshot(ctx context.Context) (bool, error) {
+	db.vectorDeltaMu.Lock()
+	defer db.vectorDeltaMu.Unlock()
+
+	raw, err := db.store.Get(vectorSnapshotKey, nil)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("levelgraph: read vector snapshot: %w", err)
+	}
+
+	var data vector.HNSWData
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&data); err != nil {
+		return false, fmt.Errorf("levelgraph: decode vector snapshot: %w", err)
+	}
+
+	hnsw := db.options.VectorIndex.(*vector.HNSWIndex)
+	if err := hnsw.Import(&data); err != nil {
+		if errors.Is(err, vector.ErrDimensionMismatch) {
+			return false, fmt.Errorf("%w: snapshot has %d dimensions, index expects %d",
+				ErrVectorDimensionMismatch, data.Dimensions, hnsw.Dimensions())
+		}
+		return false, fmt.Errorf("levelgraph: import vector snapshot: %w", err)
+	}
+
+	iter := db.store.NewIterator(vectorDeltaRange(), nil)
+	defer iter.Release()
+
+	count := 0
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		op, id, vec, ok := parseVectorDelta(iter.Value())
+		if !ok {
+			return false, fmt.Errorf("%w: malformed vector delta %x", ErrCorrupted, iter.Key())
+		}
+
+		switch op {
+		case vectorDeltaAdd:
+			if err := hnsw.Add(id, vec); err != nil {
+				return false, fmt.Errorf("levelgraph: replay vector %s: %w", id, err)
+			}
+		case vectorDeltaDelete:
+			// The vector may have been added and removed since the snapshot
+			if err := hnsw.Delete(id); err != nil && !errors.Is(err, vector.ErrNotFound) {
+				return false, fmt.Errorf("levelgraph: replay vector delete %s: %w", id, err)
+			}
+		}
+		count++
+	}
+
+	if err := iter.Error(); err != nil {
+		return false, fmt.Errorf("levelgraph: iterate vector deltas: %w", err)
+	}
+
+	db.vectorDeltaCount = count
+
+	if db.options.Logger != nil {
+		db.options.Logger.Info("loaded vector snapshot", "count", hnsw.Len(), "deltas", count)
+	}
+
+	return true, nil
+}
+
+// parseVectorDelta decodes a delta record written by recordVectorDelta.
+func parseVectorDelta(value []byte) (op byte, id []byte, vec []float32, ok bool) {
+	if len(value) < 1 {
+		return 0, nil, nil, false
+	}
+	op = value[0]
+
+	idLen, n := binary.Uvarint(value[1:])
+	if n <= 0 || uint64(len(value)-1-n) < idLen {
+		return 0, nil, nil, false
+	}
+	offset := 1 + n
+	id = value[offset : offset+int(idLen)]
+	offset += int(idLen)
+
+	if op == vectorDeltaAdd {
+		vec = vector.BytesToVector(value[offset:])
+		if vec == nil {
+			return 0, nil, nil, false
+		}
+	}
+	return op, id, vec, true
+}
+
+// vectorDeltaRange returns the key range covering all delta records.
+func vectorDeltaRange() *Range {
+	limit := make([]byte, len(vectorDeltaPrefix)+16)
+	copy(limit, vectorDeltaPrefix)
+	for i := len(vectorDeltaPrefix); i < len(limit); i++ {
+		limit[i] = 0xFF
+	}
+	return &Range{Start: vectorDeltaPrefix, Limit: limit}
 }
@@ -26,10 +26,19 @@ package levelgraph
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/benbenbenbenbenben/levelgraph/vector"
 
@@ -189,6 +198,327 @@ func TestDB_VectorSearch(t *testing.T) {
 	}
 }
 
+func TestDB_SearchVectorsMinScore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	for name, index := range map[string]vector.Index{
+		"flat": vector.NewFlatIndex(3),
+		"hnsw": vector.NewHNSWIndex(3, vector.WithSeed(3)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithVectors(index))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer db.Close()
+
+			// Two vectors close to the x axis, the rest far away
+			db.SetVector(ctx, []byte("near1"), []float32{1, 0.05, 0})
+			db.SetVector(ctx, []byte("near2"), []float32{1, 0, 0.1})
+			db.SetVector(ctx, []byte("far1"), []float32{0, 1, 0})
+			db.SetVector(ctx, []byte("far2"), []float32{0, 0, 1})
+			db.SetVector(ctx, []byte("far3"), []float32{-1, 0, 0})
+
+			results, err := db.SearchVectorsMinScore(ctx, []float32{1, 0, 0}, 5, 0.9)
+			if err != nil {
+				t.Fatalf("SearchVectorsMinScore() error = %v", err)
+			}
+			if len(results) != 2 {
+				t.Fatalf("SearchVectorsMinScore() returned %d results, want 2: %v", len(results), results)
+			}
+			for _, r := range results {
+				if r.Score < 0.9 {
+					t.Errorf("result %s has score %f below threshold", r.ID, r.Score)
+				}
+				if !bytes.HasPrefix(r.ID, []byte("near")) {
+					t.Errorf("unexpected result %s", r.ID)
+				}
+			}
+
+			// k still caps the result count
+			results, err = db.SearchVectorsMinScore(ctx, []float32{1, 0, 0}, 1, 0.9)
+			if err != nil {
+				t.Fatalf("SearchVectorsMinScore() error = %v", err)
+			}
+			if len(results) != 1 || string(results[0].ID) != "near1" {
+				t.Errorf("SearchVectorsMinScore(k=1) = %v, want near1", results)
+			}
+
+			// A zero threshold behaves like SearchVectors
+			results, err = db.SearchVectorsMinScore(ctx, []float32{1, 0, 0}, 5, 0)
+			if err != nil {
+				t.Fatalf("SearchVectorsMinScore() error = %v", err)
+			}
+			if len(results) != 5 {
+				t.Errorf("SearchVectorsMinScore(minScore=0) returned %d results, want 5", len(results))
+			}
+		})
+	}
+}
+
+func TestDB_SearchVectorsMulti(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+
+	ctx := context.Background()
+	db.SetVector(ctx, []byte("x"), []float32{1, 0, 0})
+	db.SetVector(ctx, []byte("y"), []float32{0, 1, 0})
+	db.SetVector(ctx, []byte("z"), []float32{0, 0, 1})
+	db.SetVector(ctx, []byte("xy"), []float32{1, 1, 0})
+	db.SetVector(ctx, []byte("-x"), []float32{-1, 0, 0})
+
+	examples := [][]float32{{1, 0.1, 0}, {0.1, 1, 0}}
+
+	t.Run("Centroid", func(t *testing.T) {
+		results, err := db.SearchVectorsMulti(ctx, examples, 1, MultiModeCentroid)
+		if err != nil {
+			t.Fatalf("SearchVectorsMulti() error = %v", err)
+		}
+		if len(results) != 1 || string(results[0].ID) != "xy" {
+			t.Errorf("SearchVectorsMulti(Centroid) = %v, want xy", results)
+		}
+	})
+
+	t.Run("AnyOf", func(t *testing.T) {
+		results, err := db.SearchVectorsMulti(ctx, examples, 2, MultiModeAnyOf)
+		if err != nil {
+			t.Fatalf("SearchVectorsMulti() error = %v", err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("SearchVectorsMulti(AnyOf) returned %d results, want 2", len(results))
+		}
+		got := map[string]bool{string(results[0].ID): true, string(results[1].ID): true}
+		if !got["x"] || !got["y"] {
+			t.Errorf("SearchVectorsMulti(AnyOf) = %v, want x and y", got)
+		}
+		if results[0].Score < results[1].Score {
+			t.Error("results should be sorted by descending score")
+		}
+	})
+
+	t.Run("CentroidEuclidean", func(t *testing.T) {
+		db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+			WithVectors(vector.NewFlatIndex(2, vector.WithDistance(vector.Euclidean))))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer db.Close()
+		db.SetVector(ctx, []byte("mid"), []float32{5, 0})
+		db.SetVector(ctx, []byte("near"), []float32{0.5, 0})
+
+		// The raw centroid is (5, 0); averaging unit vectors would give (0.5, 0).
+		results, err := db.SearchVectorsMulti(ctx, [][]float32{{0, 0}, {10, 0}}, 1, MultiModeCentroid)
+		if err != nil {
+			t.Fatalf("SearchVectorsMulti() error = %v", err)
+		}
+		if len(results) != 1 || string(results[0].ID) != "mid" {
+			t.Errorf("SearchVectorsMulti(Centroid) = %v, want mid", results)
+		}
+	})
+
+	t.Run("DimensionMismatch", func(t *testing.T) {
+		_, err := db.SearchVectorsMulti(ctx, [][]float32{{1, 0, 0}, {1, 0}}, 1, MultiModeAnyOf)
+		if !errors.Is(err, vector.ErrDimensionMismatch) {
+			t.Errorf("SearchVectorsMulti() error = %v, want ErrDimensionMismatch", err)
+		}
+	})
+}
+
+// unbatchedIndex hides an index's SearchBatch method, so vector.SearchBatch
+// falls back to one Search per query.
+type unbatchedIndex struct {
+	vector.Index
+}
+
+func TestDB_SearchVectorsBatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	for name, index := range map[string]vector.Index{
+		"flat":      vector.NewFlatIndex(3),
+		"hnsw":      vector.NewHNSWIndex(3, vector.WithSeed(3)),
+		"unbatched": unbatchedIndex{vector.NewFlatIndex(3)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithVectors(index))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer db.Close()
+
+			rng := rand.New(rand.NewSource(5))
+			for i := range 50 {
+				vec := []float32{rng.Float32() - 0.5, rng.Float32() - 0.5, rng.Float32() - 0.5}
+				if err := db.SetVector(ctx, fmt.Appendf(nil, "v%d", i), vec); err != nil {
+					t.Fatalf("SetVector() error = %v", err)
+				}
+			}
+
+			queries := [][]float32{{1, 0, 0}, {0, 1, 0}, {-1, -1, 0.5}}
+			batch, err := db.SearchVectorsBatch(ctx, queries, 4)
+			if err != nil {
+				t.Fatalf("SearchVectorsBatch() error = %v", err)
+			}
+			if len(batch) != len(queries) {
+				t.Fatalf("SearchVectorsBatch() returned %d result sets, want %d", len(batch), len(queries))
+			}
+			for i, query := range queries {
+				single, err := db.SearchVectors(ctx, query, 4)
+				if err != nil {
+					t.Fatalf("SearchVectors() error = %v", err)
+				}
+				if len(batch[i]) != len(single) {
+					t.Fatalf("SearchVectorsBatch()[%d] returned %d matches, SearchVectors %d", i, len(batch[i]), len(single))
+				}
+				for j := range single {
+					if !bytes.Equal(batch[i][j].ID, single[j].ID) || batch[i][j].Score != single[j].Score {
+						t.Errorf("SearchVectorsBatch()[%d][%d] = %s (%f), SearchVectors = %s (%f)",
+							i, j, batch[i][j].ID, batch[i][j].Score, single[j].ID, single[j].Score)
+					}
+				}
+			}
+
+			_, err = db.SearchVectorsBatch(ctx, [][]float32{{1, 0, 0}, {1, 0}}, 4)
+			if !errors.Is(err, ErrDimensionMismatch) || !errors.Is(err, vector.ErrDimensionMismatch) {
+				t.Errorf("SearchVectorsBatch(mismatch) error = %v, want ErrDimensionMismatch", err)
+			}
+		})
+	}
+}
+
+func TestDB_SearchVectorsWindow(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+
+	ctx := context.Background()
+	// Ordered by similarity to {1, 0, 0}: v0 nearest, v5 farthest.
+	vecs := [][]float32{{1, 0, 0}, {1, 0.2, 0}, {1, 0.5, 0}, {1, 1, 0}, {0.5, 1, 0}, {0, 1, 0}}
+	for i, vec := range vecs {
+		if err := db.SetVector(ctx, fmt.Appendf(nil, "v%d", i), vec); err != nil {
+			t.Fatalf("SetVector() error = %v", err)
+		}
+	}
+
+	query := []float32{1, 0, 0}
+	all, err := db.SearchVectors(ctx, query, len(vecs))
+	if err != nil {
+		t.Fatalf("SearchVectors() error = %v", err)
+	}
+
+	tests := []struct {
+		offset, k int
+		want      []string
+	}{
+		{0, 2, []string{"v0", "v1"}},
+		{2, 2, []string{"v2", "v3"}},
+		{5, 2, []string{"v5"}},
+		{10, 2, nil},
+	}
+	for _, tt := range tests {
+		window, err := db.SearchVectorsWindow(ctx, query, tt.offset, tt.k)
+		if err != nil {
+			t.Fatalf("SearchVectorsWindow(%d, %d) error = %v", tt.offset, tt.k, err)
+		}
+		if len(window) != len(tt.want) {
+			t.Fatalf("SearchVectorsWindow(%d, %d) returned %d matches, want %d", tt.offset, tt.k, len(window), len(tt.want))
+		}
+		for i, match := range window {
+			if string(match.ID) != tt.want[i] {
+				t.Errorf("SearchVectorsWindow(%d, %d)[%d] = %s, want %s", tt.offset, tt.k, i, match.ID, tt.want[i])
+			}
+			if full := all[tt.offset+i]; match.Score != full.Score {
+				t.Errorf("SearchVectorsWindow(%d, %d)[%d] score = %f, SearchVectors = %f", tt.offset, tt.k, i, match.Score, full.Score)
+			}
+		}
+	}
+
+	if _, err := db.SearchVectorsWindow(ctx, query, -1, 2); !errors.Is(err, ErrInvalidOffset) {
+		t.Errorf("SearchVectorsWindow(offset -1) error = %v, want ErrInvalidOffset", err)
+	}
+	if _, err := db.SearchVectorsWindow(ctx, query, 2, 0); !errors.Is(err, vector.ErrInvalidK) {
+		t.Errorf("SearchVectorsWindow(k 0) error = %v, want vector.ErrInvalidK", err)
+	}
+}
+
+func TestDB_DeleteVectorsByType(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+
+	ctx := context.Background()
+	db.SetSubjectVector(ctx, []byte("alice"), []float32{1, 0, 0})
+	db.SetSubjectVector(ctx, []byte("bob"), []float32{0, 1, 0})
+	db.SetObjectVector(ctx, []byte("tennis"), []float32{0, 0, 1})
+	db.SetObjectVector(ctx, []byte("golf"), []float32{1, 1, 0})
+	db.SetObjectVector(ctx, []byte("chess"), []float32{0, 1, 1})
+	db.SetTripleVector(ctx, &Triple{Subject: []byte("alice"), Predicate: []byte("likes"), Object: []byte("tennis")}, []float32{1, 0, 1})
+
+	n, err := db.DeleteVectorsByType(ctx, vector.IDTypeObject)
+	if err != nil {
+		t.Fatalf("DeleteVectorsByType() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("DeleteVectorsByType() = %d, want 3", n)
+	}
+	if db.VectorCount() != 3 {
+		t.Errorf("VectorCount() = %d, want 3", db.VectorCount())
+	}
+	if _, err := db.GetVector(ctx, vector.MakeID(vector.IDTypeObject, []byte("golf"))); !errors.Is(err, vector.ErrNotFound) {
+		t.Errorf("GetVector(object golf) error = %v, want ErrNotFound", err)
+	}
+	if _, err := db.GetVector(ctx, vector.MakeID(vector.IDTypeSubject, []byte("alice"))); err != nil {
+		t.Errorf("GetVector(subject alice) error = %v", err)
+	}
+
+	// Deleted vectors must stay deleted after reloading from the store
+	db.options.VectorIndex = vector.NewFlatIndex(3)
+	if err := db.LoadVectors(ctx); err != nil {
+		t.Fatalf("LoadVectors() error = %v", err)
+	}
+	if db.VectorCount() != 3 {
+		t.Errorf("VectorCount() after reload = %d, want 3", db.VectorCount())
+	}
+
+	n, err = db.DeleteVectorsByPrefix(ctx, vector.MakeID(vector.IDTypeSubject, []byte("bob")))
+	if err != nil {
+		t.Fatalf("DeleteVectorsByPrefix() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("DeleteVectorsByPrefix() = %d, want 1", n)
+	}
+
+	n, err = db.DeleteVectorsByType(ctx, vector.IDTypeObject)
+	if err != nil || n != 0 {
+		t.Errorf("DeleteVectorsByType() on empty type = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestDB_VectorDimensionErrors(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	err := db.SetVector(ctx, []byte("short"), []float32{1, 0})
+	if !errors.Is(err, ErrDimensionMismatch) || !errors.Is(err, vector.ErrDimensionMismatch) {
+		t.Errorf("SetVector() error = %v, want ErrDimensionMismatch", err)
+	}
+
+	_, err = db.SearchVectors(ctx, []float32{1, 0}, 1)
+	if !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("SearchVectors() error = %v, want ErrDimensionMismatch", err)
+	}
+
+	_, err = db.SearchVectorsMulti(ctx, [][]float32{{1, 0, 0}}, 1, MultiMode(99))
+	if !errors.Is(err, ErrInvalidMultiMode) {
+		t.Errorf("SearchVectorsMulti() error = %v, want ErrInvalidMultiMode", err)
+	}
+}
+
 func TestDB_VectorWithTypedIDs(t *testing.T) {
 	t.Parallel()
 	db, cleanup := setupTestDBWithVectors(t, 3)
@@ -232,6 +562,119 @@ func TestDB_VectorWithTypedIDs(t *testing.T) {
 	}
 }
 
+// slashIDCodec is a custom vector.IDCodec writing IDs as "type/part/part".
+type slashIDCodec struct{}
+
+func (slashIDCodec) MakeID(idType vector.IDType, parts ...[]byte) []byte {
+	id := []byte(string(idType) + "/")
+	return append(id, bytes.Join(parts, []byte("/"))...)
+}
+
+func (slashIDCodec) ParseID(id []byte) (vector.IDType, [][]byte) {
+	fields := bytes.Split(id, []byte("/"))
+	if len(fields) < 2 {
+		return vector.IDTypeCustom, [][]byte{id}
+	}
+	return vector.IDType(fields[0]), fields[1:]
+}
+
+func TestDB_VectorIDCodec(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	codec := slashIDCodec{}
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+		WithVectors(vector.NewFlatIndex(3)),
+		WithVectorIDCodec(codec),
+	)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	triple := graph.NewTripleFromStrings("alice", "likes", "tennis")
+	if err := db.SetSubjectVector(ctx, []byte("alice"), []float32{1, 0, 0}); err != nil {
+		t.Fatalf("SetSubjectVector() error = %v", err)
+	}
+	if err := db.SetObjectVector(ctx, []byte("tennis"), []float32{0, 1, 0}); err != nil {
+		t.Fatalf("SetObjectVector() error = %v", err)
+	}
+	if err := db.SetTripleVector(ctx, triple, []float32{0, 0, 1}); err != nil {
+		t.Fatalf("SetTripleVector() error = %v", err)
+	}
+
+	// Vectors are stored under the codec's IDs.
+	for _, tc := range []struct {
+		id    string
+		typ   vector.IDType
+		value string
+	}{
+		{"subject/alice", vector.IDTypeSubject, "alice"},
+		{"object/tennis", vector.IDTypeObject, "tennis"},
+		{"triple/" + triple.HashHex(), vector.IDTypeTriple, triple.HashHex()},
+	} {
+		if _, err := db.GetVector(ctx, []byte(tc.id)); err != nil {
+			t.Errorf("GetVector(%q) error = %v", tc.id, err)
+		}
+		typ, parts := codec.ParseID([]byte(tc.id))
+		if typ != tc.typ || len(parts) != 1 || string(parts[0]) != tc.value {
+			t.Errorf("ParseID(%q) = %v %q, want %v %q", tc.id, typ, parts, tc.typ, tc.value)
+		}
+	}
+	if _, err := db.GetVector(ctx, TripleVectorID(triple)); !errors.Is(err, vector.ErrNotFound) {
+		t.Errorf("GetVector(default triple ID) error = %v, want ErrNotFound", err)
+	}
+
+	objects, err := db.SearchSimilarObjects(ctx, []float32{0, 1, 0}, 3)
+	if err != nil {
+		t.Fatalf("SearchSimilarObjects() error = %v", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("SearchSimilarObjects() returned %d matches, want 1", len(objects))
+	}
+	if objects[0].IDType != vector.IDTypeObject || string(objects[0].Parts[0]) != "tennis" {
+		t.Errorf("SearchSimilarObjects()[0] = %v %q, want object tennis", objects[0].IDType, objects[0].Parts)
+	}
+
+	n, err := db.DeleteVectorsByType(ctx, vector.IDTypeSubject)
+	if err != nil {
+		t.Fatalf("DeleteVectorsByType() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("DeleteVectorsByType() = %d, want 1", n)
+	}
+}
+
+func TestDB_VectorMemoryBytes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	plain, cleanupPlain := setupTestDB(t)
+	defer cleanupPlain()
+	if got := plain.VectorMemoryBytes(); got != 0 {
+		t.Errorf("VectorMemoryBytes() without vectors = %d, want 0", got)
+	}
+
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+
+	if got := db.VectorMemoryBytes(); got != 0 {
+		t.Errorf("VectorMemoryBytes() on empty index = %d, want 0", got)
+	}
+	if err := db.SetVector(ctx, []byte("a"), []float32{1, 0, 0}); err != nil {
+		t.Fatalf("SetVector() error = %v", err)
+	}
+	withOne := db.VectorMemoryBytes()
+	if withOne <= 0 {
+		t.Fatalf("VectorMemoryBytes() after SetVector = %d, want > 0", withOne)
+	}
+	if err := db.DeleteVector(ctx, []byte("a")); err != nil {
+		t.Fatalf("DeleteVector() error = %v", err)
+	}
+	if got := db.VectorMemoryBytes(); got >= withOne {
+		t.Errorf("VectorMemoryBytes() after DeleteVector = %d, want < %d", got, withOne)
+	}
+}
+
 func TestDB_ConvenienceVectorMethods(t *testing.T) {
 	t.Parallel()
 	db, cleanup := setupTestDBWithVectors(t, 3)
@@ -257,6 +700,11 @@ func TestDB_ConvenienceVectorMethods(t *testing.T) {
 	if err != nil {
 		t.Fatalf("SetTripleVector() error = %v", err)
 	}
+	// Stored under the triple's hash, however the triple was built
+	rebuilt := &Triple{Subject: []byte("alice"), Predicate: []byte("likes"), Object: []byte("tennis")}
+	if _, err := db.GetVector(ctx, TripleVectorID(rebuilt)); err != nil {
+		t.Errorf("GetVector(TripleVectorID) error = %v", err)
+	}
 
 	// Verify all three vectors exist
 	if db.VectorCount() != 3 {
@@ -264,6 +712,120 @@ func TestDB_ConvenienceVectorMethods(t *testing.T) {
 	}
 }
 
+func TestDB_SearchVectorsWithinHops(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+
+	ctx := context.Background()
+	// x -> a -> b -> c along relatedTo, and x -> d along another predicate.
+	err := db.Put(ctx,
+		graph.NewTripleFromStrings("x", "relatedTo", "a"),
+		graph.NewTripleFromStrings("a", "relatedTo", "b"),
+		graph.NewTripleFromStrings("b", "relatedTo", "c"),
+		graph.NewTripleFromStrings("b", "relatedTo", "x"),
+		graph.NewTripleFromStrings("x", "boughtWith", "d"),
+	)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// c and d are the closest to the query but out of reach.
+	for id, vec := range map[string][]float32{
+		"x": {1, 0, 0},
+		"a": {0.5, 0.5, 0},
+		"b": {0, 1, 0},
+		"c": {1, 0, 0},
+		"d": {1, 0.01, 0},
+	} {
+		if err := db.SetObjectVector(ctx, []byte(id), vec); err != nil {
+			t.Fatalf("SetObjectVector(%s) error = %v", id, err)
+		}
+	}
+
+	query := []float32{1, 0, 0}
+	results, err := db.SearchVectorsWithinHops(ctx, []byte("x"), []byte("relatedTo"), 2, query, 10)
+	if err != nil {
+		t.Fatalf("SearchVectorsWithinHops() error = %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, string(r.Parts[0]))
+	}
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("SearchVectorsWithinHops(2 hops) = %v, want %v", got, want)
+	}
+
+	results, err = db.SearchVectorsWithinHops(ctx, []byte("x"), []byte("relatedTo"), 3, query, 1)
+	if err != nil {
+		t.Fatalf("SearchVectorsWithinHops() error = %v", err)
+	}
+	if len(results) != 1 || string(results[0].Parts[0]) != "c" {
+		t.Errorf("SearchVectorsWithinHops(3 hops, k=1) = %v, want c", results)
+	}
+
+	// A nil predicate follows edges of any predicate.
+	results, err = db.SearchVectorsWithinHops(ctx, []byte("x"), nil, 1, query, 10)
+	if err != nil {
+		t.Fatalf("SearchVectorsWithinHops(nil predicate) error = %v", err)
+	}
+	got = got[:0]
+	for _, r := range results {
+		got = append(got, string(r.Parts[0]))
+	}
+	if want := []string{"d", "a"}; !slices.Equal(got, want) {
+		t.Errorf("SearchVectorsWithinHops(nil predicate) = %v, want %v", got, want)
+	}
+
+	if _, err := db.SearchVectorsWithinHops(ctx, []byte("x"), []byte("relatedTo"), 2, []float32{1, 0}, 10); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("SearchVectorsWithinHops() with wrong dimensions error = %v, want ErrDimensionMismatch", err)
+	}
+}
+
+func TestDB_SearchVectorsWithinHopsDistance(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+		WithVectors(vector.NewFlatIndex(2, vector.WithDistance(vector.Euclidean))))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("x", "relatedTo", "a"),
+		graph.NewTripleFromStrings("x", "relatedTo", "b"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// a points the same way as the query but is far from it; b is near.
+	if err := db.SetObjectVector(ctx, []byte("a"), []float32{10, 0}); err != nil {
+		t.Fatalf("SetObjectVector() error = %v", err)
+	}
+	if err := db.SetObjectVector(ctx, []byte("b"), []float32{1, 1}); err != nil {
+		t.Fatalf("SetObjectVector() error = %v", err)
+	}
+
+	query := []float32{1, 0}
+	results, err := db.SearchVectorsWithinHops(ctx, []byte("x"), []byte("relatedTo"), 1, query, 2)
+	if err != nil {
+		t.Fatalf("SearchVectorsWithinHops() error = %v", err)
+	}
+	want, err := db.SearchVectors(ctx, query, 2)
+	if err != nil {
+		t.Fatalf("SearchVectors() error = %v", err)
+	}
+	if len(results) != 2 || string(results[0].Parts[0]) != "b" {
+		t.Fatalf("SearchVectorsWithinHops() = %v, want b first", results)
+	}
+	for i := range results {
+		if !bytes.Equal(results[i].ID, want[i].ID) || results[i].Distance != want[i].Distance {
+			t.Errorf("result %d = %s at %v, SearchVectors has %s at %v",
+				i, results[i].ID, results[i].Distance, want[i].ID, want[i].Distance)
+		}
+	}
+}
+
 func TestDB_SearchSimilarObjects(t *testing.T) {
 	t.Parallel()
 	db, cleanup := setupTestDBWithVectors(t, 3)
@@ -369,6 +931,228 @@ func TestDB_VectorPersistence(t *testing.T) {
 	}
 }
 
+func countVectorDeltas(t *testing.T, db *DB) int {
+	t.Helper()
+	iter := db.store.NewIterator(vectorDeltaRange(), nil)
+	defer iter.Release()
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	return count
+}
+
+func TestDB_VectorDeltaLog(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(7))
+
+	randomVec := func() []float32 {
+		v := make([]float32, 8)
+		for i := range v {
+			v[i] = rng.Float32()*2 - 1
+		}
+		return v
+	}
+
+	want := make(map[string][]float32)
+
+	// Write in several rounds: a snapshot, then deltas on top of it
+	{
+		db, err := Open(dbPath, WithVectors(vector.NewHNSWIndex(8, vector.WithSeed(1))), WithVectorDeltaLog(0))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+
+		for i := 0; i < 20; i++ {
+			id := fmt.Sprintf("base-%d", i)
+			want[id] = randomVec()
+			if err := db.SetVector(ctx, []byte(id), want[id]); err != nil {
+				t.Fatalf("SetVector() error = %v", err)
+			}
+		}
+		if got := countVectorDeltas(t, db); got != 20 {
+			t.Errorf("deltas before save = %d, want 20", got)
+		}
+		if err := db.SaveVectorIndex(ctx); err != nil {
+			t.Fatalf("SaveVectorIndex() error = %v", err)
+		}
+		if got := countVectorDeltas(t, db); got != 0 {
+			t.Errorf("deltas after save = %d, want 0", got)
+		}
+
+		for round := 0; round < 3; round++ {
+			for i := 0; i < 5; i++ {
+				id := fmt.Sprintf("round%d-%d", round, i)
+				want[id] = randomVec()
+				if err := db.SetVector(ctx, []byte(id), want[id]); err != nil {
+					t.Fatalf("SetVector() error = %v", err)
+				}
+			}
+		}
+
+		// Delete one snapshot vector and one delta vector
+		for _, id := range []string{"base-3", "round1-2"} {
+			if err := db.DeleteVector(ctx, []byte(id)); err != nil {
+				t.Fatalf("DeleteVector() error = %v", err)
+			}
+			delete(want, id)
+		}
+		// Overwrite a snapshot vector
+		want["base-5"] = randomVec()
+		if err := db.SetVector(ctx, []byte("base-5"), want["base-5"]); err != nil {
+			t.Fatalf("SetVector() error = %v", err)
+		}
+
+		if got := countVectorDeltas(t, db); got != 18 {
+			t.Errorf("deltas after incremental writes = %d, want 18", got)
+		}
+		db.Close()
+	}
+
+	// Reopen: the snapshot is imported and the deltas replayed
+	{
+		db, err := Open(dbPath, WithVectors(vector.NewHNSWIndex(8)), WithVectorDeltaLog(0))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer db.Close()
+
+		if err := db.LoadVectors(ctx); err != nil {
+			t.Fatalf("LoadVectors() error = %v", err)
+		}
+		if db.VectorCount() != len(want) {
+			t.Fatalf("VectorCount() = %d, want %d", db.VectorCount(), len(want))
+		}
+		for id, vec := range want {
+			got, err := db.GetVector(ctx, []byte(id))
+			if err != nil {
+				t.Fatalf("GetVector(%s) error = %v", id, err)
+			}
+			for i := range vec {
+				if got[i] != vec[i] {
+					t.Fatalf("GetVector(%s) = %v, want %v", id, got, vec)
+				}
+			}
+			results, err := db.SearchVectors(ctx, vec, 1)
+			if err != nil {
+				t.Fatalf("SearchVectors() error = %v", err)
+			}
+			if len(results) != 1 || string(results[0].ID) != id {
+				t.Errorf("SearchVectors(%s) nearest = %v", id, results)
+			}
+		}
+		for _, id := range []string{"base-3", "round1-2"} {
+			if _, err := db.GetVector(ctx, []byte(id)); err == nil {
+				t.Errorf("GetVector(%s) should fail after delete", id)
+			}
+		}
+	}
+}
+
+func TestDB_VectorDeltaLogAutoCompact(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+		WithVectors(vector.NewHNSWIndex(3)), WithVectorDeltaLog(4))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 6; i++ {
+		if err := db.SetVector(ctx, []byte(fmt.Sprintf("v%d", i)), []float32{1, float32(i), 0}); err != nil {
+			t.Fatalf("SetVector() error = %v", err)
+		}
+	}
+
+	// The fourth write folds the log into a snapshot; two deltas remain
+	if got := countVectorDeltas(t, db); got != 2 {
+		t.Errorf("deltas = %d, want 2", got)
+	}
+	if _, err := db.store.Get(vectorSnapshotKey, nil); err != nil {
+		t.Errorf("snapshot should exist after compaction: %v", err)
+	}
+}
+
+func TestDB_VectorDeltaLogClockStep(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	open := func() *DB {
+		db, err := Open(dbPath, WithVectors(vector.NewHNSWIndex(3)), WithVectorDeltaLog(0))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		return db
+	}
+
+	db := open()
+	if err := db.SaveVectorIndex(ctx); err != nil {
+		t.Fatalf("SaveVectorIndex() error = %v", err)
+	}
+	if err := db.SetVector(ctx, []byte("a"), []float32{1, 0, 0}); err != nil {
+		t.Fatalf("SetVector() error = %v", err)
+	}
+
+	// Move the delta an hour ahead, as if the clock has since stepped back
+	iter := db.store.NewIterator(vectorDeltaRange(), nil)
+	if !iter.Next() {
+		t.Fatal("expected a vector delta")
+	}
+	key := append([]byte{}, iter.Key()...)
+	value := append([]byte{}, iter.Value()...)
+	iter.Release()
+	ahead := append([]byte{}, key...)
+	binary.BigEndian.PutUint64(ahead[len(vectorDeltaPrefix):], uint64(time.Now().Add(time.Hour).UnixNano()))
+	batch := NewBatch()
+	batch.Delete(key)
+	batch.Put(ahead, value)
+	if err := db.writeBatch(batch); err != nil {
+		t.Fatalf("writeBatch() error = %v", err)
+	}
+	db.Close()
+
+	db = open()
+	if err := db.SetVector(ctx, []byte("a"), []float32{0, 1, 0}); err != nil {
+		t.Fatalf("SetVector() error = %v", err)
+	}
+	db.Close()
+
+	db = open()
+	defer db.Close()
+	if err := db.LoadVectors(ctx); err != nil {
+		t.Fatalf("LoadVectors() error = %v", err)
+	}
+	results, err := db.SearchVectors(ctx, []float32{0, 1, 0}, 1)
+	if err != nil {
+		t.Fatalf("SearchVectors() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Score < 0.99 {
+		t.Errorf("SearchVectors() = %v, want the replayed second write", results)
+	}
+}
+
+func TestDB_VectorDeltaLogRequiresHNSW(t *testing.T) {
+	t.Parallel()
+
+	_, err := Open(filepath.Join(t.TempDir(), "test.db"),
+		WithVectors(vector.NewFlatIndex(3)), WithVectorDeltaLog(0))
+	if !errors.Is(err, ErrVectorSnapshotUnsupported) {
+		t.Errorf("Open() error = %v, want ErrVectorSnapshotUnsupported", err)
+	}
+
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+	if err := db.SaveVectorIndex(context.Background()); !errors.Is(err, ErrVectorSnapshotUnsupported) {
+		t.Errorf("SaveVectorIndex() error = %v, want ErrVectorSnapshotUnsupported", err)
+	}
+}
+
 func TestDB_LoadVectorsDimensionMismatch(t *testing.T) {
 	t.Parallel()
 
@@ -516,6 +1300,62 @@ func TestDB_VectorAndTriplesTogether(t *testing.T) {
 	}
 }
 
+func TestDB_SearchSimilarWithTriples(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDBWithVectors(t, 3)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "likes", "tennis"),
+		graph.NewTripleFromStrings("dave", "likes", "tennis"),
+		graph.NewTripleFromStrings("bob", "likes", "badminton"),
+		graph.NewTripleFromStrings("bob", "plays", "tennis"),
+		graph.NewTripleFromStrings("charlie", "likes", "football"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	db.SetObjectVector(ctx, []byte("tennis"), []float32{1, 0, 0})
+	db.SetObjectVector(ctx, []byte("badminton"), []float32{0.9, 0.1, 0})
+	db.SetObjectVector(ctx, []byte("squash"), []float32{0.8, 0.2, 0})
+	db.SetObjectVector(ctx, []byte("football"), []float32{0, 1, 0})
+	db.SetSubjectVector(ctx, []byte("bob"), []float32{1, 0, 0})
+
+	results, err := db.SearchSimilarObjectsWithTriples(ctx, []byte("likes"), []float32{1, 0, 0}, 3)
+	if err != nil {
+		t.Fatalf("SearchSimilarObjectsWithTriples() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"tennis":    {"alice likes tennis", "dave likes tennis"},
+		"badminton": {"bob likes badminton"},
+		"squash":    nil, // nobody likes it, but it is still similar
+	}
+	if len(results) != len(want) {
+		t.Fatalf("SearchSimilarObjectsWithTriples() returned %d results, want %d", len(results), len(want))
+	}
+	for _, r := range results {
+		sport := string(r.Parts[0])
+		var got []string
+		for _, triple := range r.Triples {
+			got = append(got, fmt.Sprintf("%s %s %s", triple.Subject, triple.Predicate, triple.Object))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want[sport]) {
+			t.Errorf("%s triples = %v, want %v", sport, got, want[sport])
+		}
+	}
+
+	// Subjects, with every predicate
+	results, err = db.SearchSimilarSubjectsWithTriples(ctx, nil, []float32{1, 0, 0}, 1)
+	if err != nil {
+		t.Fatalf("SearchSimilarSubjectsWithTriples() error = %v", err)
+	}
+	if len(results) != 1 || string(results[0].Parts[0]) != "bob" || len(results[0].Triples) != 2 {
+		t.Errorf("SearchSimilarSubjectsWithTriples() = %+v, want bob with 2 triples", results)
+	}
+}
+
 // MockEmbedder for testing auto-embed functionality
 type mockEmbedder struct {
 	dims int
@@ -546,6 +1386,41 @@ func (m *mockEmbedder) Dimensions() int {
 	return m.dims
 }
 
+func TestDB_ReEmbedAll(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	index := vector.NewFlatIndex(8)
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(&mockEmbedder{dims: 8}, AutoEmbedObjects))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.PutWithOptions(ctx, PutOptions{SkipAutoEmbed: true},
+		graph.NewTripleFromStrings("alice", "likes", "tennis"),
+		graph.NewTripleFromStrings("bob", "likes", "golf"),
+	); err != nil {
+		t.Fatalf("PutWithOptions() error = %v", err)
+	}
+	if n := index.Len(); n != 0 {
+		t.Fatalf("index has %d vectors after SkipAutoEmbed put, want 0", n)
+	}
+
+	if err := db.ReEmbedAll(ctx); err != nil {
+		t.Fatalf("ReEmbedAll() error = %v", err)
+	}
+	for _, object := range []string{"tennis", "golf"} {
+		if _, err := db.GetVector(ctx, vector.MakeID(vector.IDTypeObject, []byte(object))); err != nil {
+			t.Errorf("GetVector(%s) after ReEmbedAll error = %v", object, err)
+		}
+	}
+	if n := index.Len(); n != 2 {
+		t.Errorf("index has %d vectors after ReEmbedAll, want 2", n)
+	}
+}
+
 func TestDB_EmbedAndSetVector(t *testing.T) {
 	t.Parallel()
 
@@ -623,6 +1498,56 @@ func TestDB_SearchVectorsByTextNoEmbedder(t *testing.T) {
 	if err != ErrEmbedderRequired {
 		t.Errorf("SearchVectorsByText() error = %v, want ErrEmbedderRequired", err)
 	}
+
+	_, err = db.SearchSimilarObjectsByText(ctx, "test", 5)
+	if err != ErrEmbedderRequired {
+		t.Errorf("SearchSimilarObjectsByText() error = %v, want ErrEmbedderRequired", err)
+	}
+}
+
+func TestDB_SearchVectorsByTextIDType(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+
+	index := vector.NewFlatIndex(8)
+	embedder := &mockEmbedder{dims: 8}
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	// Subjects embedded with the exact query text rank above every object
+	db.EmbedAndSetVector(ctx, vector.MakeID(vector.IDTypeSubject, []byte("s1")), "racket sports")
+	db.EmbedAndSetVector(ctx, vector.MakeID(vector.IDTypeSubject, []byte("s2")), "racket sports")
+	db.EmbedAndSetVector(ctx, vector.MakeID(vector.IDTypeSubject, []byte("s3")), "racket sports")
+	db.EmbedAndSetVector(ctx, vector.MakeID(vector.IDTypeObject, []byte("tennis")), "tennis racket sport")
+	db.EmbedAndSetVector(ctx, vector.MakeID(vector.IDTypeObject, []byte("football")), "football soccer ball")
+
+	results, err := db.SearchVectorsByText(ctx, "racket sports", 2, vector.IDTypeObject)
+	if err != nil {
+		t.Fatalf("SearchVectorsByText() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("SearchVectorsByText() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.IDType != vector.IDTypeObject {
+			t.Errorf("SearchVectorsByText() returned non-object: %v", r.IDType)
+		}
+	}
+
+	subjects, err := db.SearchSimilarSubjectsByText(ctx, "racket sports", 5)
+	if err != nil {
+		t.Fatalf("SearchSimilarSubjectsByText() error = %v", err)
+	}
+	if len(subjects) != 3 {
+		t.Errorf("SearchSimilarSubjectsByText() returned %d results, want 3", len(subjects))
+	}
 }
 
 // ============================================================================
@@ -699,6 +1624,90 @@ func TestDB_HybridSearch(t *testing.T) {
 	}
 }
 
+func TestDB_HybridSearchVectorFirst(t *testing.T) {
+	t.Parallel()
+
+	distances := map[string]vector.DistanceFunc{
+		"cosine":    vector.Cosine,
+		"euclidean": vector.Euclidean,
+	}
+	for name, distance := range distances {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			testHybridSearchVectorFirst(t, vector.NewFlatIndex(3, vector.WithDistance(distance)))
+		})
+	}
+}
+
+func testHybridSearchVectorFirst(t *testing.T, index vector.Index) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+
+	db, err := Open(dbPath, WithVectors(index))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	db.Put(ctx, graph.NewTripleFromStrings("alice", "likes", "tennis"))
+	db.Put(ctx, graph.NewTripleFromStrings("alice", "likes", "badminton"))
+	db.Put(ctx, graph.NewTripleFromStrings("bob", "likes", "football"))
+	db.Put(ctx, graph.NewTripleFromStrings("bob", "likes", "tennis"))
+	db.Put(ctx, graph.NewTripleFromStrings("charlie", "likes", "swimming"))
+	db.Put(ctx, graph.NewTripleFromStrings("charlie", "likes", "chess")) // no vector
+	db.Put(ctx, graph.NewTripleFromStrings("dave", "likes", "golf"))
+
+	db.SetObjectVector(ctx, []byte("tennis"), []float32{0.9, 0.3, 0})
+	db.SetObjectVector(ctx, []byte("badminton"), []float32{1, 0, 0})
+	db.SetObjectVector(ctx, []byte("football"), []float32{0.1, 0.95, 0})
+	db.SetObjectVector(ctx, []byte("swimming"), []float32{0, 0, 1})
+	db.SetObjectVector(ctx, []byte("squash"), []float32{1, 0.05, 0}) // nobody likes it
+	db.SetObjectVector(ctx, []byte("golf"), []float32{4, 2, 0})      // close in angle, far in space
+	db.SetSubjectVector(ctx, []byte("alice"), []float32{1, 0, 0})    // wrong type
+
+	resultSet := func(solutions []Solution) []string {
+		keys := make([]string, len(solutions))
+		for i, sol := range solutions {
+			keys[i] = fmt.Sprintf("%s/%s/%.4f", sol["person"], sol["sport"], GetVectorScore(sol))
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	patterns := []*graph.Pattern{
+		{Subject: graph.Binding("person"), Predicate: graph.ExactString("likes"), Object: graph.Binding("sport")},
+	}
+
+	// TopK values avoid splitting the tied tennis solutions
+	for _, topK := range []int{1, 3, 4, 10} {
+		for _, minScore := range []float32{0, 0.8} {
+			search := func(vectorFirst bool) []string {
+				solutions, err := db.Search(ctx, patterns, &SearchOptions{
+					VectorFilter: &VectorFilter{
+						Variable:    "sport",
+						Query:       []float32{1, 0, 0},
+						TopK:        topK,
+						MinScore:    minScore,
+						VectorFirst: vectorFirst,
+					},
+				})
+				if err != nil {
+					t.Fatalf("Search(VectorFirst=%v) error = %v", vectorFirst, err)
+				}
+				return resultSet(solutions)
+			}
+
+			want := search(false)
+			got := search(true)
+			if !slices.Equal(got, want) {
+				t.Errorf("TopK=%d MinScore=%v: VectorFirst results = %v, want %v", topK, minScore, got, want)
+			}
+		}
+	}
+}
+
 func TestDB_HybridSearchWithMinScore(t *testing.T) {
 	t.Parallel()
 
@@ -1070,6 +2079,110 @@ func TestDB_AutoEmbedDisabled(t *testing.T) {
 	}
 }
 
+func TestDB_AutoEmbedPredicate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+
+	index := vector.NewFlatIndex(8)
+	embedder := &mockEmbedder{dims: 8}
+
+	db, err := Open(dbPath,
+		WithVectors(index),
+		WithAutoEmbed(embedder, AutoEmbedNone),
+		WithAutoEmbedPredicate([]byte("description"), AutoEmbedObjects),
+	)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	err = db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "description", "plays tennis on weekends"),
+		graph.NewTripleFromStrings("alice", "type", "person"),
+		graph.NewTripleFromStrings("bob", "description", "enjoys long walks"),
+		graph.NewTripleFromStrings("bob", "type", "person"),
+	)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if db.VectorCount() != 2 {
+		t.Errorf("VectorCount() = %d, want 2", db.VectorCount())
+	}
+	for _, text := range []string{"plays tennis on weekends", "enjoys long walks"} {
+		if _, err := db.GetVector(ctx, vector.MakeID(vector.IDTypeObject, []byte(text))); err != nil {
+			t.Errorf("GetVector(%q) error = %v", text, err)
+		}
+	}
+	for _, id := range [][]byte{
+		vector.MakeID(vector.IDTypeObject, []byte("person")),
+		vector.MakeID(vector.IDTypeSubject, []byte("alice")),
+	} {
+		if _, err := db.GetVector(ctx, id); err == nil {
+			t.Errorf("GetVector(%s) found a vector, want none", id)
+		}
+	}
+}
+
+func TestDB_AutoEmbedTriples(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "test.db")
+
+	index := vector.NewFlatIndex(8)
+	embedder := &mockEmbedder{dims: 8}
+
+	var texts []string
+	db, err := Open(dbPath,
+		WithVectors(index),
+		WithAutoEmbed(embedder, AutoEmbedTriples),
+		WithTripleTextFunc(func(t *Triple) string {
+			text := DefaultTripleText(t)
+			texts = append(texts, text)
+			return text
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	fact := graph.NewTripleFromStrings("alice", "likes", "tennis")
+	err = db.Put(ctx,
+		fact,
+		graph.NewTripleFromStrings("bob", "reads", "poetry"),
+		graph.NewTripleFromStrings("carol", "owns", "a boat"),
+	)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Only whole triples are embedded, not their components.
+	if db.VectorCount() != 3 {
+		t.Errorf("VectorCount() = %d, want 3", db.VectorCount())
+	}
+	if len(texts) != 3 || !slices.Contains(texts, "alice likes tennis") {
+		t.Errorf("embedded texts = %q, want the three triples as text", texts)
+	}
+	id := TripleVectorID(fact)
+	if _, err := db.GetVector(ctx, id); err != nil {
+		t.Fatalf("GetVector(triple) error = %v", err)
+	}
+
+	results, err := db.SearchVectorsByText(ctx, "alice likes tennis", 1, vector.IDTypeTriple)
+	if err != nil {
+		t.Fatalf("SearchVectorsByText() error = %v", err)
+	}
+	if len(results) != 1 || !bytes.Equal(results[0].ID, id) {
+		t.Fatalf("SearchVectorsByText() = %v, want %s", results, id)
+	}
+}
+
 func TestDB_AutoEmbedIntegrationWithSearch(t *testing.T) {
 	t.Parallel()
 
@@ -1740,7 +2853,7 @@ func TestDB_AsyncAutoEmbed(t *testing.T) {
 	embedder := &slowMockEmbedder{dims: 8}
 
 	// Enable async auto-embed with buffer size 10
-	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(10))
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(10, 1))
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
@@ -1776,6 +2889,221 @@ func TestDB_AsyncAutoEmbed(t *testing.T) {
 	}
 }
 
+// latencyEmbedder is a concurrency-safe embedder that sleeps on every batch,
+// like a remote embedding service, and counts how often each text is embedded.
+type latencyEmbedder struct {
+	dims  int
+	delay time.Duration
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *latencyEmbedder) Embed(text string) ([]float32, error) {
+	vecs, err := m.EmbedBatch([]string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+func (m *latencyEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	time.Sleep(m.delay)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts == nil {
+		m.counts = make(map[string]int)
+	}
+	results := make([][]float32, len(texts))
+	for i, text := range texts {
+		m.counts[text]++
+		vec := make([]float32, m.dims)
+		for j, c := range text {
+			vec[j%m.dims] += float32(c) / 1000
+		}
+		results[i] = vector.NormalizeCopy(vec)
+	}
+	return results, nil
+}
+
+func (m *latencyEmbedder) Dimensions() int {
+	return m.dims
+}
+
+// TestDB_AsyncAutoEmbedWorkers tests that several workers drain the queue
+// concurrently without embedding a shared value twice.
+func TestDB_AsyncAutoEmbedWorkers(t *testing.T) {
+	t.Parallel()
+
+	const puts = 16
+	run := func(workers int) (time.Duration, *latencyEmbedder) {
+		t.Helper()
+		embedder := &latencyEmbedder{dims: 8, delay: 20 * time.Millisecond}
+		db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+			WithVectors(vector.NewFlatIndex(8)),
+			WithAutoEmbed(embedder, AutoEmbedObjects),
+			WithAsyncAutoEmbed(puts, workers),
+		)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer db.Close()
+
+		ctx := context.Background()
+		start := time.Now()
+		for i := 0; i < puts; i++ {
+			// Every Put shares the "shared" object with all the others.
+			if err := db.Put(ctx,
+				graph.NewTripleFromStrings("s", "likes", "shared"),
+				graph.NewTripleFromStrings("s", "likes", fmt.Sprintf("thing%d", i)),
+			); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+		}
+		if err := db.WaitForEmbeddings(ctx); err != nil {
+			t.Fatalf("WaitForEmbeddings() error = %v", err)
+		}
+		elapsed := time.Since(start)
+
+		if got := db.VectorCount(); got != puts+1 {
+			t.Errorf("workers=%d: VectorCount() = %d, want %d", workers, got, puts+1)
+		}
+		return elapsed, embedder
+	}
+
+	serial, _ := run(1)
+	parallel, embedder := run(4)
+
+	for text, n := range embedder.counts {
+		if n != 1 {
+			t.Errorf("%q embedded %d times, want 1", text, n)
+		}
+	}
+	if parallel >= serial*3/4 {
+		t.Errorf("4 workers took %v, 1 worker took %v; want a clear speed-up", parallel, serial)
+	}
+}
+
+// flakyEmbedder fails its first failures batches, then embeds like
+// latencyEmbedder. A negative failures count fails forever.
+type flakyEmbedder struct {
+	latencyEmbedder
+	failures int
+	calls    int
+}
+
+func (m *flakyEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	m.mu.Lock()
+	m.calls++
+	fail := m.failures < 0 || m.calls <= m.failures
+	m.mu.Unlock()
+	if fail {
+		return nil, errors.New("service unavailable")
+	}
+	return m.latencyEmbedder.EmbedBatch(texts)
+}
+
+// TestDB_AsyncAutoEmbedRetry tests that failed async embeddings are retried
+// and, once retries are exhausted, handed to the error handler.
+func TestDB_AsyncAutoEmbedRetry(t *testing.T) {
+	t.Parallel()
+
+	type failure struct {
+		id, text string
+		err      error
+	}
+	run := func(embedder *flakyEmbedder) (*DB, []failure) {
+		t.Helper()
+		var mu sync.Mutex
+		var failures []failure
+		db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+			WithVectors(vector.NewFlatIndex(8)),
+			WithAutoEmbed(embedder, AutoEmbedObjects),
+			WithAsyncAutoEmbed(10, 1),
+			WithEmbedRetry(3, time.Millisecond),
+			WithEmbedErrorHandler(func(id []byte, text string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				failures = append(failures, failure{string(id), text, err})
+			}),
+		)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		ctx := context.Background()
+		if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "likes", "tennis")); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		if err := db.WaitForEmbeddings(ctx); err != nil {
+			t.Fatalf("WaitForEmbeddings() error = %v", err)
+		}
+		if n := db.PendingEmbeddings(); n != 0 {
+			t.Errorf("PendingEmbeddings() = %d, want 0", n)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return db, slices.Clone(failures)
+	}
+
+	// Two failures fit within three retries.
+	flaky := &flakyEmbedder{latencyEmbedder: latencyEmbedder{dims: 8}, failures: 2}
+	db, failures := run(flaky)
+	if len(failures) != 0 {
+		t.Errorf("error handler called for recovered embedding: %v", failures)
+	}
+	if db.VectorCount() != 1 {
+		t.Errorf("VectorCount() = %d, want 1", db.VectorCount())
+	}
+	if flaky.calls != 3 {
+		t.Errorf("embedder called %d times, want 3", flaky.calls)
+	}
+
+	// A permanent failure ends up in the error handler after all retries.
+	broken := &flakyEmbedder{latencyEmbedder: latencyEmbedder{dims: 8}, failures: -1}
+	db, failures = run(broken)
+	if db.VectorCount() != 0 {
+		t.Errorf("VectorCount() = %d, want 0", db.VectorCount())
+	}
+	if broken.calls != 4 {
+		t.Errorf("embedder called %d times, want 4", broken.calls)
+	}
+	wantID := string(vector.MakeID(vector.IDTypeObject, []byte("tennis")))
+	if len(failures) != 1 || failures[0].id != wantID || failures[0].text != "tennis" || failures[0].err == nil {
+		t.Errorf("error handler calls = %v, want one for tennis", failures)
+	}
+
+	// An operation waiting for its retry still counts as pending.
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"),
+		WithVectors(vector.NewFlatIndex(8)),
+		WithAutoEmbed(&flakyEmbedder{latencyEmbedder: latencyEmbedder{dims: 8}, failures: -1}, AutoEmbedObjects),
+		WithAsyncAutoEmbed(10, 1),
+		WithEmbedRetry(1, 200*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "likes", "golf")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for db.embedRetrying.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := db.PendingEmbeddings(); n != 1 {
+		t.Errorf("PendingEmbeddings() while retrying = %d, want 1", n)
+	}
+	if err := db.WaitForEmbeddings(ctx); err != nil {
+		t.Fatalf("WaitForEmbeddings() error = %v", err)
+	}
+	if n := db.PendingEmbeddings(); n != 0 {
+		t.Errorf("PendingEmbeddings() after retries = %d, want 0", n)
+	}
+}
+
 // TestDB_AsyncAutoEmbedMultiple tests async embedding with multiple Puts.
 func TestDB_AsyncAutoEmbedMultiple(t *testing.T) {
 	t.Parallel()
@@ -1786,7 +3114,7 @@ func TestDB_AsyncAutoEmbedMultiple(t *testing.T) {
 	index := vector.NewFlatIndex(8)
 	embedder := &slowMockEmbedder{dims: 8}
 
-	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(100))
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(100, 1))
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
@@ -1825,7 +3153,7 @@ func TestDB_AsyncAutoEmbedBatchPut(t *testing.T) {
 	index := vector.NewFlatIndex(8)
 	embedder := &slowMockEmbedder{dims: 8}
 
-	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(50))
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(50, 1))
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
@@ -1866,7 +3194,7 @@ func TestDB_AsyncAutoEmbedClose(t *testing.T) {
 	index := vector.NewFlatIndex(8)
 	embedder := &slowMockEmbedder{dims: 8}
 
-	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(10))
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(10, 1))
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
@@ -1906,6 +3234,63 @@ func TestDB_AsyncAutoEmbedClose(t *testing.T) {
 	}
 }
 
+// TestDB_AsyncAutoEmbedConcurrentClose tests that Close can be called from
+// many goroutines at once, draining the embed workers exactly once.
+func TestDB_AsyncAutoEmbedConcurrentClose(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	db, err := Open(t.TempDir(),
+		WithVectors(vector.NewFlatIndex(8)),
+		WithAutoEmbed(&slowMockEmbedder{dims: 8}, AutoEmbedObjects),
+		WithAsyncAutoEmbed(10, 1),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for i := range 5 {
+		sport := []byte{'s', byte('0' + i)}
+		if err := db.Put(ctx, NewTriple([]byte("alice"), []byte("likes"), sport)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- db.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	}
+	if db.IsOpen() {
+		t.Error("IsOpen() = true after Close")
+	}
+	if n := strings.Count(logs.String(), "embed worker stopped"); n != 1 {
+		t.Errorf("embed workers drained %d times, want 1", n)
+	}
+	if n := strings.Count(logs.String(), "database closed"); n != 1 {
+		t.Errorf("database closed %d times, want 1", n)
+	}
+	if err := db.WaitForEmbeddings(ctx); err != nil {
+		t.Errorf("WaitForEmbeddings() after Close error = %v", err)
+	}
+}
+
 // TestDB_AsyncAutoEmbedPendingCount tests the PendingEmbeddings method.
 func TestDB_AsyncAutoEmbedPendingCount(t *testing.T) {
 	t.Parallel()
@@ -1917,7 +3302,7 @@ func TestDB_AsyncAutoEmbedPendingCount(t *testing.T) {
 	embedder := &slowMockEmbedder{dims: 8}
 
 	// Use small buffer to observe queueing
-	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(5))
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(5, 1))
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
@@ -2000,7 +3385,7 @@ func TestDB_AsyncAutoEmbedWithContextCancel(t *testing.T) {
 	index := vector.NewFlatIndex(8)
 	embedder := &slowMockEmbedder{dims: 8}
 
-	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(100))
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(100, 1))
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
@@ -2040,7 +3425,7 @@ func TestDB_AsyncAutoEmbedIntegrationWithSearch(t *testing.T) {
 	index := vector.NewFlatIndex(8)
 	embedder := &slowMockEmbedder{dims: 8}
 
-	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(50))
+	db, err := Open(dbPath, WithVectors(index), WithAutoEmbed(embedder, AutoEmbedObjects), WithAsyncAutoEmbed(50, 1))
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
 	}
@@ -2864,3 +4249,138 @@ func TestDB_EmbedAndSetVector_EmbedderError(t *testing.T) {
 		t.Error("EmbedAndSetVector with failing embedder should return error")
 	}
 }
+
+// TestVectorEncodingRoundTrip tests that reduced-precision encodings decode
+// within their documented error bounds.
+func TestVectorEncodingRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(7))
+	vec := make([]float32, 64)
+	var maxAbs float32
+	for i := range vec {
+		vec[i] = rng.Float32()*4 - 2
+		if a := float32(math.Abs(float64(vec[i]))); a > maxAbs {
+			maxAbs = a
+		}
+	}
+
+	tests := []struct {
+		name    string
+		enc     VectorEncoding
+		size    int
+		maxDiff func(v float32) float64
+	}{
+		{"float32", VectorFloat32, 4 * len(vec), func(float32) float64 { return 0 }},
+		{"float16", VectorFloat16, 1 + 2*len(vec), func(v float32) float64 { return math.Abs(float64(v))/1024 + 1e-7 }},
+		{"int8", VectorInt8, 5 + len(vec), func(float32) float64 { return float64(maxAbs)/127/2 + 1e-6 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := encodeVectorValue(vec, tt.enc)
+			if len(value) != tt.size {
+				t.Errorf("encoded length = %d, want %d", len(value), tt.size)
+			}
+			got := decodeVectorValue(value)
+			if len(got) != len(vec) {
+				t.Fatalf("decoded length = %d, want %d", len(got), len(vec))
+			}
+			for i := range vec {
+				if diff := math.Abs(float64(got[i] - vec[i])); diff > tt.maxDiff(vec[i]) {
+					t.Errorf("value %d = %v, want %v (diff %v)", i, got[i], vec[i], diff)
+				}
+			}
+		})
+	}
+
+	zero := decodeVectorValue(encodeVectorValue(make([]float32, 8), VectorInt8))
+	for i, v := range zero {
+		if v != 0 {
+			t.Errorf("zero vector value %d = %v, want 0", i, v)
+		}
+	}
+}
+
+// TestDB_VectorStorageEncoding tests that vectors persisted with a reduced
+// encoding reload with the same search ranking.
+func TestDB_VectorStorageEncoding(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	const dims = 32
+	rng := rand.New(rand.NewSource(11))
+	query := make([]float32, dims)
+	orth := make([]float32, dims)
+	for i := range query {
+		query[i] = rng.Float32()*2 - 1
+		orth[i] = rng.Float32()*2 - 1
+	}
+	vector.Normalize(query)
+	// Make orth perpendicular to query so each vector's similarity to the
+	// query is set exactly by its angle.
+	var dot float32
+	for i := range query {
+		dot += query[i] * orth[i]
+	}
+	for i := range orth {
+		orth[i] -= dot * query[i]
+	}
+	vector.Normalize(orth)
+
+	var ids []string
+	vecs := make(map[string][]float32)
+	for n := 0; n < 12; n++ {
+		angle := float64(n) * 7 * math.Pi / 180
+		vec := make([]float32, dims)
+		for i := range vec {
+			vec[i] = query[i]*float32(math.Cos(angle)) + orth[i]*float32(math.Sin(angle))
+		}
+		id := fmt.Sprintf("vec-%02d", n)
+		ids = append(ids, id)
+		vecs[id] = vec
+	}
+
+	for _, enc := range []VectorEncoding{VectorFloat32, VectorFloat16, VectorInt8} {
+		t.Run(fmt.Sprintf("encoding-%d", enc), func(t *testing.T) {
+			t.Parallel()
+			dbPath := filepath.Join(t.TempDir(), "test.db")
+
+			db, err := Open(dbPath, WithVectors(vector.NewFlatIndex(dims)), WithVectorStorageEncoding(enc))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			// Insert in reverse so ranking does not follow insertion order.
+			for i := len(ids) - 1; i >= 0; i-- {
+				if err := db.SetVector(ctx, []byte(ids[i]), vecs[ids[i]]); err != nil {
+					t.Fatalf("SetVector() error = %v", err)
+				}
+			}
+			db.Close()
+
+			db, err = Open(dbPath, WithVectors(vector.NewFlatIndex(dims)))
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer db.Close()
+			if err := db.LoadVectors(ctx); err != nil {
+				t.Fatalf("LoadVectors() error = %v", err)
+			}
+			if db.VectorCount() != len(ids) {
+				t.Fatalf("VectorCount() = %d, want %d", db.VectorCount(), len(ids))
+			}
+
+			results, err := db.SearchVectors(ctx, query, len(ids))
+			if err != nil {
+				t.Fatalf("SearchVectors() error = %v", err)
+			}
+			if len(results) != len(ids) {
+				t.Fatalf("SearchVectors() returned %d results, want %d", len(results), len(ids))
+			}
+			for i, r := range results {
+				if string(r.ID) != ids[i] {
+					t.Errorf("result %d = %s, want %s", i, r.ID, ids[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+// VerifyReport is the result of DB.Verify.
+type VerifyReport struct {
+	// Triples is the number of triples checked in the SPO index.
+	Triples int
+	// Missing lists the index entries that a triple in the SPO index should
+	// have but does not.
+	Missing []MissingIndexKey
+}
+
+// MissingIndexKey is an index entry absent from the store.
+type MissingIndexKey struct {
+	// Triple is the triple whose entry is missing.
+	Triple *Triple
+	// Index names the index, such as "pos" or "osp".
+	Index string
+	// Key is the missing key.
+	Key []byte
+}
+
+// OK reports whether Verify found no inconsistencies.
+func (r *VerifyReport) OK() bool {
+	return len(r.Missing) == 0
+}
+
+// Verify checks that the six hexastore indexes agree: it streams the SPO
+// index and, for each triple, looks up its key in the other five indexes.
+// Missing keys are reported rather than returned as an error; the error is
+// only set if the scan itself fails. Use it before trusting a restored or
+// copied database.
+//
+// Example:
+//
+//	report, err := db.Verify(ctx)
+//	if err == nil && !report.OK() {
+//	    log.Printf("%d index entries missing", len(report.Missing))
+//	}
+func (db *DB) Verify(ctx context.Context) (*VerifyReport, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	iter := db.store.NewIterator(spoRange(), nil)
+	defer iter.Release()
+
+	report := &VerifyReport{}
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: verify: %w", err)
+		}
+		report.Triples++
+
+		for _, idx := range index.AllIndexes {
+			if idx == index.IndexSPO {
+				continue
+			}
+			key := index.GenKey(idx, triple)
+			_, err := db.store.Get(key, nil)
+			if err == ErrNotFound {
+				report.Missing = append(report.Missing, MissingIndexKey{
+					Triple: triple,
+					Index:  string(idx),
+					Key:    key,
+				})
+			} else if err != nil {
+				return nil, fmt.Errorf("levelgraph: verify: %w", err)
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("levelgraph: verify: %w", err)
+	}
+
+	return report, nil
+}
+
+// reindexBatchSize is the number of index keys ReindexSecondary writes per
+// batch.
+const reindexBatchSize = 1000
+
+// ReindexSecondary is the repair counterpart to Verify: it streams the SPO
+// index and writes any of the other five index keys that are missing, in
+// batches, returning how many keys were written. Use it after Verify reports
+// missing entries or after importing only SPO keys. Entries in the other
+// indexes without an SPO counterpart are left alone.
+//
+// Example:
+//
+//	if report, err := db.Verify(ctx); err == nil && !report.OK() {
+//	    rewritten, err := db.ReindexSecondary(ctx)
+//	}
+func (db *DB) ReindexSecondary(ctx context.Context) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return 0, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if db.options.ReadOnly {
+		return 0, fmt.Errorf("levelgraph: %w", ErrReadOnly)
+	}
+
+	// Restored index keys can change results read from other indexes
+	if db.queryCache != nil {
+		defer db.queryCache.clear()
+	}
+	if db.adjacency != nil {
+		defer db.adjacency.clear()
+	}
+
+	iter := db.store.NewIterator(spoRange(), nil)
+	defer iter.Release()
+
+	written := 0
+	batch := NewBatch()
+	for iter.Next() {
+		select {
+		case <-ctx.Done():
+			return written, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		triple, err := decodeTripleValue(iter.Value())
+		if err != nil {
+			return written, fmt.Errorf("levelgraph: reindex: %w", err)
+		}
+
+		for _, key := range index.GenKeys(triple) {
+			_, err := db.store.Get(key, nil)
+			if err == nil {
+				continue
+			}
+			if err != ErrNotFound {
+				return written, fmt.Errorf("levelgraph: reindex: %w", err)
+			}
+			// Every index stores the same encoded value as SPO.
+			batch.Put(key, iter.Value())
+		}
+
+		if batch.Len() >= reindexBatchSize {
+			if err := db.writeBatch(batch); err != nil {
+				return written, fmt.Errorf("levelgraph: reindex: %w", err)
+			}
+			written += batch.Len()
+			batch = NewBatch()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return written, fmt.Errorf("levelgraph: reindex: %w", err)
+	}
+
+	if batch.Len() > 0 {
+		if err := db.writeBatch(batch); err != nil {
+			return written, fmt.Errorf("levelgraph: reindex: %w", err)
+		}
+		written += batch.Len()
+	}
+
+	if db.options.Logger != nil {
+		db.options.Logger.Debug("reindex secondary", "written", written)
+	}
+	return written, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+func TestDB_Verify(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	broken := graph.NewTripleFromStrings("bob", "knows", "carol")
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		broken,
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	report, err := db.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() || report.Triples != 2 {
+		t.Fatalf("Verify() = %+v, want 2 consistent triples", report)
+	}
+
+	// Lose one non-SPO entry behind the database's back.
+	key := index.GenKey(index.IndexPOS, broken)
+	if err := db.store.Delete(key, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	report, err = db.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if report.OK() || len(report.Missing) != 1 {
+		t.Fatalf("Verify() missing = %+v, want one entry", report.Missing)
+	}
+	missing := report.Missing[0]
+	if missing.Index != string(index.IndexPOS) || string(missing.Key) != string(key) ||
+		string(missing.Triple.Subject) != "bob" || string(missing.Triple.Object) != "carol" {
+		t.Errorf("Verify() missing = %+v, want pos entry for bob knows carol", missing)
+	}
+}
+
+func TestDB_ReindexSecondary(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	triples := []*graph.Triple{
+		graph.NewTripleFromStrings("alice", "knows", "bob"),
+		graph.NewTripleFromStrings("bob", "knows", "carol"),
+		graph.NewTripleFromStrings("carol", "likes", "alice"),
+	}
+	if err := db.Put(ctx, triples...); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Nothing to do on a consistent database.
+	written, err := db.ReindexSecondary(ctx)
+	if err != nil {
+		t.Fatalf("ReindexSecondary() error = %v", err)
+	}
+	if written != 0 {
+		t.Errorf("ReindexSecondary() = %d, want 0", written)
+	}
+
+	// Drop every POS key, which predicate queries rely on.
+	for _, triple := range triples {
+		if err := db.store.Delete(index.GenKey(index.IndexPOS, triple), nil); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	}
+	knows := &graph.Pattern{Predicate: graph.ExactString("knows")}
+	if results, err := db.Get(ctx, knows); err != nil || len(results) != 0 {
+		t.Fatalf("Get() before repair = %v, %v, want no results", results, err)
+	}
+
+	written, err = db.ReindexSecondary(ctx)
+	if err != nil {
+		t.Fatalf("ReindexSecondary() error = %v", err)
+	}
+	if written != len(triples) {
+		t.Errorf("ReindexSecondary() = %d, want %d", written, len(triples))
+	}
+
+	results, err := db.Get(ctx, knows)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Get() after repair = %v, want 2 triples", results)
+	}
+	report, err := db.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Verify() after repair missing = %+v", report.Missing)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// FormatVersion is the version of the on-disk encoding of indexes, journal
+// entries, facets and vectors written by this package. It is stored in the
+// database on first Open and checked on every later Open.
+//
+// Version 2 added compressed triple values (WithValueCompression) and
+// reduced-precision vector blobs (WithVectorStorageEncoding). Version 1
+// databases contain neither and are upgraded by restamping them.
+const FormatVersion = 2
+
+var (
+	// formatVersionKey holds the database's format version
+	formatVersionKey = []byte("meta::format_version")
+
+	// ErrVersionMismatch is returned by Open when the database was written in
+	// a different format version and no migration brings it up to date.
+	ErrVersionMismatch = errors.New("levelgraph: format version mismatch")
+)
+
+// compatibleVersions lists older format versions whose data is already
+// valid in the next version. Open upgrades them without a registered
+// migration, and reads them as-is when the database is read-only.
+var compatibleVersions = map[int]bool{1: true}
+
+// MigrationFunc upgrades the raw contents of a store by one format version.
+// See WithMigrations.
+type MigrationFunc func(store KVStore) error
+
+// checkFormatVersion compares the stored format version with FormatVersion,
+// running migrations for older databases. A database without a version
+// marker predates versioning and is upgraded like any other version 1
+// database.
+func (db *DB) checkFormatVersion() error {
+	version := 1
+	value, err := db.store.Get(formatVersionKey, nil)
+	switch {
+	case err == nil:
+		version, err = strconv.Atoi(string(value))
+		if err != nil {
+			return fmt.Errorf("%w: format version %q", ErrCorrupted, value)
+		}
+	case errors.Is(err, ErrNotFound):
+		if db.options.ReadOnly {
+			return nil
+		}
+	default:
+		return fmt.Errorf("levelgraph: read format version: %w", err)
+	}
+
+	if version > FormatVersion {
+		return fmt.Errorf("%w: database is version %d, newer than supported version %d",
+			ErrVersionMismatch, version, FormatVersion)
+	}
+
+	for ; version < FormatVersion; version++ {
+		if compatibleVersions[version] {
+			if db.options.ReadOnly {
+				continue
+			}
+			if err := db.writeFormatVersion(version + 1); err != nil {
+				return err
+			}
+			continue
+		}
+		migrate, ok := db.options.Migrations[version]
+		if !ok {
+			return fmt.Errorf("%w: database is version %d, want %d, and no migration from version %d is registered",
+				ErrVersionMismatch, version, FormatVersion, version)
+		}
+		if db.options.ReadOnly {
+			return fmt.Errorf("%w: database is version %d, want %d, and cannot be migrated read-only",
+				ErrVersionMismatch, version, FormatVersion)
+		}
+		if err := migrate(db.store); err != nil {
+			return fmt.Errorf("levelgraph: migrate from format version %d: %w", version, err)
+		}
+		if err := db.writeFormatVersion(version + 1); err != nil {
+			return err
+		}
+		if db.options.Logger != nil {
+			db.options.Logger.Info("database migrated", "from", version, "to", version+1)
+		}
+	}
+	return nil
+}
+
+// writeFormatVersion stores version as the database's format version.
+func (db *DB) writeFormatVersion(version int) error {
+	if err := db.store.Put(formatVersionKey, []byte(strconv.Itoa(version)), nil); err != nil {
+		return fmt.Errorf("levelgraph: write format version: %w", err)
+	}
+	return nil
+}
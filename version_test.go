@@ -0,0 +1,161 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// stampFormatVersion writes a raw format version marker to the LevelDB at path.
+func stampFormatVersion(t *testing.T, path string, version int) {
+	t.Helper()
+	ldb, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		t.Fatalf("leveldb.OpenFile() error = %v", err)
+	}
+	if err := ldb.Put(formatVersionKey, []byte(strconv.Itoa(version)), nil); err != nil {
+		t.Fatalf("Put(version) error = %v", err)
+	}
+	ldb.Close()
+}
+
+func TestDB_FormatVersion(t *testing.T) {
+	t.Parallel()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	ctx := context.Background()
+
+	// A new database is stamped with the current version.
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	value, err := db.store.Get(formatVersionKey, nil)
+	if err != nil || string(value) != strconv.Itoa(FormatVersion) {
+		t.Errorf("stored version = %q, %v, want %d", value, err, FormatVersion)
+	}
+	db.Close()
+
+	// An older, incompatible database cannot be opened without a migration.
+	// Version 0 stands in for such a version.
+	stampFormatVersion(t, dbPath, 0)
+	if _, err := Open(dbPath); !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("Open() old version error = %v, want ErrVersionMismatch", err)
+	}
+
+	// With a migration, it is upgraded and the marker updated.
+	migrated := false
+	db, err = Open(dbPath, WithMigrations(map[int]MigrationFunc{
+		0: func(store KVStore) error {
+			migrated = true
+			return store.Put([]byte("migration::marker"), []byte("done"), nil)
+		},
+	}))
+	if err != nil {
+		t.Fatalf("Open() with migration error = %v", err)
+	}
+	if !migrated {
+		t.Error("migration was not run")
+	}
+	value, err = db.store.Get(formatVersionKey, nil)
+	if err != nil || string(value) != strconv.Itoa(FormatVersion) {
+		t.Errorf("stored version after migration = %q, %v, want %d", value, err, FormatVersion)
+	}
+	results, err := db.Get(ctx, &graph.Pattern{Subject: ExactString("alice")})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Get() after migration = %v, %v, want 1 triple", results, err)
+	}
+	db.Close()
+
+	// A failing migration is reported and leaves the version unchanged.
+	stampFormatVersion(t, dbPath, 0)
+	failure := errors.New("boom")
+	_, err = Open(dbPath, WithMigrations(map[int]MigrationFunc{
+		0: func(KVStore) error { return failure },
+	}))
+	if !errors.Is(err, failure) {
+		t.Errorf("Open() failing migration error = %v, want %v", err, failure)
+	}
+
+	// A newer database is always rejected.
+	stampFormatVersion(t, dbPath, FormatVersion+1)
+	if _, err := Open(dbPath); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("Open() newer version error = %v, want ErrVersionMismatch", err)
+	}
+}
+
+func TestDB_FormatVersionCompatible(t *testing.T) {
+	t.Parallel()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	ctx := context.Background()
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := db.Put(ctx, graph.NewTripleFromStrings("alice", "knows", "bob")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	db.Close()
+
+	// A version 1 database is read as-is when read-only...
+	stampFormatVersion(t, dbPath, 1)
+	db, err = Open(dbPath, WithReadOnly())
+	if err != nil {
+		t.Fatalf("Open(read-only) version 1 error = %v", err)
+	}
+	value, err := db.store.Get(formatVersionKey, nil)
+	if err != nil || string(value) != "1" {
+		t.Errorf("stored version after read-only open = %q, %v, want 1", value, err)
+	}
+	db.Close()
+
+	// ...and restamped without a migration otherwise, so that binaries
+	// that only know version 1 refuse the compressed values and vector
+	// encodings version 2 may add.
+	db, err = Open(dbPath, WithValueCompression(16))
+	if err != nil {
+		t.Fatalf("Open() version 1 error = %v", err)
+	}
+	value, err = db.store.Get(formatVersionKey, nil)
+	if err != nil || string(value) != strconv.Itoa(FormatVersion) {
+		t.Errorf("stored version after upgrade = %q, %v, want %d", value, err, FormatVersion)
+	}
+	results, err := db.Get(ctx, &graph.Pattern{Subject: ExactString("alice")})
+	if err != nil || len(results) != 1 {
+		t.Errorf("Get() after upgrade = %v, %v, want 1 triple", results, err)
+	}
+	db.Close()
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+	"github.com/benbenbenbenbenben/levelgraph/pkg/index"
+)
+
+var (
+	// tripleVersionPrefix is the prefix for the version WithVersioning
+	// stamps on each triple. Versions are kept apart from facets so they
+	// never show up as facets or get collected by GC.
+	tripleVersionPrefix = []byte("version::triple::")
+
+	// pairVersionPrefix is the prefix for the last version WithVersioning
+	// issued for each (subject, predicate) pair.
+	pairVersionPrefix = []byte("version::pair::")
+)
+
+// genTripleVersionKey generates the version key for a triple.
+// Format: version::triple::<subject>::<predicate>::<object>
+func genTripleVersionKey(triple *graph.Triple) []byte {
+	var buf bytes.Buffer
+	buf.Write(tripleVersionPrefix)
+	buf.Write(index.Escape(triple.Subject))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(triple.Predicate))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(triple.Object))
+	return buf.Bytes()
+}
+
+// genPairVersionKey generates the version counter key for a pair.
+// Format: version::pair::<subject>::<predicate>
+func genPairVersionKey(subject, predicate []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(pairVersionPrefix)
+	buf.Write(index.Escape(subject))
+	buf.Write(index.KeySeparator)
+	buf.Write(index.Escape(predicate))
+	return buf.Bytes()
+}
+
+// recordVersion stamps triple with the next version of its (subject,
+// predicate) pair, adding the triple's version and the pair's counter to
+// batch. issued holds the versions already issued in this batch, by pair
+// version key.
+// Caller must hold versionMu.
+func (db *DB) recordVersion(batch *Batch, triple *graph.Triple, issued map[string]int64) error {
+	pairKey := genPairVersionKey(triple.Subject, triple.Predicate)
+	last, ok := issued[string(pairKey)]
+	if !ok {
+		value, err := db.store.Get(pairKey, nil)
+		switch {
+		case err == nil:
+			last, _ = graph.DecodeInt(value)
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+	}
+	version := last + 1
+	issued[string(pairKey)] = version
+
+	encoded := graph.EncodeInt(version)
+	batch.Put(pairKey, encoded)
+	batch.Put(genTripleVersionKey(triple), encoded)
+	return nil
+}
+
+// tripleVersion returns the version WithVersioning stored for triple, or 0
+// if it has none.
+// Caller must hold at least a read lock.
+func (db *DB) tripleVersion(triple *graph.Triple) (int64, error) {
+	value, err := db.store.Get(genTripleVersionKey(triple), nil)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	version, _ := graph.DecodeInt(value)
+	return version, nil
+}
+
+// GetLatest is Get for versioned facts (see WithVersioning): of the triples
+// matching pattern, it returns for each (subject, predicate) pair only the
+// one with the highest version, that is the one put most recently. Triples
+// without a version, such as those stored before versioning was enabled,
+// count as version 0, and of equal versions the first in Get order wins.
+// Results keep Get's order; Offset and Limit apply after
+// the older versions are dropped, so every match is read first.
+//
+// Example:
+//
+//	db.Put(ctx, levelgraph.NewTripleFromStrings("alice", "livesIn", "Paris"))
+//	db.Put(ctx, levelgraph.NewTripleFromStrings("alice", "livesIn", "Berlin"))
+//	latest, _ := db.GetLatest(ctx, levelgraph.NewPattern("alice", "livesIn", nil))
+//	// latest holds only "alice livesIn Berlin"
+func (db *DB) GetLatest(ctx context.Context, pattern *graph.Pattern) ([]*graph.Triple, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+	default:
+	}
+
+	all := *pattern
+	all.Offset, all.Limit = 0, 0
+	iter, err := db.getIteratorUnlocked(&all, "")
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+	iter.limit = 0 // DefaultLimit applies to the latest versions
+
+	type latest struct {
+		at      int
+		version int64
+	}
+	byPair := make(map[string]latest)
+	var results []*graph.Triple
+	for iter.Next() {
+		triple, err := iter.Triple()
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: parse triple: %w", err)
+		}
+		version, err := db.tripleVersion(triple)
+		if err != nil {
+			return nil, fmt.Errorf("levelgraph: read version: %w", err)
+		}
+		pair := string(genPairFacetPrefix(triple.Subject, triple.Predicate))
+		prev, seen := byPair[pair]
+		switch {
+		case !seen:
+			byPair[pair] = latest{at: len(results), version: version}
+			results = append(results, triple)
+		case version > prev.version:
+			results[prev.at] = nil
+			byPair[pair] = latest{at: len(results), version: version}
+			results = append(results, triple)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	results = slices.DeleteFunc(results, func(t *graph.Triple) bool { return t == nil })
+	results = results[min(max(pattern.Offset, 0), len(results)):]
+	limit := pattern.Limit
+	if limit <= 0 && db.options.DefaultLimit > 0 {
+		limit = db.options.DefaultLimit
+	}
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+func TestDB_Versioning(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithVersioning(), WithFacets())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	put := func(s, p, o string) {
+		t.Helper()
+		if err := db.Put(ctx, graph.NewTripleFromStrings(s, p, o)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	latest := func(pattern *Pattern) []string {
+		t.Helper()
+		triples, err := db.GetLatest(ctx, pattern)
+		if err != nil {
+			t.Fatalf("GetLatest() error = %v", err)
+		}
+		var objects []string
+		for _, triple := range triples {
+			objects = append(objects, string(triple.Subject)+" "+string(triple.Object))
+		}
+		return objects
+	}
+
+	put("alice", "livesIn", "Paris")
+	put("alice", "livesIn", "London")
+	put("alice", "livesIn", "Berlin")
+	put("bob", "livesIn", "Rome")
+
+	// Get returns every version; GetLatest only the newest per pair.
+	all, err := db.Get(ctx, graph.NewPattern("alice", "livesIn", nil))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Get() returned %d versions, want 3", len(all))
+	}
+	if got := latest(graph.NewPattern("alice", "livesIn", nil)); len(got) != 1 || got[0] != "alice Berlin" {
+		t.Errorf("GetLatest(alice) = %v, want [alice Berlin]", got)
+	}
+	if got := latest(graph.NewPattern(nil, "livesIn", nil)); len(got) != 2 || got[0] != "alice Berlin" || got[1] != "bob Rome" {
+		t.Errorf("GetLatest(livesIn) = %v, want [alice Berlin bob Rome]", got)
+	}
+
+	// The version is kept apart from the triple's facets.
+	berlin := graph.NewTripleFromStrings("alice", "livesIn", "Berlin")
+	if n, err := db.tripleVersion(berlin); err != nil || n != 3 {
+		t.Errorf("Berlin version = %d, %v, want 3", n, err)
+	}
+	facets, err := db.GetTripleFacets(ctx, berlin)
+	if err != nil {
+		t.Fatalf("GetTripleFacets() error = %v", err)
+	}
+	if len(facets) != 0 {
+		t.Errorf("GetTripleFacets() = %v, want none", facets)
+	}
+	pairFacets, err := db.GetPairFacets(ctx, []byte("alice"), []byte("livesIn"))
+	if err != nil {
+		t.Fatalf("GetPairFacets() error = %v", err)
+	}
+	if len(pairFacets) != 0 {
+		t.Errorf("GetPairFacets() = %v, want none", pairFacets)
+	}
+
+	// Putting an old version again makes it the latest.
+	put("alice", "livesIn", "Paris")
+	if got := latest(graph.NewPattern("alice", "livesIn", nil)); len(got) != 1 || got[0] != "alice Paris" {
+		t.Errorf("GetLatest() after re-put = %v, want [alice Paris]", got)
+	}
+
+	// Deleting the latest version falls back to the next newest.
+	if err := db.Del(ctx, graph.NewTripleFromStrings("alice", "livesIn", "Paris")); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	if got := latest(graph.NewPattern("alice", "livesIn", nil)); len(got) != 1 || got[0] != "alice Berlin" {
+		t.Errorf("GetLatest() after Del = %v, want [alice Berlin]", got)
+	}
+	if n, err := db.tripleVersion(graph.NewTripleFromStrings("alice", "livesIn", "Paris")); err != nil || n != 0 {
+		t.Errorf("Paris version after Del = %d, %v, want 0", n, err)
+	}
+
+	// Versions in one Put follow the argument order.
+	if err := db.Put(ctx,
+		graph.NewTripleFromStrings("carol", "livesIn", "Oslo"),
+		graph.NewTripleFromStrings("carol", "livesIn", "Lima"),
+	); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := latest(graph.NewPattern("carol", "livesIn", nil)); len(got) != 1 || got[0] != "carol Lima" {
+		t.Errorf("GetLatest(carol) = %v, want [carol Lima]", got)
+	}
+
+	// Limit applies to the latest versions.
+	limited := graph.NewPattern(nil, "livesIn", nil)
+	limited.Limit = 2
+	if got := latest(limited); len(got) != 2 {
+		t.Errorf("GetLatest(limit 2) = %v, want 2 results", got)
+	}
+}
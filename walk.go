@@ -0,0 +1,208 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/benbenbenbenbenben/levelgraph/pkg/graph"
+)
+
+// Follow starts at start and follows each predicate in turn, one hop per
+// predicate, and returns the distinct nodes reached by the last hop, in
+// the order they were first found. Every hop fans out to all objects of
+// the current nodes, so Follow(ctx, alice, knows, knows) returns the
+// friends of alice's friends. A nil predicate follows edges with any
+// predicate. With no predicates the result is start itself; when a hop
+// reaches no nodes the result is empty.
+//
+// Follow is a shorthand for fixed-depth chains with one predicate per hop.
+// Use Navigator for anything else, such as incoming edges or conditions:
+//
+//	names, err := db.Follow(ctx, []byte("alice"), []byte("bestFriend"), []byte("bestFriend"), []byte("name"))
+func (db *DB) Follow(ctx context.Context, start []byte, predicates ...[]byte) ([][]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if len(start) == 0 {
+		return nil, fmt.Errorf("levelgraph: %w", ErrInvalidTriple)
+	}
+
+	nodes := [][]byte{start}
+	for _, predicate := range predicates {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		seen := make(map[string]bool)
+		var next [][]byte
+		for _, node := range nodes {
+			edges, err := db.getUnlocked(graph.NewPattern(node, predicate, nil), "")
+			if err != nil {
+				return nil, err
+			}
+			for _, edge := range edges {
+				if !seen[string(edge.Object)] {
+					seen[string(edge.Object)] = true
+					next = append(next, edge.Object)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return [][]byte{}, nil
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// RandomWalk walks the graph from start for up to length steps, following
+// outgoing edges with the given predicate (any predicate when predicate is
+// nil), and returns the nodes visited, starting with start. At each step
+// one outgoing edge is picked at random: uniformly, or in proportion to the
+// edge weights when WithWalkWeightFacet is set. The walk stops early at a
+// node with no outgoing edges to take.
+//
+// Walks are reproducible for a given graph and seed, which suits
+// node2vec-style embedding pipelines and tests:
+//
+//	walk, err := db.RandomWalk(ctx, []byte("alice"), []byte("knows"), 10, rand.New(rand.NewSource(1)))
+//
+// A nil rng uses the math/rand default source.
+func (db *DB) RandomWalk(ctx context.Context, start []byte, predicate []byte, length int, rng *rand.Rand) ([][]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, fmt.Errorf("levelgraph: %w", ErrClosed)
+	}
+	if len(start) == 0 {
+		return nil, fmt.Errorf("levelgraph: %w", ErrInvalidTriple)
+	}
+
+	pick := rand.Intn
+	pickFloat := rand.Float64
+	if rng != nil {
+		pick = rng.Intn
+		pickFloat = rng.Float64
+	}
+
+	walk := [][]byte{start}
+	current := start
+	for step := 0; step < length; step++ {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("levelgraph: %w", ctx.Err())
+		default:
+		}
+
+		edges, err := db.getUnlocked(graph.NewPattern(current, predicate, nil), "")
+		if err != nil {
+			return nil, err
+		}
+		if len(edges) == 0 {
+			break
+		}
+
+		var next *graph.Triple
+		if db.options.WalkWeightFacet == "" {
+			next = edges[pick(len(edges))]
+		} else {
+			next, err = db.pickWeightedEdge(edges, pickFloat)
+			if err != nil {
+				return nil, err
+			}
+			if next == nil {
+				break
+			}
+		}
+		current = next.Object
+		walk = append(walk, current)
+	}
+	return walk, nil
+}
+
+// pickWeightedEdge picks one of edges with probability proportional to its
+// WalkWeightFacet weight, or returns nil if no edge has a positive weight.
+// Caller must hold at least a read lock.
+func (db *DB) pickWeightedEdge(edges []*graph.Triple, random func() float64) (*graph.Triple, error) {
+	key := []byte(db.options.WalkWeightFacet)
+	weights := make([]float64, len(edges))
+	total := 0.0
+	for i, edge := range edges {
+		weights[i] = 1
+		value, err := db.store.Get(genTripleFacetKey(edge, key), nil)
+		if err == nil {
+			var ok bool
+			if weights[i], ok = decodeWalkWeight(value); !ok {
+				return nil, fmt.Errorf("levelgraph: walk weight of %s: %w", edge, ErrCorrupted)
+			}
+		} else if err != ErrNotFound {
+			return nil, fmt.Errorf("levelgraph: %w", err)
+		}
+		if weights[i] > 0 {
+			total += weights[i]
+		}
+	}
+	if total <= 0 {
+		return nil, nil
+	}
+
+	target := random() * total
+	var last *graph.Triple
+	for i, edge := range edges {
+		if weights[i] <= 0 {
+			continue
+		}
+		last = edge
+		target -= weights[i]
+		if target < 0 {
+			return edge, nil
+		}
+	}
+	// Rounding can leave target just above zero; take the last candidate
+	return last, nil
+}
+
+// decodeWalkWeight returns the number held by a walk weight facet value,
+// stored either with EncodeInt or EncodeFloat or as plain decimal text.
+func decodeWalkWeight(value []byte) (float64, bool) {
+	if i, ok := graph.DecodeInt(value); ok {
+		return float64(i), true
+	}
+	if f, ok := graph.DecodeFloat(value); ok {
+		return f, true
+	}
+	f, err := strconv.ParseFloat(string(value), 64)
+	return f, err == nil
+}
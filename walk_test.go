@@ -0,0 +1,198 @@
+// Copyright (c) 2013-2024 Matteo Collina and LevelGraph Contributors
+// Copyright (c) 2024 LevelGraph Go Contributors
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use,
+// copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following
+// conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES
+// OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT
+// HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package levelgraph
+
+import (
+	"context"
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDB_RandomWalk(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	edges := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+		"c": {"a", "d"},
+	}
+	for from, tos := range edges {
+		for _, to := range tos {
+			if err := db.Put(ctx, NewTripleFromStrings(from, "link", to)); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+		}
+	}
+	if err := db.Put(ctx, NewTripleFromStrings("a", "label", "start")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// Seeded walks are reproducible and stop early at the dead end d
+	walkStrings := func(walk [][]byte) []string {
+		nodes := make([]string, len(walk))
+		for i, node := range walk {
+			nodes[i] = string(node)
+		}
+		return nodes
+	}
+	want := []string{"a", "b", "c", "a", "b", "c", "d"}
+	for range 2 {
+		walk, err := db.RandomWalk(ctx, []byte("a"), []byte("link"), 8, rand.New(rand.NewSource(3)))
+		if err != nil {
+			t.Fatalf("RandomWalk() error = %v", err)
+		}
+		if got := walkStrings(walk); !slices.Equal(got, want) {
+			t.Errorf("RandomWalk() = %q, want %q", got, want)
+		}
+	}
+
+	walk, err := db.RandomWalk(ctx, []byte("a"), []byte("link"), 2, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatalf("RandomWalk() error = %v", err)
+	}
+	if got := walkStrings(walk); !slices.Equal(got, want[:3]) {
+		t.Errorf("RandomWalk(length 2) = %q, want %q", got, want[:3])
+	}
+
+	// A nil predicate follows edges with any predicate
+	walk, err = db.RandomWalk(ctx, []byte("b"), nil, 3, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("RandomWalk(nil predicate) error = %v", err)
+	}
+	if len(walk) < 2 || string(walk[1]) != "c" {
+		t.Errorf("RandomWalk(nil predicate) = %q, want b then c", walk)
+	}
+
+	walk, err = db.RandomWalk(ctx, []byte("d"), []byte("link"), 5, nil)
+	if err != nil {
+		t.Fatalf("RandomWalk(dead end) error = %v", err)
+	}
+	if len(walk) != 1 || string(walk[0]) != "d" {
+		t.Errorf("RandomWalk(dead end) = %q, want [d]", walk)
+	}
+}
+
+func TestDB_RandomWalkWeighted(t *testing.T) {
+	t.Parallel()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"), WithFacets(), WithWalkWeightFacet("weight"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	never := NewTripleFromStrings("a", "link", "b")
+	always := NewTripleFromStrings("a", "link", "c")
+	back := NewTripleFromStrings("c", "link", "a")
+	if err := db.Put(ctx, never, always, back); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// Weights may be typed values or plain decimal text
+	if err := db.SetTripleFacet(ctx, never, []byte("weight"), EncodeInt(0)); err != nil {
+		t.Fatalf("SetTripleFacet() error = %v", err)
+	}
+	if err := db.SetTripleFacet(ctx, always, []byte("weight"), EncodeFloat(2.5)); err != nil {
+		t.Fatalf("SetTripleFacet() error = %v", err)
+	}
+
+	walk, err := db.RandomWalk(ctx, []byte("a"), []byte("link"), 6, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatalf("RandomWalk() error = %v", err)
+	}
+	want := []string{"a", "c", "a", "c", "a", "c", "a"}
+	got := make([]string, len(walk))
+	for i, node := range walk {
+		got[i] = string(node)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("RandomWalk() = %q, want %q", got, want)
+	}
+
+	// With every edge weighing nothing, a is a dead end
+	if err := db.SetTripleFacet(ctx, always, []byte("weight"), []byte("0")); err != nil {
+		t.Fatalf("SetTripleFacet() error = %v", err)
+	}
+	walk, err = db.RandomWalk(ctx, []byte("a"), []byte("link"), 6, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Fatalf("RandomWalk() error = %v", err)
+	}
+	if len(walk) != 1 {
+		t.Errorf("RandomWalk() = %q, want only the start", walk)
+	}
+}
+
+func TestDB_Follow(t *testing.T) {
+	t.Parallel()
+	db, cleanup := setupSocialGraph(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	got, err := db.Follow(ctx, []byte("alice"), []byte("knows"), []byte("knows"))
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+	want, err := db.Nav(ctx, []byte("alice")).ArchOut("knows").ArchOut("knows").Values()
+	if err != nil {
+		t.Fatalf("Values() error = %v", err)
+	}
+	sortValues := func(values [][]byte) []string {
+		names := make([]string, len(values))
+		for i, v := range values {
+			names[i] = string(v)
+		}
+		slices.Sort(names)
+		return names
+	}
+	if !slices.Equal(sortValues(got), sortValues(want)) {
+		t.Errorf("Follow() = %q, want Navigator result %q", got, want)
+	}
+	if !slices.Equal(sortValues(got), []string{"charlie", "diana"}) {
+		t.Errorf("Follow() = %q, want [charlie diana]", got)
+	}
+
+	tests := []struct {
+		name       string
+		predicates [][]byte
+		want       []string
+	}{
+		{"no predicates", nil, []string{"alice"}},
+		{"mixed predicates", [][]byte{[]byte("knows"), []byte("likes")}, []string{"coding", "hiking", "music", "photography"}},
+		{"dead end", [][]byte{[]byte("knows"), []byte("unknown"), []byte("knows")}, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := db.Follow(ctx, []byte("alice"), tt.predicates...)
+		if err != nil {
+			t.Fatalf("%s: Follow() error = %v", tt.name, err)
+		}
+		if !slices.Equal(sortValues(got), tt.want) {
+			t.Errorf("%s: Follow() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}